@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// GoldenSet is a list of queries with judged relevant SKUs:
+//
+//	{"tenant": "heybabes", "queries": [
+//	  {"query": "крем для сухой кожи", "filters": {"skin_types": ["dry"]}, "relevant": {"SKU-1": 3, "SKU-2": 1}},
+//	  {"query": "сыворотка с ниацинамидом", "relevant": ["SKU-3", "SKU-4"]}
+//	]}
+type GoldenSet struct {
+	Tenant  string        `json:"tenant,omitempty"`
+	Queries []GoldenQuery `json:"queries"`
+}
+
+// GoldenQuery is one judged query. VectorQuery and Filters stand in for what Agent 1
+// would pass to catalog_search; VectorQuery defaults to Query.
+type GoldenQuery struct {
+	Query       string                 `json:"query"`
+	VectorQuery string                 `json:"vector_query,omitempty"`
+	Filters     map[string]interface{} `json:"filters,omitempty"`
+	Relevant    Judgments              `json:"relevant"`
+}
+
+// toolInput builds catalog_search input for the query
+func (q GoldenQuery) toolInput(k int) map[string]interface{} {
+	vq := q.VectorQuery
+	if vq == "" {
+		vq = q.Query
+	}
+	input := map[string]interface{}{
+		"vector_query": vq,
+		"entity_type":  "product",
+		"limit":        float64(k),
+	}
+	if len(q.Filters) > 0 {
+		input["filters"] = q.Filters
+	}
+	return input
+}
+
+// Judgments maps SKU → relevance grade (≥1). JSON accepts a grade object
+// or a plain SKU list (every SKU graded 1).
+type Judgments map[string]int
+
+// UnmarshalJSON implements json.Unmarshaler
+func (j *Judgments) UnmarshalJSON(data []byte) error {
+	var graded map[string]int
+	if err := json.Unmarshal(data, &graded); err == nil {
+		*j = graded
+		return nil
+	}
+	var skus []string
+	if err := json.Unmarshal(data, &skus); err != nil {
+		return fmt.Errorf("relevant: want {\"sku\": grade} or [\"sku\", ...]")
+	}
+	*j = make(Judgments, len(skus))
+	for _, sku := range skus {
+		(*j)[sku] = 1
+	}
+	return nil
+}
+
+// Run is a saved evaluation: the baseline file format
+type Run struct {
+	Tenant    string        `json:"tenant"`
+	Golden    string        `json:"golden"`
+	K         int           `json:"k"`
+	CreatedAt time.Time     `json:"createdAt"`
+	Mean      Metrics       `json:"mean"`             // over queries that returned results
+	Errors    int           `json:"errors,omitempty"` // queries the search failed on
+	Queries   []QueryResult `json:"queries"`
+}
+
+// QueryResult is the outcome of one golden query
+type QueryResult struct {
+	Query string `json:"query"`
+	Metrics
+	Ranked    []string `json:"ranked"` // top-k SKUs as returned
+	LatencyMs int64    `json:"latencyMs"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// MetricDelta is a metric in the baseline and in the current run
+type MetricDelta struct {
+	Metric   string
+	Query    string
+	Baseline float64
+	Run      float64
+}
+
+// Comparison of a run against a baseline
+type Comparison struct {
+	Mean        []MetricDelta
+	Queries     []MetricDelta // queries whose nDCG dropped by more than the tolerance
+	Regressions []string      // mean metrics that dropped by more than the tolerance
+	Warnings    []string
+}
+
+// compareRuns flags every mean metric that dropped by more than tolerance.
+// Per-query nDCG drops are reported but do not fail the run on their own.
+func compareRuns(baseline, run *Run, tolerance float64) Comparison {
+	var cmp Comparison
+	if baseline.K != run.K {
+		cmp.Warnings = append(cmp.Warnings, fmt.Sprintf("baseline k=%d, run k=%d: metrics are not comparable", baseline.K, run.K))
+	}
+	if baseline.Tenant != run.Tenant {
+		cmp.Warnings = append(cmp.Warnings, fmt.Sprintf("baseline tenant %s, run tenant %s", baseline.Tenant, run.Tenant))
+	}
+
+	for _, d := range []MetricDelta{
+		{Metric: "recall", Baseline: baseline.Mean.Recall, Run: run.Mean.Recall},
+		{Metric: "mrr", Baseline: baseline.Mean.MRR, Run: run.Mean.MRR},
+		{Metric: "ndcg", Baseline: baseline.Mean.NDCG, Run: run.Mean.NDCG},
+	} {
+		cmp.Mean = append(cmp.Mean, d)
+		if d.Baseline-d.Run > tolerance {
+			cmp.Regressions = append(cmp.Regressions, fmt.Sprintf("%s %.3f → %.3f (%+.3f)", d.Metric, d.Baseline, d.Run, d.Run-d.Baseline))
+		}
+	}
+
+	before := make(map[string]float64, len(baseline.Queries))
+	for _, q := range baseline.Queries {
+		before[q.Query] = q.NDCG
+	}
+	for _, q := range run.Queries {
+		if b, ok := before[q.Query]; ok && b-q.NDCG > tolerance {
+			cmp.Queries = append(cmp.Queries, MetricDelta{Metric: "ndcg", Query: q.Query, Baseline: b, Run: q.NDCG})
+		}
+	}
+	return cmp
+}
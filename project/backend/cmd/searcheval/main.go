@@ -0,0 +1,253 @@
+// Command searcheval measures catalog search relevance offline.
+//
+// It runs CatalogSearchTool directly (no LLM agents) against the configured DB
+// for every query of a golden set, reports recall@k, MRR and nDCG@k, and
+// optionally compares the run with a saved baseline:
+//
+//	go run ./cmd/searcheval -tenant heybabes -golden golden.json \
+//	    -baseline baseline.json -save run.json
+//
+// Exit codes: 0 ok, 1 regression against the baseline, 2 usage or runtime error
+// (including any golden query the search failed on).
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+	"keepstar/internal/adapters/localembed"
+	openaiAdapter "keepstar/internal/adapters/openai"
+	"keepstar/internal/adapters/postgres"
+	"keepstar/internal/adapters/rerank"
+	"keepstar/internal/config"
+	"keepstar/internal/ports"
+	"keepstar/internal/tools"
+)
+
+var (
+	flagTenant    = flag.String("tenant", "", "Tenant slug (default: golden set tenant, then TENANT_SLUG)")
+	flagGolden    = flag.String("golden", "", "Golden set JSON file (required)")
+	flagK         = flag.Int("k", 10, "Cut-off for recall@k and nDCG@k")
+	flagBaseline  = flag.String("baseline", "", "Saved run to compare against")
+	flagSave      = flag.String("save", "", "Write this run as JSON (e.g. the next baseline)")
+	flagTolerance = flag.Float64("tolerance", 0.01, "Allowed drop of a mean metric before it counts as a regression")
+	flagTimeout   = flag.Duration("timeout", 30*time.Second, "Timeout per query")
+)
+
+func main() {
+	flag.Parse()
+	_ = godotenv.Load("../.env")
+	_ = godotenv.Load(".env")
+
+	if *flagGolden == "" || *flagK <= 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	golden, err := loadGoldenSet(*flagGolden)
+	if err != nil {
+		fatalf("load golden set: %v", err)
+	}
+
+	cfg := config.Load()
+	tenant := *flagTenant
+	if tenant == "" {
+		tenant = golden.Tenant
+	}
+	if tenant == "" {
+		tenant = cfg.TenantSlug
+	}
+	if !cfg.HasDatabase() {
+		fatalf("DATABASE_URL is required")
+	}
+
+	ctx := context.Background()
+	dbClient, err := postgres.NewClient(ctx, cfg.DatabaseURL)
+	if err != nil {
+		fatalf("connect to database: %v", err)
+	}
+	defer dbClient.Close()
+
+	// Same embedding provider as the server: query vectors must match stored product vectors
+	var embedding ports.EmbeddingPort
+	switch {
+	case cfg.UsesLocalEmbeddings():
+		embedding = localembed.NewHashEmbedder(384)
+	case cfg.HasEmbeddings():
+		embedding = openaiAdapter.NewEmbeddingClient(cfg.OpenAIAPIKey, cfg.EmbeddingModel, 384)
+	default:
+		fmt.Fprintln(os.Stderr, "warning: no embedding provider configured, evaluating keyword search only")
+	}
+
+	// LLM reranking is left out on purpose: runs must be reproducible and free of LLM calls
+	state := newScratchState()
+	search := tools.NewCatalogSearchTool(state, postgres.NewCatalogAdapter(dbClient), embedding).
+		WithRerankers(cfg.RerankStrategy, rerank.NewFeatureReranker()).
+		WithStockPolicy(cfg.StockPolicy)
+
+	run := evaluate(ctx, search, state, tenant, golden, *flagGolden, *flagK, *flagTimeout)
+	printRun(run)
+	// A run with failed queries is neither a valid baseline nor comparable to one
+	if run.Errors > 0 {
+		fatalf("%d of %d queries failed", run.Errors, len(run.Queries))
+	}
+
+	if *flagSave != "" {
+		if err := saveRun(*flagSave, run); err != nil {
+			fatalf("save run: %v", err)
+		}
+		fmt.Printf("\nsaved run to %s\n", *flagSave)
+	}
+
+	if *flagBaseline != "" {
+		baseline, err := loadRun(*flagBaseline)
+		if err != nil {
+			fatalf("load baseline: %v", err)
+		}
+		cmp := compareRuns(baseline, run, *flagTolerance)
+		printComparison(*flagBaseline, baseline, cmp)
+		if len(cmp.Regressions) > 0 {
+			os.Exit(1)
+		}
+	}
+}
+
+// evaluate runs every golden query through catalog_search and scores the top k SKUs
+func evaluate(ctx context.Context, search *tools.CatalogSearchTool, state *scratchState, tenant string, golden *GoldenSet, goldenPath string, k int, timeout time.Duration) *Run {
+	run := &Run{Tenant: tenant, Golden: goldenPath, K: k, CreatedAt: time.Now().UTC()}
+
+	for i, q := range golden.Queries {
+		sessionID := fmt.Sprintf("searcheval-%d", i)
+		result := QueryResult{Query: q.Query}
+
+		qctx, cancel := context.WithTimeout(ctx, timeout)
+		start := time.Now()
+		res, err := search.Execute(qctx, tools.ToolContext{
+			SessionID:  sessionID,
+			TurnID:     sessionID,
+			ActorID:    "searcheval",
+			TenantSlug: tenant,
+			UserQuery:  q.Query,
+		}, q.toolInput(k))
+		cancel()
+		result.LatencyMs = time.Since(start).Milliseconds()
+
+		switch {
+		case err != nil:
+			result.Error = err.Error()
+		case res.IsError:
+			result.Error = res.Content
+		default:
+			for _, p := range state.products(sessionID) {
+				result.Ranked = append(result.Ranked, p.SKU)
+			}
+			if len(result.Ranked) > k {
+				result.Ranked = result.Ranked[:k]
+			}
+		}
+
+		if result.Error == "" {
+			result.Metrics = scoreRanking(result.Ranked, q.Relevant, k)
+		}
+		run.Queries = append(run.Queries, result)
+	}
+
+	summarizeRun(run)
+	return run
+}
+
+func printRun(run *Run) {
+	fmt.Printf("tenant %s, %d queries, k=%d\n\n", run.Tenant, len(run.Queries), run.K)
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "QUERY\tRECALL@%d\tRR\tNDCG@%d\tMS\t\n", run.K, run.K)
+	for _, q := range run.Queries {
+		if q.Error != "" {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t%d\terror: %s\n", q.Query, q.LatencyMs, q.Error)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%.3f\t%.3f\t%.3f\t%d\t\n", q.Query, q.Recall, q.MRR, q.NDCG, q.LatencyMs)
+	}
+	fmt.Fprintf(w, "MEAN\t%.3f\t%.3f\t%.3f\t\t\n", run.Mean.Recall, run.Mean.MRR, run.Mean.NDCG)
+	w.Flush()
+	if run.Errors > 0 {
+		fmt.Printf("\nERRORS: %d of %d queries failed (left out of the mean)\n", run.Errors, len(run.Queries))
+	}
+}
+
+func printComparison(path string, baseline *Run, cmp Comparison) {
+	fmt.Printf("\nbaseline %s (%s)\n", path, baseline.CreatedAt.Format(time.RFC3339))
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "METRIC\tBASELINE\tRUN\tDELTA\t")
+	for _, d := range cmp.Mean {
+		fmt.Fprintf(w, "%s\t%.3f\t%.3f\t%+.3f\t\n", d.Metric, d.Baseline, d.Run, d.Run-d.Baseline)
+	}
+	w.Flush()
+
+	if len(cmp.Queries) > 0 {
+		fmt.Println("\nqueries with lower nDCG:")
+		for _, d := range cmp.Queries {
+			fmt.Printf("  %q %.3f → %.3f\n", d.Query, d.Baseline, d.Run)
+		}
+	}
+	for _, warn := range cmp.Warnings {
+		fmt.Println("warning:", warn)
+	}
+	if len(cmp.Regressions) > 0 {
+		fmt.Println("\nREGRESSION:")
+		for _, r := range cmp.Regressions {
+			fmt.Println("  " + r)
+		}
+		return
+	}
+	fmt.Println("\nno regressions")
+}
+
+func loadGoldenSet(path string) (*GoldenSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var g GoldenSet
+	if err := json.Unmarshal(data, &g); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if len(g.Queries) == 0 {
+		return nil, fmt.Errorf("%s has no queries", path)
+	}
+	for i, q := range g.Queries {
+		if q.Query == "" || len(q.Relevant) == 0 {
+			return nil, fmt.Errorf("query #%d needs \"query\" and judged \"relevant\" SKUs", i+1)
+		}
+	}
+	return &g, nil
+}
+
+func loadRun(path string) (*Run, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Run
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+func saveRun(path string, run *Run) error {
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "searcheval: "+format+"\n", args...)
+	os.Exit(2)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// Metrics are the ranking quality of one query or the mean over a golden set
+type Metrics struct {
+	Recall float64 `json:"recall"` // share of relevant SKUs found in the top k
+	MRR    float64 `json:"mrr"`    // 1 / rank of the first relevant SKU (0 if none in top k)
+	NDCG   float64 `json:"ndcg"`   // graded gain of the top k against the ideal order
+}
+
+// scoreRanking computes recall@k, reciprocal rank and nDCG@k of ranked SKUs.
+// relevant maps SKU → judged grade (≥1; higher = more relevant).
+func scoreRanking(ranked []string, relevant map[string]int, k int) Metrics {
+	if len(relevant) == 0 {
+		return Metrics{}
+	}
+	if len(ranked) > k {
+		ranked = ranked[:k]
+	}
+
+	var m Metrics
+	var found int
+	var dcg float64
+	seen := make(map[string]bool, len(ranked))
+	for i, sku := range ranked {
+		grade := relevant[sku]
+		if grade <= 0 || seen[sku] {
+			continue
+		}
+		seen[sku] = true
+		found++
+		if m.MRR == 0 {
+			m.MRR = 1 / float64(i+1)
+		}
+		dcg += gain(grade) / math.Log2(float64(i+2))
+	}
+	if judged := countRelevant(relevant); judged > 0 {
+		m.Recall = float64(found) / float64(judged)
+	}
+	if ideal := idealDCG(relevant, k); ideal > 0 {
+		m.NDCG = dcg / ideal
+	}
+	return m
+}
+
+func countRelevant(relevant map[string]int) int {
+	var n int
+	for _, g := range relevant {
+		if g > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+func gain(grade int) float64 {
+	return math.Pow(2, float64(grade)) - 1
+}
+
+// idealDCG is the DCG of relevant SKUs sorted by grade, cut at k
+func idealDCG(relevant map[string]int, k int) float64 {
+	grades := make([]int, 0, len(relevant))
+	for _, g := range relevant {
+		if g > 0 {
+			grades = append(grades, g)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(grades)))
+	var dcg float64
+	for i, g := range grades {
+		if i >= k {
+			break
+		}
+		dcg += gain(g) / math.Log2(float64(i+2))
+	}
+	return dcg
+}
+
+// meanMetrics averages per-query metrics
+// summarizeRun counts failed queries and averages the rest: an error is not a zero-relevance answer
+func summarizeRun(run *Run) {
+	answered := make([]Metrics, 0, len(run.Queries))
+	run.Errors = 0
+	for _, q := range run.Queries {
+		if q.Error != "" {
+			run.Errors++
+			continue
+		}
+		answered = append(answered, q.Metrics)
+	}
+	run.Mean = meanMetrics(answered)
+}
+
+func meanMetrics(all []Metrics) Metrics {
+	var m Metrics
+	if len(all) == 0 {
+		return m
+	}
+	for _, q := range all {
+		m.Recall += q.Recall
+		m.MRR += q.MRR
+		m.NDCG += q.NDCG
+	}
+	n := float64(len(all))
+	return Metrics{Recall: m.Recall / n, MRR: m.MRR / n, NDCG: m.NDCG / n}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestScoreRanking(t *testing.T) {
+	relevant := map[string]int{"A": 3, "B": 1, "Z": 1}
+	m := scoreRanking([]string{"X", "A", "Y", "B"}, relevant, 3)
+
+	if !near(m.Recall, 1.0/3) {
+		t.Errorf("recall@3: want 1/3 (B is past k, Z missing), got %v", m.Recall)
+	}
+	if !near(m.MRR, 0.5) {
+		t.Errorf("MRR: first hit at rank 2, got %v", m.MRR)
+	}
+	// DCG = 7/log2(3); ideal = 7/log2(2) + 1/log2(3) + 1/log2(4)
+	want := (7 / math.Log2(3)) / (7 + 1/math.Log2(3) + 0.5)
+	if !near(m.NDCG, want) {
+		t.Errorf("nDCG@3: want %v, got %v", want, m.NDCG)
+	}
+
+	perfect := scoreRanking([]string{"A", "B", "Z"}, relevant, 3)
+	if perfect != (Metrics{Recall: 1, MRR: 1, NDCG: 1}) {
+		t.Errorf("ideal ranking should score 1, got %+v", perfect)
+	}
+	if miss := scoreRanking(nil, relevant, 3); miss != (Metrics{}) {
+		t.Errorf("empty ranking should score 0, got %+v", miss)
+	}
+}
+
+func TestJudgments_Unmarshal(t *testing.T) {
+	var q GoldenQuery
+	if err := json.Unmarshal([]byte(`{"query":"крем","relevant":["A","B"]}`), &q); err != nil {
+		t.Fatal(err)
+	}
+	if q.Relevant["A"] != 1 || len(q.Relevant) != 2 {
+		t.Errorf("list should grade every SKU 1, got %v", q.Relevant)
+	}
+	if err := json.Unmarshal([]byte(`{"query":"крем","relevant":{"A":3}}`), &q); err != nil {
+		t.Fatal(err)
+	}
+	if q.Relevant["A"] != 3 {
+		t.Errorf("want graded judgments, got %v", q.Relevant)
+	}
+}
+
+func TestCompareRuns(t *testing.T) {
+	baseline := &Run{Tenant: "t", K: 10, Mean: Metrics{Recall: 0.8, MRR: 0.7, NDCG: 0.75},
+		Queries: []QueryResult{{Query: "a", Metrics: Metrics{NDCG: 0.9}}, {Query: "b", Metrics: Metrics{NDCG: 0.6}}}}
+	run := &Run{Tenant: "t", K: 10, Mean: Metrics{Recall: 0.795, MRR: 0.6, NDCG: 0.8},
+		Queries: []QueryResult{{Query: "a", Metrics: Metrics{NDCG: 0.5}}, {Query: "b", Metrics: Metrics{NDCG: 1}}}}
+
+	cmp := compareRuns(baseline, run, 0.01)
+	if len(cmp.Regressions) != 1 {
+		t.Fatalf("only MRR dropped past tolerance, got %v", cmp.Regressions)
+	}
+	if len(cmp.Queries) != 1 || cmp.Queries[0].Query != "a" {
+		t.Errorf("query a lost nDCG, got %+v", cmp.Queries)
+	}
+	if len(cmp.Warnings) != 0 {
+		t.Errorf("unexpected warnings %v", cmp.Warnings)
+	}
+
+	run.K = 5
+	if cmp := compareRuns(baseline, run, 0.01); len(cmp.Warnings) != 1 {
+		t.Errorf("different k should warn, got %v", cmp.Warnings)
+	}
+}
+
+func TestSummarizeRun_FailedQueriesCountedApart(t *testing.T) {
+	run := &Run{Queries: []QueryResult{
+		{Query: "a", Metrics: Metrics{Recall: 1, MRR: 1, NDCG: 1}},
+		{Query: "b", Metrics: Metrics{Recall: 0.5, MRR: 0.5, NDCG: 0.5}},
+		{Query: "c", Error: "context deadline exceeded"},
+	}}
+	summarizeRun(run)
+	if run.Errors != 1 {
+		t.Errorf("want 1 failed query, got %d", run.Errors)
+	}
+	if run.Mean.NDCG != 0.75 || run.Mean.Recall != 0.75 {
+		t.Errorf("failed query must not drag the mean down, got %+v", run.Mean)
+	}
+}
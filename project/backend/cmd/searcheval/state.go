@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+)
+
+// scratchState is an in-memory StatePort holding only what catalog_search touches,
+// so an evaluation run never writes sessions into the configured DB.
+// Methods outside the catalog_search path panic via the nil embedded interface.
+type scratchState struct {
+	ports.StatePort
+
+	mu     sync.Mutex
+	states map[string]*domain.SessionState
+}
+
+func newScratchState() *scratchState {
+	return &scratchState{states: make(map[string]*domain.SessionState)}
+}
+
+func (s *scratchState) CreateState(_ context.Context, sessionID string) (*domain.SessionState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := &domain.SessionState{SessionID: sessionID, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	s.states[sessionID] = st
+	return st, nil
+}
+
+func (s *scratchState) GetState(_ context.Context, sessionID string) (*domain.SessionState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.states[sessionID]
+	if !ok {
+		return nil, domain.ErrSessionNotFound
+	}
+	return st, nil
+}
+
+func (s *scratchState) AddDelta(_ context.Context, sessionID string, _ *domain.Delta) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.states[sessionID]
+	if !ok {
+		return 0, domain.ErrSessionNotFound
+	}
+	st.Step++
+	return st.Step, nil
+}
+
+func (s *scratchState) UpdateData(_ context.Context, sessionID string, data domain.StateData, meta domain.StateMeta, _ domain.DeltaInfo) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.states[sessionID]
+	if !ok {
+		return 0, domain.ErrSessionNotFound
+	}
+	st.Current.Data = data
+	st.Current.Meta = meta
+	st.Step++
+	return st.Step, nil
+}
+
+// products returns what the last search wrote for the session
+func (s *scratchState) products(sessionID string) []domain.Product {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.states[sessionID]; ok {
+		return st.Current.Data.Products
+	}
+	return nil
+}
@@ -14,8 +14,13 @@ import (
 
 	"github.com/joho/godotenv"
 	"keepstar/internal/adapters/anthropic"
+	"keepstar/internal/adapters/cassette"
+	"keepstar/internal/adapters/llmrouter"
+	"keepstar/internal/adapters/localembed"
 	openaiAdapter "keepstar/internal/adapters/openai"
 	"keepstar/internal/adapters/postgres"
+	"keepstar/internal/adapters/rerank"
+	"keepstar/internal/adapters/resilient"
 	"keepstar/internal/config"
 	"keepstar/internal/handlers"
 	"keepstar/internal/logger"
@@ -36,10 +41,17 @@ func main() {
 
 	// Initialize logger
 	appLog := logger.New(cfg.LogLevel)
+	if err := cfg.Validate(); err != nil {
+		appLog.Error("config_invalid", "error", err)
+		os.Exit(1)
+	}
 
-	// Initialize embedding client (OpenAI)
+	// Initialize embedding client (OpenAI or local hashed n-grams)
 	var embeddingClient ports.EmbeddingPort
-	if cfg.HasEmbeddings() {
+	if cfg.UsesLocalEmbeddings() {
+		embeddingClient = localembed.NewHashEmbedder(384)
+		appLog.Info("embedding_client_initialized", "provider", "local", "dims", 384)
+	} else if cfg.HasEmbeddings() {
 		embeddingClient = openaiAdapter.NewEmbeddingClient(cfg.OpenAIAPIKey, cfg.EmbeddingModel, 384)
 		appLog.Info("embedding_client_initialized", "model", cfg.EmbeddingModel, "dims", 384)
 	}
@@ -95,7 +107,51 @@ func main() {
 	}
 
 	// Initialize adapters
-	llmClient := anthropic.NewClient(cfg.AnthropicAPIKey, cfg.LLMModel)
+	// One client per model, each with its own retries, per-attempt deadline and
+	// circuit breaker (configured per stage); the router picks and falls back between them.
+	newModelClient := func(model string) ports.LLMPort {
+		var raw ports.LLMPort
+		if cfg.UsesOpenAICompatibleLLM() {
+			raw = openaiAdapter.NewChatClient(cfg.LLMBaseURL, cfg.LLMAPIKey, model)
+		} else {
+			raw = anthropic.NewClient(cfg.AnthropicAPIKey, model)
+		}
+		return resilient.NewLLM(raw, retryPolicy(cfg.LLMRetry), map[string]resilient.Policy{
+			"agent1": retryPolicy(cfg.Agent1LLMRetry),
+			"agent2": retryPolicy(cfg.Agent2LLMRetry),
+		}, appLog.Logger)
+	}
+	routes, err := llmrouter.ParseRules(cfg.LLMRoutes)
+	if err != nil {
+		appLog.Error("llm_routes_invalid", "error", err)
+		os.Exit(1)
+	}
+	router, err := llmrouter.NewRouter(newModelClient, routes, cfg.LLMDefaultChain(), appLog.Logger)
+	if err != nil {
+		appLog.Error("llm_router_failed", "error", err)
+		os.Exit(1)
+	}
+	var llmClient ports.LLMPort = router
+	if cfg.UsesOpenAICompatibleLLM() {
+		appLog.Info("llm_client_initialized", "provider", "openai", "models", router.Models(), "routes", len(routes), "base_url", cfg.LLMBaseURL)
+	} else {
+		appLog.Info("llm_client_initialized", "provider", "anthropic", "models", router.Models(), "routes", len(routes))
+	}
+
+	// Record/replay LLM traffic (dev only): record real sessions into a cassette for offline tests
+	switch cassette.Mode(cfg.LLMCassetteMode) {
+	case cassette.ModeRecord:
+		llmClient = cassette.NewRecorder(cfg.LLMCassettePath, llmClient)
+		appLog.Info("llm_cassette_recording", "path", cfg.LLMCassettePath)
+	case cassette.ModeReplay:
+		replayer, err := cassette.NewReplayer(cfg.LLMCassettePath)
+		if err != nil {
+			appLog.Error("llm_cassette_load_failed", "error", err)
+			os.Exit(1)
+		}
+		llmClient = replayer
+		appLog.Info("llm_cassette_replaying", "path", cfg.LLMCassettePath)
+	}
 
 	// Initialize database adapters (nil interface if no database)
 	var cacheAdapter ports.CachePort
@@ -103,6 +159,9 @@ func main() {
 	var catalogAdapter ports.CatalogPort
 	var stateAdapter ports.StatePort
 	var traceAdapter ports.TracePort
+	var usageAdapter ports.UsagePort
+	var promptAdapter ports.PromptPort
+	var responseCacheAdapter ports.ResponseCachePort
 	if dbClient != nil {
 		cacheAdapter = postgres.NewCacheAdapter(dbClient)
 		eventAdapter = postgres.NewEventAdapter(dbClient)
@@ -119,6 +178,38 @@ func main() {
 		}
 		traceCancel()
 
+		// Run usage migrations (per-tenant LLM quotas)
+		usageCtx, usageCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := dbClient.RunUsageMigrations(usageCtx); err != nil {
+			appLog.Error("usage_migrations_failed", "error", err)
+		} else {
+			usageAdapter = postgres.NewUsageAdapter(dbClient)
+			appLog.Info("usage_migrations_completed", "status", "ok")
+		}
+		usageCancel()
+
+		// Run prompt registry migrations (versioned prompts, A/B splits)
+		promptCtx, promptCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := dbClient.RunPromptMigrations(promptCtx); err != nil {
+			appLog.Error("prompt_migrations_failed", "error", err)
+		} else {
+			promptAdapter = postgres.NewPromptAdapter(dbClient)
+			appLog.Info("prompt_migrations_completed", "status", "ok")
+		}
+		promptCancel()
+
+		// Run response cache migrations (needs pgvector from catalog migrations)
+		if cfg.ResponseCacheTTLSeconds > 0 {
+			rcCtx, rcCancel := context.WithTimeout(context.Background(), 10*time.Second)
+			if err := dbClient.RunResponseCacheMigrations(rcCtx); err != nil {
+				appLog.Error("response_cache_migrations_failed", "error", err)
+			} else {
+				responseCacheAdapter = postgres.NewResponseCacheAdapter(dbClient)
+				appLog.Info("response_cache_migrations_completed", "status", "ok")
+			}
+			rcCancel()
+		}
+
 		// Run log migrations
 		logCtx, logCancel := context.WithTimeout(context.Background(), 10*time.Second)
 		if err := dbClient.RunLogMigrations(logCtx); err != nil {
@@ -136,20 +227,23 @@ func main() {
 	// Initialize tool registry (requires state and catalog adapters)
 	var toolRegistry *tools.Registry
 	if stateAdapter != nil && catalogAdapter != nil {
-		toolRegistry = tools.NewRegistry(stateAdapter, catalogAdapter, presetRegistry, embeddingClient)
+		toolRegistry = tools.NewRegistry(stateAdapter, catalogAdapter, presetRegistry, embeddingClient).
+			WithRerankers(cfg.RerankStrategy, rerank.NewFeatureReranker(), rerank.NewLLMReranker(llmClient)).
+			WithStockPolicy(cfg.StockPolicy)
 		toolNames := make([]string, 0)
 		for _, def := range toolRegistry.GetDefinitions() {
 			if !strings.HasPrefix(def.Name, "_internal_") {
 				toolNames = append(toolNames, def.Name)
 			}
 		}
-		appLog.Info("tool_registry_initialized", "tools", strings.Join(toolNames, ", "), "count", len(toolNames))
+		appLog.Info("tool_registry_initialized", "tools", strings.Join(toolNames, ", "), "count", len(toolNames), "rerank", cfg.RerankStrategy, "stock_policy", cfg.StockPolicy)
 	}
 
 	// Initialize Agent 1 use case (Two-Agent Pipeline)
 	var agent1UC *usecases.Agent1ExecuteUseCase
 	if toolRegistry != nil {
-		agent1UC = usecases.NewAgent1ExecuteUseCase(llmClient, stateAdapter, catalogAdapter, toolRegistry, appLog)
+		agent1UC = usecases.NewAgent1ExecuteUseCase(llmClient, stateAdapter, catalogAdapter, toolRegistry, appLog).
+			WithLoopLimits(cfg.Agent1MaxSteps, cfg.Agent1MaxTokens)
 		appLog.Info("agent1_usecase_initialized", "status", "ok")
 	}
 	_ = agent1UC // Available for direct Agent 1 calls
@@ -162,10 +256,29 @@ func main() {
 	}
 	_ = agent2UC // Available for direct Agent 2 calls
 
+	// Prompt selector: registry versions over the built-in prompts (nil = built-in only)
+	var promptSelector *usecases.PromptSelector
+	if promptAdapter != nil {
+		promptSelector = usecases.NewPromptSelector(promptAdapter, 30*time.Second)
+	}
+
+	// Response cache: exact + semantic (if embeddings are configured) lookup of tenant queries
+	var responseCache *usecases.ResponseCache
+	if responseCacheAdapter != nil {
+		responseCache = usecases.NewResponseCache(responseCacheAdapter, embeddingClient, cfg.ResponseCacheMinSimilarity,
+			time.Duration(cfg.ResponseCacheTTLSeconds)*time.Second, appLog)
+		appLog.Info("response_cache_enabled", "ttl_seconds", cfg.ResponseCacheTTLSeconds,
+			"min_similarity", cfg.ResponseCacheMinSimilarity, "semantic", embeddingClient != nil)
+	}
+
 	// Initialize Pipeline orchestrator (Agent 1 → Agent 2 → Formation)
 	var pipelineUC *usecases.PipelineExecuteUseCase
 	if toolRegistry != nil && stateAdapter != nil && cacheAdapter != nil {
-		pipelineUC = usecases.NewPipelineExecuteUseCase(llmClient, stateAdapter, cacheAdapter, traceAdapter, catalogAdapter, toolRegistry, presetRegistry, appLog)
+		pipelineUC = usecases.NewPipelineExecuteUseCase(llmClient, stateAdapter, cacheAdapter, traceAdapter, catalogAdapter, toolRegistry, presetRegistry, appLog).
+			WithUsage(usageAdapter).
+			WithAgent1Limits(cfg.Agent1MaxSteps, cfg.Agent1MaxTokens).
+			WithPrompts(promptSelector).
+			WithResponseCache(responseCache)
 		appLog.Info("pipeline_usecase_initialized", "status", "ok")
 	}
 	_ = pipelineUC // Pipeline is ready to be called from handlers
@@ -176,6 +289,10 @@ func main() {
 	// Initialize handlers
 	chatHandler := handlers.NewChatHandler(sendMessage, appLog)
 	sessionHandler := handlers.NewSessionHandler(cacheAdapter, stateAdapter, catalogAdapter, appLog)
+	if stateAdapter != nil {
+		sessionHandler.WithHistory(usecases.NewHistoryUseCase(stateAdapter)).
+			WithFork(usecases.NewForkUseCase(stateAdapter))
+	}
 	healthHandler := handlers.NewHealthHandler()
 
 	// Create metrics store for debug page
@@ -188,12 +305,16 @@ func main() {
 		appLog.Info("pipeline_handler_initialized", "status", "ok")
 	}
 
-	// Initialize Navigation handler (expand/back)
+	// Initialize Navigation handler (expand/back/forward/similar)
 	var navigationHandler *handlers.NavigationHandler
 	if stateAdapter != nil && presetRegistry != nil {
 		expandUC := usecases.NewExpandUseCase(stateAdapter, presetRegistry)
 		backUC := usecases.NewBackUseCase(stateAdapter, presetRegistry)
-		navigationHandler = handlers.NewNavigationHandler(expandUC, backUC, appLog)
+		navigationHandler = handlers.NewNavigationHandler(expandUC, backUC, appLog).
+			WithForward(usecases.NewForwardUseCase(stateAdapter, presetRegistry))
+		if toolRegistry != nil {
+			navigationHandler.WithSimilar(usecases.NewSimilarUseCase(stateAdapter, toolRegistry, presetRegistry))
+		}
 		appLog.Info("navigation_handler_initialized", "status", "ok")
 	}
 
@@ -214,7 +335,7 @@ func main() {
 
 	handlers.SetupRoutes(mux, chatHandler, sessionHandler, healthHandler, pipelineHandler, tenantMiddleware, cfg.TenantSlug)
 
-	// Setup navigation routes (expand/back)
+	// Setup navigation routes (expand/back/forward/similar)
 	if navigationHandler != nil {
 		handlers.SetupNavigationRoutes(mux, navigationHandler)
 		appLog.Info("navigation_routes_enabled", "status", "ok")
@@ -267,9 +388,35 @@ func main() {
 		appLog.Info("admin_reindex_route_enabled", "url", "POST /admin/reindex-embeddings")
 	}
 
+	// Admin: drop a tenant's cached responses (e.g. after a stock feed that bypasses catalog.stock)
+	if responseCacheAdapter != nil {
+		mux.HandleFunc("/admin/response-cache/invalidate", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost {
+				http.Error(w, "POST only", http.StatusMethodNotAllowed)
+				return
+			}
+			tenant := r.URL.Query().Get("tenant")
+			if tenant == "" {
+				http.Error(w, "tenant required", http.StatusBadRequest)
+				return
+			}
+			deleted, err := responseCacheAdapter.InvalidateTenant(r.Context(), tenant)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			appLog.Info("response_cache_invalidated", "tenant", tenant, "deleted", deleted)
+			fmt.Fprintf(w, "Invalidated %d cached responses for %s", deleted, tenant)
+		})
+		appLog.Info("admin_response_cache_route_enabled", "url", "POST /admin/response-cache/invalidate?tenant=")
+	}
+
 	// Setup trace routes (new debug view)
 	if traceAdapter != nil {
 		traceHandler := handlers.NewTraceHandler(traceAdapter, cacheAdapter)
+		if stateAdapter != nil {
+			traceHandler.WithBranches(stateAdapter)
+		}
 		mux.HandleFunc("/debug/traces/", traceHandler.HandleTraces)
 		mux.HandleFunc("/debug/traces", traceHandler.HandleTraces)
 		mux.HandleFunc("/debug/kill-session", traceHandler.HandleKillSession)
@@ -381,6 +528,18 @@ func main() {
 	appLog.Info("server_stopped")
 }
 
+// retryPolicy converts env config (milliseconds) to a resilient.Policy
+func retryPolicy(c config.LLMRetryConfig) resilient.Policy {
+	return resilient.Policy{
+		MaxAttempts:      c.MaxAttempts,
+		BaseDelay:        time.Duration(c.BaseDelayMs) * time.Millisecond,
+		MaxDelay:         time.Duration(c.MaxDelayMs) * time.Millisecond,
+		AttemptTimeout:   time.Duration(c.AttemptTimeoutMs) * time.Millisecond,
+		BreakerThreshold: c.BreakerThreshold,
+		BreakerCooldown:  time.Duration(c.BreakerCooldownMs) * time.Millisecond,
+	}
+}
+
 func generateAllDigests(ctx context.Context, catalog *postgres.CatalogAdapter, log *logger.Logger) error {
 	tenants, err := catalog.GetAllTenants(ctx)
 	if err != nil {
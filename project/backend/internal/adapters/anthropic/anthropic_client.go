@@ -94,6 +94,10 @@ func (c *Client) Chat(ctx context.Context, message string) (string, error) {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode >= 400 {
+		return "", providerError(resp)
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return "", fmt.Errorf("failed to read response: %w", err)
@@ -152,6 +156,10 @@ func (c *Client) ChatWithUsage(ctx context.Context, systemPrompt, userMessage st
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode >= 400 {
+		return nil, providerError(resp)
+	}
+
 	var anthroResp anthropicToolResponse
 	if err := json.NewDecoder(resp.Body).Decode(&anthroResp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
@@ -310,6 +318,13 @@ func (c *Client) ChatWithTools(
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode >= 400 {
+		if endLLM != nil {
+			endLLM(fmt.Sprintf("status %d", resp.StatusCode))
+		}
+		return nil, providerError(resp)
+	}
+
 	// Read body span
 	var endBody func(...string)
 	if sc != nil && stage != "" {
@@ -505,6 +520,13 @@ func (c *Client) ChatWithToolsCached(
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode >= 400 {
+		if endLLM != nil {
+			endLLM(fmt.Sprintf("status %d", resp.StatusCode))
+		}
+		return nil, providerError(resp)
+	}
+
 	// Read body span
 	var endBody func(...string)
 	if sc != nil && stage != "" {
@@ -641,3 +663,19 @@ func convertToAnthropicMessage(msg domain.LLMMessage) anthropicToolMsg {
 	// Simple text message
 	return anthropicToolMsg{Role: msg.Role, Content: msg.Content}
 }
+
+// providerError converts a non-2xx response (429, 529 overloaded, 5xx) into
+// domain.LLMProviderError, keeping retry-after so callers can back off.
+func providerError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	message := strings.TrimSpace(string(body))
+	var parsed struct {
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &parsed) == nil && parsed.Error != nil {
+		message = parsed.Error.Message
+	}
+	return domain.NewLLMProviderError(resp.StatusCode, resp.Header.Get("retry-after"), message)
+}
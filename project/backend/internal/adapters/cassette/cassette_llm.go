@@ -0,0 +1,354 @@
+package cassette
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+)
+
+// Mode selects whether the cassette records real responses or replays them
+type Mode string
+
+const (
+	ModeRecord Mode = "record" // call inner LLM, append request/response to the cassette file
+	ModeReplay Mode = "replay" // serve responses from the cassette file, error on miss
+)
+
+const cassetteVersion = 1
+
+// Request is the normalized part of an LLM call that forms the cassette key
+type Request struct {
+	Method   string                  `json:"method"` // "Chat", "ChatWithTools", "ChatWithToolsCached", "ChatWithUsage"
+	System   string                  `json:"system,omitempty"`
+	Messages []domain.LLMMessage     `json:"messages,omitempty"`
+	Tools    []domain.ToolDefinition `json:"tools,omitempty"`
+}
+
+// Interaction is one recorded request/response pair
+type Interaction struct {
+	Key      string              `json:"key"`
+	Request  Request             `json:"request"`
+	Response *domain.LLMResponse `json:"response"`
+}
+
+// File is the on-disk cassette format
+type File struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// LLM implements ports.LLMPort over a cassette file.
+// Keys are sha256 of the normalized request (system prompt, messages, tools),
+// so unrelated noise (UUIDs, whitespace, tool order) does not cause misses.
+// Identical requests within a cassette are served in recorded order;
+// a call beyond the recorded ones is an error, not a repeat.
+type LLM struct {
+	mode  Mode
+	path  string
+	inner ports.LLMPort // only in record mode
+
+	mu     sync.Mutex
+	file   File
+	byKey  map[string][]int // key → interaction indices in recorded order
+	served map[string]int   // key → how many were served (replay)
+}
+
+// NewRecorder wraps inner and writes every successful call to path (file is truncated)
+func NewRecorder(path string, inner ports.LLMPort) *LLM {
+	return &LLM{
+		mode:   ModeRecord,
+		path:   path,
+		inner:  inner,
+		file:   File{Version: cassetteVersion},
+		byKey:  make(map[string][]int),
+		served: make(map[string]int),
+	}
+}
+
+// NewReplayer loads a cassette from path and serves recorded responses
+func NewReplayer(path string) (*LLM, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read cassette: %w", err)
+	}
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("decode cassette %s: %w", path, err)
+	}
+	if f.Version != cassetteVersion {
+		return nil, fmt.Errorf("cassette %s: unsupported version %d", path, f.Version)
+	}
+	l := &LLM{
+		mode:   ModeReplay,
+		path:   path,
+		file:   f,
+		byKey:  make(map[string][]int),
+		served: make(map[string]int),
+	}
+	for i, it := range f.Interactions {
+		l.byKey[it.Key] = append(l.byKey[it.Key], i)
+	}
+	return l, nil
+}
+
+// Chat implements ports.LLMPort
+func (l *LLM) Chat(ctx context.Context, message string) (string, error) {
+	req := Request{Method: "Chat", Messages: []domain.LLMMessage{{Role: "user", Content: message}}}
+	resp, err := l.do(ctx, req, func() (*domain.LLMResponse, error) {
+		text, err := l.inner.Chat(ctx, message)
+		if err != nil {
+			return nil, err
+		}
+		return &domain.LLMResponse{Text: text}, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.Text, nil
+}
+
+// ChatWithTools implements ports.LLMPort
+func (l *LLM) ChatWithTools(ctx context.Context, systemPrompt string, messages []domain.LLMMessage, tools []domain.ToolDefinition) (*domain.LLMResponse, error) {
+	req := Request{Method: "ChatWithTools", System: systemPrompt, Messages: messages, Tools: tools}
+	return l.do(ctx, req, func() (*domain.LLMResponse, error) {
+		return l.inner.ChatWithTools(ctx, systemPrompt, messages, tools)
+	})
+}
+
+// ChatWithToolsCached implements ports.LLMPort. Cache flags do not change the
+// model output, so they are not part of the key.
+func (l *LLM) ChatWithToolsCached(ctx context.Context, systemPrompt string, messages []domain.LLMMessage, tools []domain.ToolDefinition, cacheConfig *ports.CacheConfig) (*domain.LLMResponse, error) {
+	req := Request{Method: "ChatWithToolsCached", System: systemPrompt, Messages: messages, Tools: tools}
+	return l.do(ctx, req, func() (*domain.LLMResponse, error) {
+		return l.inner.ChatWithToolsCached(ctx, systemPrompt, messages, tools, cacheConfig)
+	})
+}
+
+// ChatWithUsage implements ports.LLMPort
+func (l *LLM) ChatWithUsage(ctx context.Context, systemPrompt, userMessage string) (*ports.ChatResponse, error) {
+	req := Request{Method: "ChatWithUsage", System: systemPrompt, Messages: []domain.LLMMessage{{Role: "user", Content: userMessage}}}
+	resp, err := l.do(ctx, req, func() (*domain.LLMResponse, error) {
+		r, err := l.inner.ChatWithUsage(ctx, systemPrompt, userMessage)
+		if err != nil {
+			return nil, err
+		}
+		return &domain.LLMResponse{Text: r.Text, Usage: r.Usage}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ports.ChatResponse{Text: resp.Text, Usage: resp.Usage}, nil
+}
+
+func (l *LLM) do(ctx context.Context, req Request, call func() (*domain.LLMResponse, error)) (*domain.LLMResponse, error) {
+	req = normalize(req)
+	key := Key(req)
+
+	if l.mode == ModeRecord {
+		resp, err := call()
+		if err != nil {
+			return nil, err
+		}
+		if err := l.record(key, req, resp); err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+
+	// Replay: emit the same {stage}.llm span a real adapter would
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		if stage := domain.StageFromContext(ctx); stage != "" {
+			defer sc.Start(stage + ".llm")("replay")
+		}
+	}
+	return l.replay(key, req)
+}
+
+func (l *LLM) record(key string, req Request, resp *domain.LLMResponse) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.file.Interactions = append(l.file.Interactions, Interaction{Key: key, Request: req, Response: resp})
+	l.byKey[key] = append(l.byKey[key], len(l.file.Interactions)-1)
+
+	// Rewrite the whole file after every call: a crashed test still leaves a usable cassette
+	data, err := json.MarshalIndent(l.file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return fmt.Errorf("create cassette dir: %w", err)
+	}
+	if err := os.WriteFile(l.path, data, 0o644); err != nil {
+		return fmt.Errorf("write cassette: %w", err)
+	}
+	return nil
+}
+
+func (l *LLM) replay(key string, req Request) (*domain.LLMResponse, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	indices, ok := l.byKey[key]
+	if !ok {
+		return nil, fmt.Errorf("cassette miss in %s: %s request %s not recorded; %s (re-record the cassette if the change is intended)",
+			l.path, req.Method, key[:12], l.closestDiff(req))
+	}
+	n := l.served[key]
+	if n >= len(indices) {
+		return nil, fmt.Errorf("cassette exhausted in %s: %s request %s recorded %d time(s), called again (re-record the cassette if the extra call is intended)",
+			l.path, req.Method, key[:12], len(indices))
+	}
+	l.served[key] = n + 1
+	resp := *l.file.Interactions[indices[n]].Response
+	return &resp, nil
+}
+
+// closestDiff describes how req differs from the most similar recorded request
+// of the same method, to point at the prompt/message/tool change that caused the miss.
+func (l *LLM) closestDiff(req Request) string {
+	best := ""
+	bestScore := -1
+	for _, it := range l.file.Interactions {
+		if it.Request.Method != req.Method {
+			continue
+		}
+		score, diff := compare(it.Request, req)
+		if score > bestScore {
+			bestScore, best = score, diff
+		}
+	}
+	if bestScore < 0 {
+		return fmt.Sprintf("no %s interactions recorded", req.Method)
+	}
+	return "closest recorded request differs: " + best
+}
+
+// compare scores similarity (higher = closer) and reports the first difference
+func compare(recorded, got Request) (int, string) {
+	score := 0
+	var diffs []string
+
+	if recorded.System == got.System {
+		score += 2
+	} else {
+		diffs = append(diffs, "system prompt "+firstDiff(recorded.System, got.System))
+	}
+
+	if toolsKey(recorded.Tools) == toolsKey(got.Tools) {
+		score += 2
+	} else {
+		diffs = append(diffs, fmt.Sprintf("tools %s vs %s", toolNames(recorded.Tools), toolNames(got.Tools)))
+	}
+
+	if len(recorded.Messages) != len(got.Messages) {
+		diffs = append(diffs, fmt.Sprintf("message count %d vs %d", len(recorded.Messages), len(got.Messages)))
+	}
+	for i := 0; i < len(recorded.Messages) && i < len(got.Messages); i++ {
+		a, _ := json.Marshal(recorded.Messages[i])
+		b, _ := json.Marshal(got.Messages[i])
+		if string(a) == string(b) {
+			score++
+			continue
+		}
+		diffs = append(diffs, fmt.Sprintf("message[%d] %s", i, firstDiff(string(a), string(b))))
+		break
+	}
+
+	if len(diffs) == 0 {
+		return score, "identical (key collision?)"
+	}
+	return score, strings.Join(diffs, "; ")
+}
+
+// firstDiff shows a short window around the first differing character
+func firstDiff(a, b string) string {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	start := i - 20
+	if start < 0 {
+		start = 0
+	}
+	return fmt.Sprintf("at byte %d: recorded %q, got %q", i, window(a, start), window(b, start))
+}
+
+func window(s string, start int) string {
+	if start >= len(s) {
+		return ""
+	}
+	end := start + 60
+	if end > len(s) {
+		end = len(s)
+	}
+	return s[start:end]
+}
+
+func toolNames(tools []domain.ToolDefinition) string {
+	names := make([]string, len(tools))
+	for i, t := range tools {
+		names[i] = t.Name
+	}
+	return "[" + strings.Join(names, ",") + "]"
+}
+
+func toolsKey(tools []domain.ToolDefinition) string {
+	b, _ := json.Marshal(tools)
+	return string(b)
+}
+
+var (
+	uuidRe       = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	whitespaceRe = regexp.MustCompile(`\s+`)
+)
+
+// normalizeText strips run-specific noise: UUIDs (session/turn IDs) and whitespace differences
+func normalizeText(s string) string {
+	s = uuidRe.ReplaceAllString(s, "<uuid>")
+	s = whitespaceRe.ReplaceAllString(s, " ")
+	return strings.TrimSpace(s)
+}
+
+// normalize returns a copy of req with noise removed and tools sorted by name.
+// Tool-call IDs are kept: in replay they come from the cassette, so they are stable.
+func normalize(req Request) Request {
+	out := Request{Method: req.Method, System: normalizeText(req.System)}
+
+	for _, m := range req.Messages {
+		nm := domain.LLMMessage{Role: m.Role, Content: normalizeText(m.Content)}
+		for _, tc := range m.ToolCalls {
+			nm.ToolCalls = append(nm.ToolCalls, domain.ToolCall{ID: tc.ID, Name: tc.Name, Input: tc.Input})
+		}
+		if m.ToolResult != nil {
+			tr := *m.ToolResult
+			tr.Content = normalizeText(tr.Content)
+			tr.Metadata = nil // internal tracing breakdown, never sent to the model
+			nm.ToolResult = &tr
+		}
+		out.Messages = append(out.Messages, nm)
+	}
+
+	out.Tools = append([]domain.ToolDefinition(nil), req.Tools...)
+	sort.Slice(out.Tools, func(i, j int) bool { return out.Tools[i].Name < out.Tools[j].Name })
+	return out
+}
+
+// Key is the sha256 of the normalized request. encoding/json sorts map keys,
+// so tool inputs and schemas hash deterministically.
+func Key(req Request) string {
+	b, _ := json.Marshal(req)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
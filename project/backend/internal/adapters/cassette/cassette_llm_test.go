@@ -0,0 +1,145 @@
+package cassette_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"keepstar/internal/adapters/cassette"
+	"keepstar/internal/domain"
+	"keepstar/internal/testutil"
+)
+
+var searchTools = []domain.ToolDefinition{
+	{Name: "catalog_search", Description: "Search", InputSchema: map[string]interface{}{"type": "object"}},
+	{Name: "_internal_state_filter", Description: "Filter", InputSchema: map[string]interface{}{"type": "object"}},
+}
+
+func conversation(sessionID string) []domain.LLMMessage {
+	return []domain.LLMMessage{
+		{Role: "user", Content: "покажи  кроссовки Nike\n(session " + sessionID + ")"},
+	}
+}
+
+func TestCassette_RecordThenReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scenario.json")
+	inner := testutil.NewMockLLMClient(
+		&domain.LLMResponse{
+			ToolCalls:  []domain.ToolCall{{ID: "call-1", Name: "catalog_search", Input: map[string]interface{}{"query": "кроссовки", "brand": "Nike"}}},
+			StopReason: "tool_use",
+			Usage:      domain.LLMUsage{InputTokens: 100, OutputTokens: 20, Model: "mock"},
+		},
+		&domain.LLMResponse{Text: "Готово", StopReason: "end_turn"},
+	)
+
+	rec := cassette.NewRecorder(path, inner)
+	ctx := context.Background()
+	first, err := rec.ChatWithToolsCached(ctx, "system v1", conversation("0b7c6a2e-1111-4222-8333-944445555666"), searchTools, nil)
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	followUp := append(conversation("0b7c6a2e-1111-4222-8333-944445555666"),
+		domain.LLMMessage{Role: "assistant", ToolCalls: first.ToolCalls},
+		domain.LLMMessage{Role: "user", ToolResult: &domain.ToolResult{ToolUseID: "call-1", Content: "ok: 12 products"}},
+	)
+	if _, err := rec.ChatWithToolsCached(ctx, "system v1", followUp, searchTools, nil); err != nil {
+		t.Fatalf("record follow-up: %v", err)
+	}
+
+	// Replay with a different session UUID, reordered tools and extra whitespace: still hits
+	rep, err := cassette.NewReplayer(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	reordered := []domain.ToolDefinition{searchTools[1], searchTools[0]}
+	got, err := rep.ChatWithToolsCached(ctx, "system   v1\n", conversation("9f9f9f9f-aaaa-4bbb-8ccc-dddddddddddd"), reordered, nil)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if len(got.ToolCalls) != 1 || got.ToolCalls[0].Input["brand"] != "Nike" {
+		t.Errorf("unexpected replayed tool calls: %+v", got.ToolCalls)
+	}
+	if got.Usage.InputTokens != 100 {
+		t.Errorf("usage should be replayed, got %+v", got.Usage)
+	}
+
+	followUp[0] = conversation("9f9f9f9f-aaaa-4bbb-8ccc-dddddddddddd")[0]
+	second, err := rep.ChatWithToolsCached(ctx, "system v1", followUp, searchTools, nil)
+	if err != nil {
+		t.Fatalf("replay follow-up: %v", err)
+	}
+	if second.Text != "Готово" {
+		t.Errorf("want recorded text, got %q", second.Text)
+	}
+}
+
+func TestCassette_ReplayMissFailsWithDiff(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scenario.json")
+	rec := cassette.NewRecorder(path, testutil.NewMockLLMClient(&domain.LLMResponse{Text: "ok", StopReason: "end_turn"}))
+	ctx := context.Background()
+	if _, err := rec.ChatWithTools(ctx, "You are Agent 1. Always call a tool.", conversation("x"), searchTools); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+
+	rep, err := cassette.NewReplayer(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	_, err = rep.ChatWithTools(ctx, "You are Agent 1. Never call a tool.", conversation("x"), searchTools)
+	if err == nil {
+		t.Fatal("want error on cassette miss")
+	}
+	if !strings.Contains(err.Error(), "cassette miss") || !strings.Contains(err.Error(), "system prompt") {
+		t.Errorf("miss error should point at the system prompt change, got: %v", err)
+	}
+}
+
+func TestCassette_RepeatedRequestsServedInOrder(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scenario.json")
+	rec := cassette.NewRecorder(path, testutil.NewMockLLMClient(
+		&domain.LLMResponse{Text: "first"},
+		&domain.LLMResponse{Text: "second"},
+	))
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := rec.ChatWithTools(ctx, "sys", conversation("x"), nil); err != nil {
+			t.Fatalf("record %d: %v", i, err)
+		}
+	}
+
+	rep, err := cassette.NewReplayer(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	for _, want := range []string{"first", "second"} {
+		got, err := rep.ChatWithTools(ctx, "sys", conversation("x"), nil)
+		if err != nil {
+			t.Fatalf("replay: %v", err)
+		}
+		if got.Text != want {
+			t.Errorf("want %q, got %q", want, got.Text)
+		}
+	}
+}
+
+func TestCassette_ReplayFailsWhenCallsExceedRecordings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scenario.json")
+	rec := cassette.NewRecorder(path, testutil.NewMockLLMClient(&domain.LLMResponse{Text: "once"}))
+	ctx := context.Background()
+	if _, err := rec.ChatWithTools(ctx, "sys", conversation("x"), nil); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+
+	rep, err := cassette.NewReplayer(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if _, err := rep.ChatWithTools(ctx, "sys", conversation("x"), nil); err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	_, err = rep.ChatWithTools(ctx, "sys", conversation("x"), nil)
+	if err == nil || !strings.Contains(err.Error(), "cassette exhausted") {
+		t.Errorf("want exhausted error on a call beyond the recording, got %v", err)
+	}
+}
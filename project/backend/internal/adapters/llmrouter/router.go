@@ -0,0 +1,240 @@
+package llmrouter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+)
+
+// Rule maps requests to an ordered model chain. Empty Stage/Complexity/Tenant match anything.
+type Rule struct {
+	Stage      string   // "agent1", "agent2", "chat", ...
+	Complexity string   // "simple" or "complex" (domain.QueryComplexity)
+	Tenant     string   // tenant slug
+	Models     []string // primary first, then fallbacks
+}
+
+// specificity ranks matching rules: tenant outweighs stage, stage outweighs complexity.
+// The highest wins; on a tie the rule listed first wins.
+func (r Rule) specificity() int {
+	n := 0
+	if r.Tenant != "" {
+		n += 4
+	}
+	if r.Stage != "" {
+		n += 2
+	}
+	if r.Complexity != "" {
+		n++
+	}
+	return n
+}
+
+func (r Rule) matches(stage string, hints domain.LLMRouteHints) bool {
+	return (r.Stage == "" || r.Stage == stage) &&
+		(r.Complexity == "" || r.Complexity == string(hints.Complexity)) &&
+		(r.Tenant == "" || r.Tenant == hints.TenantSlug)
+}
+
+// ParseRules parses LLM_ROUTES: rules separated by ";", each "selector=model[,fallback...]".
+// Selector is "stage[:complexity][@tenant]", stage "*" matches any stage.
+//
+//	agent2=claude-haiku-4-5-20251001; agent1:complex=claude-sonnet-4-5-20250929,claude-haiku-4-5-20251001; *@acme=claude-sonnet-4-5-20250929
+func ParseRules(spec string) ([]Rule, error) {
+	var rules []Rule
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		selector, models, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("route %q: want selector=model[,fallback...]", part)
+		}
+		var r Rule
+		selector = strings.TrimSpace(selector)
+		if s, tenant, ok := strings.Cut(selector, "@"); ok {
+			selector, r.Tenant = s, strings.TrimSpace(tenant)
+		}
+		if s, complexity, ok := strings.Cut(selector, ":"); ok {
+			selector, r.Complexity = s, strings.TrimSpace(complexity)
+			if r.Complexity != string(domain.QueryComplexitySimple) && r.Complexity != string(domain.QueryComplexityComplex) {
+				return nil, fmt.Errorf("route %q: unknown complexity %q", part, r.Complexity)
+			}
+		}
+		if selector = strings.TrimSpace(selector); selector != "*" {
+			r.Stage = selector
+		}
+		for _, m := range strings.Split(models, ",") {
+			if m = strings.TrimSpace(m); m != "" {
+				r.Models = append(r.Models, m)
+			}
+		}
+		if len(r.Models) == 0 {
+			return nil, fmt.Errorf("route %q: no models", part)
+		}
+		rules = append(rules, r)
+	}
+	return rules, nil
+}
+
+// Router implements ports.LLMPort by picking a model chain per request
+// (stage from domain.StageFromContext, tenant and complexity from domain.RouteHintsFromContext)
+// and falling back to the next model when the current one is overloaded or unavailable.
+type Router struct {
+	clients  map[string]ports.LLMPort // model → client (already wrapped with retries)
+	rules    []Rule
+	defaults []string
+	log      *slog.Logger
+}
+
+// NewRouter creates a client per referenced model via newClient.
+// defaults is the chain for requests no rule matches (LLM_MODEL + LLM_FALLBACK_MODELS).
+func NewRouter(newClient func(model string) ports.LLMPort, rules []Rule, defaults []string, log *slog.Logger) (*Router, error) {
+	if len(defaults) == 0 {
+		return nil, errors.New("llm router: default model chain is empty")
+	}
+	if log == nil {
+		log = slog.Default()
+	}
+	r := &Router{clients: make(map[string]ports.LLMPort), rules: rules, defaults: defaults, log: log}
+	for _, chain := range r.chains() {
+		for _, model := range chain {
+			if _, ok := r.clients[model]; !ok {
+				r.clients[model] = newClient(model)
+			}
+		}
+	}
+	return r, nil
+}
+
+func (r *Router) chains() [][]string {
+	out := [][]string{r.defaults}
+	for _, rule := range r.rules {
+		out = append(out, rule.Models)
+	}
+	return out
+}
+
+// Models returns all models the router may call (for startup logging)
+func (r *Router) Models() []string {
+	models := make([]string, 0, len(r.clients))
+	seen := make(map[string]bool)
+	for _, chain := range r.chains() {
+		for _, m := range chain {
+			if !seen[m] {
+				seen[m] = true
+				models = append(models, m)
+			}
+		}
+	}
+	return models
+}
+
+// Route returns the model chain for a request context
+func (r *Router) Route(ctx context.Context) []string {
+	stage := domain.StageFromContext(ctx)
+	hints := domain.RouteHintsFromContext(ctx)
+	best := -1
+	chain := r.defaults
+	for _, rule := range r.rules {
+		if rule.matches(stage, hints) && rule.specificity() > best {
+			best = rule.specificity()
+			chain = rule.Models
+		}
+	}
+	return chain
+}
+
+// Chat implements ports.LLMPort
+func (r *Router) Chat(ctx context.Context, message string) (string, error) {
+	var out string
+	err := r.do(ctx, func(client ports.LLMPort) error {
+		var err error
+		out, err = client.Chat(ctx, message)
+		return err
+	})
+	return out, err
+}
+
+// ChatWithTools implements ports.LLMPort
+func (r *Router) ChatWithTools(ctx context.Context, systemPrompt string, messages []domain.LLMMessage, tools []domain.ToolDefinition) (*domain.LLMResponse, error) {
+	var out *domain.LLMResponse
+	err := r.do(ctx, func(client ports.LLMPort) error {
+		var err error
+		out, err = client.ChatWithTools(ctx, systemPrompt, messages, tools)
+		return err
+	})
+	return out, err
+}
+
+// ChatWithToolsCached implements ports.LLMPort
+func (r *Router) ChatWithToolsCached(ctx context.Context, systemPrompt string, messages []domain.LLMMessage, tools []domain.ToolDefinition, cacheConfig *ports.CacheConfig) (*domain.LLMResponse, error) {
+	var out *domain.LLMResponse
+	err := r.do(ctx, func(client ports.LLMPort) error {
+		var err error
+		out, err = client.ChatWithToolsCached(ctx, systemPrompt, messages, tools, cacheConfig)
+		return err
+	})
+	return out, err
+}
+
+// ChatWithUsage implements ports.LLMPort
+func (r *Router) ChatWithUsage(ctx context.Context, systemPrompt, userMessage string) (*ports.ChatResponse, error) {
+	var out *ports.ChatResponse
+	err := r.do(ctx, func(client ports.LLMPort) error {
+		var err error
+		out, err = client.ChatWithUsage(ctx, systemPrompt, userMessage)
+		return err
+	})
+	return out, err
+}
+
+// do walks the chain until a model succeeds. Each switch to a fallback model is
+// recorded as a "{stage}.llm.fallback" span. The used model is reported by the
+// adapter in LLMUsage.Model, so traces and costs follow the actual model.
+func (r *Router) do(ctx context.Context, call func(client ports.LLMPort) error) error {
+	chain := r.Route(ctx)
+	stage := domain.StageFromContext(ctx)
+	sc := domain.SpanFromContext(ctx)
+
+	var err error
+	for i, model := range chain {
+		var endSpan func(...string)
+		if i > 0 {
+			r.log.Warn("llm_model_fallback", "stage", stage, "from", chain[i-1], "to", model, "error", err)
+			if sc != nil && stage != "" {
+				endSpan = sc.Start(stage + ".llm.fallback")
+			}
+		}
+		err = call(r.clients[model])
+		if endSpan != nil {
+			endSpan(fmt.Sprintf("%s → %s", chain[i-1], model))
+		}
+		if err == nil || !shouldFallback(ctx, err) {
+			return err
+		}
+	}
+	return err
+}
+
+// shouldFallback: overloaded/unavailable provider or attempt timeout — another model may answer.
+// Client errors (400, auth) and caller cancellation are returned as is.
+func shouldFallback(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if errors.Is(err, domain.ErrLLMUnavailable) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var perr *domain.LLMProviderError
+	if errors.As(err, &perr) {
+		return perr.Retryable()
+	}
+	return false
+}
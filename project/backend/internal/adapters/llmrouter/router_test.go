@@ -0,0 +1,135 @@
+package llmrouter_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"keepstar/internal/adapters/llmrouter"
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+	"keepstar/internal/testutil"
+)
+
+// modelClients records which model was called and fails for models in failing
+type modelClients struct {
+	calls   []string
+	failing map[string]error
+}
+
+func (m *modelClients) newClient(model string) ports.LLMPort {
+	return &modelLLM{MockLLMClient: testutil.NewMockLLMClient(), model: model, parent: m}
+}
+
+type modelLLM struct {
+	*testutil.MockLLMClient
+	model  string
+	parent *modelClients
+}
+
+func (l *modelLLM) ChatWithToolsCached(ctx context.Context, systemPrompt string, messages []domain.LLMMessage, tools []domain.ToolDefinition, cacheConfig *ports.CacheConfig) (*domain.LLMResponse, error) {
+	l.parent.calls = append(l.parent.calls, l.model)
+	if err := l.parent.failing[l.model]; err != nil {
+		return nil, err
+	}
+	return &domain.LLMResponse{Text: "ok", Usage: domain.LLMUsage{Model: l.model}}, nil
+}
+
+const routes = "agent2=haiku; agent1:complex=sonnet,haiku; agent1@acme=opus; *@acme=sonnet"
+
+func newRouter(t *testing.T, clients *modelClients) *llmrouter.Router {
+	t.Helper()
+	rules, err := llmrouter.ParseRules(routes)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	r, err := llmrouter.NewRouter(clients.newClient, rules, []string{"haiku", "haiku-fallback"}, nil)
+	if err != nil {
+		t.Fatalf("router: %v", err)
+	}
+	return r
+}
+
+func TestRouter_RoutesByStageComplexityTenant(t *testing.T) {
+	r := newRouter(t, &modelClients{})
+	cases := []struct {
+		name  string
+		stage string
+		hints domain.LLMRouteHints
+		want  string
+	}{
+		{"default chain", "chat", domain.LLMRouteHints{}, "haiku"},
+		{"agent2 rendering", "agent2", domain.LLMRouteHints{}, "haiku"},
+		{"simple agent1 uses default", "agent1", domain.LLMRouteHints{Complexity: domain.QueryComplexitySimple}, "haiku"},
+		{"ambiguous agent1", "agent1", domain.LLMRouteHints{Complexity: domain.QueryComplexityComplex}, "sonnet"},
+		{"tenant-specific agent1", "agent1", domain.LLMRouteHints{TenantSlug: "acme"}, "opus"},
+		{"tenant wildcard stage", "agent2", domain.LLMRouteHints{TenantSlug: "acme"}, "sonnet"},
+	}
+	for _, c := range cases {
+		ctx := domain.WithRouteHints(domain.WithStage(context.Background(), c.stage), c.hints)
+		if got := r.Route(ctx)[0]; got != c.want {
+			t.Errorf("%s: want %s, got %s", c.name, c.want, got)
+		}
+	}
+}
+
+func TestRouter_FallsBackOnOverload(t *testing.T) {
+	clients := &modelClients{failing: map[string]error{
+		"sonnet": domain.NewLLMProviderError(529, "", "overloaded"),
+	}}
+	r := newRouter(t, clients)
+
+	sc := domain.NewSpanCollector()
+	ctx := domain.WithQueryComplexity(domain.WithStage(domain.WithSpanCollector(context.Background(), sc), "agent1"), domain.QueryComplexityComplex)
+	resp, err := r.ChatWithToolsCached(ctx, "", nil, nil, nil)
+	if err != nil {
+		t.Fatalf("want fallback success, got %v", err)
+	}
+	if resp.Usage.Model != "haiku" {
+		t.Errorf("usage must report the model that answered, got %q", resp.Usage.Model)
+	}
+	if len(clients.calls) != 2 || clients.calls[0] != "sonnet" {
+		t.Errorf("want sonnet then haiku, got %v", clients.calls)
+	}
+	var fallbackSpans int
+	for _, s := range sc.Spans() {
+		if s.Name == "agent1.llm.fallback" {
+			fallbackSpans++
+		}
+	}
+	if fallbackSpans != 1 {
+		t.Errorf("want 1 fallback span, got %d", fallbackSpans)
+	}
+}
+
+func TestRouter_OpenBreakerFallsBack(t *testing.T) {
+	clients := &modelClients{failing: map[string]error{"haiku": domain.ErrLLMUnavailable}}
+	r := newRouter(t, clients)
+
+	resp, err := r.ChatWithToolsCached(domain.WithStage(context.Background(), "chat"), "", nil, nil, nil)
+	if err != nil || resp.Usage.Model != "haiku-fallback" {
+		t.Fatalf("want default fallback model, got %v / %+v", err, resp)
+	}
+}
+
+func TestRouter_ClientErrorDoesNotFallBack(t *testing.T) {
+	bad := domain.NewLLMProviderError(400, "", "bad request")
+	clients := &modelClients{failing: map[string]error{"haiku": bad}}
+	r := newRouter(t, clients)
+
+	_, err := r.ChatWithToolsCached(domain.WithStage(context.Background(), "agent2"), "", nil, nil, nil)
+	if !errors.Is(err, bad) {
+		t.Fatalf("want the 400 error, got %v", err)
+	}
+	if len(clients.calls) != 1 {
+		t.Errorf("400 must not fall back, got calls %v", clients.calls)
+	}
+}
+
+func TestParseRules_Errors(t *testing.T) {
+	for _, spec := range []string{"agent1", "agent1=", "agent1:medium=haiku"} {
+		if _, err := llmrouter.ParseRules(spec); err == nil {
+			t.Errorf("%q: want parse error", spec)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package localembed
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Algorithm must stay identical to project_admin/backend/internal/adapters/localembed:
+// admin writes product vectors, this module writes query vectors.
+
+const defaultDims = 384
+
+// HashEmbedder implements ports.EmbeddingPort without network or model files.
+// Each text becomes a signed feature-hashed bag of words + character n-grams,
+// L2-normalized — so cosine similarity tracks lexical/morphological overlap
+// ("кроссовки" ~ "кроссовок", "sneaker" ~ "sneakers"). Deterministic across runs.
+type HashEmbedder struct {
+	dims int
+}
+
+// NewHashEmbedder creates a local embedder producing vectors of the given dimension
+func NewHashEmbedder(dims int) *HashEmbedder {
+	if dims <= 0 {
+		dims = defaultDims
+	}
+	return &HashEmbedder{dims: dims}
+}
+
+// Embed returns one vector per input text
+func (e *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		out[i] = e.embed(text)
+	}
+	return out, nil
+}
+
+func (e *HashEmbedder) embed(text string) []float32 {
+	vec := make([]float64, e.dims)
+
+	for _, word := range tokenize(text) {
+		// Whole word carries the strongest signal
+		e.add(vec, "w:"+word, 1.0)
+
+		// Char 3/4-grams over "^word$" capture stems and inflections
+		runes := []rune("^" + word + "$")
+		for n := 3; n <= 4; n++ {
+			for i := 0; i+n <= len(runes); i++ {
+				e.add(vec, "g:"+string(runes[i:i+n]), 0.5)
+			}
+		}
+	}
+
+	var norm float64
+	for _, v := range vec {
+		norm += v * v
+	}
+	out := make([]float32, e.dims)
+	if norm == 0 {
+		// Empty text: fixed unit vector (zero vectors break cosine distance in pgvector)
+		out[0] = 1
+		return out
+	}
+	norm = math.Sqrt(norm)
+	for i, v := range vec {
+		out[i] = float32(v / norm)
+	}
+	return out
+}
+
+// add hashes a feature into a bucket with a hash-derived sign to cancel collision bias
+func (e *HashEmbedder) add(vec []float64, feature string, weight float64) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+	idx := int(sum % uint64(e.dims))
+	if sum>>63 == 1 {
+		weight = -weight
+	}
+	vec[idx] += weight
+}
+
+// tokenize lowercases, folds ё→е and splits on anything that is not a letter or digit
+func tokenize(text string) []string {
+	text = strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
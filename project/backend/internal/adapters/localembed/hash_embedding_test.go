@@ -0,0 +1,62 @@
+package localembed
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func cosine(a, b []float32) float64 {
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot // vectors are L2-normalized
+}
+
+func TestHashEmbedder_DeterministicAndNormalized(t *testing.T) {
+	e := NewHashEmbedder(384)
+	a, err := e.Embed(context.Background(), []string{"Крем для лица увлажняющий", "Крем для лица увлажняющий"})
+	if err != nil {
+		t.Fatalf("embed: %v", err)
+	}
+	if len(a[0]) != 384 {
+		t.Fatalf("want 384 dims, got %d", len(a[0]))
+	}
+	for i := range a[0] {
+		if a[0][i] != a[1][i] {
+			t.Fatal("same text must produce identical vectors")
+		}
+	}
+	var norm float64
+	for _, v := range a[0] {
+		norm += float64(v) * float64(v)
+	}
+	if math.Abs(norm-1) > 1e-5 {
+		t.Errorf("want unit vector, got norm² %f", norm)
+	}
+}
+
+func TestHashEmbedder_SimilarityTracksOverlap(t *testing.T) {
+	e := NewHashEmbedder(384)
+	v, _ := e.Embed(context.Background(), []string{
+		"кроссовки Nike беговые",
+		"Беговые кроссовок nike",
+		"сыворотка с витамином C",
+	})
+	related := cosine(v[0], v[1])
+	unrelated := cosine(v[0], v[2])
+	if related <= unrelated {
+		t.Errorf("inflected query should be closer: related=%f unrelated=%f", related, unrelated)
+	}
+	if related < 0.5 {
+		t.Errorf("want high similarity for inflected forms, got %f", related)
+	}
+}
+
+func TestHashEmbedder_EmptyTextIsUnitVector(t *testing.T) {
+	v, _ := NewHashEmbedder(16).Embed(context.Background(), []string{"  "})
+	if v[0][0] != 1 {
+		t.Errorf("empty text should map to fixed unit vector, got %v", v[0])
+	}
+}
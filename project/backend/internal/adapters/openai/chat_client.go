@@ -0,0 +1,396 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strings"
+	"time"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+)
+
+const defaultChatBaseURL = "https://api.openai.com/v1"
+
+// ChatClient implements ports.LLMPort for any OpenAI-compatible /v1/chat/completions
+// endpoint (OpenAI, vLLM, llama.cpp server, Azure OpenAI).
+// Uses raw net/http (no SDK) — same pattern as adapters/anthropic.
+type ChatClient struct {
+	apiKey     string
+	model      string
+	endpoint   string // full chat completions URL
+	azure      bool   // Azure uses "api-key" header instead of Bearer
+	httpClient *http.Client
+	log        *slog.Logger
+}
+
+// NewChatClient creates an OpenAI-compatible chat client.
+// baseURL is either an API root ("http://localhost:8000/v1") or a full
+// chat completions URL (Azure: ".../deployments/{name}/chat/completions?api-version=...").
+// apiKey may be empty for self-hosted servers without auth.
+func NewChatClient(baseURL, apiKey, model string) *ChatClient {
+	if baseURL == "" {
+		baseURL = defaultChatBaseURL
+	}
+	endpoint := baseURL
+	if !strings.Contains(baseURL, "/chat/completions") {
+		endpoint = strings.TrimRight(baseURL, "/") + "/chat/completions"
+	}
+	azure := false
+	if u, err := url.Parse(endpoint); err == nil {
+		azure = strings.HasSuffix(u.Hostname(), ".azure.com")
+	}
+	return &ChatClient{
+		apiKey:     apiKey,
+		model:      model,
+		endpoint:   endpoint,
+		azure:      azure,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+		log:        slog.Default(),
+	}
+}
+
+// Wire types
+
+type chatRequest struct {
+	Model      string        `json:"model"`
+	Messages   []chatMessage `json:"messages"`
+	MaxTokens  int           `json:"max_tokens,omitempty"`
+	Tools      []chatTool    `json:"tools,omitempty"`
+	ToolChoice interface{}   `json:"tool_choice,omitempty"` // "auto" | "required" | {type, function:{name}}
+}
+
+type chatMessage struct {
+	Role       string         `json:"role"` // "system", "user", "assistant", "tool"
+	Content    *string        `json:"content"`
+	ToolCalls  []chatToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+}
+
+type chatTool struct {
+	Type     string       `json:"type"` // "function"
+	Function chatFunction `json:"function"`
+}
+
+type chatFunction struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+type chatToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"` // "function"
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"` // JSON-encoded object
+	} `json:"function"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message struct {
+			Content   *string        `json:"content"`
+			ToolCalls []chatToolCall `json:"tool_calls"`
+		} `json:"message"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Chat sends a single user message and returns the text response
+func (c *ChatClient) Chat(ctx context.Context, message string) (string, error) {
+	resp, err := c.do(ctx, chatRequest{
+		Model:     c.model,
+		MaxTokens: 1024,
+		Messages:  []chatMessage{{Role: "user", Content: strPtr(message)}},
+	})
+	if err != nil {
+		return "", err
+	}
+	return resp.Text, nil
+}
+
+// ChatWithUsage sends a message with system prompt and returns response with usage stats
+func (c *ChatClient) ChatWithUsage(ctx context.Context, systemPrompt, userMessage string) (*ports.ChatResponse, error) {
+	messages := make([]chatMessage, 0, 2)
+	if systemPrompt != "" {
+		messages = append(messages, chatMessage{Role: "system", Content: strPtr(systemPrompt)})
+	}
+	messages = append(messages, chatMessage{Role: "user", Content: strPtr(userMessage)})
+
+	resp, err := c.do(ctx, chatRequest{
+		Model:     c.model,
+		MaxTokens: 1024,
+		Messages:  messages,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ports.ChatResponse{Text: resp.Text, Usage: resp.Usage}, nil
+}
+
+// ChatWithTools sends messages with tool definitions, returns potential tool calls
+func (c *ChatClient) ChatWithTools(
+	ctx context.Context,
+	systemPrompt string,
+	messages []domain.LLMMessage,
+	tools []domain.ToolDefinition,
+) (*domain.LLMResponse, error) {
+	return c.do(ctx, c.buildToolRequest(systemPrompt, messages, tools, ""))
+}
+
+// ChatWithToolsCached has no prompt caching on OpenAI-compatible servers —
+// cache flags are ignored, only ToolChoice is honored.
+func (c *ChatClient) ChatWithToolsCached(
+	ctx context.Context,
+	systemPrompt string,
+	messages []domain.LLMMessage,
+	tools []domain.ToolDefinition,
+	cacheConfig *ports.CacheConfig,
+) (*domain.LLMResponse, error) {
+	toolChoice := ""
+	if cacheConfig != nil {
+		toolChoice = cacheConfig.ToolChoice
+	}
+	return c.do(ctx, c.buildToolRequest(systemPrompt, messages, tools, toolChoice))
+}
+
+// buildToolRequest maps domain messages/tools to the chat completions format
+func (c *ChatClient) buildToolRequest(systemPrompt string, messages []domain.LLMMessage, tools []domain.ToolDefinition, toolChoice string) chatRequest {
+	chatMsgs := make([]chatMessage, 0, len(messages)+1)
+	if systemPrompt != "" {
+		chatMsgs = append(chatMsgs, chatMessage{Role: "system", Content: strPtr(systemPrompt)})
+	}
+	for _, msg := range messages {
+		chatMsgs = append(chatMsgs, convertToChatMessage(msg))
+	}
+
+	chatTools := make([]chatTool, 0, len(tools))
+	for _, t := range tools {
+		chatTools = append(chatTools, chatTool{
+			Type: "function",
+			Function: chatFunction{
+				Name:        t.Name,
+				Description: t.Description,
+				Parameters:  t.InputSchema,
+			},
+		})
+	}
+
+	req := chatRequest{
+		Model:     c.model,
+		MaxTokens: 4096,
+		Messages:  chatMsgs,
+		Tools:     chatTools,
+	}
+	if len(chatTools) > 0 {
+		req.ToolChoice = convertToolChoice(toolChoice)
+	}
+	return req
+}
+
+// do sends the request and converts the response to domain.LLMResponse.
+// Emits {stage}.llm / .llm.ttfb / .llm.body spans like the Anthropic adapter.
+func (c *ChatClient) do(ctx context.Context, reqBody chatRequest) (*domain.LLMResponse, error) {
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		if c.azure {
+			req.Header.Set("api-key", c.apiKey)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+c.apiKey)
+		}
+	}
+
+	// Span instrumentation via httptrace
+	sc := domain.SpanFromContext(ctx)
+	stage := domain.StageFromContext(ctx)
+	reqSize := len(jsonBody)
+
+	var endLLM func(...string)
+	var endTTFB func(...string)
+	if sc != nil && stage != "" {
+		endLLM = sc.Start(stage + ".llm")
+		endTTFB = sc.Start(stage + ".llm.ttfb")
+		trace := &httptrace.ClientTrace{
+			GotFirstResponseByte: func() {
+				if endTTFB != nil {
+					endTTFB()
+					endTTFB = nil
+				}
+			},
+		}
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if endTTFB != nil {
+			endTTFB()
+		}
+		if endLLM != nil {
+			endLLM()
+		}
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var endBody func(...string)
+	if sc != nil && stage != "" {
+		endBody = sc.Start(stage + ".llm.body")
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	if endBody != nil {
+		endBody()
+	}
+	if endLLM != nil {
+		endLLM(fmt.Sprintf("%dKB→%dKB", reqSize/1024, len(respBody)/1024))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		message := strings.TrimSpace(string(respBody))
+		var errResp chatResponse
+		if json.Unmarshal(respBody, &errResp) == nil && errResp.Error != nil {
+			message = errResp.Error.Message
+		}
+		return nil, domain.NewLLMProviderError(resp.StatusCode, resp.Header.Get("retry-after"), message)
+	}
+
+	var chatResp chatResponse
+	if err := json.Unmarshal(respBody, &chatResp); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if chatResp.Error != nil {
+		return nil, fmt.Errorf("openai-compatible error: %s", chatResp.Error.Message)
+	}
+	if len(chatResp.Choices) == 0 {
+		return nil, fmt.Errorf("empty response from API")
+	}
+
+	choice := chatResp.Choices[0]
+	result := &domain.LLMResponse{
+		StopReason: convertFinishReason(choice.FinishReason),
+	}
+	if choice.Message.Content != nil {
+		result.Text = *choice.Message.Content
+	}
+	for _, tc := range choice.Message.ToolCalls {
+		input := map[string]interface{}{}
+		if tc.Function.Arguments != "" {
+			if err := json.Unmarshal([]byte(tc.Function.Arguments), &input); err != nil {
+				c.log.Warn("tool arguments not valid JSON", "tool", tc.Function.Name, "error", err)
+			}
+		}
+		result.ToolCalls = append(result.ToolCalls, domain.ToolCall{
+			ID:    tc.ID,
+			Name:  tc.Function.Name,
+			Input: input,
+		})
+	}
+	// Some servers report "stop" even when tool calls are present
+	if len(result.ToolCalls) > 0 {
+		result.StopReason = "tool_use"
+	}
+
+	result.Usage = domain.LLMUsage{
+		InputTokens:  chatResp.Usage.PromptTokens,
+		OutputTokens: chatResp.Usage.CompletionTokens,
+		TotalTokens:  chatResp.Usage.PromptTokens + chatResp.Usage.CompletionTokens,
+		Model:        c.model,
+	}
+	// Self-hosted models have no price list — report $0 instead of guessing
+	if _, ok := domain.PricingForModel(result.Usage.Model); ok {
+		result.Usage.CostUSD = result.Usage.CalculateCost()
+	}
+
+	return result, nil
+}
+
+// convertToChatMessage converts a domain message to the chat completions format
+func convertToChatMessage(msg domain.LLMMessage) chatMessage {
+	if msg.ToolResult != nil {
+		return chatMessage{
+			Role:       "tool",
+			ToolCallID: msg.ToolResult.ToolUseID,
+			Content:    strPtr(msg.ToolResult.Content),
+		}
+	}
+
+	if len(msg.ToolCalls) > 0 {
+		out := chatMessage{Role: "assistant"}
+		if msg.Content != "" {
+			out.Content = strPtr(msg.Content)
+		}
+		for _, tc := range msg.ToolCalls {
+			args, err := json.Marshal(tc.Input)
+			if err != nil || tc.Input == nil {
+				args = []byte("{}")
+			}
+			call := chatToolCall{ID: tc.ID, Type: "function"}
+			call.Function.Name = tc.Name
+			call.Function.Arguments = string(args)
+			out.ToolCalls = append(out.ToolCalls, call)
+		}
+		return out
+	}
+
+	return chatMessage{Role: msg.Role, Content: strPtr(msg.Content)}
+}
+
+// convertToolChoice maps CacheConfig.ToolChoice ("auto", "any", "tool:name") to tool_choice
+func convertToolChoice(choice string) interface{} {
+	switch {
+	case choice == "any":
+		return "required"
+	case strings.HasPrefix(choice, "tool:"):
+		return map[string]interface{}{
+			"type":     "function",
+			"function": map[string]string{"name": strings.TrimPrefix(choice, "tool:")},
+		}
+	default:
+		return "auto"
+	}
+}
+
+// convertFinishReason maps OpenAI finish_reason to Anthropic-style stop_reason used in domain
+func convertFinishReason(reason string) string {
+	switch reason {
+	case "tool_calls", "function_call":
+		return "tool_use"
+	case "length":
+		return "max_tokens"
+	case "stop", "":
+		return "end_turn"
+	default:
+		return reason
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,118 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+)
+
+func TestChatClient_ChatWithToolsCached_ToolCallRoundTrip(t *testing.T) {
+	var got chatRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer test-key" {
+			t.Errorf("unexpected auth header %q", auth)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatalf("decode request: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"choices": [{
+				"message": {
+					"content": null,
+					"tool_calls": [{
+						"id": "call_1",
+						"type": "function",
+						"function": {"name": "catalog_search", "arguments": "{\"query\":\"кроссовки\"}"}
+					}]
+				},
+				"finish_reason": "tool_calls"
+			}],
+			"usage": {"prompt_tokens": 120, "completion_tokens": 15}
+		}`))
+	}))
+	defer srv.Close()
+
+	client := NewChatClient(srv.URL+"/v1", "test-key", "local-model")
+	messages := []domain.LLMMessage{
+		{Role: "user", Content: "покажи кроссовки"},
+		{Role: "assistant", ToolCalls: []domain.ToolCall{{ID: "call_0", Name: "catalog_search", Input: map[string]interface{}{"query": "обувь"}}}},
+		{Role: "user", ToolResult: &domain.ToolResult{ToolUseID: "call_0", Content: "ok"}},
+	}
+	tools := []domain.ToolDefinition{{
+		Name:        "catalog_search",
+		Description: "Search catalog",
+		InputSchema: map[string]interface{}{"type": "object"},
+	}}
+
+	resp, err := client.ChatWithToolsCached(context.Background(), "system prompt", messages, tools,
+		&ports.CacheConfig{CacheTools: true, CacheSystem: true, ToolChoice: "any"})
+	if err != nil {
+		t.Fatalf("ChatWithToolsCached: %v", err)
+	}
+
+	// Request mapping
+	if len(got.Messages) != 4 {
+		t.Fatalf("want 4 messages (system + 3), got %d", len(got.Messages))
+	}
+	if got.Messages[0].Role != "system" {
+		t.Errorf("first message should be system, got %s", got.Messages[0].Role)
+	}
+	if len(got.Messages[2].ToolCalls) != 1 || got.Messages[2].ToolCalls[0].Function.Arguments != `{"query":"обувь"}` {
+		t.Errorf("assistant tool call not mapped: %+v", got.Messages[2])
+	}
+	if got.Messages[3].Role != "tool" || got.Messages[3].ToolCallID != "call_0" {
+		t.Errorf("tool result not mapped: %+v", got.Messages[3])
+	}
+	if got.ToolChoice != "required" {
+		t.Errorf("want tool_choice required, got %v", got.ToolChoice)
+	}
+	if len(got.Tools) != 1 || got.Tools[0].Function.Name != "catalog_search" {
+		t.Errorf("tools not mapped: %+v", got.Tools)
+	}
+
+	// Response mapping
+	if resp.StopReason != "tool_use" {
+		t.Errorf("want stop_reason tool_use, got %s", resp.StopReason)
+	}
+	if len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Input["query"] != "кроссовки" {
+		t.Errorf("tool call not parsed: %+v", resp.ToolCalls)
+	}
+	if resp.Usage.InputTokens != 120 || resp.Usage.OutputTokens != 15 || resp.Usage.TotalTokens != 135 {
+		t.Errorf("usage not mapped: %+v", resp.Usage)
+	}
+	if resp.Usage.CostUSD != 0 {
+		t.Errorf("unknown model should cost $0, got %f", resp.Usage.CostUSD)
+	}
+}
+
+func TestChatClient_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"message":"rate limited"}}`))
+	}))
+	defer srv.Close()
+
+	client := NewChatClient(srv.URL, "", "local-model")
+	if _, err := client.Chat(context.Background(), "hi"); err == nil {
+		t.Fatal("want error on 429")
+	}
+}
+
+func TestConvertToolChoice(t *testing.T) {
+	if convertToolChoice("auto") != "auto" {
+		t.Error("auto should map to auto")
+	}
+	forced, ok := convertToolChoice("tool:render_product_preset").(map[string]interface{})
+	if !ok || forced["type"] != "function" {
+		t.Fatalf("tool:name should map to function choice, got %v", forced)
+	}
+}
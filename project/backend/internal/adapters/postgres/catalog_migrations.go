@@ -26,6 +26,11 @@ func (c *Client) RunCatalogMigrations(ctx context.Context) error {
 		migrationCatalogPIMIndexes,
 		migrationCatalogVolumeColumns,
 		migrationCatalogDropLegacyColumns,
+		migrationCatalogFullTextSearch,
+		migrationCatalogTrigram,
+		migrationCatalogSynonyms,
+		migrationCatalogIngredientInteractions,
+		migrationCatalogIngredientInteractionsSeed,
 	}
 
 	for i, migration := range migrations {
@@ -288,3 +293,107 @@ ALTER TABLE catalog.master_products DROP COLUMN IF EXISTS attributes;
 ALTER TABLE catalog.master_products DROP COLUMN IF EXISTS inci_text;
 DROP INDEX IF EXISTS idx_catalog_mp_short_name;
 `
+
+// migrationCatalogFullTextSearch adds stored tsvector columns for the keyword leg of hybrid search.
+// Every field is indexed with both russian and english stemming; weights: name A, brand B,
+// benefits C, description D. array_to_string is only STABLE, hence the immutable wrapper.
+const migrationCatalogFullTextSearch = `
+CREATE OR REPLACE FUNCTION catalog.immutable_array_to_string(arr TEXT[], sep TEXT)
+RETURNS TEXT LANGUAGE sql IMMUTABLE PARALLEL SAFE AS $$ SELECT array_to_string(arr, sep) $$;
+
+ALTER TABLE catalog.master_products ADD COLUMN IF NOT EXISTS search_tsv tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', COALESCE(name, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+    setweight(to_tsvector('russian', COALESCE(brand, '')), 'B') ||
+    setweight(to_tsvector('english', COALESCE(brand, '')), 'B') ||
+    setweight(to_tsvector('russian', COALESCE(catalog.immutable_array_to_string(benefits, ' '), '')), 'C') ||
+    setweight(to_tsvector('english', COALESCE(catalog.immutable_array_to_string(benefits, ' '), '')), 'C') ||
+    setweight(to_tsvector('russian', COALESCE(description, '')), 'D') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'D')
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_catalog_mp_search_tsv ON catalog.master_products USING gin (search_tsv);
+
+ALTER TABLE catalog.master_services ADD COLUMN IF NOT EXISTS search_tsv tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('russian', COALESCE(name, '')), 'A') ||
+    setweight(to_tsvector('english', COALESCE(name, '')), 'A') ||
+    setweight(to_tsvector('russian', COALESCE(brand, '')), 'B') ||
+    setweight(to_tsvector('english', COALESCE(brand, '')), 'B') ||
+    setweight(to_tsvector('russian', COALESCE(description, '')), 'D') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'D')
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_catalog_ms_search_tsv ON catalog.master_services USING gin (search_tsv);
+`
+
+// migrationCatalogTrigram enables pg_trgm for snapping misspelled brand/category filter values
+const migrationCatalogTrigram = `
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+`
+
+const migrationCatalogSynonyms = `
+CREATE TABLE IF NOT EXISTS catalog.tenant_synonyms (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES catalog.tenants(id) ON DELETE CASCADE,
+    term TEXT NOT NULL,
+    synonyms TEXT[] NOT NULL DEFAULT '{}',
+    bidirectional BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_catalog_tenant_synonyms_term ON catalog.tenant_synonyms(tenant_id, lower(term));
+`
+
+// migrationCatalogIngredientInteractions holds the rules for catalog_compatibility. Not tenant-scoped:
+// ingredient chemistry is the same for every shop.
+const migrationCatalogIngredientInteractions = `
+CREATE TABLE IF NOT EXISTS catalog.ingredient_interactions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(200) NOT NULL UNIQUE,
+    group_a TEXT[] NOT NULL,
+    group_b TEXT[] NOT NULL,
+    severity VARCHAR(20) NOT NULL DEFAULT 'caution',
+    message TEXT NOT NULL,
+    advice TEXT,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+`
+
+const migrationCatalogIngredientInteractionsSeed = `
+INSERT INTO catalog.ingredient_interactions (name, group_a, group_b, severity, message, advice) VALUES
+('retinoids + AHA/BHA',
+ ARRAY['retinol', 'retinal', 'retinyl', 'tretinoin', 'adapalene'],
+ ARRAY['salicylic acid', 'glycolic acid', 'lactic acid', 'mandelic acid', 'gluconolactone', 'aha bha'],
+ 'avoid',
+ 'Ретиноиды и кислоты вместе усиливают раздражение, сухость и шелушение',
+ 'Разнесите: кислоты утром или через день, ретиноид вечером'),
+('retinoids + benzoyl peroxide',
+ ARRAY['retinol', 'retinal', 'retinyl', 'tretinoin'],
+ ARRAY['benzoyl peroxide'],
+ 'avoid',
+ 'Бензоилпероксид окисляет ретинол и снижает его эффект',
+ 'Бензоилпероксид утром, ретиноид вечером'),
+('vitamin C + AHA/BHA',
+ ARRAY['ascorbic acid', 'vitamin c'],
+ ARRAY['salicylic acid', 'glycolic acid', 'lactic acid', 'mandelic acid', 'aha bha'],
+ 'caution',
+ 'Оба работают при низком pH: вместе повышают риск раздражения',
+ 'Витамин C утром, кислоты вечером'),
+('vitamin C + retinoids',
+ ARRAY['ascorbic acid', 'ascorbyl', 'vitamin c'],
+ ARRAY['retinol', 'retinal', 'retinyl', 'tretinoin'],
+ 'caution',
+ 'Вместе сильнее раздражают чувствительную кожу',
+ 'Витамин C утром, ретиноид вечером'),
+('vitamin C + niacinamide',
+ ARRAY['ascorbic acid', 'vitamin c'],
+ ARRAY['niacinamide'],
+ 'caution',
+ 'Чистая аскорбиновая кислота с ниацинамидом может давать покраснение',
+ 'Наносите с интервалом 10–15 минут или в разное время суток'),
+('AHA + BHA',
+ ARRAY['glycolic acid', 'lactic acid', 'mandelic acid'],
+ ARRAY['salicylic acid'],
+ 'caution',
+ 'Две отшелушивающие кислоты подряд легко пересушивают кожу',
+ 'Чередуйте по дням')
+ON CONFLICT (name) DO NOTHING;
+`
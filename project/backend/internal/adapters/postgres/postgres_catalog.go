@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/jackc/pgx/v5"
 	pgvector "github.com/pgvector/pgvector-go"
@@ -144,7 +145,8 @@ func (a *CatalogAdapter) ListProducts(ctx context.Context, tenantID string, filt
 		SELECT
 			p.id, p.tenant_id, COALESCE(p.master_product_id::text, '') as master_product_id,
 			COALESCE(p.name, '') as name, COALESCE(p.description, '') as description,
-			p.price, p.currency, COALESCE(s.quantity, 0) as stock_quantity, COALESCE(p.rating, 0) as rating,
+			p.price, p.currency, COALESCE(s.quantity, 0) as stock_quantity,
+			CASE WHEN s.product_id IS NULL THEN NULL ELSE GREATEST(s.quantity - s.reserved, 0) END as stock_available, COALESCE(p.rating, 0) as rating,
 			COALESCE(p.images, '[]') as images, COALESCE(p.tags, '[]') as tags,
 			mp.id as mp_id, mp.sku, mp.name as mp_name, mp.description as mp_description,
 			mp.brand, mp.category_id, mp.images as mp_images,
@@ -154,7 +156,7 @@ func (a *CatalogAdapter) ListProducts(ctx context.Context, tenantID string, filt
 			COALESCE(mp.routine_step, '') as routine_step,
 			mp.skin_type, mp.concern, mp.key_ingredients, mp.target_area,
 			COALESCE(mp.marketing_claim, '') as marketing_claim,
-			mp.benefits
+			mp.benefits, mp.free_from, p.created_at
 		FROM catalog.products p
 		LEFT JOIN catalog.master_products mp ON p.master_product_id = mp.id
 		LEFT JOIN catalog.categories c ON mp.category_id = c.id
@@ -170,94 +172,11 @@ func (a *CatalogAdapter) ListProducts(ctx context.Context, tenantID string, filt
 		WHERE p.tenant_id = $1
 	`
 
-	args := []interface{}{tenantID}
-	argNum := 2
-	var conditions []string
-
-	if filter.CategoryID != "" {
-		conditions = append(conditions, fmt.Sprintf("mp.category_id = $%d", argNum))
-		args = append(args, filter.CategoryID)
-		argNum++
-	}
-
-	if filter.Brand != "" {
-		conditions = append(conditions, fmt.Sprintf("mp.brand ILIKE $%d", argNum))
-		args = append(args, "%"+filter.Brand+"%")
-		argNum++
-	}
-
-	if filter.MinPrice > 0 {
-		conditions = append(conditions, fmt.Sprintf("p.price >= $%d", argNum))
-		args = append(args, filter.MinPrice)
-		argNum++
-	}
-
-	if filter.MaxPrice > 0 {
-		conditions = append(conditions, fmt.Sprintf("p.price <= $%d", argNum))
-		args = append(args, filter.MaxPrice)
-		argNum++
-	}
-
-	if filter.Search != "" {
-		// Split search into words and match ANY word in any field (OR between words)
-		words := strings.Fields(filter.Search)
-		if len(words) == 1 {
-			conditions = append(conditions, fmt.Sprintf("(p.name ILIKE $%d OR mp.name ILIKE $%d OR mp.brand ILIKE $%d)", argNum, argNum, argNum))
-			args = append(args, "%"+words[0]+"%")
-			argNum++
-		} else if len(words) > 1 {
-			var wordConds []string
-			for _, word := range words {
-				wordConds = append(wordConds, fmt.Sprintf("(p.name ILIKE $%d OR mp.name ILIKE $%d OR mp.brand ILIKE $%d)", argNum, argNum, argNum))
-				args = append(args, "%"+word+"%")
-				argNum++
-			}
-			conditions = append(conditions, "("+strings.Join(wordConds, " OR ")+")")
-		}
-	}
-
-	if filter.CategoryName != "" {
-		conditions = append(conditions, fmt.Sprintf("(c.name ILIKE $%d OR c.slug ILIKE $%d)", argNum, argNum))
-		args = append(args, "%"+filter.CategoryName+"%")
-		argNum++
-	}
-
-	// Typed PIM filters
-	if filter.ProductForm != "" {
-		conditions = append(conditions, fmt.Sprintf("mp.product_form = $%d", argNum))
-		args = append(args, filter.ProductForm)
-		argNum++
-	}
-	if filter.SkinType != "" {
-		conditions = append(conditions, fmt.Sprintf("$%d = ANY(mp.skin_type)", argNum))
-		args = append(args, filter.SkinType)
-		argNum++
-	}
-	if filter.Concern != "" {
-		conditions = append(conditions, fmt.Sprintf("$%d = ANY(mp.concern)", argNum))
-		args = append(args, filter.Concern)
-		argNum++
-	}
-	if filter.KeyIngredient != "" {
-		conditions = append(conditions, fmt.Sprintf("$%d = ANY(mp.key_ingredients)", argNum))
-		args = append(args, filter.KeyIngredient)
-		argNum++
-	}
-	if filter.TargetArea != "" {
-		conditions = append(conditions, fmt.Sprintf("$%d = ANY(mp.target_area)", argNum))
-		args = append(args, filter.TargetArea)
-		argNum++
-	}
-	if filter.RoutineStep != "" {
-		conditions = append(conditions, fmt.Sprintf("mp.routine_step = $%d", argNum))
-		args = append(args, filter.RoutineStep)
-		argNum++
-	}
-	if filter.Texture != "" {
-		conditions = append(conditions, fmt.Sprintf("mp.texture = $%d", argNum))
-		args = append(args, filter.Texture)
-		argNum++
+	conditions, search, args := productFilterConditions(filter, []interface{}{tenantID})
+	if search.cond != "" {
+		conditions = append(conditions, search.cond)
 	}
+	argNum := len(args) + 1
 
 	if len(conditions) > 0 {
 		condStr := " AND " + strings.Join(conditions, " AND ")
@@ -281,8 +200,12 @@ func (a *CatalogAdapter) ListProducts(ctx context.Context, tenantID string, filt
 		offset = 0
 	}
 
-	// Dynamic ORDER BY (whitelist only — prevents SQL injection)
+	// Dynamic ORDER BY (whitelist only — prevents SQL injection).
+	// Text search orders by ts_rank_cd unless an explicit sort is requested.
 	orderClause := "p.created_at DESC"
+	if search.rank != "" {
+		orderClause = search.rank + " DESC NULLS LAST, p.created_at DESC"
+	}
 	if filter.SortField != "" {
 		sortOrder := "ASC"
 		if strings.ToUpper(filter.SortOrder) == "DESC" {
@@ -312,17 +235,17 @@ func (a *CatalogAdapter) ListProducts(ctx context.Context, tenantID string, filt
 		var masterProductID, mpID, mpSKU, mpName, mpDesc, mpBrand, mpCategoryID, categoryName *string
 		var productImagesJSON, tagsJSON, mpImagesJSON []byte
 		var mpProductForm, mpTexture, mpRoutineStep, mpMarketingClaim *string
-		var mpSkinType, mpConcern, mpKeyIngredients, mpTargetArea, mpBenefits []string
+		var mpSkinType, mpConcern, mpKeyIngredients, mpTargetArea, mpBenefits, mpFreeFrom []string
 
 		err := rows.Scan(
 			&p.ID, &p.TenantID, &masterProductID,
-			&p.Name, &p.Description, &p.Price, &p.Currency, &p.StockQuantity, &p.Rating, &productImagesJSON, &tagsJSON,
+			&p.Name, &p.Description, &p.Price, &p.Currency, &p.StockQuantity, &p.StockAvailable, &p.Rating, &productImagesJSON, &tagsJSON,
 			&mpID, &mpSKU, &mpName, &mpDesc,
 			&mpBrand, &mpCategoryID, &mpImagesJSON,
 			&categoryName,
 			&mpProductForm, &mpTexture, &mpRoutineStep,
 			&mpSkinType, &mpConcern, &mpKeyIngredients, &mpTargetArea,
-			&mpMarketingClaim, &mpBenefits,
+			&mpMarketingClaim, &mpBenefits, &mpFreeFrom, &p.CreatedAt,
 		)
 		if err != nil {
 			return nil, 0, fmt.Errorf("scan product: %w", err)
@@ -343,6 +266,7 @@ func (a *CatalogAdapter) ListProducts(ctx context.Context, tenantID string, filt
 		// Merge with master product data
 		if err := mergeProductWithMaster(&p, masterProductRow{
 			MasterProductID: masterProductID,
+			SKU:             mpSKU,
 			Name:            mpName,
 			Description:     mpDesc,
 			Brand:           mpBrand,
@@ -357,6 +281,7 @@ func (a *CatalogAdapter) ListProducts(ctx context.Context, tenantID string, filt
 			TargetArea:      mpTargetArea,
 			MarketingClaim:  mpMarketingClaim,
 			Benefits:        mpBenefits,
+			FreeFrom:        mpFreeFrom,
 		}); err != nil {
 			return nil, 0, err
 		}
@@ -380,7 +305,8 @@ func (a *CatalogAdapter) GetProduct(ctx context.Context, tenantID string, produc
 		SELECT
 			p.id, p.tenant_id, COALESCE(p.master_product_id::text, '') as master_product_id,
 			COALESCE(p.name, '') as name, COALESCE(p.description, '') as description,
-			p.price, p.currency, COALESCE(s.quantity, 0) as stock_quantity, COALESCE(p.rating, 0) as rating,
+			p.price, p.currency, COALESCE(s.quantity, 0) as stock_quantity,
+			CASE WHEN s.product_id IS NULL THEN NULL ELSE GREATEST(s.quantity - s.reserved, 0) END as stock_available, COALESCE(p.rating, 0) as rating,
 			COALESCE(p.images, '[]') as images, COALESCE(p.tags, '[]') as tags,
 			mp.id as mp_id, mp.sku, mp.name as mp_name, mp.description as mp_description,
 			mp.brand, mp.category_id, mp.images as mp_images,
@@ -390,7 +316,7 @@ func (a *CatalogAdapter) GetProduct(ctx context.Context, tenantID string, produc
 			COALESCE(mp.routine_step, '') as routine_step,
 			mp.skin_type, mp.concern, mp.key_ingredients, mp.target_area,
 			COALESCE(mp.marketing_claim, '') as marketing_claim,
-			mp.benefits
+			mp.benefits, mp.free_from, p.created_at
 		FROM catalog.products p
 		LEFT JOIN catalog.master_products mp ON p.master_product_id = mp.id
 		LEFT JOIN catalog.categories c ON mp.category_id = c.id
@@ -402,17 +328,17 @@ func (a *CatalogAdapter) GetProduct(ctx context.Context, tenantID string, produc
 	var masterProductID, mpID, mpSKU, mpName, mpDesc, mpBrand, mpCategoryID, categoryName *string
 	var productImagesJSON, tagsJSON, mpImagesJSON []byte
 	var mpProductForm, mpTexture, mpRoutineStep, mpMarketingClaim *string
-	var mpSkinType, mpConcern, mpKeyIngredients, mpTargetArea, mpBenefits []string
+	var mpSkinType, mpConcern, mpKeyIngredients, mpTargetArea, mpBenefits, mpFreeFrom []string
 
 	err := a.client.pool.QueryRow(ctx, query, tenantID, productID).Scan(
 		&p.ID, &p.TenantID, &masterProductID,
-		&p.Name, &p.Description, &p.Price, &p.Currency, &p.StockQuantity, &p.Rating, &productImagesJSON, &tagsJSON,
+		&p.Name, &p.Description, &p.Price, &p.Currency, &p.StockQuantity, &p.StockAvailable, &p.Rating, &productImagesJSON, &tagsJSON,
 		&mpID, &mpSKU, &mpName, &mpDesc,
 		&mpBrand, &mpCategoryID, &mpImagesJSON,
 		&categoryName,
 		&mpProductForm, &mpTexture, &mpRoutineStep,
 		&mpSkinType, &mpConcern, &mpKeyIngredients, &mpTargetArea,
-		&mpMarketingClaim, &mpBenefits,
+		&mpMarketingClaim, &mpBenefits, &mpFreeFrom, &p.CreatedAt,
 	)
 
 	if err != nil {
@@ -437,6 +363,7 @@ func (a *CatalogAdapter) GetProduct(ctx context.Context, tenantID string, produc
 	// Merge with master product data
 	if err := mergeProductWithMaster(&p, masterProductRow{
 		MasterProductID: masterProductID,
+		SKU:             mpSKU,
 		Name:            mpName,
 		Description:     mpDesc,
 		Brand:           mpBrand,
@@ -451,6 +378,7 @@ func (a *CatalogAdapter) GetProduct(ctx context.Context, tenantID string, produc
 		TargetArea:      mpTargetArea,
 		MarketingClaim:  mpMarketingClaim,
 		Benefits:        mpBenefits,
+		FreeFrom:        mpFreeFrom,
 	}); err != nil {
 		return nil, err
 	}
@@ -491,6 +419,7 @@ func formatPrice(kopecks int, currency string) string {
 // masterProductRow holds scanned master-product join columns.
 type masterProductRow struct {
 	MasterProductID *string
+	SKU             *string
 	Name            *string
 	Description     *string
 	Brand           *string
@@ -506,6 +435,7 @@ type masterProductRow struct {
 	TargetArea     []string
 	MarketingClaim *string
 	Benefits       []string
+	FreeFrom       []string
 }
 
 // mergeProductWithMaster fills product fields from a master-product row.
@@ -514,6 +444,9 @@ func mergeProductWithMaster(p *domain.Product, mp masterProductRow) error {
 		return nil
 	}
 	p.MasterProductID = *mp.MasterProductID
+	if mp.SKU != nil {
+		p.SKU = *mp.SKU
+	}
 
 	if p.Name == "" && mp.Name != nil {
 		p.Name = *mp.Name
@@ -550,6 +483,7 @@ func mergeProductWithMaster(p *domain.Product, mp masterProductRow) error {
 		p.MarketingClaim = *mp.MarketingClaim
 	}
 	p.Benefits = mp.Benefits
+	p.FreeFrom = mp.FreeFrom
 	return nil
 }
 
@@ -564,7 +498,8 @@ func (a *CatalogAdapter) VectorSearch(ctx context.Context, tenantID string, embe
 		SELECT
 			p.id, p.tenant_id, COALESCE(p.master_product_id::text, '') as master_product_id,
 			COALESCE(p.name, '') as name, COALESCE(p.description, '') as description,
-			p.price, p.currency, COALESCE(st.quantity, 0) as stock_quantity, COALESCE(p.rating, 0) as rating,
+			p.price, p.currency, COALESCE(st.quantity, 0) as stock_quantity,
+			CASE WHEN st.product_id IS NULL THEN NULL ELSE GREATEST(st.quantity - st.reserved, 0) END as stock_available, COALESCE(p.rating, 0) as rating,
 			COALESCE(p.images, '[]') as images, COALESCE(p.tags, '[]') as tags,
 			mp.id as mp_id, mp.sku, mp.name as mp_name, mp.description as mp_description,
 			mp.brand, mp.category_id, mp.images as mp_images,
@@ -574,7 +509,7 @@ func (a *CatalogAdapter) VectorSearch(ctx context.Context, tenantID string, embe
 			COALESCE(mp.routine_step, '') as routine_step,
 			mp.skin_type, mp.concern, mp.key_ingredients, mp.target_area,
 			COALESCE(mp.marketing_claim, '') as marketing_claim,
-			mp.benefits
+			mp.benefits, mp.free_from, p.created_at
 		FROM catalog.products p
 		JOIN catalog.master_products mp ON p.master_product_id = mp.id
 		LEFT JOIN catalog.categories c ON mp.category_id = c.id
@@ -632,6 +567,20 @@ func (a *CatalogAdapter) VectorSearch(ctx context.Context, tenantID string, embe
 			args = append(args, filter.TargetArea)
 			argNum++
 		}
+		if filter.MaxPrice > 0 {
+			query += fmt.Sprintf(" AND p.price <= $%d", argNum)
+			args = append(args, filter.MaxPrice)
+			argNum++
+		}
+		var conditions []string
+		conditions, args = attributeFilterConditions(filter.AttributeFilter, args)
+		for _, c := range conditions {
+			query += " AND " + c
+		}
+		if filter.InStockOnly {
+			query += " AND " + inStockCondition
+		}
+		argNum = len(args) + 1
 	}
 
 	query += fmt.Sprintf(" ORDER BY mp.embedding <=> $2 LIMIT $%d", argNum)
@@ -649,17 +598,17 @@ func (a *CatalogAdapter) VectorSearch(ctx context.Context, tenantID string, embe
 		var masterProductID, mpID, mpSKU, mpName, mpDesc, mpBrand, mpCategoryID, categoryName *string
 		var productImagesJSON, tagsJSON, mpImagesJSON []byte
 		var mpProductForm, mpTexture, mpRoutineStep, mpMarketingClaim *string
-		var mpSkinType, mpConcern, mpKeyIngredients, mpTargetArea, mpBenefits []string
+		var mpSkinType, mpConcern, mpKeyIngredients, mpTargetArea, mpBenefits, mpFreeFrom []string
 
 		err := rows.Scan(
 			&p.ID, &p.TenantID, &masterProductID,
-			&p.Name, &p.Description, &p.Price, &p.Currency, &p.StockQuantity, &p.Rating, &productImagesJSON, &tagsJSON,
+			&p.Name, &p.Description, &p.Price, &p.Currency, &p.StockQuantity, &p.StockAvailable, &p.Rating, &productImagesJSON, &tagsJSON,
 			&mpID, &mpSKU, &mpName, &mpDesc,
 			&mpBrand, &mpCategoryID, &mpImagesJSON,
 			&categoryName,
 			&mpProductForm, &mpTexture, &mpRoutineStep,
 			&mpSkinType, &mpConcern, &mpKeyIngredients, &mpTargetArea,
-			&mpMarketingClaim, &mpBenefits,
+			&mpMarketingClaim, &mpBenefits, &mpFreeFrom, &p.CreatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scan vector product: %w", err)
@@ -677,6 +626,7 @@ func (a *CatalogAdapter) VectorSearch(ctx context.Context, tenantID string, embe
 
 		if err := mergeProductWithMaster(&p, masterProductRow{
 			MasterProductID: masterProductID,
+			SKU:             mpSKU,
 			Name:            mpName,
 			Description:     mpDesc,
 			Brand:           mpBrand,
@@ -691,6 +641,7 @@ func (a *CatalogAdapter) VectorSearch(ctx context.Context, tenantID string, embe
 			TargetArea:      mpTargetArea,
 			MarketingClaim:  mpMarketingClaim,
 			Benefits:        mpBenefits,
+			FreeFrom:        mpFreeFrom,
 		}); err != nil {
 			return nil, err
 		}
@@ -1126,6 +1077,8 @@ func (a *CatalogAdapter) ListServices(ctx context.Context, tenantID string, filt
 		args = append(args, "%"+filter.Brand+"%")
 		argNum++
 	}
+	conditions, args = brandListConditions("ms.brand", filter.Brands, filter.ExcludeBrands, conditions, args)
+	argNum = len(args) + 1
 
 	if filter.MinPrice > 0 {
 		conditions = append(conditions, fmt.Sprintf("sv.price >= $%d", argNum))
@@ -1139,21 +1092,14 @@ func (a *CatalogAdapter) ListServices(ctx context.Context, tenantID string, filt
 		argNum++
 	}
 
-	if filter.Search != "" {
-		words := strings.Fields(filter.Search)
-		if len(words) == 1 {
-			conditions = append(conditions, fmt.Sprintf("(sv.name ILIKE $%d OR ms.name ILIKE $%d OR ms.brand ILIKE $%d)", argNum, argNum, argNum))
-			args = append(args, "%"+words[0]+"%")
-			argNum++
-		} else if len(words) > 1 {
-			var wordConds []string
-			for _, word := range words {
-				wordConds = append(wordConds, fmt.Sprintf("(sv.name ILIKE $%d OR ms.name ILIKE $%d OR ms.brand ILIKE $%d)", argNum, argNum, argNum))
-				args = append(args, "%"+word+"%")
-				argNum++
-			}
-			conditions = append(conditions, "("+strings.Join(wordConds, " OR ")+")")
-		}
+	// Full-text: same scheme as products, against ms.search_tsv
+	var searchRank string
+	if terms := tsQueryTerms(filter.Search); terms != "" {
+		tsq := fmt.Sprintf("(to_tsquery('russian', $%d) || to_tsquery('english', $%d))", argNum, argNum)
+		conditions = append(conditions, fmt.Sprintf("(ms.search_tsv @@ %s OR (sv.name IS NOT NULL AND to_tsvector('russian', sv.name) @@ %s))", tsq, tsq))
+		searchRank = fmt.Sprintf("ts_rank_cd(ms.search_tsv, %s)", tsq)
+		args = append(args, terms)
+		argNum++
 	}
 
 	if len(conditions) > 0 {
@@ -1177,6 +1123,9 @@ func (a *CatalogAdapter) ListServices(ctx context.Context, tenantID string, filt
 	}
 
 	orderClause := "sv.created_at DESC"
+	if searchRank != "" {
+		orderClause = searchRank + " DESC NULLS LAST, sv.created_at DESC"
+	}
 	if filter.SortField != "" {
 		sortOrder := "ASC"
 		if strings.ToUpper(filter.SortOrder) == "DESC" {
@@ -1355,6 +1304,12 @@ func (a *CatalogAdapter) VectorSearchServices(ctx context.Context, tenantID stri
 			args = append(args, "%"+filter.CategoryName+"%")
 			argNum++
 		}
+		var conditions []string
+		conditions, args = brandListConditions("ms.brand", filter.Brands, filter.ExcludeBrands, nil, args)
+		for _, c := range conditions {
+			query += " AND " + c
+		}
+		argNum = len(args) + 1
 	}
 
 	query += fmt.Sprintf(" ORDER BY ms.embedding <=> $2 LIMIT $%d", argNum)
@@ -1454,3 +1409,193 @@ func (a *CatalogAdapter) GetMasterServicesWithoutEmbedding(ctx context.Context)
 
 	return services, nil
 }
+
+// textSearch is a full-text match condition and its rank expression (zero value = no search)
+type textSearch struct {
+	cond string
+	rank string
+}
+
+// inStockCondition drops products whose stock row has nothing available; products without
+// a stock row are not tracked and stay (stock_available is NULL for them). It does not rely
+// on the outer query joining catalog.stock, so count and facet queries can use it as is.
+const inStockCondition = `NOT EXISTS (SELECT 1 FROM catalog.stock sa
+	WHERE sa.tenant_id = p.tenant_id AND sa.product_id = p.id AND sa.quantity - sa.reserved <= 0)`
+
+// productFilterConditions builds the WHERE conditions of a ProductFilter, numbering
+// placeholders after args. The full-text Search condition is returned separately
+// so callers can widen it (facets OR it with vector hits) and order by its rank.
+func productFilterConditions(filter ports.ProductFilter, args []interface{}) ([]string, textSearch, []interface{}) {
+	argNum := len(args) + 1
+	var conditions []string
+	var search textSearch
+
+	if filter.CategoryID != "" {
+		conditions = append(conditions, fmt.Sprintf("mp.category_id = $%d", argNum))
+		args = append(args, filter.CategoryID)
+		argNum++
+	}
+
+	if filter.Brand != "" {
+		conditions = append(conditions, fmt.Sprintf("mp.brand ILIKE $%d", argNum))
+		args = append(args, "%"+filter.Brand+"%")
+		argNum++
+	}
+
+	if filter.MinPrice > 0 {
+		conditions = append(conditions, fmt.Sprintf("p.price >= $%d", argNum))
+		args = append(args, filter.MinPrice)
+		argNum++
+	}
+
+	if filter.MaxPrice > 0 {
+		conditions = append(conditions, fmt.Sprintf("p.price <= $%d", argNum))
+		args = append(args, filter.MaxPrice)
+		argNum++
+	}
+
+	if filter.CategoryName != "" {
+		conditions = append(conditions, fmt.Sprintf("(c.name ILIKE $%d OR c.slug ILIKE $%d)", argNum, argNum))
+		args = append(args, "%"+filter.CategoryName+"%")
+		argNum++
+	}
+
+	// Typed PIM filters
+	if filter.ProductForm != "" {
+		conditions = append(conditions, fmt.Sprintf("mp.product_form = $%d", argNum))
+		args = append(args, filter.ProductForm)
+		argNum++
+	}
+	if filter.SkinType != "" {
+		conditions = append(conditions, fmt.Sprintf("$%d = ANY(mp.skin_type)", argNum))
+		args = append(args, filter.SkinType)
+		argNum++
+	}
+	if filter.Concern != "" {
+		conditions = append(conditions, fmt.Sprintf("$%d = ANY(mp.concern)", argNum))
+		args = append(args, filter.Concern)
+		argNum++
+	}
+	if filter.KeyIngredient != "" {
+		conditions = append(conditions, fmt.Sprintf("$%d = ANY(mp.key_ingredients)", argNum))
+		args = append(args, filter.KeyIngredient)
+		argNum++
+	}
+	if filter.TargetArea != "" {
+		conditions = append(conditions, fmt.Sprintf("$%d = ANY(mp.target_area)", argNum))
+		args = append(args, filter.TargetArea)
+		argNum++
+	}
+	if filter.RoutineStep != "" {
+		conditions = append(conditions, fmt.Sprintf("mp.routine_step = $%d", argNum))
+		args = append(args, filter.RoutineStep)
+		argNum++
+	}
+	if filter.RoutineTime != "" {
+		// "both" products belong to morning and evening routines
+		conditions = append(conditions, fmt.Sprintf("mp.routine_time IN ($%d, 'both')", argNum))
+		args = append(args, filter.RoutineTime)
+		argNum++
+	}
+	if filter.Texture != "" {
+		conditions = append(conditions, fmt.Sprintf("mp.texture = $%d", argNum))
+		args = append(args, filter.Texture)
+		argNum++
+	}
+
+	var attrConditions []string
+	attrConditions, args = attributeFilterConditions(filter.AttributeFilter, args)
+	conditions = append(conditions, attrConditions...)
+	argNum = len(args) + 1
+
+	if filter.InStockOnly {
+		conditions = append(conditions, inStockCondition)
+	}
+
+	// Full-text: stemmed (russian + english) match of ANY word against mp.search_tsv.
+	// Tenant name overrides have no stored vector and are matched on the fly.
+	if terms := tsQueryTerms(filter.Search); terms != "" {
+		tsq := fmt.Sprintf("(to_tsquery('russian', $%d) || to_tsquery('english', $%d))", argNum, argNum)
+		search.cond = fmt.Sprintf("(mp.search_tsv @@ %s OR (p.name IS NOT NULL AND to_tsvector('russian', p.name) @@ %s))", tsq, tsq)
+		search.rank = fmt.Sprintf("ts_rank_cd(mp.search_tsv, %s)", tsq)
+		args = append(args, terms)
+	}
+
+	return conditions, search, args
+}
+
+// attributeFilterConditions builds SQL for the list-valued product filters (mp = master_products).
+// Exclusions keep rows with NULL columns: a product without a brand is "not La Roche-Posay".
+func attributeFilterConditions(f ports.AttributeFilter, args []interface{}) ([]string, []interface{}) {
+	var conditions []string
+	conditions, args = brandListConditions("mp.brand", f.Brands, f.ExcludeBrands, conditions, args)
+
+	if len(f.ProductForms) > 0 {
+		args = append(args, f.ProductForms)
+		conditions = append(conditions, fmt.Sprintf("mp.product_form = ANY($%d)", len(args)))
+	}
+	if len(f.ExcludeProductForms) > 0 {
+		args = append(args, f.ExcludeProductForms)
+		conditions = append(conditions, fmt.Sprintf("COALESCE(mp.product_form, '') <> ALL($%d)", len(args)))
+	}
+
+	op := "&&"
+	if f.Match == ports.MatchAll {
+		op = "@>"
+	}
+	for _, c := range []struct {
+		column string
+		values []string
+	}{
+		{"mp.skin_type", f.SkinTypes},
+		{"mp.concern", f.Concerns},
+		{"mp.key_ingredients", f.KeyIngredients},
+		{"mp.target_area", f.TargetAreas},
+	} {
+		if len(c.values) > 0 {
+			args = append(args, c.values)
+			conditions = append(conditions, fmt.Sprintf("%s %s $%d::text[]", c.column, op, len(args)))
+		}
+	}
+
+	if len(f.FreeFrom) > 0 {
+		args = append(args, f.FreeFrom)
+		conditions = append(conditions, fmt.Sprintf("mp.free_from @> $%d::text[]", len(args)))
+	}
+	if len(f.ExcludeKeyIngredients) > 0 {
+		args = append(args, f.ExcludeKeyIngredients)
+		conditions = append(conditions, fmt.Sprintf("NOT (COALESCE(mp.key_ingredients, '{}') && $%d::text[])", len(args)))
+	}
+	return conditions, args
+}
+
+// brandListConditions adds include (ILIKE ANY) and exclude (NOT ILIKE ALL) brand lists for column
+func brandListConditions(column string, include, exclude []string, conditions []string, args []interface{}) ([]string, []interface{}) {
+	if len(include) > 0 {
+		args = append(args, likePatterns(include))
+		conditions = append(conditions, fmt.Sprintf("%s ILIKE ANY($%d)", column, len(args)))
+	}
+	if len(exclude) > 0 {
+		args = append(args, likePatterns(exclude))
+		conditions = append(conditions, fmt.Sprintf("COALESCE(%s, '') NOT ILIKE ALL($%d)", column, len(args)))
+	}
+	return conditions, args
+}
+
+// likePatterns wraps values for substring ILIKE matching
+func likePatterns(values []string) []string {
+	patterns := make([]string, len(values))
+	for i, v := range values {
+		patterns[i] = "%" + v + "%"
+	}
+	return patterns
+}
+
+// tsQueryTerms turns free text into a to_tsquery OR-expression ("кремы | увлажняющие").
+// Only letters and digits survive, so the result is always valid tsquery syntax.
+func tsQueryTerms(text string) string {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " | ")
+}
@@ -0,0 +1,132 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+)
+
+// facetOrder is the order facets are returned in
+var facetOrder = []string{
+	domain.FacetBrand,
+	domain.FacetCategory,
+	domain.FacetPrice,
+	domain.FacetProductForm,
+	domain.FacetSkinType,
+	domain.FacetConcern,
+}
+
+// GetProductFacets counts attribute values over the products a search matched.
+// Uses the same conditions as ListProducts; the full-text condition is widened with
+// productIDs so semantic (vector) hits that miss the keywords are counted too.
+func (a *CatalogAdapter) GetProductFacets(ctx context.Context, tenantID string, filter ports.ProductFilter, productIDs []string) ([]domain.Facet, error) {
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("db.product_facets")
+		defer endSpan()
+	}
+
+	conditions, search, args := productFilterConditions(filter, []interface{}{tenantID})
+	if search.cond != "" {
+		searchCond := search.cond
+		if len(productIDs) > 0 {
+			searchCond = fmt.Sprintf("(%s OR p.id::text = ANY($%d))", searchCond, len(args)+1)
+			args = append(args, productIDs)
+		}
+		conditions = append(conditions, searchCond)
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = " AND " + strings.Join(conditions, " AND ")
+	}
+
+	// Price edges in kopecks
+	edges := make([]int64, len(domain.FacetPriceEdges))
+	for i, e := range domain.FacetPriceEdges {
+		edges[i] = int64(e) * 100
+	}
+	args = append(args, edges)
+	edgesArg := len(args)
+
+	query := `
+		WITH f AS (
+			SELECT p.price::bigint AS price,
+				COALESCE(mp.brand, '') AS brand,
+				COALESCE(c.name, '') AS category,
+				COALESCE(mp.product_form, '') AS product_form,
+				COALESCE(mp.skin_type, '{}') AS skin_type,
+				COALESCE(mp.concern, '{}') AS concern
+			FROM catalog.products p
+			LEFT JOIN catalog.master_products mp ON p.master_product_id = mp.id
+			LEFT JOIN catalog.categories c ON mp.category_id = c.id
+			WHERE p.tenant_id = $1` + where + `
+		)
+		SELECT 'brand', brand, COUNT(*) FROM f WHERE brand <> '' GROUP BY brand
+		UNION ALL
+		SELECT 'category', category, COUNT(*) FROM f WHERE category <> '' GROUP BY category
+		UNION ALL
+		SELECT 'price', width_bucket(price, $` + strconv.Itoa(edgesArg) + `::bigint[])::text, COUNT(*) FROM f GROUP BY 2
+		UNION ALL
+		SELECT 'product_form', product_form, COUNT(*) FROM f WHERE product_form <> '' GROUP BY product_form
+		UNION ALL
+		SELECT 'skin_type', v, COUNT(*) FROM f, unnest(f.skin_type) AS v GROUP BY v
+		UNION ALL
+		SELECT 'concern', v, COUNT(*) FROM f, unnest(f.concern) AS v GROUP BY v
+	`
+
+	rows, err := a.client.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query product facets: %w", err)
+	}
+	defer rows.Close()
+
+	values := make(map[string][]domain.FacetValue)
+	for rows.Next() {
+		var field, value string
+		var count int
+		if err := rows.Scan(&field, &value, &count); err != nil {
+			return nil, fmt.Errorf("scan product facet: %w", err)
+		}
+		fv := domain.FacetValue{Value: value, Count: count}
+		if field == domain.FacetPrice {
+			bucket, err := strconv.Atoi(value)
+			if err != nil {
+				continue
+			}
+			fv = domain.FacetPriceBucket(bucket)
+			fv.Count = count
+		}
+		values[field] = append(values[field], fv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate product facets: %w", err)
+	}
+
+	facets := make([]domain.Facet, 0, len(facetOrder))
+	for _, field := range facetOrder {
+		vals := values[field]
+		if len(vals) == 0 {
+			continue
+		}
+		if field == domain.FacetPrice {
+			// Buckets in price order
+			sort.Slice(vals, func(i, j int) bool { return vals[i].Min < vals[j].Min })
+		} else {
+			sort.Slice(vals, func(i, j int) bool {
+				if vals[i].Count != vals[j].Count {
+					return vals[i].Count > vals[j].Count
+				}
+				return vals[i].Value < vals[j].Value
+			})
+			if len(vals) > domain.FacetMaxValues {
+				vals = vals[:domain.FacetMaxValues]
+			}
+		}
+		facets = append(facets, domain.Facet{Field: field, Values: vals})
+	}
+	return facets, nil
+}
@@ -197,6 +197,103 @@ func TestCatalogIntegration_ListProducts_FilterBrand(t *testing.T) {
 	}
 }
 
+func TestCatalogIntegration_ListProducts_FilterBrandLists(t *testing.T) {
+	ctx, client, catalog := catalogTestSetup(t)
+	slug := fmt.Sprintf("brands-%d", time.Now().UnixNano())
+	tenantID := ensureTestTenant(t, client, slug, "Brand Lists Test Store")
+	seedTestProducts(t, client, tenantID, 8) // Nike, Adidas, Puma, Reebok ×2
+
+	products, count, err := catalog.ListProducts(ctx, tenantID, ports.ProductFilter{
+		AttributeFilter: ports.AttributeFilter{Brands: []string{"nike", "Adidas"}},
+		Limit:           50,
+	})
+	if err != nil {
+		t.Fatalf("ListProducts with brand list: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("want 4 Nike/Adidas products, got %d", count)
+	}
+	for _, p := range products {
+		if p.Brand != "Nike" && p.Brand != "Adidas" {
+			t.Errorf("brand list leak: got product with brand %s", p.Brand)
+		}
+	}
+
+	products, count, err = catalog.ListProducts(ctx, tenantID, ports.ProductFilter{
+		AttributeFilter: ports.AttributeFilter{ExcludeBrands: []string{"Nike", "puma"}},
+		Limit:           50,
+	})
+	if err != nil {
+		t.Fatalf("ListProducts with excluded brands: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("want 4 products without Nike/Puma, got %d", count)
+	}
+	for _, p := range products {
+		if p.Brand == "Nike" || p.Brand == "Puma" {
+			t.Errorf("excluded brand leak: got product with brand %s", p.Brand)
+		}
+	}
+}
+
+func TestCatalogIntegration_ListProducts_InStockOnly(t *testing.T) {
+	ctx, client, catalog := catalogTestSetup(t)
+	slug := fmt.Sprintf("stock-%d", time.Now().UnixNano())
+	tenantID := ensureTestTenant(t, client, slug, "Stock Test Store")
+	ids := seedTestProducts(t, client, tenantID, 3)
+
+	// ids[0]: 5 in stock, 2 reserved → 3 available; ids[1]: fully reserved; ids[2]: no stock row
+	for _, row := range []struct {
+		id                 string
+		quantity, reserved int
+	}{{ids[0], 5, 2}, {ids[1], 4, 4}} {
+		if _, err := client.Pool().Exec(ctx, `
+			INSERT INTO catalog.stock (tenant_id, product_id, quantity, reserved) VALUES ($1, $2, $3, $4)
+		`, tenantID, row.id, row.quantity, row.reserved); err != nil {
+			t.Fatalf("seed stock: %v", err)
+		}
+	}
+
+	products, count, err := catalog.ListProducts(ctx, tenantID, ports.ProductFilter{Limit: 50})
+	if err != nil {
+		t.Fatalf("ListProducts: %v", err)
+	}
+	if count != 3 {
+		t.Fatalf("want 3 products without the flag, got %d", count)
+	}
+	for _, p := range products {
+		n, tracked := p.AvailableStock()
+		switch p.ID {
+		case ids[0]:
+			if !tracked || n != 3 {
+				t.Errorf("want 3 available (quantity - reserved), got %d tracked=%v", n, tracked)
+			}
+		case ids[1]:
+			if !tracked || n != 0 {
+				t.Errorf("fully reserved: want 0 available, got %d tracked=%v", n, tracked)
+			}
+		case ids[2]:
+			if tracked {
+				t.Errorf("no stock row: want stock not tracked, got %d available", n)
+			}
+		}
+	}
+
+	// Untracked products are not out of stock: only the fully reserved one goes
+	products, count, err = catalog.ListProducts(ctx, tenantID, ports.ProductFilter{Limit: 50, InStockOnly: true})
+	if err != nil {
+		t.Fatalf("ListProducts in stock only: %v", err)
+	}
+	if count != 2 || len(products) != 2 {
+		t.Fatalf("want 2 products without the fully reserved one, got count %d: %+v", count, products)
+	}
+	for _, p := range products {
+		if p.ID == ids[1] {
+			t.Errorf("fully reserved %s must be hidden", ids[1])
+		}
+	}
+}
+
 func TestCatalogIntegration_ListProducts_FilterPriceRange(t *testing.T) {
 	ctx, client, catalog := catalogTestSetup(t)
 	slug := fmt.Sprintf("price-%d", time.Now().UnixNano())
@@ -293,6 +390,61 @@ func TestCatalogIntegration_ListProducts_Search(t *testing.T) {
 	}
 }
 
+func TestCatalogIntegration_ListProducts_FullTextStemming(t *testing.T) {
+	ctx, client, catalog := catalogTestSetup(t)
+	slug := fmt.Sprintf("fts-%d", time.Now().UnixNano())
+	tenantID := ensureTestTenant(t, client, slug, "FTS Test Store")
+
+	seed := []struct{ name, description string }{
+		{"Увлажняющий крем для лица", "Лёгкая текстура"},
+		{"Очищающий гель", "Крем-гель не упоминается в названии, увлажняет кожу"},
+		{"Шампунь для волос", "Для ежедневного применения"},
+	}
+	var masterIDs []string
+	for i, p := range seed {
+		masterID := uuid.New().String()
+		masterIDs = append(masterIDs, masterID)
+		if _, err := client.Pool().Exec(ctx, `
+			INSERT INTO catalog.master_products (id, sku, name, description, brand, images, owner_tenant_id, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, 'FTS Brand', '[]', $5, NOW(), NOW())
+		`, masterID, fmt.Sprintf("FTS-SKU-%s", uuid.New().String()[:8]), p.name, p.description, tenantID); err != nil {
+			t.Fatalf("seed master product: %v", err)
+		}
+		if _, err := client.Pool().Exec(ctx, `
+			INSERT INTO catalog.products (id, tenant_id, master_product_id, price, currency, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, 'RUB', NOW(), NOW())
+		`, uuid.New().String(), tenantID, masterID, (i+1)*10000); err != nil {
+			t.Fatalf("seed product: %v", err)
+		}
+	}
+	t.Cleanup(func() {
+		_, _ = client.Pool().Exec(context.Background(), `DELETE FROM catalog.products WHERE tenant_id = $1`, tenantID)
+		for _, id := range masterIDs {
+			_, _ = client.Pool().Exec(context.Background(), `DELETE FROM catalog.master_products WHERE id = $1`, id)
+		}
+	})
+
+	// Plural/inflected query words must match the singular name via stemming
+	products, total, err := catalog.ListProducts(ctx, tenantID, ports.ProductFilter{
+		Search: "кремы увлажняющие",
+		Limit:  10,
+	})
+	if err != nil {
+		t.Fatalf("ListProducts full-text: %v", err)
+	}
+	if total != 2 {
+		t.Errorf("want 2 matches (name + description), got %d", total)
+	}
+	if len(products) == 0 || products[0].Name != "Увлажняющий крем для лица" {
+		t.Errorf("want name match ranked first, got %+v", products)
+	}
+	for _, p := range products {
+		if p.Name == "Шампунь для волос" {
+			t.Error("shampoo must not match 'кремы увлажняющие'")
+		}
+	}
+}
+
 func TestCatalogIntegration_ListProducts_SortByPrice(t *testing.T) {
 	ctx, client, catalog := catalogTestSetup(t)
 	slug := fmt.Sprintf("sort-%d", time.Now().UnixNano())
@@ -402,6 +554,220 @@ func TestCatalogIntegration_DigestGenerate(t *testing.T) {
 	}
 }
 
+// ---------- GetProductFacets ----------
+
+func TestCatalogIntegration_GetProductFacets(t *testing.T) {
+	ctx, client, catalog := catalogTestSetup(t)
+	slug := fmt.Sprintf("facets-%d", time.Now().UnixNano())
+	tenantID := ensureTestTenant(t, client, slug, "Facets Test Store")
+	ids := seedTestProducts(t, client, tenantID, 8) // Nike, Adidas, Puma, Reebok ×2; prices 100..800 ₽
+
+	facets, err := catalog.GetProductFacets(ctx, tenantID, ports.ProductFilter{Limit: 1}, nil)
+	if err != nil {
+		t.Fatalf("GetProductFacets: %v", err)
+	}
+	byField := make(map[string]domain.Facet)
+	for _, f := range facets {
+		byField[f.Field] = f
+	}
+	brand := byField[domain.FacetBrand]
+	if len(brand.Values) != 4 {
+		t.Fatalf("want 4 brand values, got %+v", brand.Values)
+	}
+	for _, v := range brand.Values {
+		if v.Count != 2 {
+			t.Errorf("brand %s: want count 2, got %d", v.Value, v.Count)
+		}
+	}
+	price := byField[domain.FacetPrice]
+	if len(price.Values) != 1 || price.Values[0].Value != "<1000" || price.Values[0].Count != 8 {
+		t.Errorf("want all 8 products in the <1000 bucket, got %+v", price.Values)
+	}
+	if c := byField[domain.FacetCategory]; len(c.Values) != 1 || c.Values[0].Count != 8 {
+		t.Errorf("want 8 products in one category, got %+v", c.Values)
+	}
+
+	// Keyword miss widened with vector hit IDs
+	facets, err = catalog.GetProductFacets(ctx, tenantID, ports.ProductFilter{Search: "nomatchxyz"}, ids[:2])
+	if err != nil {
+		t.Fatalf("GetProductFacets with IDs: %v", err)
+	}
+	total := 0
+	for _, f := range facets {
+		if f.Field == domain.FacetBrand {
+			for _, v := range f.Values {
+				total += v.Count
+			}
+		}
+	}
+	if total != 2 {
+		t.Errorf("want brand counts over the 2 vector hits, got %d", total)
+	}
+}
+
+// ---------- CorrectFilterValue ----------
+
+func TestCatalogIntegration_CorrectFilterValue(t *testing.T) {
+	ctx, client, catalog := catalogTestSetup(t)
+	slug := fmt.Sprintf("correct-%d", time.Now().UnixNano())
+	tenantID := ensureTestTenant(t, client, slug, "Correction Test Store")
+
+	digest := &domain.CatalogDigest{
+		GeneratedAt: time.Now(),
+		TopBrands:   []string{"CeraVe", "La Roche-Posay", "Nike"},
+		CategoryTree: []domain.DigestCategoryGroup{
+			{Name: "Уход за лицом", Slug: "face-care", Children: []domain.DigestCategoryLeaf{
+				{Name: "Кремы", Slug: "creams", Count: 10},
+			}},
+		},
+	}
+	if err := catalog.SaveCatalogDigest(ctx, tenantID, digest); err != nil {
+		t.Fatalf("SaveCatalogDigest: %v", err)
+	}
+
+	cases := []struct {
+		field, value, want, method string
+	}{
+		{"brand", "сераве", "CeraVe", domain.CorrectionTranslit},
+		{"brand", "тшлу", "Nike", domain.CorrectionLayout},
+		{"brand", "la roshe", "La Roche-Posay", domain.CorrectionFuzzy},
+		{"category", "rhtvs", "Кремы", domain.CorrectionLayout},
+	}
+	for _, tc := range cases {
+		c, err := catalog.CorrectFilterValue(ctx, tenantID, tc.field, tc.value)
+		if err != nil {
+			t.Fatalf("CorrectFilterValue(%q): %v", tc.value, err)
+		}
+		if c == nil {
+			t.Errorf("%q: want correction to %q, got nil", tc.value, tc.want)
+			continue
+		}
+		if c.To != tc.want || c.Method != tc.method {
+			t.Errorf("%q: want %q via %s, got %q via %s", tc.value, tc.want, tc.method, c.To, c.Method)
+		}
+	}
+
+	// Known and unrelated values are left alone
+	for _, v := range []string{"cerave", "xyzqwerty"} {
+		c, err := catalog.CorrectFilterValue(ctx, tenantID, "brand", v)
+		if err != nil {
+			t.Fatalf("CorrectFilterValue(%q): %v", v, err)
+		}
+		if c != nil {
+			t.Errorf("%q: want no correction, got %+v", v, c)
+		}
+	}
+}
+
+func TestCatalogIntegration_CorrectFilterValue_BrandOutsideTopList(t *testing.T) {
+	ctx, client, catalog := catalogTestSetup(t)
+	slug := fmt.Sprintf("correct-full-%d", time.Now().UnixNano())
+	tenantID := ensureTestTenant(t, client, slug, "Correction Full List Store")
+
+	// "Nikel" is sold by the tenant but is not among the digest's top brands
+	if err := catalog.SaveCatalogDigest(ctx, tenantID, &domain.CatalogDigest{GeneratedAt: time.Now(), TopBrands: []string{"Nike", "CeraVe"}}); err != nil {
+		t.Fatalf("SaveCatalogDigest: %v", err)
+	}
+	masterID, productID := uuid.New().String(), uuid.New().String()
+	if _, err := client.Pool().Exec(ctx, `
+		INSERT INTO catalog.master_products (id, sku, name, description, brand, images, owner_tenant_id, created_at, updated_at)
+		VALUES ($1, $2, 'Nikel Cream', '', 'Nikel', '[]', $3, NOW(), NOW())
+	`, masterID, "NIKEL-"+masterID[:8], tenantID); err != nil {
+		t.Fatalf("seed master product: %v", err)
+	}
+	if _, err := client.Pool().Exec(ctx, `
+		INSERT INTO catalog.products (id, tenant_id, master_product_id, price, currency, created_at, updated_at)
+		VALUES ($1, $2, $3, 10000, 'RUB', NOW(), NOW())
+	`, productID, tenantID, masterID); err != nil {
+		t.Fatalf("seed product: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = client.Pool().Exec(context.Background(), `DELETE FROM catalog.products WHERE id = $1`, productID)
+		_, _ = client.Pool().Exec(context.Background(), `DELETE FROM catalog.master_products WHERE id = $1`, masterID)
+	})
+
+	c, err := catalog.CorrectFilterValue(ctx, tenantID, "brand", "nikel")
+	if err != nil {
+		t.Fatalf("CorrectFilterValue(nikel): %v", err)
+	}
+	if c != nil {
+		t.Errorf("a brand the tenant sells must be left alone, got %+v", c)
+	}
+
+	// Misspellings snap to the full list, not only to the top brands
+	c, err = catalog.CorrectFilterValue(ctx, tenantID, "brand", "nikell")
+	if err != nil {
+		t.Fatalf("CorrectFilterValue(nikell): %v", err)
+	}
+	if c == nil || c.To != "Nikel" {
+		t.Errorf("want nikell corrected to Nikel, got %+v", c)
+	}
+}
+
+// ---------- GetSynonyms ----------
+
+func TestCatalogIntegration_GetSynonyms(t *testing.T) {
+	ctx, client, catalog := catalogTestSetup(t)
+	slug := fmt.Sprintf("synonyms-%d", time.Now().UnixNano())
+	tenantID := ensureTestTenant(t, client, slug, "Synonyms Test Store")
+	otherID := ensureTestTenant(t, client, slug+"-other", "Other Store")
+
+	_, err := client.Pool().Exec(ctx, `
+		INSERT INTO catalog.tenant_synonyms (tenant_id, term, synonyms, bidirectional)
+		VALUES ($1, 'SPF', ARRAY['солнцезащитный', 'sunscreen'], TRUE),
+		       ($1, 'патчи', ARRAY['hydrogel eye patches'], FALSE),
+		       ($2, 'крем', ARRAY['cream'], TRUE)
+	`, tenantID, otherID)
+	if err != nil {
+		t.Fatalf("insert synonyms: %v", err)
+	}
+
+	synonyms, err := catalog.GetSynonyms(ctx, tenantID)
+	if err != nil {
+		t.Fatalf("GetSynonyms: %v", err)
+	}
+	if len(synonyms) != 2 {
+		t.Fatalf("want 2 tenant-scoped rules, got %d", len(synonyms))
+	}
+	byTerm := map[string]domain.Synonym{}
+	for _, s := range synonyms {
+		byTerm[s.Term] = s
+	}
+	if s := byTerm["SPF"]; !s.Bidirectional || len(s.Synonyms) != 2 {
+		t.Errorf("unexpected SPF rule: %+v", s)
+	}
+	if s := byTerm["патчи"]; s.Bidirectional || len(s.Synonyms) != 1 {
+		t.Errorf("unexpected патчи rule: %+v", s)
+	}
+}
+
+// ---------- GetIngredientInteractions ----------
+
+func TestCatalogIntegration_GetIngredientInteractions(t *testing.T) {
+	ctx, _, catalog := catalogTestSetup(t)
+
+	rules, err := catalog.GetIngredientInteractions(ctx)
+	if err != nil {
+		t.Fatalf("GetIngredientInteractions: %v", err)
+	}
+	if len(rules) == 0 {
+		t.Fatal("expected seeded interaction rules")
+	}
+	if rules[0].Severity != domain.InteractionAvoid {
+		t.Errorf("avoid rules should come first, got %+v", rules[0])
+	}
+	for _, r := range rules {
+		if len(r.GroupA) == 0 || len(r.GroupB) == 0 || r.Message == "" {
+			t.Errorf("incomplete rule: %+v", r)
+		}
+	}
+
+	ingredients, err := catalog.GetProductIngredients(ctx, "00000000-0000-0000-0000-000000000000", nil)
+	if err != nil || len(ingredients) != 0 {
+		t.Errorf("no product IDs should give an empty map, got %v, %v", ingredients, err)
+	}
+}
+
 // ---------- GetAllTenants ----------
 
 func TestCatalogIntegration_GetAllTenants(t *testing.T) {
@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	pgvector "github.com/pgvector/pgvector-go"
+	"keepstar/internal/domain"
+)
+
+// GetProductEmbedding returns the master-product embedding of a tenant product.
+// Nil embedding (no error) when the product has no master product or was not embedded yet.
+func (a *CatalogAdapter) GetProductEmbedding(ctx context.Context, tenantID string, productID string) ([]float32, error) {
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("db.product_embedding")
+		defer endSpan()
+	}
+
+	var raw *string
+	err := a.client.pool.QueryRow(ctx, `
+		SELECT mp.embedding::text
+		FROM catalog.products p
+		LEFT JOIN catalog.master_products mp ON p.master_product_id = mp.id
+		WHERE p.tenant_id = $1 AND p.id = $2
+	`, tenantID, productID).Scan(&raw)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrProductNotFound
+		}
+		return nil, fmt.Errorf("query product embedding: %w", err)
+	}
+	if raw == nil {
+		return nil, nil
+	}
+
+	var v pgvector.Vector
+	if err := v.Parse(*raw); err != nil {
+		return nil, fmt.Errorf("parse product embedding: %w", err)
+	}
+	return v.Slice(), nil
+}
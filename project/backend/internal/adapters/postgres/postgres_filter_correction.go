@@ -0,0 +1,180 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"keepstar/internal/domain"
+)
+
+// filterCorrectionMinSimilarity is the pg_trgm score a known value needs to replace a misspelled one
+const filterCorrectionMinSimilarity = 0.45
+
+// CorrectFilterValue snaps a brand/category filter value to a value known for the tenant: the
+// digest's top values plus every distinct brand/category in the tenant's catalog.
+// Tries, in order: the value as is, wrong keyboard layout, transliteration (exact or substring
+// match against known values), then the best pg_trgm similarity over all spellings.
+func (a *CatalogAdapter) CorrectFilterValue(ctx context.Context, tenantID string, field string, value string) (*domain.FilterCorrection, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("db.correct_filter")
+		defer endSpan()
+	}
+
+	digest, err := a.GetCatalogDigest(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	var known []string
+	if digest != nil {
+		known = digestFilterValues(digest, field)
+	}
+	// A valid value outside the digest's top list must not be "corrected" to a top one
+	catalogValues, err := a.catalogFilterValues(ctx, tenantID, field)
+	if err != nil {
+		return nil, err
+	}
+	known = appendUniqueFold(known, catalogValues)
+	if len(known) == 0 || matchKnownValue(known, value) != "" {
+		return nil, nil
+	}
+
+	type spelling struct{ text, method string }
+	spellings := []spelling{{value, domain.CorrectionFuzzy}}
+	if switched := domain.SwitchKeyboardLayout(value); switched != value {
+		spellings = append(spellings, spelling{switched, domain.CorrectionLayout})
+	}
+	for _, v := range domain.TransliterateVariants(value) {
+		spellings = append(spellings, spelling{v, domain.CorrectionTranslit})
+	}
+	for _, s := range spellings[1:] {
+		if k := matchKnownValue(known, s.text); k != "" {
+			return &domain.FilterCorrection{Field: field, From: value, To: k, Method: s.method, Similarity: 1}, nil
+		}
+	}
+
+	texts := make([]string, len(spellings))
+	for i, s := range spellings {
+		texts[i] = strings.ToLower(s.text)
+	}
+	var (
+		best  string
+		ord   int
+		score float64
+	)
+	err = a.client.pool.QueryRow(ctx, `
+		SELECT k, c.ord, GREATEST(similarity(lower(k), c.txt), word_similarity(c.txt, lower(k)))::float8 AS score
+		FROM unnest($1::text[]) AS k, unnest($2::text[]) WITH ORDINALITY AS c(txt, ord)
+		ORDER BY score DESC, length(k)
+		LIMIT 1
+	`, known, texts).Scan(&best, &ord, &score)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("trigram match %s: %w", field, err)
+	}
+	if score < filterCorrectionMinSimilarity {
+		return nil, nil
+	}
+	return &domain.FilterCorrection{Field: field, From: value, To: best, Method: spellings[ord-1].method, Similarity: score}, nil
+}
+
+// digestFilterValues lists the known values of a filter field: top brands, or category names and slugs
+func digestFilterValues(digest *domain.CatalogDigest, field string) []string {
+	switch field {
+	case "brand":
+		return digest.TopBrands
+	case "category":
+		var values []string
+		for _, g := range digest.CategoryTree {
+			values = append(values, g.Name, g.Slug)
+			for _, c := range g.Children {
+				values = append(values, c.Name, c.Slug)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// catalogFilterValues lists every distinct brand, or category name and slug, of the tenant's products
+func (a *CatalogAdapter) catalogFilterValues(ctx context.Context, tenantID string, field string) ([]string, error) {
+	var query string
+	switch field {
+	case "brand":
+		query = `
+			SELECT DISTINCT mp.brand
+			FROM catalog.products p
+			JOIN catalog.master_products mp ON p.master_product_id = mp.id
+			WHERE p.tenant_id = $1 AND mp.brand IS NOT NULL AND mp.brand != ''
+		`
+	case "category":
+		query = `
+			SELECT DISTINCT v
+			FROM catalog.products p
+			JOIN catalog.master_products mp ON p.master_product_id = mp.id
+			JOIN catalog.categories c ON mp.category_id = c.id
+			CROSS JOIN LATERAL (VALUES (c.name), (c.slug)) AS names(v)
+			WHERE p.tenant_id = $1 AND v IS NOT NULL AND v != ''
+		`
+	default:
+		return nil, nil
+	}
+
+	rows, err := a.client.pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("query %s values: %w", field, err)
+	}
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, fmt.Errorf("scan %s value: %w", field, err)
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
+
+// appendUniqueFold appends the values not already present, compared case-insensitively
+func appendUniqueFold(known []string, values []string) []string {
+	seen := make(map[string]bool, len(known)+len(values))
+	for _, k := range known {
+		seen[strings.ToLower(k)] = true
+	}
+	for _, v := range values {
+		if !seen[strings.ToLower(v)] {
+			seen[strings.ToLower(v)] = true
+			known = append(known, v)
+		}
+	}
+	return known
+}
+
+// matchKnownValue returns the known value the filter would already hit with ILIKE '%value%'
+func matchKnownValue(known []string, value string) string {
+	v := strings.ToLower(value)
+	if len([]rune(v)) < 3 {
+		for _, k := range known {
+			if strings.ToLower(k) == v {
+				return k
+			}
+		}
+		return ""
+	}
+	for _, k := range known {
+		if strings.Contains(strings.ToLower(k), v) {
+			return k
+		}
+	}
+	return ""
+}
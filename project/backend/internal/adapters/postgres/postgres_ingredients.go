@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"keepstar/internal/domain"
+)
+
+// GetIngredientInteractions returns all ingredient interaction rules, "avoid" first
+func (a *CatalogAdapter) GetIngredientInteractions(ctx context.Context) ([]domain.IngredientInteraction, error) {
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("db.ingredient_interactions")
+		defer endSpan()
+	}
+
+	rows, err := a.client.pool.Query(ctx, `
+		SELECT id::text, name, group_a, group_b, severity, message, COALESCE(advice, '')
+		FROM catalog.ingredient_interactions
+		ORDER BY severity = 'avoid' DESC, name
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query ingredient interactions: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []domain.IngredientInteraction
+	for rows.Next() {
+		var r domain.IngredientInteraction
+		if err := rows.Scan(&r.ID, &r.Name, &r.GroupA, &r.GroupB, &r.Severity, &r.Message, &r.Advice); err != nil {
+			return nil, fmt.Errorf("scan ingredient interaction: %w", err)
+		}
+		rules = append(rules, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate ingredient interactions: %w", err)
+	}
+	return rules, nil
+}
+
+// GetProductIngredients returns product ID → INCI names of its master product, in label order
+func (a *CatalogAdapter) GetProductIngredients(ctx context.Context, tenantID string, productIDs []string) (map[string][]string, error) {
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("db.product_ingredients")
+		defer endSpan()
+	}
+	result := make(map[string][]string, len(productIDs))
+	if len(productIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := a.client.pool.Query(ctx, `
+		SELECT p.id::text, i.inci_name
+		FROM catalog.products p
+		JOIN catalog.product_ingredients pi ON pi.master_product_id = p.master_product_id
+		JOIN catalog.ingredients i ON i.id = pi.ingredient_id
+		WHERE p.tenant_id = $1 AND p.id::text = ANY($2)
+		ORDER BY p.id, pi.position
+	`, tenantID, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query product ingredients: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID, inci string
+		if err := rows.Scan(&productID, &inci); err != nil {
+			return nil, fmt.Errorf("scan product ingredient: %w", err)
+		}
+		result[productID] = append(result[productID], inci)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate product ingredients: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"keepstar/internal/domain"
+)
+
+// PromptAdapter implements ports.PromptPort
+type PromptAdapter struct {
+	client *Client
+}
+
+// NewPromptAdapter creates a new PromptAdapter
+func NewPromptAdapter(client *Client) *PromptAdapter {
+	return &PromptAdapter{client: client}
+}
+
+const promptColumns = `id, name, version, tenant_slug, content, weight, active, note, created_at`
+
+func scanPromptVersions(rows pgx.Rows) ([]domain.PromptVersion, error) {
+	defer rows.Close()
+	var out []domain.PromptVersion
+	for rows.Next() {
+		var p domain.PromptVersion
+		if err := rows.Scan(&p.ID, &p.Name, &p.Version, &p.TenantSlug, &p.Content, &p.Weight, &p.Active, &p.Note, &p.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan prompt version: %w", err)
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// GetActivePrompts returns the tenant's active versions, falling back to global ones
+func (a *PromptAdapter) GetActivePrompts(ctx context.Context, name, tenantSlug string) ([]domain.PromptVersion, error) {
+	rows, err := a.client.pool.Query(ctx, `
+		SELECT `+promptColumns+` FROM prompt_versions
+		WHERE name = $1 AND active AND tenant_slug IN ($2, '')
+		ORDER BY version
+	`, name, tenantSlug)
+	if err != nil {
+		return nil, fmt.Errorf("query active prompts: %w", err)
+	}
+	all, err := scanPromptVersions(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	var tenant, global []domain.PromptVersion
+	for _, p := range all {
+		if p.TenantSlug != "" {
+			tenant = append(tenant, p)
+		} else {
+			global = append(global, p)
+		}
+	}
+	if len(tenant) > 0 {
+		return tenant, nil
+	}
+	return global, nil
+}
@@ -0,0 +1,56 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"keepstar/internal/adapters/postgres"
+)
+
+func TestPromptAdapter_ActiveSetAndTenantOverride(t *testing.T) {
+	client := getSharedClient(t)
+	ctx := context.Background()
+	adapter := postgres.NewPromptAdapter(client)
+
+	name := "test-prompt-" + uuid.New().String()[:8]
+	t.Cleanup(func() {
+		_, _ = client.Pool().Exec(context.Background(), `DELETE FROM prompt_versions WHERE name = $1`, name)
+	})
+
+	// Versions are written by the admin backend; seed them directly
+	insert := func(version int, tenantSlug, content string, active bool) {
+		t.Helper()
+		if _, err := client.Pool().Exec(ctx, `
+			INSERT INTO prompt_versions (name, version, tenant_slug, content, weight, active)
+			VALUES ($1, $2, $3, $4, 50, $5)
+		`, name, version, tenantSlug, content, active); err != nil {
+			t.Fatalf("insert prompt version: %v", err)
+		}
+	}
+	insert(1, "", "global v1", true)
+	insert(2, "", "global v2", true)
+	insert(3, "acme", "acme v3", false)
+
+	// Inactive tenant version: tenant still gets the global experiment
+	active, err := adapter.GetActivePrompts(ctx, name, "acme")
+	if err != nil {
+		t.Fatalf("active: %v", err)
+	}
+	if len(active) != 2 || active[0].Version != 1 {
+		t.Fatalf("want 2 global versions in version order, got %+v", active)
+	}
+
+	// Activated tenant version overrides all global ones for that tenant only
+	if _, err := client.Pool().Exec(ctx, `UPDATE prompt_versions SET active = TRUE WHERE name = $1 AND version = 3`, name); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	active, _ = adapter.GetActivePrompts(ctx, name, "acme")
+	if len(active) != 1 || active[0].Content != "acme v3" {
+		t.Errorf("want tenant override, got %+v", active)
+	}
+	other, _ := adapter.GetActivePrompts(ctx, name, "nike")
+	if len(other) != 2 {
+		t.Errorf("other tenants keep global versions, got %d", len(other))
+	}
+}
@@ -0,0 +1,167 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	pgvector "github.com/pgvector/pgvector-go"
+	"keepstar/internal/domain"
+)
+
+// ResponseCacheAdapter implements ports.ResponseCachePort
+type ResponseCacheAdapter struct {
+	client *Client
+}
+
+// NewResponseCacheAdapter creates a new ResponseCacheAdapter
+func NewResponseCacheAdapter(client *Client) *ResponseCacheAdapter {
+	return &ResponseCacheAdapter{client: client}
+}
+
+// tenantCatalogVersionSQL fingerprints what a cached answer was built from ($1 = tenant slug):
+// the catalog digest, stock levels (catalog.stock), product rows, synonym rules, rerank and stock settings.
+// Any digest regeneration, stock movement, product, synonym, rerank weight or stock policy update yields a new version.
+const tenantCatalogVersionSQL = `COALESCE((
+	SELECT md5(
+		COALESCE(t.catalog_digest::text, '') || '|' ||
+		COALESCE((t.settings->'rerank')::text, '') || '|' ||
+		COALESCE((t.settings->'stock')::text, '') || '|' ||
+		COALESCE((SELECT MAX(s.updated_at)::text || ':' || COUNT(*)::text || ':' || SUM(s.quantity - s.reserved)::text
+		          FROM catalog.stock s WHERE s.tenant_id = t.id), '') || '|' ||
+		COALESCE((SELECT MAX(p.updated_at)::text || ':' || COUNT(*)::text
+		          FROM catalog.products p WHERE p.tenant_id = t.id), '') || '|' ||
+		COALESCE((SELECT MAX(ts.updated_at)::text || ':' || COUNT(*)::text
+		          FROM catalog.tenant_synonyms ts WHERE ts.tenant_id = t.id), '')
+	)
+	FROM catalog.tenants t WHERE t.slug = $1
+), '')`
+
+// responseCachePayload is the JSONB body of a cache row
+type responseCachePayload struct {
+	Data      domain.StateData          `json:"data"`
+	Meta      domain.StateMeta          `json:"meta"`
+	Formation *domain.FormationWithData `json:"formation"`
+	ToolName  string                    `json:"toolName"`
+	ToolInput string                    `json:"toolInput,omitempty"`
+	CostUSD   float64                   `json:"costUsd"`
+}
+
+// LookupResponse tries an exact normalized-query match, then the nearest embedding
+func (a *ResponseCacheAdapter) LookupResponse(ctx context.Context, tenantSlug, stateKey, queryNorm string, embedding []float32, minSimilarity float64) (*domain.ResponseCacheHit, error) {
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("db.response_cache")
+		defer endSpan()
+	}
+
+	hit, err := a.lookup(ctx, `
+		SELECT id, tenant_slug, state_key, query_norm, payload, hits, created_at, 1.0::float8
+		FROM response_cache
+		WHERE tenant_slug = $1 AND state_key = $2 AND query_norm = $3
+		  AND expires_at > NOW() AND catalog_version = `+tenantCatalogVersionSQL+`
+		LIMIT 1
+	`, tenantSlug, stateKey, queryNorm)
+	if err != nil {
+		return nil, err
+	}
+	if hit != nil {
+		hit.Match = domain.CacheMatchExact
+	} else if len(embedding) > 0 {
+		hit, err = a.lookup(ctx, `
+			SELECT id, tenant_slug, state_key, query_norm, payload, hits, created_at, 1 - (embedding <=> $3) AS similarity
+			FROM response_cache
+			WHERE tenant_slug = $1 AND state_key = $2 AND embedding IS NOT NULL
+			  AND expires_at > NOW() AND catalog_version = `+tenantCatalogVersionSQL+`
+			  AND 1 - (embedding <=> $3) >= $4
+			ORDER BY embedding <=> $3
+			LIMIT 1
+		`, tenantSlug, stateKey, pgvector.NewVector(embedding), minSimilarity)
+		if err != nil {
+			return nil, err
+		}
+		if hit != nil {
+			hit.Match = domain.CacheMatchSemantic
+		}
+	}
+	if hit == nil {
+		return nil, nil
+	}
+
+	if _, err := a.client.pool.Exec(ctx, `UPDATE response_cache SET hits = hits + 1 WHERE id = $1`, hit.Entry.ID); err != nil {
+		return nil, fmt.Errorf("count response cache hit: %w", err)
+	}
+	hit.Entry.Hits++
+	return hit, nil
+}
+
+func (a *ResponseCacheAdapter) lookup(ctx context.Context, query string, args ...interface{}) (*domain.ResponseCacheHit, error) {
+	var (
+		entry   domain.ResponseCacheEntry
+		payload []byte
+		hit     domain.ResponseCacheHit
+	)
+	err := a.client.pool.QueryRow(ctx, query, args...).Scan(
+		&entry.ID, &entry.TenantSlug, &entry.StateKey, &entry.QueryNorm, &payload, &entry.Hits, &entry.CreatedAt, &hit.Similarity)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lookup response cache: %w", err)
+	}
+
+	var p responseCachePayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, fmt.Errorf("unmarshal response cache payload: %w", err)
+	}
+	entry.Data, entry.Meta, entry.Formation = p.Data, p.Meta, p.Formation
+	entry.ToolName, entry.ToolInput, entry.CostUSD = p.ToolName, p.ToolInput, p.CostUSD
+	hit.Entry = &entry
+	return &hit, nil
+}
+
+// StoreResponse upserts the entry under the tenant's current catalog version
+func (a *ResponseCacheAdapter) StoreResponse(ctx context.Context, entry *domain.ResponseCacheEntry, ttl time.Duration) error {
+	payload, err := json.Marshal(responseCachePayload{
+		Data:      entry.Data,
+		Meta:      entry.Meta,
+		Formation: entry.Formation,
+		ToolName:  entry.ToolName,
+		ToolInput: entry.ToolInput,
+		CostUSD:   entry.CostUSD,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal response cache payload: %w", err)
+	}
+
+	var embedding *pgvector.Vector
+	if len(entry.Embedding) > 0 {
+		v := pgvector.NewVector(entry.Embedding)
+		embedding = &v
+	}
+
+	err = a.client.pool.QueryRow(ctx, `
+		INSERT INTO response_cache (tenant_slug, state_key, query_norm, catalog_version, embedding, payload, expires_at)
+		VALUES ($1, $2, $3, `+tenantCatalogVersionSQL+`, $4, $5, NOW() + $6 * INTERVAL '1 second')
+		ON CONFLICT (tenant_slug, state_key, query_norm, catalog_version) DO UPDATE SET
+			embedding = EXCLUDED.embedding,
+			payload = EXCLUDED.payload,
+			expires_at = EXCLUDED.expires_at
+		RETURNING id, created_at
+	`, entry.TenantSlug, entry.StateKey, entry.QueryNorm, embedding, payload, int64(ttl.Seconds())).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("store response cache: %w", err)
+	}
+	return nil
+}
+
+// InvalidateTenant deletes all cached responses of a tenant
+func (a *ResponseCacheAdapter) InvalidateTenant(ctx context.Context, tenantSlug string) (int64, error) {
+	tag, err := a.client.pool.Exec(ctx, `DELETE FROM response_cache WHERE tenant_slug = $1`, tenantSlug)
+	if err != nil {
+		return 0, fmt.Errorf("invalidate response cache: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
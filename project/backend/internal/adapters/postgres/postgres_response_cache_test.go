@@ -0,0 +1,86 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"keepstar/internal/adapters/postgres"
+	"keepstar/internal/domain"
+)
+
+func TestResponseCacheAdapter_ExactSemanticAndDigestInvalidation(t *testing.T) {
+	client := getSharedClient(t)
+	ctx := context.Background()
+	adapter := postgres.NewResponseCacheAdapter(client)
+
+	slug := "rc-test-" + uuid.New().String()[:8]
+	if _, err := client.Pool().Exec(ctx, `
+		INSERT INTO catalog.tenants (slug, name, type, catalog_digest) VALUES ($1, $1, 'brand', '{"v":1}')
+	`, slug); err != nil {
+		t.Fatalf("insert tenant: %v", err)
+	}
+	t.Cleanup(func() {
+		bg := context.Background()
+		_, _ = client.Pool().Exec(bg, `DELETE FROM response_cache WHERE tenant_slug = $1`, slug)
+		_, _ = client.Pool().Exec(bg, `DELETE FROM catalog.tenants WHERE slug = $1`, slug)
+	})
+
+	embedding := make([]float32, 384)
+	embedding[0], embedding[1] = 1, 0.1
+	entry := &domain.ResponseCacheEntry{
+		TenantSlug: slug,
+		QueryNorm:  "крем для сухой кожи",
+		StateKey:   "empty|a1v0|a2v0",
+		Embedding:  embedding,
+		Data:       domain.StateData{Products: []domain.Product{{ID: "p1", Name: "Крем"}}},
+		Meta:       domain.StateMeta{Count: 1},
+		Formation:  &domain.FormationWithData{Mode: domain.FormationTypeGrid},
+		ToolName:   "catalog_search",
+		CostUSD:    0.003,
+	}
+	if err := adapter.StoreResponse(ctx, entry, time.Hour); err != nil {
+		t.Fatalf("StoreResponse: %v", err)
+	}
+	if entry.ID == "" {
+		t.Fatal("StoreResponse must assign ID")
+	}
+
+	hit, err := adapter.LookupResponse(ctx, slug, entry.StateKey, entry.QueryNorm, nil, 0.9)
+	if err != nil || hit == nil || hit.Match != domain.CacheMatchExact {
+		t.Fatalf("want exact hit, got %+v err=%v", hit, err)
+	}
+	if len(hit.Entry.Data.Products) != 1 || hit.Entry.Formation == nil || hit.Entry.CostUSD != 0.003 {
+		t.Errorf("payload not restored: %+v", hit.Entry)
+	}
+
+	near := make([]float32, 384)
+	near[0], near[1] = 1, 0.15
+	hit, err = adapter.LookupResponse(ctx, slug, entry.StateKey, "крем для очень сухой кожи", near, 0.9)
+	if err != nil || hit == nil || hit.Match != domain.CacheMatchSemantic || hit.Similarity < 0.9 {
+		t.Fatalf("want semantic hit, got %+v err=%v", hit, err)
+	}
+
+	far := make([]float32, 384)
+	far[5] = 1
+	if hit, _ := adapter.LookupResponse(ctx, slug, entry.StateKey, "шампунь", far, 0.9); hit != nil {
+		t.Errorf("dissimilar query must miss, got %+v", hit)
+	}
+	if hit, _ := adapter.LookupResponse(ctx, slug, "loaded|a1v0|a2v0", entry.QueryNorm, nil, 0.9); hit != nil {
+		t.Error("other state key must miss")
+	}
+
+	// Regenerated digest → new catalog version → old entry is never served
+	if _, err := client.Pool().Exec(ctx, `UPDATE catalog.tenants SET catalog_digest = '{"v":2}' WHERE slug = $1`, slug); err != nil {
+		t.Fatalf("update digest: %v", err)
+	}
+	if hit, _ := adapter.LookupResponse(ctx, slug, entry.StateKey, entry.QueryNorm, nil, 0.9); hit != nil {
+		t.Error("entry built on an older digest must miss")
+	}
+
+	deleted, err := adapter.InvalidateTenant(ctx, slug)
+	if err != nil || deleted != 1 {
+		t.Errorf("InvalidateTenant: deleted=%d err=%v", deleted, err)
+	}
+}
@@ -85,17 +85,17 @@ func (a *StateAdapter) GetState(ctx context.Context, sessionID string) (*domain.
 		defer endSpan()
 	}
 	var state domain.SessionState
-	var dataJSON, metaJSON, templateJSON, viewFocusedJSON, viewStackJSON, conversationHistoryJSON []byte
+	var dataJSON, metaJSON, templateJSON, viewFocusedJSON, viewStackJSON, viewForwardJSON, conversationHistoryJSON []byte
 	var viewMode *string
 
 	err := a.client.pool.QueryRow(ctx, `
 		SELECT id, session_id, current_data, current_meta, current_template,
-		       view_mode, view_focused, view_stack, conversation_history, step, created_at, updated_at
+		       view_mode, view_focused, view_stack, view_forward, conversation_history, step, created_at, updated_at
 		FROM chat_session_state
 		WHERE session_id = $1
 	`, sessionID).Scan(
 		&state.ID, &state.SessionID, &dataJSON, &metaJSON, &templateJSON,
-		&viewMode, &viewFocusedJSON, &viewStackJSON, &conversationHistoryJSON,
+		&viewMode, &viewFocusedJSON, &viewStackJSON, &viewForwardJSON, &conversationHistoryJSON,
 		&state.Step, &state.CreatedAt, &state.UpdatedAt,
 	)
 	if err == pgx.ErrNoRows {
@@ -137,6 +137,11 @@ func (a *StateAdapter) GetState(ctx context.Context, sessionID string) (*domain.
 			a.log.Warn("unmarshal view stack", "session_id", sessionID, "error", err)
 		}
 	}
+	if len(viewForwardJSON) > 0 {
+		if err := json.Unmarshal(viewForwardJSON, &state.ForwardStack); err != nil {
+			a.log.Warn("unmarshal view forward", "session_id", sessionID, "error", err)
+		}
+	}
 	if len(conversationHistoryJSON) > 0 {
 		if err := json.Unmarshal(conversationHistoryJSON, &state.ConversationHistory); err != nil {
 			a.log.Warn("unmarshal conversation history", "session_id", sessionID, "error", err)
@@ -172,6 +177,10 @@ func (a *StateAdapter) UpdateState(ctx context.Context, state *domain.SessionSta
 	if err != nil {
 		return fmt.Errorf("marshal view stack: %w", err)
 	}
+	viewForwardJSON, err := snapshotsJSON(state.ForwardStack)
+	if err != nil {
+		return fmt.Errorf("marshal view forward: %w", err)
+	}
 	conversationHistoryJSON, err := json.Marshal(state.ConversationHistory)
 	if err != nil {
 		return fmt.Errorf("marshal conversation history: %w", err)
@@ -180,11 +189,11 @@ func (a *StateAdapter) UpdateState(ctx context.Context, state *domain.SessionSta
 	_, err = a.client.pool.Exec(ctx, `
 		UPDATE chat_session_state
 		SET current_data = $1, current_meta = $2, current_template = $3,
-		    view_mode = $4, view_focused = $5, view_stack = $6,
-		    conversation_history = $7, step = $8, updated_at = NOW()
-		WHERE session_id = $9
+		    view_mode = $4, view_focused = $5, view_stack = $6, view_forward = $7,
+		    conversation_history = $8, step = $9, updated_at = NOW()
+		WHERE session_id = $10
 	`, dataJSON, metaJSON, templateJSON,
-		state.View.Mode, viewFocusedJSON, viewStackJSON,
+		state.View.Mode, viewFocusedJSON, viewStackJSON, viewForwardJSON,
 		conversationHistoryJSON, state.Step, state.SessionID)
 	if err != nil {
 		return fmt.Errorf("update state: %w", err)
@@ -214,6 +223,14 @@ func (a *StateAdapter) AddDelta(ctx context.Context, sessionID string, delta *do
 			return 0, fmt.Errorf("marshal template: %w", tErr)
 		}
 	}
+	var payloadJSON []byte
+	if delta.Payload != nil {
+		var pErr error
+		payloadJSON, pErr = json.Marshal(delta.Payload)
+		if pErr != nil {
+			return 0, fmt.Errorf("marshal payload: %w", pErr)
+		}
+	}
 
 	// Use default values for new fields if not set
 	source := delta.Source
@@ -235,15 +252,15 @@ func (a *StateAdapter) AddDelta(ctx context.Context, sessionID string, delta *do
 		),
 		inserted AS (
 			INSERT INTO chat_session_deltas
-				(session_id, step, trigger, source, actor_id, delta_type, path, action, result, template, turn_id)
-			SELECT $1, next_step.step, $2, $3, $4, $5, $6, $7, $8, $9, $10
+				(session_id, step, trigger, source, actor_id, delta_type, path, action, result, template, turn_id, payload)
+			SELECT $1, next_step.step, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
 			FROM next_step
 			RETURNING step
 		)
 		SELECT step FROM inserted
 	`, sessionID, delta.Trigger,
 		source, delta.ActorID, deltaType, delta.Path,
-		actionJSON, resultJSON, templateJSON, delta.TurnID).Scan(&assignedStep)
+		actionJSON, resultJSON, templateJSON, delta.TurnID, payloadJSON).Scan(&assignedStep)
 	if err != nil {
 		return 0, fmt.Errorf("add delta: %w", err)
 	}
@@ -277,11 +294,16 @@ func (a *StateAdapter) UpdateData(ctx context.Context, sessionID string, data do
 		return 0, fmt.Errorf("marshal meta: %w", err)
 	}
 	delta := info.ToDelta()
+	delta.Payload = &domain.DeltaPayload{Data: &data, Meta: &meta}
+	// A new shopper query starts a new navigation branch: views left by back have nothing to return to
+	clearForward := info.Trigger == domain.TriggerUserQuery
 	return a.zoneWriteWithDelta(ctx, sessionID, delta, `
 		UPDATE chat_session_state
-		SET current_data = $1, current_meta = $2, updated_at = NOW()
+		SET current_data = $1, current_meta = $2,
+		    view_forward = CASE WHEN $4 THEN '[]'::jsonb ELSE view_forward END,
+		    updated_at = NOW()
 		WHERE session_id = $3
-	`, dataJSON, metaJSON, sessionID)
+	`, dataJSON, metaJSON, sessionID, clearForward)
 }
 
 // UpdateTemplate updates the template zone and creates a delta
@@ -295,6 +317,7 @@ func (a *StateAdapter) UpdateTemplate(ctx context.Context, sessionID string, tem
 		return 0, fmt.Errorf("marshal template: %w", err)
 	}
 	delta := info.ToDelta()
+	delta.Template = template
 	return a.zoneWriteWithDelta(ctx, sessionID, delta, `
 		UPDATE chat_session_state
 		SET current_template = $1, updated_at = NOW()
@@ -316,7 +339,12 @@ func (a *StateAdapter) UpdateView(ctx context.Context, sessionID string, view do
 	if err != nil {
 		return 0, fmt.Errorf("marshal view stack: %w", err)
 	}
+	forward, err := a.GetForwardStack(ctx, sessionID)
+	if err != nil {
+		return 0, fmt.Errorf("get forward stack: %w", err)
+	}
 	delta := info.ToDelta()
+	delta.Payload = &domain.DeltaPayload{View: &view, ViewStack: stack, ForwardStack: forward}
 	return a.zoneWriteWithDelta(ctx, sessionID, delta, `
 		UPDATE chat_session_state
 		SET view_mode = $1, view_focused = $2, view_stack = $3, updated_at = NOW()
@@ -365,7 +393,7 @@ func (a *StateAdapter) GetDeltas(ctx context.Context, sessionID string) ([]domai
 // GetDeltasSince retrieves deltas from a specific step
 func (a *StateAdapter) GetDeltasSince(ctx context.Context, sessionID string, fromStep int) ([]domain.Delta, error) {
 	rows, err := a.client.pool.Query(ctx, `
-		SELECT step, trigger, source, actor_id, delta_type, path, action, result, template, turn_id, created_at
+		SELECT step, trigger, source, actor_id, delta_type, path, action, result, template, turn_id, payload, created_at
 		FROM chat_session_deltas
 		WHERE session_id = $1 AND step >= $2
 		ORDER BY step ASC
@@ -381,7 +409,7 @@ func (a *StateAdapter) GetDeltasSince(ctx context.Context, sessionID string, fro
 // GetDeltasUntil retrieves deltas up to and including a specific step (for reconstruction)
 func (a *StateAdapter) GetDeltasUntil(ctx context.Context, sessionID string, toStep int) ([]domain.Delta, error) {
 	rows, err := a.client.pool.Query(ctx, `
-		SELECT step, trigger, source, actor_id, delta_type, path, action, result, template, turn_id, created_at
+		SELECT step, trigger, source, actor_id, delta_type, path, action, result, template, turn_id, payload, created_at
 		FROM chat_session_deltas
 		WHERE session_id = $1 AND step <= $2
 		ORDER BY step ASC
@@ -399,12 +427,12 @@ func (a *StateAdapter) scanDeltas(rows pgx.Rows) ([]domain.Delta, error) {
 	var deltas []domain.Delta
 	for rows.Next() {
 		var d domain.Delta
-		var actionJSON, resultJSON, templateJSON []byte
+		var actionJSON, resultJSON, templateJSON, payloadJSON []byte
 		var trigger string
 		var source, actorID, deltaType, path, turnID *string
 
 		err := rows.Scan(&d.Step, &trigger, &source, &actorID, &deltaType, &path,
-			&actionJSON, &resultJSON, &templateJSON, &turnID, &d.CreatedAt)
+			&actionJSON, &resultJSON, &templateJSON, &turnID, &payloadJSON, &d.CreatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("scan delta: %w", err)
 		}
@@ -436,6 +464,11 @@ func (a *StateAdapter) scanDeltas(rows pgx.Rows) ([]domain.Delta, error) {
 				a.log.Warn("unmarshal delta template", "step", d.Step, "error", err)
 			}
 		}
+		if len(payloadJSON) > 0 {
+			if err := json.Unmarshal(payloadJSON, &d.Payload); err != nil {
+				a.log.Warn("unmarshal delta payload", "step", d.Step, "error", err)
+			}
+		}
 
 		deltas = append(deltas, d)
 	}
@@ -473,76 +506,128 @@ func (a *StateAdapter) PopView(ctx context.Context, sessionID string) (*domain.V
 		endSpan := sc.Start("db.pop_view")
 		defer endSpan()
 	}
-	var viewStackJSON []byte
+	return a.popSnapshot(ctx, sessionID, "view_stack")
+}
 
-	// Get current view stack
-	err := a.client.pool.QueryRow(ctx, `
-		SELECT view_stack
-		FROM chat_session_state
-		WHERE session_id = $1
-	`, sessionID).Scan(&viewStackJSON)
-	if err == pgx.ErrNoRows {
-		return nil, domain.ErrSessionNotFound
+// GetViewStack retrieves the entire view stack for a session
+func (a *StateAdapter) GetViewStack(ctx context.Context, sessionID string) ([]domain.ViewSnapshot, error) {
+	return a.getSnapshots(ctx, sessionID, "view_stack")
+}
+
+// PushForward pushes the view left by "back" onto the forward stack
+func (a *StateAdapter) PushForward(ctx context.Context, sessionID string, snapshot *domain.ViewSnapshot) error {
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("db.push_forward")
+		defer endSpan()
 	}
+	snapshotJSON, err := json.Marshal(snapshot)
 	if err != nil {
-		return nil, fmt.Errorf("get view stack: %w", err)
+		return fmt.Errorf("marshal snapshot: %w", err)
 	}
 
-	var viewStack []domain.ViewSnapshot
-	if len(viewStackJSON) > 0 {
-		if err := json.Unmarshal(viewStackJSON, &viewStack); err != nil {
-			a.log.Warn("unmarshal view stack in pop", "session_id", sessionID, "error", err)
-		}
+	_, err = a.client.pool.Exec(ctx, `
+		UPDATE chat_session_state
+		SET view_forward = COALESCE(view_forward, '[]'::jsonb) || $1::jsonb,
+		    updated_at = NOW()
+		WHERE session_id = $2
+	`, snapshotJSON, sessionID)
+	if err != nil {
+		return fmt.Errorf("push forward: %w", err)
+	}
+
+	return nil
+}
+
+// PopForward pops and returns the last view snapshot from the forward stack
+func (a *StateAdapter) PopForward(ctx context.Context, sessionID string) (*domain.ViewSnapshot, error) {
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("db.pop_forward")
+		defer endSpan()
+	}
+	return a.popSnapshot(ctx, sessionID, "view_forward")
+}
+
+// ClearForward empties the forward stack (new navigation branch)
+func (a *StateAdapter) ClearForward(ctx context.Context, sessionID string) error {
+	_, err := a.client.pool.Exec(ctx, `
+		UPDATE chat_session_state
+		SET view_forward = '[]'::jsonb,
+		    updated_at = NOW()
+		WHERE session_id = $1
+	`, sessionID)
+	if err != nil {
+		return fmt.Errorf("clear forward: %w", err)
 	}
+	return nil
+}
+
+// GetForwardStack retrieves the entire forward stack for a session
+func (a *StateAdapter) GetForwardStack(ctx context.Context, sessionID string) ([]domain.ViewSnapshot, error) {
+	return a.getSnapshots(ctx, sessionID, "view_forward")
+}
 
-	if len(viewStack) == 0 {
+// popSnapshot pops the last snapshot of a stack column (view_stack / view_forward); nil for an empty stack
+func (a *StateAdapter) popSnapshot(ctx context.Context, sessionID, column string) (*domain.ViewSnapshot, error) {
+	stack, err := a.getSnapshots(ctx, sessionID, column)
+	if err != nil {
+		return nil, err
+	}
+	if len(stack) == 0 {
 		return nil, nil // Empty stack
 	}
 
 	// Pop the last element
-	lastSnapshot := viewStack[len(viewStack)-1]
-	viewStack = viewStack[:len(viewStack)-1]
+	lastSnapshot := stack[len(stack)-1]
+	stack = stack[:len(stack)-1]
 
 	// Update the stack
-	newStackJSON, err := json.Marshal(viewStack)
+	newStackJSON, err := json.Marshal(stack)
 	if err != nil {
-		return nil, fmt.Errorf("marshal view stack: %w", err)
+		return nil, fmt.Errorf("marshal %s: %w", column, err)
 	}
 	_, err = a.client.pool.Exec(ctx, `
 		UPDATE chat_session_state
-		SET view_stack = $1,
+		SET `+column+` = $1,
 		    updated_at = NOW()
 		WHERE session_id = $2
 	`, newStackJSON, sessionID)
 	if err != nil {
-		return nil, fmt.Errorf("update view stack: %w", err)
+		return nil, fmt.Errorf("update %s: %w", column, err)
 	}
 
 	return &lastSnapshot, nil
 }
 
-// GetViewStack retrieves the entire view stack for a session
-func (a *StateAdapter) GetViewStack(ctx context.Context, sessionID string) ([]domain.ViewSnapshot, error) {
-	var viewStackJSON []byte
+// getSnapshots reads a stack column (view_stack / view_forward)
+func (a *StateAdapter) getSnapshots(ctx context.Context, sessionID, column string) ([]domain.ViewSnapshot, error) {
+	var stackJSON []byte
 
 	err := a.client.pool.QueryRow(ctx, `
-		SELECT view_stack
+		SELECT `+column+`
 		FROM chat_session_state
 		WHERE session_id = $1
-	`, sessionID).Scan(&viewStackJSON)
+	`, sessionID).Scan(&stackJSON)
 	if err == pgx.ErrNoRows {
 		return nil, domain.ErrSessionNotFound
 	}
 	if err != nil {
-		return nil, fmt.Errorf("get view stack: %w", err)
+		return nil, fmt.Errorf("get %s: %w", column, err)
 	}
 
-	var viewStack []domain.ViewSnapshot
-	if len(viewStackJSON) > 0 {
-		if err := json.Unmarshal(viewStackJSON, &viewStack); err != nil {
-			a.log.Warn("unmarshal view stack", "session_id", sessionID, "error", err)
+	var stack []domain.ViewSnapshot
+	if len(stackJSON) > 0 {
+		if err := json.Unmarshal(stackJSON, &stack); err != nil {
+			a.log.Warn("unmarshal "+column, "session_id", sessionID, "error", err)
 		}
 	}
 
-	return viewStack, nil
+	return stack, nil
+}
+
+// snapshotsJSON marshals a stack, nil as [] (the stack columns are appended to with ||)
+func snapshotsJSON(stack []domain.ViewSnapshot) ([]byte, error) {
+	if stack == nil {
+		stack = []domain.ViewSnapshot{}
+	}
+	return json.Marshal(stack)
 }
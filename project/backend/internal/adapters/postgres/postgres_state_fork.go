@@ -0,0 +1,221 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"keepstar/internal/domain"
+)
+
+// ForkState creates session forkID as a branch of parentID in one transaction:
+// a chat_sessions row linked to the parent (same tenant, user and metadata),
+// the state row and a copy of the parent's deltas up to toStep (steps kept).
+func (a *StateAdapter) ForkState(ctx context.Context, parentID, forkID string, toStep int, state *domain.SessionState) error {
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("db.fork_state")
+		defer endSpan()
+	}
+	dataJSON, err := json.Marshal(state.Current.Data)
+	if err != nil {
+		return fmt.Errorf("marshal data: %w", err)
+	}
+	metaJSON, err := json.Marshal(state.Current.Meta)
+	if err != nil {
+		return fmt.Errorf("marshal meta: %w", err)
+	}
+	templateJSON, err := json.Marshal(state.Current.Template)
+	if err != nil {
+		return fmt.Errorf("marshal template: %w", err)
+	}
+	viewFocusedJSON, err := json.Marshal(state.View.Focused)
+	if err != nil {
+		return fmt.Errorf("marshal view focused: %w", err)
+	}
+	viewStackJSON, err := snapshotsJSON(state.ViewStack)
+	if err != nil {
+		return fmt.Errorf("marshal view stack: %w", err)
+	}
+	viewForwardJSON, err := snapshotsJSON(state.ForwardStack)
+	if err != nil {
+		return fmt.Errorf("marshal view forward: %w", err)
+	}
+	conversationHistoryJSON, err := json.Marshal(state.ConversationHistory)
+	if err != nil {
+		return fmt.Errorf("marshal conversation history: %w", err)
+	}
+
+	tx, err := a.client.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// 1. Session row linked to the parent
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO chat_sessions (id, user_id, tenant_id, status, metadata, parent_session_id, forked_at_step)
+		SELECT $1, user_id, tenant_id, 'active', metadata, id, $3
+		FROM chat_sessions
+		WHERE id = $2
+	`, forkID, parentID, toStep)
+	if err != nil {
+		return fmt.Errorf("insert fork session: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSessionNotFound
+	}
+
+	// 2. State row
+	_, err = tx.Exec(ctx, `
+		INSERT INTO chat_session_state
+			(session_id, current_data, current_meta, current_template, step,
+			 view_mode, view_focused, view_stack, view_forward, conversation_history)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, forkID, dataJSON, metaJSON, templateJSON, toStep,
+		state.View.Mode, viewFocusedJSON, viewStackJSON, viewForwardJSON, conversationHistoryJSON)
+	if err != nil {
+		return fmt.Errorf("insert fork state: %w", err)
+	}
+
+	// 3. Deltas up to the fork step, so replay and history work on the fork
+	_, err = tx.Exec(ctx, `
+		INSERT INTO chat_session_deltas
+			(session_id, step, trigger, source, actor_id, delta_type, path, action, result, template, turn_id, payload, created_at)
+		SELECT $1, step, trigger, source, actor_id, delta_type, path, action, result, template, turn_id, payload, created_at
+		FROM chat_session_deltas
+		WHERE session_id = $2 AND step <= $3
+		ORDER BY step
+	`, forkID, parentID, toStep)
+	if err != nil {
+		return fmt.Errorf("copy deltas: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetBranches returns the fork tree the session belongs to: walks up to the root
+// session, then down through all its forks (parents first, oldest first).
+func (a *StateAdapter) GetBranches(ctx context.Context, sessionID string) ([]domain.SessionBranch, error) {
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("db.get_branches")
+		defer endSpan()
+	}
+	rows, err := a.client.pool.Query(ctx, `
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_session_id, 0 AS depth
+			FROM chat_sessions
+			WHERE id = $1
+			UNION ALL
+			SELECT s.id, s.parent_session_id, a.depth + 1
+			FROM chat_sessions s
+			JOIN ancestors a ON s.id = a.parent_session_id
+			WHERE a.depth < 100
+		),
+		tree AS (
+			SELECT s.id, s.parent_session_id, s.forked_at_step, s.status, s.created_at, 0 AS depth
+			FROM chat_sessions s
+			WHERE s.id = (SELECT id FROM ancestors ORDER BY depth DESC LIMIT 1)
+			UNION ALL
+			SELECT s.id, s.parent_session_id, s.forked_at_step, s.status, s.created_at, t.depth + 1
+			FROM chat_sessions s
+			JOIN tree t ON s.parent_session_id = t.id
+			WHERE t.depth < 100
+		)
+		SELECT t.id, t.parent_session_id, COALESCE(t.forked_at_step, 0), COALESCE(st.step, 0), t.status, t.created_at
+		FROM tree t
+		LEFT JOIN chat_session_state st ON st.session_id = t.id
+		ORDER BY t.depth, t.created_at
+	`, sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("query branches: %w", err)
+	}
+	branches, err := scanBranches(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(branches) == 0 {
+		return nil, domain.ErrSessionNotFound
+	}
+	return branches, nil
+}
+
+// GetBranchTrees returns the fork trees of several sessions in one query: finds the root
+// of each session, then walks down every distinct root (parents first, oldest first).
+// IDs that are not sessions are skipped.
+func (a *StateAdapter) GetBranchTrees(ctx context.Context, sessionIDs []string) ([]domain.SessionBranch, error) {
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("db.get_branch_trees")
+		defer endSpan()
+	}
+	ids := make([]string, 0, len(sessionIDs))
+	for _, id := range sessionIDs {
+		if _, err := uuid.Parse(id); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := a.client.pool.Query(ctx, `
+		WITH RECURSIVE ancestors AS (
+			SELECT id AS origin, id, parent_session_id, 0 AS depth
+			FROM chat_sessions
+			WHERE id = ANY($1::text[]::uuid[])
+			UNION ALL
+			SELECT a.origin, s.id, s.parent_session_id, a.depth + 1
+			FROM chat_sessions s
+			JOIN ancestors a ON s.id = a.parent_session_id
+			WHERE a.depth < 100
+		),
+		roots AS (
+			SELECT DISTINCT ON (origin) id
+			FROM ancestors
+			ORDER BY origin, depth DESC
+		),
+		tree AS (
+			SELECT s.id, s.parent_session_id, s.forked_at_step, s.status, s.created_at, 0 AS depth
+			FROM chat_sessions s
+			WHERE s.id IN (SELECT id FROM roots)
+			UNION ALL
+			SELECT s.id, s.parent_session_id, s.forked_at_step, s.status, s.created_at, t.depth + 1
+			FROM chat_sessions s
+			JOIN tree t ON s.parent_session_id = t.id
+			WHERE t.depth < 100
+		)
+		SELECT t.id, t.parent_session_id, COALESCE(t.forked_at_step, 0), COALESCE(st.step, 0), t.status, t.created_at
+		FROM tree t
+		LEFT JOIN chat_session_state st ON st.session_id = t.id
+		ORDER BY t.depth, t.created_at
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("query branch trees: %w", err)
+	}
+	return scanBranches(rows)
+}
+
+// scanBranches reads id, parent, fork step, step, status, created_at rows into branches
+func scanBranches(rows pgx.Rows) ([]domain.SessionBranch, error) {
+	defer rows.Close()
+
+	var branches []domain.SessionBranch
+	for rows.Next() {
+		var b domain.SessionBranch
+		var parentID *string
+		if err := rows.Scan(&b.SessionID, &parentID, &b.ForkStep, &b.Step, &b.Status, &b.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan branch: %w", err)
+		}
+		if parentID != nil {
+			b.ParentID = *parentID
+		}
+		branches = append(branches, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate branches: %w", err)
+	}
+	return branches, nil
+}
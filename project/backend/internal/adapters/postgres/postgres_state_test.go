@@ -2,6 +2,7 @@ package postgres_test
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -486,6 +487,103 @@ func TestStateAdapter_ViewStack(t *testing.T) {
 	}
 }
 
+// TestStateAdapter_ForwardStack tests PushForward, PopForward, ClearForward and the forward stack in state and view deltas
+func TestStateAdapter_ForwardStack(t *testing.T) {
+	client := getSharedClient(t)
+	ctx := context.Background()
+
+	adapter := postgres.NewStateAdapter(client, testLog)
+	sessionID := testSessionID(t, client)
+	defer cleanupTestSession(t, client, sessionID)
+
+	if _, err := adapter.CreateState(ctx, sessionID); err != nil {
+		t.Fatalf("CreateState failed: %v", err)
+	}
+
+	// User went back from the detail of p2: it waits on the forward stack
+	detail := &domain.ViewSnapshot{
+		Mode:      domain.ViewModeDetail,
+		Focused:   &domain.EntityRef{Type: domain.EntityTypeProduct, ID: "p2"},
+		Title:     "Hydra Cream",
+		Query:     "увлажняющий крем",
+		Step:      2,
+		CreatedAt: time.Now(),
+	}
+	if err := adapter.PushForward(ctx, sessionID, detail); err != nil {
+		t.Fatalf("PushForward failed: %v", err)
+	}
+
+	state, err := adapter.GetState(ctx, sessionID)
+	if err != nil {
+		t.Fatalf("GetState failed: %v", err)
+	}
+	if len(state.ForwardStack) != 1 || state.ForwardStack[0].Title != "Hydra Cream" {
+		t.Errorf("Expected the detail view on the forward stack, got %+v", state.ForwardStack)
+	}
+
+	// View deltas carry the forward stack
+	if _, err := adapter.UpdateView(ctx, sessionID, domain.ViewState{Mode: domain.ViewModeGrid}, nil, domain.DeltaInfo{
+		Trigger: domain.TriggerWidgetAction, Source: domain.SourceUser, ActorID: "user_back", DeltaType: domain.DeltaTypePop, Path: "view",
+	}); err != nil {
+		t.Fatalf("UpdateView failed: %v", err)
+	}
+	deltas, err := adapter.GetDeltas(ctx, sessionID)
+	if err != nil {
+		t.Fatalf("GetDeltas failed: %v", err)
+	}
+	if p := deltas[len(deltas)-1].Payload; p == nil || len(p.ForwardStack) != 1 || p.ForwardStack[0].Focused.ID != "p2" {
+		t.Errorf("Expected the forward stack in the view delta payload, got %+v", p)
+	}
+
+	// Forward pops it
+	popped, err := adapter.PopForward(ctx, sessionID)
+	if err != nil {
+		t.Fatalf("PopForward failed: %v", err)
+	}
+	if popped == nil || popped.Focused == nil || popped.Focused.ID != "p2" {
+		t.Errorf("Expected popped focused to be p2, got %+v", popped)
+	}
+	if popped, _ := adapter.PopForward(ctx, sessionID); popped != nil {
+		t.Error("Expected nil when popping empty forward stack")
+	}
+
+	// New navigation clears it
+	if err := adapter.PushForward(ctx, sessionID, detail); err != nil {
+		t.Fatalf("PushForward failed: %v", err)
+	}
+	if err := adapter.ClearForward(ctx, sessionID); err != nil {
+		t.Fatalf("ClearForward failed: %v", err)
+	}
+	forward, err := adapter.GetForwardStack(ctx, sessionID)
+	if err != nil {
+		t.Fatalf("GetForwardStack failed: %v", err)
+	}
+	if len(forward) != 0 {
+		t.Errorf("Expected empty forward stack after clear, got %d", len(forward))
+	}
+
+	// A widget data write keeps it, a new user query drops it
+	if err := adapter.PushForward(ctx, sessionID, detail); err != nil {
+		t.Fatalf("PushForward failed: %v", err)
+	}
+	dataInfo := domain.DeltaInfo{
+		Trigger: domain.TriggerWidgetAction, Source: domain.SourceUser, ActorID: "user_similar", DeltaType: domain.DeltaTypeAdd, Path: "data.products",
+	}
+	if _, err := adapter.UpdateData(ctx, sessionID, domain.StateData{}, domain.StateMeta{}, dataInfo); err != nil {
+		t.Fatalf("UpdateData failed: %v", err)
+	}
+	if forward, _ := adapter.GetForwardStack(ctx, sessionID); len(forward) != 1 {
+		t.Errorf("Expected a widget data write to keep the forward stack, got %d", len(forward))
+	}
+	dataInfo.Trigger, dataInfo.Source, dataInfo.ActorID = domain.TriggerUserQuery, domain.SourceLLM, "agent1"
+	if _, err := adapter.UpdateData(ctx, sessionID, domain.StateData{}, domain.StateMeta{}, dataInfo); err != nil {
+		t.Fatalf("UpdateData failed: %v", err)
+	}
+	if forward, _ := adapter.GetForwardStack(ctx, sessionID); len(forward) != 0 {
+		t.Errorf("Expected a new query to drop the forward stack, got %d", len(forward))
+	}
+}
+
 // TestStateAdapter_ViewStateInSessionState tests view state fields in SessionState
 func TestStateAdapter_ViewStateInSessionState(t *testing.T) {
 	client := getSharedClient(t)
@@ -617,6 +715,12 @@ func TestStateAdapter_UpdateData(t *testing.T) {
 	if deltas[0].ActorID != "agent1" {
 		t.Errorf("Expected actor_id 'agent1', got '%s'", deltas[0].ActorID)
 	}
+	// Delta carries the written zones for replay
+	if p := deltas[0].Payload; p == nil || p.Data == nil || len(p.Data.Products) != 2 || p.Meta == nil || p.Meta.Count != 2 {
+		t.Errorf("Expected data+meta payload, got %+v", deltas[0].Payload)
+	} else if p.View != nil {
+		t.Error("Expected no view in a data delta payload")
+	}
 
 	// Verify template and view were NOT affected
 	if state.Current.Template != nil {
@@ -694,6 +798,9 @@ func TestStateAdapter_UpdateTemplate(t *testing.T) {
 	if deltas[0].ActorID != "agent2" {
 		t.Errorf("Expected actor_id 'agent2', got '%s'", deltas[0].ActorID)
 	}
+	if _, ok := deltas[0].Template["formation"]; !ok {
+		t.Error("Expected template delta to carry the formation")
+	}
 }
 
 // TestStateAdapter_UpdateView tests zone-write for view zone
@@ -758,6 +865,9 @@ func TestStateAdapter_UpdateView(t *testing.T) {
 	if deltas[0].Source != domain.SourceUser {
 		t.Errorf("Expected source 'user', got '%s'", deltas[0].Source)
 	}
+	if p := deltas[0].Payload; p == nil || p.View == nil || p.View.Focused == nil || p.View.Focused.ID != "p1" || len(p.ViewStack) != 1 {
+		t.Errorf("Expected view+stack payload, got %+v", deltas[0].Payload)
+	}
 }
 
 // TestStateAdapter_AppendConversation tests conversation history zone-write
@@ -989,3 +1099,89 @@ func TestStateAdapter_ZoneWriteIsolation(t *testing.T) {
 
 	t.Log("Zone isolation verified: all 4 zones written independently, no cross-contamination")
 }
+
+// TestStateAdapter_ForkAndBranches tests ForkState (session link, state, deltas up to the step), GetBranches and GetBranchTrees
+func TestStateAdapter_ForkAndBranches(t *testing.T) {
+	client := getSharedClient(t)
+	ctx := context.Background()
+
+	adapter := postgres.NewStateAdapter(client, testLog)
+	parentID := testSessionID(t, client)
+	defer cleanupTestSession(t, client, parentID)
+
+	if _, err := adapter.CreateState(ctx, parentID); err != nil {
+		t.Fatalf("CreateState failed: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := adapter.AddDelta(ctx, parentID, &domain.Delta{
+			TurnID:    fmt.Sprintf("turn-%d", i+1),
+			Trigger:   domain.TriggerUserQuery,
+			DeltaType: domain.DeltaTypeAdd,
+			Path:      "data.products",
+			Action:    domain.Action{Type: domain.ActionSearch},
+		}); err != nil {
+			t.Fatalf("AddDelta %d failed: %v", i+1, err)
+		}
+	}
+
+	forkID := uuid.New().String()
+	defer cleanupTestSession(t, client, forkID)
+	forkState := &domain.SessionState{
+		Current:             domain.StateCurrent{Data: domain.StateData{Products: []domain.Product{{ID: "p1", Name: "Cream"}}}},
+		View:                domain.ViewState{Mode: domain.ViewModeGrid},
+		ConversationHistory: []domain.LLMMessage{{Role: "user", Content: "creams"}},
+	}
+	if err := adapter.ForkState(ctx, parentID, forkID, 2, forkState); err != nil {
+		t.Fatalf("ForkState failed: %v", err)
+	}
+
+	state, err := adapter.GetState(ctx, forkID)
+	if err != nil {
+		t.Fatalf("GetState of fork failed: %v", err)
+	}
+	if state.Step != 2 || len(state.Current.Data.Products) != 1 || len(state.ConversationHistory) != 1 {
+		t.Errorf("Expected the fork state at step 2, got step=%d %+v", state.Step, state.Current.Data)
+	}
+	deltas, err := adapter.GetDeltas(ctx, forkID)
+	if err != nil {
+		t.Fatalf("GetDeltas of fork failed: %v", err)
+	}
+	if len(deltas) != 2 || deltas[1].Step != 2 || deltas[1].TurnID != "turn-2" {
+		t.Errorf("Expected deltas 1-2 copied with their steps, got %+v", deltas)
+	}
+
+	// Both sessions see the same tree
+	for _, id := range []string{parentID, forkID} {
+		branches, err := adapter.GetBranches(ctx, id)
+		if err != nil {
+			t.Fatalf("GetBranches(%s) failed: %v", id, err)
+		}
+		if len(branches) != 2 || branches[0].SessionID != parentID || branches[1].ParentID != parentID || branches[1].ForkStep != 2 {
+			t.Errorf("Expected parent then fork at step 2, got %+v", branches)
+		}
+	}
+
+	// Batch: a standalone session is its own tree, the shared tree comes once, unknown ids are skipped
+	soloID := testSessionID(t, client)
+	defer cleanupTestSession(t, client, soloID)
+	trees, err := adapter.GetBranchTrees(ctx, []string{forkID, soloID, parentID, uuid.New().String(), "not-a-uuid"})
+	if err != nil {
+		t.Fatalf("GetBranchTrees failed: %v", err)
+	}
+	if len(trees) != 3 {
+		t.Errorf("Expected parent, fork and the standalone session, got %+v", trees)
+	}
+	roots := domain.BuildBranchTree(trees)
+	for _, root := range roots {
+		if root.SessionID == parentID && (len(root.Children) != 1 || root.Children[0].SessionID != forkID) {
+			t.Errorf("Expected the fork under its parent, got %+v", root)
+		}
+	}
+	if len(roots) != 2 {
+		t.Errorf("Expected 2 trees, got %d", len(roots))
+	}
+
+	if err := adapter.ForkState(ctx, uuid.New().String(), uuid.New().String(), 0, forkState); err != domain.ErrSessionNotFound {
+		t.Errorf("Expected ErrSessionNotFound for an unknown parent, got %v", err)
+	}
+}
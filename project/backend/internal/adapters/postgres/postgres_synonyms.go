@@ -0,0 +1,40 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"keepstar/internal/domain"
+)
+
+// GetSynonyms returns the tenant's synonym rules, oldest first
+func (a *CatalogAdapter) GetSynonyms(ctx context.Context, tenantID string) ([]domain.Synonym, error) {
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("db.synonyms")
+		defer endSpan()
+	}
+
+	rows, err := a.client.pool.Query(ctx, `
+		SELECT id::text, term, synonyms, bidirectional
+		FROM catalog.tenant_synonyms
+		WHERE tenant_id = $1
+		ORDER BY created_at, term
+	`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("query synonyms: %w", err)
+	}
+	defer rows.Close()
+
+	var synonyms []domain.Synonym
+	for rows.Next() {
+		var s domain.Synonym
+		if err := rows.Scan(&s.ID, &s.Term, &s.Synonyms, &s.Bidirectional); err != nil {
+			return nil, fmt.Errorf("scan synonym: %w", err)
+		}
+		synonyms = append(synonyms, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate synonyms: %w", err)
+	}
+	return synonyms, nil
+}
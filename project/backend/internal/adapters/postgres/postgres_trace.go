@@ -101,6 +101,19 @@ func printTrace(t *domain.PipelineTrace) {
 	fmt.Fprintf(w, "  PIPELINE  session=%.8s  query=%q\n", t.SessionID, t.Query)
 	fmt.Fprintf(w, "%s\n", line)
 
+	// Response cache
+	if c := t.ResponseCache; c != nil {
+		if c.Hit {
+			fmt.Fprintf(w, "  CACHE  hit=%s sim=%.3f entry=%.8s saved=$%.6f lookup=%dms\n", c.Match, c.Similarity, c.EntryID, c.SavedCostUSD, c.LookupMs)
+		} else {
+			fmt.Fprintf(w, "  CACHE  miss lookup=%dms stored=%v", c.LookupMs, c.Stored)
+			if c.Error != "" {
+				fmt.Fprintf(w, "  ERROR: %s", c.Error)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+
 	// Agent1
 	if t.Agent1 != nil {
 		a := t.Agent1
@@ -166,6 +179,15 @@ func printTrace(t *domain.PipelineTrace) {
 		} else {
 			fmt.Fprintf(w, "    NO TOOL CALLED  stop=%s\n", a.StopReason)
 		}
+		if len(a.Steps) > 1 {
+			for _, st := range a.Steps {
+				names := make([]string, len(st.Tools))
+				for i, tt := range st.Tools {
+					names[i] = tt.Name
+				}
+				fmt.Fprintf(w, "    step %d: llm=%dms tool=%dms stop=%s tools=[%s]\n", st.Step, st.LLMMs, st.ToolMs, st.StopReason, strings.Join(names, ","))
+			}
+		}
 		fmt.Fprintf(w, "    tokens: %d in + %d out  $%.6f\n", a.InputTokens, a.OutputTokens, a.CostUSD)
 		if a.CacheRead > 0 {
 			fmt.Fprintf(w, "    cache: read=%d write=%d\n", a.CacheRead, a.CacheWrite)
@@ -0,0 +1,50 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"keepstar/internal/domain"
+)
+
+// UsageAdapter implements ports.UsagePort with one row per tenant per UTC day
+type UsageAdapter struct {
+	client *Client
+}
+
+// NewUsageAdapter creates a new UsageAdapter
+func NewUsageAdapter(client *Client) *UsageAdapter {
+	return &UsageAdapter{client: client}
+}
+
+// AddUsage atomically increments the tenant's daily bucket
+func (a *UsageAdapter) AddUsage(ctx context.Context, tenantSlug string, at time.Time, tokens int64, costUSD float64) error {
+	_, err := a.client.pool.Exec(ctx, `
+		INSERT INTO tenant_usage_daily (tenant_slug, day, tokens, cost_usd, requests)
+		VALUES ($1, $2::date, $3, $4, 1)
+		ON CONFLICT (tenant_slug, day) DO UPDATE SET
+			tokens = tenant_usage_daily.tokens + EXCLUDED.tokens,
+			cost_usd = tenant_usage_daily.cost_usd + EXCLUDED.cost_usd,
+			requests = tenant_usage_daily.requests + 1,
+			updated_at = NOW()
+	`, tenantSlug, at.UTC().Format("2006-01-02"), tokens, costUSD)
+	if err != nil {
+		return fmt.Errorf("add usage: %w", err)
+	}
+	return nil
+}
+
+// GetUsageSince sums daily buckets from the UTC day of since
+func (a *UsageAdapter) GetUsageSince(ctx context.Context, tenantSlug string, since time.Time) (*domain.TenantUsage, error) {
+	var usage domain.TenantUsage
+	err := a.client.pool.QueryRow(ctx, `
+		SELECT COALESCE(SUM(tokens), 0), COALESCE(SUM(cost_usd), 0), COALESCE(SUM(requests), 0)
+		FROM tenant_usage_daily
+		WHERE tenant_slug = $1 AND day >= $2::date
+	`, tenantSlug, since.UTC().Format("2006-01-02")).Scan(&usage.Tokens, &usage.CostUSD, &usage.Requests)
+	if err != nil {
+		return nil, fmt.Errorf("get usage: %w", err)
+	}
+	return &usage, nil
+}
@@ -0,0 +1,50 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"keepstar/internal/adapters/postgres"
+)
+
+func TestUsageAdapter_AccumulatesDailyBuckets(t *testing.T) {
+	client := getSharedClient(t)
+	ctx := context.Background()
+	adapter := postgres.NewUsageAdapter(client)
+
+	slug := "usage-test-" + uuid.New().String()[:8]
+	t.Cleanup(func() {
+		_, _ = client.Pool().Exec(context.Background(), `DELETE FROM tenant_usage_daily WHERE tenant_slug = $1`, slug)
+	})
+
+	today := time.Now().UTC()
+	lastMonth := today.AddDate(0, -1, 0)
+
+	for _, add := range []struct {
+		at     time.Time
+		tokens int64
+		cost   float64
+	}{
+		{today, 1000, 0.01},
+		{today, 500, 0.005},
+		{lastMonth, 9999, 1.0},
+	} {
+		if err := adapter.AddUsage(ctx, slug, add.at, add.tokens, add.cost); err != nil {
+			t.Fatalf("AddUsage: %v", err)
+		}
+	}
+
+	dayStart := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	usage, err := adapter.GetUsageSince(ctx, slug, dayStart)
+	if err != nil {
+		t.Fatalf("GetUsageSince: %v", err)
+	}
+	if usage.Tokens != 1500 || usage.Requests != 2 {
+		t.Errorf("want 1500 tokens / 2 requests today, got %+v", usage)
+	}
+	if usage.CostUSD < 0.0149 || usage.CostUSD > 0.0151 {
+		t.Errorf("want ~0.015 USD today, got %f", usage.CostUSD)
+	}
+}
@@ -0,0 +1,32 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+)
+
+const migrationPromptVersions = `
+CREATE TABLE IF NOT EXISTS prompt_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    tenant_slug TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL,
+    weight INTEGER NOT NULL DEFAULT 100,
+    active BOOLEAN NOT NULL DEFAULT FALSE,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(name, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_prompt_versions_active
+    ON prompt_versions(name, tenant_slug) WHERE active;
+`
+
+// RunPromptMigrations creates the prompt_versions table for the prompt registry
+func (c *Client) RunPromptMigrations(ctx context.Context) error {
+	if _, err := c.pool.Exec(ctx, migrationPromptVersions); err != nil {
+		return fmt.Errorf("prompt migration failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+)
+
+// Requires the vector extension (RunCatalogMigrations)
+const migrationResponseCache = `
+CREATE TABLE IF NOT EXISTS response_cache (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_slug TEXT NOT NULL,
+    state_key TEXT NOT NULL,
+    query_norm TEXT NOT NULL,
+    catalog_version TEXT NOT NULL,
+    embedding vector(384),
+    payload JSONB NOT NULL,
+    hits INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    UNIQUE(tenant_slug, state_key, query_norm, catalog_version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_response_cache_scope
+    ON response_cache(tenant_slug, state_key, catalog_version);
+CREATE INDEX IF NOT EXISTS idx_response_cache_expires
+    ON response_cache(expires_at);
+`
+
+// RunResponseCacheMigrations creates the response_cache table for the semantic response cache
+func (c *Client) RunResponseCacheMigrations(ctx context.Context) error {
+	if _, err := c.pool.Exec(ctx, migrationResponseCache); err != nil {
+		return fmt.Errorf("response cache migration failed: %w", err)
+	}
+	return nil
+}
@@ -80,6 +80,13 @@ func (s *RetentionService) runCleanup(ctx context.Context, logFn func(string, ..
 		logFn("retention_history_trimmed", "sessions", trimmed)
 	}
 
+	cacheDeleted, err := s.cleanupResponseCache(ctx)
+	if err != nil {
+		logFn("retention_response_cache_error", "error", err)
+	} else if cacheDeleted > 0 {
+		logFn("retention_response_cache_cleaned", "deleted", cacheDeleted)
+	}
+
 	logsDeleted, err := s.cleanupRequestLogs(ctx)
 	if err != nil {
 		logFn("retention_request_logs_error", "error", err)
@@ -147,6 +154,17 @@ func (s *RetentionService) cleanupDeadSessions(ctx context.Context) (int64, erro
 	return int64(len(sessionIDs)), nil
 }
 
+// cleanupResponseCache deletes expired response_cache entries
+// (stale catalog versions are never read and expire on their own)
+func (s *RetentionService) cleanupResponseCache(ctx context.Context) (int64, error) {
+	result, err := s.client.pool.Exec(ctx,
+		`DELETE FROM response_cache WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired response cache: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
 // cleanupRequestLogs deletes request_logs older than RequestLogMaxAge
 func (s *RetentionService) cleanupRequestLogs(ctx context.Context) (int64, error) {
 	cutoff := time.Now().Add(-s.config.RequestLogMaxAge)
@@ -32,6 +32,10 @@ func TestMain(m *testing.M) {
 	_ = sharedClient.RunMigrations(ctx)
 	_ = sharedClient.RunStateMigrations(ctx)
 	_ = sharedClient.RunCatalogMigrations(ctx)
+	_ = sharedClient.RunUsageMigrations(ctx)
+	_ = sharedClient.RunTraceMigrations(ctx)
+	_ = sharedClient.RunPromptMigrations(ctx)
+	_ = sharedClient.RunResponseCacheMigrations(ctx)
 
 	code := m.Run()
 	sharedClient.Close()
@@ -74,6 +74,28 @@ ALTER TABLE chat_session_deltas
     ADD COLUMN IF NOT EXISTS turn_id TEXT;
 `
 
+// Replayable deltas — zone content written by the delta (data/meta/view/stack)
+const migrationDeltaPayload = `
+ALTER TABLE chat_session_deltas
+    ADD COLUMN IF NOT EXISTS payload JSONB;
+`
+
+// Forward navigation — views left by "back"
+const migrationViewForward = `
+ALTER TABLE chat_session_state
+    ADD COLUMN IF NOT EXISTS view_forward JSONB DEFAULT '[]';
+`
+
+// Session forking — branch link to the parent session and the step it was forked at
+const migrationSessionFork = `
+ALTER TABLE chat_sessions
+    ADD COLUMN IF NOT EXISTS parent_session_id UUID REFERENCES chat_sessions(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS forked_at_step INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_chat_sessions_parent
+    ON chat_sessions(parent_session_id);
+`
+
 // RunStateMigrations executes state-related migrations
 func (c *Client) RunStateMigrations(ctx context.Context) error {
 	migrations := []string{
@@ -83,6 +105,9 @@ func (c *Client) RunStateMigrations(ctx context.Context) error {
 		migrationDeltaStateExtension,
 		migrationConversationHistory,
 		migrationDeltaTurnID,
+		migrationDeltaPayload,
+		migrationViewForward,
+		migrationSessionFork,
 	}
 
 	for i, migration := range migrations {
@@ -0,0 +1,26 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+)
+
+const migrationTenantUsage = `
+CREATE TABLE IF NOT EXISTS tenant_usage_daily (
+    tenant_slug TEXT NOT NULL,
+    day DATE NOT NULL,
+    tokens BIGINT NOT NULL DEFAULT 0,
+    cost_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
+    requests BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_slug, day)
+);
+`
+
+// RunUsageMigrations creates the tenant_usage_daily table for quota accounting
+func (c *Client) RunUsageMigrations(ctx context.Context) error {
+	if _, err := c.pool.Exec(ctx, migrationTenantUsage); err != nil {
+		return fmt.Errorf("usage migration failed: %w", err)
+	}
+	return nil
+}
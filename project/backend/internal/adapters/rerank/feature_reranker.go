@@ -0,0 +1,157 @@
+package rerank
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+)
+
+// recencyHalfLife is the listing age at which the recency feature drops to 0.5
+const recencyHalfLife = 90 * 24 * time.Hour
+
+// FeatureReranker implements ports.RerankerPort with a weighted sum of product features.
+// Deterministic for a fixed clock: same candidates and weights → same order.
+type FeatureReranker struct {
+	now func() time.Time
+}
+
+// NewFeatureReranker creates the feature-based reranker
+func NewFeatureReranker() *FeatureReranker {
+	return &FeatureReranker{now: time.Now}
+}
+
+// Name implements ports.RerankerPort
+func (r *FeatureReranker) Name() string { return domain.RerankStrategyFeatures }
+
+// Rerank implements ports.RerankerPort
+func (r *FeatureReranker) Rerank(ctx context.Context, req ports.RerankRequest) (*ports.RerankResponse, error) {
+	now := r.now()
+	maxBase := maxBaseScore(req.Candidates)
+	scores := make([]domain.RerankScore, len(req.Candidates))
+	for i, c := range req.Candidates {
+		features := productFeatures(c, req.Filter, maxBase, now)
+		w := req.Weights
+		score := w.Relevance*features["relevance"] +
+			w.FilterMatch*features["filter_match"] +
+			w.Stock*features["stock"] +
+			w.Rating*features["rating"] +
+			w.Recency*features["recency"]
+		scores[i] = domain.RerankScore{ID: c.Product.ID, Score: score, Features: features}
+	}
+	return &ports.RerankResponse{Scores: scores}, nil
+}
+
+// productFeatures computes the normalized (0..1) features of a candidate
+func productFeatures(c domain.RerankCandidate, f ports.ProductFilter, maxBase float64, now time.Time) map[string]float64 {
+	p := c.Product
+	features := map[string]float64{
+		"relevance":    0,
+		"filter_match": filterMatch(p, f),
+		"stock":        0,
+		"rating":       math.Min(p.Rating/5, 1),
+		"recency":      0,
+	}
+	if maxBase > 0 {
+		features["relevance"] = c.BaseScore / maxBase
+	}
+	inStock := p.StockQuantity > 0
+	if n, tracked := p.AvailableStock(); tracked {
+		inStock = n > 0 // reserved units cannot be sold
+	}
+	if inStock {
+		features["stock"] = 1
+	}
+	if p.CreatedAt != nil {
+		age := now.Sub(*p.CreatedAt)
+		if age < 0 {
+			age = 0
+		}
+		features["recency"] = math.Pow(0.5, float64(age)/float64(recencyHalfLife))
+	}
+	return features
+}
+
+func maxBaseScore(candidates []domain.RerankCandidate) float64 {
+	var max float64
+	for _, c := range candidates {
+		if c.BaseScore > max {
+			max = c.BaseScore
+		}
+	}
+	return max
+}
+
+// filterMatch is the share of requested filter values the product has.
+// Hard filters already hold for keyword hits; this separates vector hits and
+// products matching several of the OR-ed list values. No filters → 0 for everyone.
+func filterMatch(p domain.Product, f ports.ProductFilter) float64 {
+	var wanted, matched int
+	check := func(ok bool) {
+		wanted++
+		if ok {
+			matched++
+		}
+	}
+
+	if f.Brand != "" {
+		check(containsFold(p.Brand, f.Brand))
+	}
+	for _, b := range f.Brands {
+		check(containsFold(p.Brand, b))
+	}
+	if f.CategoryName != "" {
+		check(containsFold(p.Category, f.CategoryName))
+	}
+	for _, pair := range []struct{ have, want string }{
+		{p.ProductForm, f.ProductForm},
+		{p.RoutineStep, f.RoutineStep},
+		{p.Texture, f.Texture},
+	} {
+		if pair.want != "" {
+			check(strings.EqualFold(pair.have, pair.want))
+		}
+	}
+	for _, pair := range []struct {
+		have []string
+		want []string
+	}{
+		{p.SkinType, append(nonEmpty(f.SkinType), f.SkinTypes...)},
+		{p.Concern, append(nonEmpty(f.Concern), f.Concerns...)},
+		{p.KeyIngredients, append(nonEmpty(f.KeyIngredient), f.KeyIngredients...)},
+		{p.TargetArea, append(nonEmpty(f.TargetArea), f.TargetAreas...)},
+		{p.FreeFrom, f.FreeFrom},
+	} {
+		for _, w := range pair.want {
+			check(containsValue(pair.have, w))
+		}
+	}
+
+	if wanted == 0 {
+		return 0
+	}
+	return float64(matched) / float64(wanted)
+}
+
+func nonEmpty(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
+
+func containsValue(values []string, want string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, want) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
@@ -0,0 +1,73 @@
+package rerank
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+)
+
+func TestFeatureReranker_Features(t *testing.T) {
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	listed := now.Add(-recencyHalfLife)
+	r := &FeatureReranker{now: func() time.Time { return now }}
+
+	resp, err := r.Rerank(context.Background(), ports.RerankRequest{
+		Filter: ports.ProductFilter{
+			Brand:           "cerave",
+			AttributeFilter: ports.AttributeFilter{SkinTypes: []string{"dry", "sensitive"}},
+		},
+		Candidates: []domain.RerankCandidate{
+			{Product: domain.Product{ID: "p1", Brand: "CeraVe", SkinType: []string{"dry"}, StockQuantity: 3, Rating: 4, CreatedAt: &listed}, BaseScore: 0.02},
+			{Product: domain.Product{ID: "p2", Brand: "COSRX"}, BaseScore: 0.04},
+		},
+		Weights: domain.DefaultRerankWeights(),
+	})
+	if err != nil {
+		t.Fatalf("rerank: %v", err)
+	}
+	scores := resp.Scores
+
+	f := scores[0].Features
+	want := map[string]float64{"relevance": 0.5, "filter_match": 2.0 / 3, "stock": 1, "rating": 0.8, "recency": 0.5}
+	for k, v := range want {
+		if math.Abs(f[k]-v) > 1e-9 {
+			t.Errorf("p1 %s: want %.3f, got %.3f", k, v, f[k])
+		}
+	}
+	if scores[1].Features["relevance"] != 1 || scores[1].Features["filter_match"] != 0 {
+		t.Errorf("unexpected p2 features: %v", scores[1].Features)
+	}
+
+	// 0.5 + 0.3·2/3 + 0.2 + 0.2·0.8 + 0.1·0.5 = 1.11 beats p2's relevance of 1.0
+	if math.Abs(scores[0].Score-1.11) > 1e-9 || scores[1].Score != 1 {
+		t.Errorf("unexpected scores: %.3f %.3f", scores[0].Score, scores[1].Score)
+	}
+}
+
+func TestFeatureReranker_WeightsChangeOrder(t *testing.T) {
+	r := NewFeatureReranker()
+	req := ports.RerankRequest{
+		Candidates: []domain.RerankCandidate{
+			{Product: domain.Product{ID: "out"}, BaseScore: 0.03},
+			{Product: domain.Product{ID: "in", StockQuantity: 5}, BaseScore: 0.02},
+		},
+		Weights: domain.RerankWeights{Relevance: 1},
+	}
+
+	resp, _ := r.Rerank(context.Background(), req)
+	scores := resp.Scores
+	if scores[0].Score <= scores[1].Score {
+		t.Errorf("relevance only: more relevant item should win, got %v", scores)
+	}
+
+	req.Weights.Stock = 1
+	resp, _ = r.Rerank(context.Background(), req)
+	scores = resp.Scores
+	if scores[1].Score <= scores[0].Score {
+		t.Errorf("stock weight: in-stock item should win, got %v", scores)
+	}
+}
@@ -0,0 +1,91 @@
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+	"keepstar/internal/prompts"
+)
+
+// LLMReranker implements ports.RerankerPort with an LLM relevance judgement.
+// Final score = Relevance·(RRF relative to best) + LLM·(judge score 0..1).
+// The call runs under stage "rerank", so LLM_ROUTES can give it its own (cheap) model.
+type LLMReranker struct {
+	llm ports.LLMPort
+}
+
+// NewLLMReranker creates the LLM-based reranker
+func NewLLMReranker(llm ports.LLMPort) *LLMReranker {
+	return &LLMReranker{llm: llm}
+}
+
+// Name implements ports.RerankerPort
+func (r *LLMReranker) Name() string { return domain.RerankStrategyLLM }
+
+type llmRerankResponse struct {
+	Scores []struct {
+		ID    string  `json:"id"`
+		Score float64 `json:"score"`
+	} `json:"scores"`
+}
+
+// Rerank implements ports.RerankerPort. Candidates the model skipped get judge score 0.
+// The usage of the judge call is returned even when its answer does not parse: it was paid for.
+func (r *LLMReranker) Rerank(ctx context.Context, req ports.RerankRequest) (*ports.RerankResponse, error) {
+	if len(req.Candidates) == 0 {
+		return &ports.RerankResponse{}, nil
+	}
+	var endLLM func(...string)
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endLLM = sc.Start("rerank.llm")
+	}
+	resp, err := r.llm.ChatWithUsage(domain.WithStage(ctx, "rerank"), prompts.RerankSystemPrompt, prompts.BuildRerankPrompt(req.Query, req.Candidates))
+	if err != nil {
+		if endLLM != nil {
+			endLLM("error")
+		}
+		return nil, fmt.Errorf("llm rerank: %w", err)
+	}
+	if endLLM != nil {
+		endLLM(fmt.Sprintf("%s in=%d out=%d", resp.Usage.Model, resp.Usage.InputTokens, resp.Usage.OutputTokens))
+	}
+
+	var parsed llmRerankResponse
+	if err := json.Unmarshal([]byte(extractJSON(resp.Text)), &parsed); err != nil {
+		return &ports.RerankResponse{Usage: resp.Usage}, fmt.Errorf("parse llm rerank: %w", err)
+	}
+	judged := make(map[string]float64, len(parsed.Scores))
+	for _, s := range parsed.Scores {
+		judged[s.ID] = math.Max(0, math.Min(s.Score, 1))
+	}
+
+	maxBase := maxBaseScore(req.Candidates)
+	scores := make([]domain.RerankScore, len(req.Candidates))
+	for i, c := range req.Candidates {
+		features := map[string]float64{"llm": judged[c.Product.ID], "relevance": 0}
+		if maxBase > 0 {
+			features["relevance"] = c.BaseScore / maxBase
+		}
+		scores[i] = domain.RerankScore{
+			ID:       c.Product.ID,
+			Score:    req.Weights.Relevance*features["relevance"] + req.Weights.LLM*features["llm"],
+			Features: features,
+		}
+	}
+	return &ports.RerankResponse{Scores: scores, Usage: resp.Usage}, nil
+}
+
+// extractJSON trims markdown fences and prose around the first JSON object
+func extractJSON(text string) string {
+	start := strings.Index(text, "{")
+	end := strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return text
+	}
+	return text[start : end+1]
+}
@@ -0,0 +1,77 @@
+package rerank
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+	"keepstar/internal/testutil"
+)
+
+// judgeLLM answers ChatWithUsage with a fixed text and records the call
+type judgeLLM struct {
+	*testutil.MockLLMClient
+	text   string
+	stage  string
+	prompt string
+}
+
+func (l *judgeLLM) ChatWithUsage(ctx context.Context, _ string, userMessage string) (*ports.ChatResponse, error) {
+	l.stage = domain.StageFromContext(ctx)
+	l.prompt = userMessage
+	return &ports.ChatResponse{Text: l.text, Usage: domain.LLMUsage{Model: "judge", InputTokens: 300, OutputTokens: 40, CostUSD: 0.0005}}, nil
+}
+
+func TestLLMReranker_BlendsJudgeScore(t *testing.T) {
+	llm := &judgeLLM{
+		MockLLMClient: testutil.NewMockLLMClient(),
+		text:          "```json\n{\"scores\": [{\"id\": \"p1\", \"score\": 0.1}, {\"id\": \"p2\", \"score\": 1.4}]}\n```",
+	}
+	r := NewLLMReranker(llm)
+
+	resp, err := r.Rerank(context.Background(), ports.RerankRequest{
+		Query: "крем для сухой кожи",
+		Candidates: []domain.RerankCandidate{
+			{Product: domain.Product{ID: "p1", Name: "Гель для умывания"}, BaseScore: 0.04},
+			{Product: domain.Product{ID: "p2", Name: "Крем увлажняющий"}, BaseScore: 0.02},
+			{Product: domain.Product{ID: "p3", Name: "Тоник"}, BaseScore: 0.01},
+		},
+		Weights: domain.RerankWeights{Relevance: 1, LLM: 1},
+	})
+	if err != nil {
+		t.Fatalf("rerank: %v", err)
+	}
+	scores := resp.Scores
+
+	if resp.Usage.InputTokens != 300 || resp.Usage.CostUSD != 0.0005 {
+		t.Errorf("judge call usage must reach the caller, got %+v", resp.Usage)
+	}
+	if llm.stage != "rerank" {
+		t.Errorf("want stage rerank for LLM routing, got %q", llm.stage)
+	}
+	if !strings.Contains(llm.prompt, "крем для сухой кожи") || !strings.Contains(llm.prompt, `"id":"p3"`) {
+		t.Errorf("prompt should carry query and candidates: %s", llm.prompt)
+	}
+	// p2: relevance 0.5 + judge clamped to 1 = 1.5 beats p1: 1 + 0.1
+	if scores[1].Score != 1.5 || scores[0].Score != 1.1 {
+		t.Errorf("unexpected scores: %v", scores)
+	}
+	if scores[2].Features["llm"] != 0 {
+		t.Errorf("unjudged candidate should get llm=0, got %v", scores[2].Features)
+	}
+}
+
+func TestLLMReranker_InvalidJSON(t *testing.T) {
+	r := NewLLMReranker(&judgeLLM{MockLLMClient: testutil.NewMockLLMClient(), text: "sorry, no idea"})
+	resp, err := r.Rerank(context.Background(), ports.RerankRequest{
+		Candidates: []domain.RerankCandidate{{Product: domain.Product{ID: "p1"}}},
+	})
+	if err == nil {
+		t.Fatal("want parse error")
+	}
+	if resp == nil || resp.Usage.OutputTokens != 40 {
+		t.Errorf("an unparsable answer is still paid for, want its usage, got %+v", resp)
+	}
+}
@@ -0,0 +1,343 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"sync"
+	"time"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+)
+
+// Policy configures retries, per-attempt deadline and circuit breaker for one stage
+type Policy struct {
+	MaxAttempts      int           // total attempts including the first (1 = no retries)
+	BaseDelay        time.Duration // backoff base: retry k (1-based) waits rand[0, BaseDelay*2^(k-1))
+	MaxDelay         time.Duration // backoff cap; a retry-after longer than this is not waited for
+	AttemptTimeout   time.Duration // deadline for a single attempt (0 = no per-attempt deadline)
+	BreakerThreshold int           // consecutive failed calls that open the breaker (0 = disabled)
+	BreakerCooldown  time.Duration // how long the breaker stays open before a probe call
+}
+
+// DefaultPolicy is used for stages without an explicit policy
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts:      3,
+		BaseDelay:        250 * time.Millisecond,
+		MaxDelay:         4 * time.Second,
+		AttemptTimeout:   30 * time.Second,
+		BreakerThreshold: 5,
+		BreakerCooldown:  30 * time.Second,
+	}
+}
+
+// LLM wraps any ports.LLMPort with retries, jittered exponential backoff
+// (honoring retry-after), a per-attempt deadline and a circuit breaker.
+// The policy is chosen by domain.StageFromContext ("agent1", "agent2", ...).
+type LLM struct {
+	inner    ports.LLMPort
+	policies map[string]Policy
+	fallback Policy
+	log      *slog.Logger
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+
+	// overridable in tests
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+	rand  func() float64
+}
+
+// NewLLM wraps inner. policies maps stage → Policy; stages not listed use fallback.
+func NewLLM(inner ports.LLMPort, fallback Policy, policies map[string]Policy, log *slog.Logger) *LLM {
+	if log == nil {
+		log = slog.Default()
+	}
+	return &LLM{
+		inner:    inner,
+		policies: policies,
+		fallback: fallback,
+		log:      log,
+		breakers: make(map[string]*breaker),
+		now:      time.Now,
+		sleep:    sleepCtx,
+		rand:     rand.Float64,
+	}
+}
+
+// Chat implements ports.LLMPort
+func (l *LLM) Chat(ctx context.Context, message string) (string, error) {
+	var out string
+	err := l.do(ctx, func(ctx context.Context) error {
+		var err error
+		out, err = l.inner.Chat(ctx, message)
+		return err
+	})
+	return out, err
+}
+
+// ChatWithTools implements ports.LLMPort
+func (l *LLM) ChatWithTools(ctx context.Context, systemPrompt string, messages []domain.LLMMessage, tools []domain.ToolDefinition) (*domain.LLMResponse, error) {
+	var out *domain.LLMResponse
+	err := l.do(ctx, func(ctx context.Context) error {
+		var err error
+		out, err = l.inner.ChatWithTools(ctx, systemPrompt, messages, tools)
+		return err
+	})
+	return out, err
+}
+
+// ChatWithToolsCached implements ports.LLMPort
+func (l *LLM) ChatWithToolsCached(ctx context.Context, systemPrompt string, messages []domain.LLMMessage, tools []domain.ToolDefinition, cacheConfig *ports.CacheConfig) (*domain.LLMResponse, error) {
+	var out *domain.LLMResponse
+	err := l.do(ctx, func(ctx context.Context) error {
+		var err error
+		out, err = l.inner.ChatWithToolsCached(ctx, systemPrompt, messages, tools, cacheConfig)
+		return err
+	})
+	return out, err
+}
+
+// ChatWithUsage implements ports.LLMPort
+func (l *LLM) ChatWithUsage(ctx context.Context, systemPrompt, userMessage string) (*ports.ChatResponse, error) {
+	var out *ports.ChatResponse
+	err := l.do(ctx, func(ctx context.Context) error {
+		var err error
+		out, err = l.inner.ChatWithUsage(ctx, systemPrompt, userMessage)
+		return err
+	})
+	return out, err
+}
+
+// do runs call under the stage policy. Each retry is recorded as a
+// "{stage}.llm.retry" span covering the backoff wait and the attempt.
+func (l *LLM) do(ctx context.Context, call func(ctx context.Context) error) error {
+	stage := domain.StageFromContext(ctx)
+	policy := l.policyFor(stage)
+	br := l.breakerFor(stage)
+
+	if !br.allow(l.now(), policy) {
+		l.log.Warn("llm_circuit_open", "stage", stage)
+		return domain.ErrLLMUnavailable
+	}
+
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	sc := domain.SpanFromContext(ctx)
+	spanPrefix := "llm"
+	if stage != "" {
+		spanPrefix = stage + ".llm"
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		var endRetry func(...string)
+		if attempt > 1 {
+			wait, ok := l.backoff(policy, attempt, lastErr)
+			if !ok {
+				break
+			}
+			if sc != nil {
+				endRetry = sc.Start(spanPrefix + ".retry")
+			}
+			l.log.Warn("llm_retry", "stage", stage, "attempt", attempt, "wait_ms", wait.Milliseconds(), "error", lastErr)
+			if err := l.sleep(ctx, wait); err != nil {
+				if endRetry != nil {
+					endRetry("canceled")
+				}
+				br.release()
+				return lastErr
+			}
+		}
+
+		lastErr = l.attempt(ctx, policy, call)
+
+		if endRetry != nil {
+			status := "ok"
+			if lastErr != nil {
+				status = errorLabel(lastErr)
+			}
+			endRetry(fmt.Sprintf("attempt %d/%d: %s", attempt, maxAttempts, status))
+		}
+
+		if lastErr == nil {
+			br.success()
+			return nil
+		}
+		if ctx.Err() != nil || !retryable(lastErr) {
+			break
+		}
+	}
+
+	// Caller cancellation and client errors (400, 401...) are not provider outages
+	if ctx.Err() == nil && retryable(lastErr) {
+		if br.failure(l.now(), policy) {
+			l.log.Error("llm_circuit_opened", "stage", stage, "cooldown_ms", policy.BreakerCooldown.Milliseconds(), "error", lastErr)
+		}
+	} else {
+		br.release()
+	}
+	return lastErr
+}
+
+// attempt runs a single call with the per-attempt deadline
+func (l *LLM) attempt(ctx context.Context, policy Policy, call func(ctx context.Context) error) error {
+	if policy.AttemptTimeout <= 0 {
+		return call(ctx)
+	}
+	attemptCtx, cancel := context.WithTimeout(ctx, policy.AttemptTimeout)
+	defer cancel()
+	err := call(attemptCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("llm attempt timed out after %s: %w", policy.AttemptTimeout, err)
+	}
+	return err
+}
+
+// backoff returns how long to wait before the given attempt (2..n).
+// Full jitter over an exponential window; retry-after is a lower bound.
+// Returns false when retry-after exceeds MaxDelay — waiting that long is worse than failing.
+func (l *LLM) backoff(policy Policy, attempt int, lastErr error) (time.Duration, bool) {
+	window := policy.BaseDelay << (attempt - 2)
+	if policy.MaxDelay > 0 && (window > policy.MaxDelay || window <= 0) {
+		window = policy.MaxDelay
+	}
+	wait := time.Duration(l.rand() * float64(window))
+
+	var perr *domain.LLMProviderError
+	if errors.As(lastErr, &perr) && perr.RetryAfter > 0 {
+		if policy.MaxDelay > 0 && perr.RetryAfter > policy.MaxDelay {
+			return 0, false
+		}
+		if perr.RetryAfter > wait {
+			wait = perr.RetryAfter
+		}
+	}
+	return wait, true
+}
+
+func (l *LLM) policyFor(stage string) Policy {
+	if p, ok := l.policies[stage]; ok {
+		return p
+	}
+	return l.fallback
+}
+
+func (l *LLM) breakerFor(stage string) *breaker {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.breakers[stage]
+	if !ok {
+		b = &breaker{}
+		l.breakers[stage] = b
+	}
+	return b
+}
+
+// retryable reports whether an error is worth another attempt:
+// provider 408/409/429/5xx/529, per-attempt timeouts and transport errors.
+func retryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var perr *domain.LLMProviderError
+	if errors.As(err, &perr) {
+		return perr.Retryable()
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	// Timeouts, connection resets, DNS failures — treat as transient
+	return true
+}
+
+// errorLabel is a short span detail for a failed attempt
+func errorLabel(err error) string {
+	var perr *domain.LLMProviderError
+	if errors.As(err, &perr) {
+		return fmt.Sprintf("status %d", perr.StatusCode)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	return "error"
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// breaker is a consecutive-failure circuit breaker: closed → open after
+// BreakerThreshold failed calls → half-open after BreakerCooldown (one probe)
+// → closed on success, open again on failure.
+type breaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// allow reports whether a call may proceed
+func (b *breaker) allow(now time.Time, policy Policy) bool {
+	if policy.BreakerThreshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < policy.BreakerThreshold {
+		return true
+	}
+	if now.Before(b.openUntil) || b.probing {
+		return false
+	}
+	b.probing = true // half-open: let one call through
+	return true
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.probing = false
+}
+
+// release ends a half-open probe that failed for reasons unrelated to the provider
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// failure records a failed call; returns true if this opened the breaker
+func (b *breaker) failure(now time.Time, policy Policy) bool {
+	if policy.BreakerThreshold <= 0 {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.probing = false
+	if b.failures >= policy.BreakerThreshold {
+		b.openUntil = now.Add(policy.BreakerCooldown)
+		return true
+	}
+	return false
+}
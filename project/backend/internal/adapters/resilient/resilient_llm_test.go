@@ -0,0 +1,209 @@
+package resilient
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+)
+
+// scriptedLLM returns errs[i] on the i-th call, then succeeds
+type scriptedLLM struct {
+	errs  []error
+	calls int
+}
+
+func (s *scriptedLLM) next() error {
+	s.calls++
+	if s.calls <= len(s.errs) {
+		return s.errs[s.calls-1]
+	}
+	return nil
+}
+
+func (s *scriptedLLM) Chat(ctx context.Context, message string) (string, error) {
+	if err := s.next(); err != nil {
+		return "", err
+	}
+	return "ok", nil
+}
+
+func (s *scriptedLLM) ChatWithTools(ctx context.Context, systemPrompt string, messages []domain.LLMMessage, tools []domain.ToolDefinition) (*domain.LLMResponse, error) {
+	if err := s.next(); err != nil {
+		return nil, err
+	}
+	return &domain.LLMResponse{Text: "ok"}, nil
+}
+
+func (s *scriptedLLM) ChatWithToolsCached(ctx context.Context, systemPrompt string, messages []domain.LLMMessage, tools []domain.ToolDefinition, cacheConfig *ports.CacheConfig) (*domain.LLMResponse, error) {
+	return s.ChatWithTools(ctx, systemPrompt, messages, tools)
+}
+
+func (s *scriptedLLM) ChatWithUsage(ctx context.Context, systemPrompt, userMessage string) (*ports.ChatResponse, error) {
+	if err := s.next(); err != nil {
+		return nil, err
+	}
+	return &ports.ChatResponse{Text: "ok"}, nil
+}
+
+// newTestLLM builds a decorator with no real sleeping; waits are recorded
+func newTestLLM(inner ports.LLMPort, policy Policy) (*LLM, *[]time.Duration) {
+	l := NewLLM(inner, policy, nil, nil)
+	var waits []time.Duration
+	l.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	l.rand = func() float64 { return 0.5 }
+	return l, &waits
+}
+
+func TestLLM_RetriesTransientErrors(t *testing.T) {
+	inner := &scriptedLLM{errs: []error{
+		domain.NewLLMProviderError(529, "", "overloaded"),
+		domain.NewLLMProviderError(503, "", "unavailable"),
+	}}
+	l, waits := newTestLLM(inner, Policy{MaxAttempts: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second})
+
+	sc := domain.NewSpanCollector()
+	ctx := domain.WithStage(domain.WithSpanCollector(context.Background(), sc), "agent1")
+
+	resp, err := l.ChatWithTools(ctx, "", nil, nil)
+	if err != nil {
+		t.Fatalf("want success after retries, got %v", err)
+	}
+	if resp.Text != "ok" || inner.calls != 3 {
+		t.Errorf("want 3 calls and ok, got %d calls", inner.calls)
+	}
+	// Full jitter with rand=0.5: 50ms, then 100ms
+	if len(*waits) != 2 || (*waits)[0] != 50*time.Millisecond || (*waits)[1] != 100*time.Millisecond {
+		t.Errorf("unexpected backoff waits %v", *waits)
+	}
+
+	retrySpans := 0
+	for _, s := range sc.Spans() {
+		if s.Name == "agent1.llm.retry" {
+			retrySpans++
+		}
+	}
+	if retrySpans != 2 {
+		t.Errorf("want 2 retry spans, got %d", retrySpans)
+	}
+}
+
+func TestLLM_HonorsRetryAfter(t *testing.T) {
+	inner := &scriptedLLM{errs: []error{domain.NewLLMProviderError(429, "2", "rate limited")}}
+	l, waits := newTestLLM(inner, Policy{MaxAttempts: 2, BaseDelay: 100 * time.Millisecond, MaxDelay: 5 * time.Second})
+
+	if _, err := l.Chat(context.Background(), "hi"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(*waits) != 1 || (*waits)[0] != 2*time.Second {
+		t.Errorf("want single 2s wait from retry-after, got %v", *waits)
+	}
+}
+
+func TestLLM_RetryAfterBeyondMaxDelayGivesUp(t *testing.T) {
+	inner := &scriptedLLM{errs: []error{domain.NewLLMProviderError(429, "60", "rate limited")}}
+	l, waits := newTestLLM(inner, Policy{MaxAttempts: 3, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second})
+
+	if _, err := l.Chat(context.Background(), "hi"); err == nil {
+		t.Fatal("want error when retry-after exceeds MaxDelay")
+	}
+	if inner.calls != 1 || len(*waits) != 0 {
+		t.Errorf("want no retry, got %d calls, waits %v", inner.calls, *waits)
+	}
+}
+
+func TestLLM_DoesNotRetryClientErrors(t *testing.T) {
+	inner := &scriptedLLM{errs: []error{domain.NewLLMProviderError(400, "", "bad request")}}
+	l, _ := newTestLLM(inner, Policy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	if _, err := l.Chat(context.Background(), "hi"); err == nil {
+		t.Fatal("want error")
+	}
+	if inner.calls != 1 {
+		t.Errorf("400 must not be retried, got %d calls", inner.calls)
+	}
+}
+
+func TestLLM_CircuitBreakerOpensAndRecovers(t *testing.T) {
+	down := domain.NewLLMProviderError(503, "", "down")
+	inner := &scriptedLLM{errs: []error{down, down}}
+	policy := Policy{MaxAttempts: 1, BreakerThreshold: 2, BreakerCooldown: 10 * time.Second}
+	l, _ := newTestLLM(inner, policy)
+
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+	ctx := domain.WithStage(context.Background(), "agent2")
+
+	for i := 0; i < 2; i++ {
+		if _, err := l.Chat(ctx, "hi"); err == nil {
+			t.Fatalf("call %d: want provider error", i)
+		}
+	}
+
+	// Open: fails fast without calling the provider
+	_, err := l.Chat(ctx, "hi")
+	if !errors.Is(err, domain.ErrLLMUnavailable) {
+		t.Fatalf("want ErrLLMUnavailable while open, got %v", err)
+	}
+	if inner.calls != 2 {
+		t.Errorf("open breaker must not call provider, got %d calls", inner.calls)
+	}
+
+	// Other stages have their own breaker
+	if _, err := l.Chat(domain.WithStage(context.Background(), "agent1"), "hi"); err != nil {
+		t.Errorf("agent1 should not be affected by agent2 breaker: %v", err)
+	}
+
+	// After cooldown a probe goes through and closes the breaker
+	now = now.Add(11 * time.Second)
+	if _, err := l.Chat(ctx, "hi"); err != nil {
+		t.Fatalf("probe should succeed: %v", err)
+	}
+	if _, err := l.Chat(ctx, "hi"); err != nil {
+		t.Errorf("breaker should be closed after successful probe: %v", err)
+	}
+}
+
+func TestLLM_PerStagePolicy(t *testing.T) {
+	down := domain.NewLLMProviderError(500, "", "boom")
+	inner := &scriptedLLM{errs: []error{down, down, down, down}}
+	l := NewLLM(inner, Policy{MaxAttempts: 1}, map[string]Policy{
+		"agent1": {MaxAttempts: 4},
+	}, nil)
+	l.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+
+	if _, err := l.Chat(domain.WithStage(context.Background(), "agent1"), "hi"); err == nil {
+		t.Fatal("want error after 4 failed attempts")
+	}
+	if inner.calls != 4 {
+		t.Errorf("agent1 policy allows 4 attempts, got %d", inner.calls)
+	}
+}
+
+func TestLLM_AttemptTimeout(t *testing.T) {
+	slow := &blockingLLM{}
+	l, _ := newTestLLM(slow, Policy{MaxAttempts: 2, AttemptTimeout: 10 * time.Millisecond})
+
+	_, err := l.Chat(context.Background(), "hi")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want deadline exceeded, got %v", err)
+	}
+	if slow.calls != 2 {
+		t.Errorf("timed-out attempt should be retried, got %d calls", slow.calls)
+	}
+}
+
+// blockingLLM blocks until ctx is done
+type blockingLLM struct{ scriptedLLM }
+
+func (b *blockingLLM) Chat(ctx context.Context, message string) (string, error) {
+	b.calls++
+	<-ctx.Done()
+	return "", ctx.Err()
+}
@@ -1,33 +1,116 @@
 package config
 
-import "os"
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
 
 // Config holds application configuration
 type Config struct {
-	Port            string
-	Environment     string
-	AnthropicAPIKey string
-	LLMModel        string
-	LogLevel        string
-	DatabaseURL     string
-	TenantSlug      string
-	OpenAIAPIKey    string
-	EmbeddingModel  string
+	Port              string
+	Environment       string
+	AnthropicAPIKey   string
+	LLMModel          string
+	LLMFallbackModels string // comma-separated models tried after LLMModel when it is overloaded
+	LLMRoutes         string // per stage/tenant/complexity model chains — see adapters/llmrouter
+	LLMProvider       string // "anthropic" (default) or "openai" (any OpenAI-compatible endpoint)
+	LLMBaseURL        string // OpenAI-compatible API root or full chat completions URL
+	LLMAPIKey         string // API key for the OpenAI-compatible endpoint (empty = no auth)
+	LLMCassetteMode   string // "" (off), "record" or "replay" — see adapters/cassette
+	LLMCassettePath   string // cassette file for LLM_CASSETTE_MODE
+	LogLevel          string
+	DatabaseURL       string
+	TenantSlug        string
+	OpenAIAPIKey      string
+	EmbeddingModel    string
+	EmbeddingProvider string // "openai" (default) or "local" (hashed n-grams, no network)
+
+	// LLM resilience: defaults (LLM_*) with per-stage overrides (AGENT1_LLM_*, AGENT2_LLM_*)
+	LLMRetry       LLMRetryConfig
+	Agent1LLMRetry LLMRetryConfig
+	Agent2LLMRetry LLMRetryConfig
+
+	// Response cache (repeated tenant queries served from stored pipeline results)
+	ResponseCacheTTLSeconds    int     // entry lifetime (0 = cache disabled)
+	ResponseCacheMinSimilarity float64 // cosine similarity for a semantic hit
+
+	// Agent 1 loop caps per turn
+	Agent1MaxSteps  int // LLM calls (tool_use → tool_result iterations)
+	Agent1MaxTokens int // input+output tokens across iterations (0 = unlimited)
+
+	// Rerank strategy of catalog_search for tenants without settings.rerank: "none", "features" or "llm"
+	RerankStrategy string
+
+	// Stock policy of catalog_search for tenants without settings.stock: "hide", "demote" or "badge"
+	StockPolicy string
+}
+
+// LLMRetryConfig configures retries, per-attempt timeout and circuit breaker for LLM calls
+type LLMRetryConfig struct {
+	MaxAttempts       int // total attempts including the first
+	BaseDelayMs       int // exponential backoff base (full jitter)
+	MaxDelayMs        int // backoff cap; longer retry-after is not waited for
+	AttemptTimeoutMs  int // deadline per attempt
+	BreakerThreshold  int // consecutive failures that open the circuit (0 = disabled)
+	BreakerCooldownMs int // how long the circuit stays open
 }
 
 // Load loads configuration from environment variables
 func Load() *Config {
+	llmRetry := loadLLMRetry("LLM_", LLMRetryConfig{
+		MaxAttempts:       3,
+		BaseDelayMs:       250,
+		MaxDelayMs:        4000,
+		AttemptTimeoutMs:  30000,
+		BreakerThreshold:  5,
+		BreakerCooldownMs: 30000,
+	})
+
+	llmProvider := getEnv("LLM_PROVIDER", "anthropic")
+	// The Claude default only makes sense for Anthropic; OpenAI-compatible endpoints need LLM_MODEL
+	defaultModel := "claude-haiku-4-5-20251001"
+	if llmProvider == "openai" {
+		defaultModel = ""
+	}
+
 	return &Config{
-		Port:            getEnv("PORT", "8080"),
-		Environment:     getEnv("ENVIRONMENT", "development"),
-		AnthropicAPIKey: getEnv("ANTHROPIC_API_KEY", ""),
-		LLMModel:        getEnv("LLM_MODEL", "claude-haiku-4-5-20251001"),
-		LogLevel:        getEnv("LOG_LEVEL", "info"),
-		DatabaseURL:     getEnv("DATABASE_URL", ""),
-		TenantSlug:      getEnv("TENANT_SLUG", "nike"),
-		OpenAIAPIKey:    getEnv("OPENAI_API_KEY", ""),
-		EmbeddingModel:  getEnv("EMBEDDING_MODEL", "text-embedding-3-small"),
+		LLMRetry:                   llmRetry,
+		Agent1LLMRetry:             loadLLMRetry("AGENT1_LLM_", llmRetry),
+		Agent2LLMRetry:             loadLLMRetry("AGENT2_LLM_", llmRetry),
+		ResponseCacheTTLSeconds:    getEnvInt("RESPONSE_CACHE_TTL_SECONDS", 3600),
+		ResponseCacheMinSimilarity: getEnvFloat("RESPONSE_CACHE_MIN_SIMILARITY", 0.92),
+		Agent1MaxSteps:             getEnvInt("AGENT1_MAX_STEPS", 4),
+		Agent1MaxTokens:            getEnvInt("AGENT1_MAX_TOKENS", 60000),
+		Port:                       getEnv("PORT", "8080"),
+		Environment:                getEnv("ENVIRONMENT", "development"),
+		AnthropicAPIKey:            getEnv("ANTHROPIC_API_KEY", ""),
+		LLMModel:                   getEnv("LLM_MODEL", defaultModel),
+		LLMFallbackModels:          getEnv("LLM_FALLBACK_MODELS", ""),
+		LLMRoutes:                  getEnv("LLM_ROUTES", ""),
+		LLMProvider:                llmProvider,
+		LLMBaseURL:                 getEnv("LLM_BASE_URL", ""),
+		LLMAPIKey:                  getEnv("LLM_API_KEY", ""),
+		LLMCassetteMode:            getEnv("LLM_CASSETTE_MODE", ""),
+		LLMCassettePath:            getEnv("LLM_CASSETTE_PATH", "cassettes/session.json"),
+		LogLevel:                   getEnv("LOG_LEVEL", "info"),
+		DatabaseURL:                getEnv("DATABASE_URL", ""),
+		TenantSlug:                 getEnv("TENANT_SLUG", "nike"),
+		OpenAIAPIKey:               getEnv("OPENAI_API_KEY", ""),
+		EmbeddingModel:             getEnv("EMBEDDING_MODEL", "text-embedding-3-small"),
+		EmbeddingProvider:          getEnv("EMBEDDING_PROVIDER", "openai"),
+		RerankStrategy:             getEnv("RERANK_STRATEGY", "none"),
+		StockPolicy:                getEnv("STOCK_POLICY", ""),
+	}
+}
+
+// Validate reports configuration the server cannot start with
+func (c *Config) Validate() error {
+	if c.LLMModel == "" {
+		return errors.New("LLM_MODEL is required for LLM_PROVIDER=openai")
 	}
+	return nil
 }
 
 // HasDatabase returns true if database URL is configured
@@ -35,9 +118,31 @@ func (c *Config) HasDatabase() bool {
 	return c.DatabaseURL != ""
 }
 
-// HasEmbeddings returns true if OpenAI API key is configured for embeddings
+// UsesOpenAICompatibleLLM returns true if the LLM provider is an OpenAI-compatible endpoint
+func (c *Config) UsesOpenAICompatibleLLM() bool {
+	return c.LLMProvider == "openai"
+}
+
+// HasEmbeddings returns true if an embedding provider is available:
+// the local embedder, or OpenAI with an API key
 func (c *Config) HasEmbeddings() bool {
-	return c.OpenAIAPIKey != ""
+	return c.UsesLocalEmbeddings() || c.OpenAIAPIKey != ""
+}
+
+// UsesLocalEmbeddings returns true if EMBEDDING_PROVIDER=local (no network, no API key)
+func (c *Config) UsesLocalEmbeddings() bool {
+	return c.EmbeddingProvider == "local"
+}
+
+// LLMDefaultChain returns LLM_MODEL followed by LLM_FALLBACK_MODELS
+func (c *Config) LLMDefaultChain() []string {
+	chain := []string{c.LLMModel}
+	for _, m := range strings.Split(c.LLMFallbackModels, ",") {
+		if m = strings.TrimSpace(m); m != "" && m != c.LLMModel {
+			chain = append(chain, m)
+		}
+	}
+	return chain
 }
 
 func getEnv(key, defaultValue string) string {
@@ -46,3 +151,34 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// loadLLMRetry reads {prefix}MAX_ATTEMPTS, {prefix}RETRY_BASE_DELAY_MS, {prefix}RETRY_MAX_DELAY_MS,
+// {prefix}ATTEMPT_TIMEOUT_MS, {prefix}BREAKER_THRESHOLD, {prefix}BREAKER_COOLDOWN_MS over defaults
+func loadLLMRetry(prefix string, defaults LLMRetryConfig) LLMRetryConfig {
+	return LLMRetryConfig{
+		MaxAttempts:       getEnvInt(prefix+"MAX_ATTEMPTS", defaults.MaxAttempts),
+		BaseDelayMs:       getEnvInt(prefix+"RETRY_BASE_DELAY_MS", defaults.BaseDelayMs),
+		MaxDelayMs:        getEnvInt(prefix+"RETRY_MAX_DELAY_MS", defaults.MaxDelayMs),
+		AttemptTimeoutMs:  getEnvInt(prefix+"ATTEMPT_TIMEOUT_MS", defaults.AttemptTimeoutMs),
+		BreakerThreshold:  getEnvInt(prefix+"BREAKER_THRESHOLD", defaults.BreakerThreshold),
+		BreakerCooldownMs: getEnvInt(prefix+"BREAKER_COOLDOWN_MS", defaults.BreakerCooldownMs),
+	}
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return defaultValue
+}
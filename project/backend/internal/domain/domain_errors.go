@@ -1,5 +1,12 @@
 package domain
 
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
 // Error represents a domain error
 type Error struct {
 	Code    string
@@ -19,4 +26,45 @@ var (
 	ErrRateLimitExceeded = &Error{Code: "RATE_LIMIT", Message: "Rate limit exceeded"}
 	ErrTenantNotFound    = &Error{Code: "TENANT_NOT_FOUND", Message: "tenant not found"}
 	ErrCategoryNotFound  = &Error{Code: "CATEGORY_NOT_FOUND", Message: "category not found"}
+	ErrInvalidEntityType = &Error{Code: "INVALID_ENTITY_TYPE", Message: "unsupported entity type"}
+	ErrSimilarFailed     = &Error{Code: "SIMILAR_FAILED", Message: "similar products unavailable"}
 )
+
+// LLMProviderError is a non-2xx response from an LLM provider.
+// Adapters return it so callers can decide whether to retry.
+type LLMProviderError struct {
+	StatusCode int
+	RetryAfter time.Duration // from retry-after header, 0 if absent
+	Message    string
+}
+
+func (e *LLMProviderError) Error() string {
+	return fmt.Sprintf("llm provider error (status %d): %s", e.StatusCode, e.Message)
+}
+
+// Retryable reports whether the request may succeed if repeated:
+// 408 timeout, 409 conflict, 429 rate limit, 5xx and 529 overloaded.
+func (e *LLMProviderError) Retryable() bool {
+	switch e.StatusCode {
+	case http.StatusRequestTimeout, http.StatusConflict, http.StatusTooManyRequests:
+		return true
+	}
+	return e.StatusCode >= 500
+}
+
+// NewLLMProviderError builds an LLMProviderError, parsing a retry-after header
+// given either as delay-seconds or an HTTP date.
+func NewLLMProviderError(statusCode int, retryAfter, message string) *LLMProviderError {
+	e := &LLMProviderError{StatusCode: statusCode, Message: message}
+	if retryAfter == "" {
+		return e
+	}
+	if secs, err := strconv.ParseFloat(retryAfter, 64); err == nil && secs > 0 {
+		e.RetryAfter = time.Duration(secs * float64(time.Second))
+	} else if t, err := http.ParseTime(retryAfter); err == nil {
+		if d := time.Until(t); d > 0 {
+			e.RetryAfter = d
+		}
+	}
+	return e
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewLLMProviderError_RetryAfterSeconds(t *testing.T) {
+	err := NewLLMProviderError(429, "3", "rate limited")
+	if err.RetryAfter != 3*time.Second {
+		t.Errorf("want 3s, got %s", err.RetryAfter)
+	}
+	if !err.Retryable() {
+		t.Error("429 should be retryable")
+	}
+}
+
+func TestNewLLMProviderError_RetryAfterDate(t *testing.T) {
+	at := time.Now().Add(10 * time.Second).UTC().Format(http.TimeFormat)
+	err := NewLLMProviderError(503, at, "unavailable")
+	if err.RetryAfter <= 0 || err.RetryAfter > 10*time.Second {
+		t.Errorf("want 0 < retryAfter <= 10s, got %s", err.RetryAfter)
+	}
+}
+
+func TestLLMProviderError_Retryable(t *testing.T) {
+	cases := map[int]bool{400: false, 401: false, 404: false, 408: true, 429: true, 500: true, 502: true, 529: true}
+	for status, want := range cases {
+		if got := NewLLMProviderError(status, "", "").Retryable(); got != want {
+			t.Errorf("status %d: want retryable=%v, got %v", status, want, got)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package domain
+
+import "fmt"
+
+// Facet fields computed for catalog search results
+const (
+	FacetBrand       = "brand"
+	FacetCategory    = "category"
+	FacetPrice       = "price"
+	FacetProductForm = "product_form"
+	FacetSkinType    = "skin_type"
+	FacetConcern     = "concern"
+)
+
+// FacetPriceEdges are the price bucket boundaries in rubles:
+// [0,1000), [1000,3000), [3000,5000), [5000,10000), [10000,∞)
+var FacetPriceEdges = []int{1000, 3000, 5000, 10000}
+
+// FacetMaxValues caps the values kept per facet (highest counts first)
+const FacetMaxValues = 10
+
+// Facet is the distribution of one attribute over a search result
+type Facet struct {
+	Field  string       `json:"field"`
+	Values []FacetValue `json:"values"`
+}
+
+// FacetValue is one attribute value with the number of matching products.
+// Price buckets also carry their range in rubles (Max=0 means open-ended).
+type FacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+	Min   int    `json:"min,omitempty"`
+	Max   int    `json:"max,omitempty"`
+}
+
+// FacetPriceBucket returns the price facet value for bucket i
+// (0 = below the first edge, len(FacetPriceEdges) = above the last)
+func FacetPriceBucket(i int) FacetValue {
+	var v FacetValue
+	if i > 0 {
+		v.Min = FacetPriceEdges[i-1]
+	}
+	if i < len(FacetPriceEdges) {
+		v.Max = FacetPriceEdges[i]
+	}
+	switch {
+	case v.Min == 0:
+		v.Value = fmt.Sprintf("<%d", v.Max)
+	case v.Max == 0:
+		v.Value = fmt.Sprintf("%d+", v.Min)
+	default:
+		v.Value = fmt.Sprintf("%d-%d", v.Min, v.Max)
+	}
+	return v
+}
@@ -0,0 +1,127 @@
+package domain
+
+import (
+	"sort"
+	"strings"
+)
+
+// Interaction severities (catalog.ingredient_interactions.severity)
+const (
+	InteractionAvoid   = "avoid"   // do not use in the same routine
+	InteractionCaution = "caution" // fine when spaced out (AM/PM, alternate days)
+)
+
+// IngredientInteraction is a tenant-agnostic rule from catalog.ingredient_interactions:
+// a product with an ingredient of GroupA used together with a product with one of GroupB.
+// Group entries match INCI names and key_ingredients as case-insensitive substrings
+// ("retin" → Retinol, Retinyl Palmitate; "-" and "_" count as spaces).
+type IngredientInteraction struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"` // "retinoids + AHA/BHA"
+	GroupA   []string `json:"groupA"`
+	GroupB   []string `json:"groupB"`
+	Severity string   `json:"severity"`
+	Message  string   `json:"message"`          // why the pair is a problem
+	Advice   string   `json:"advice,omitempty"` // how to use both anyway
+}
+
+// IngredientSet is a product prepared for the compatibility check
+type IngredientSet struct {
+	ProductID   string
+	ProductName string
+	Ingredients []string // INCI names from catalog.product_ingredients + key_ingredients
+	Times       []string // routine times the product is used in; empty = unknown
+}
+
+// InteractionWarning is a rule triggered by a pair of products
+type InteractionWarning struct {
+	Rule        string `json:"rule"`
+	Severity    string `json:"severity"`
+	Message     string `json:"message"`
+	Advice      string `json:"advice,omitempty"`
+	ProductA    string `json:"productA"` // product names
+	ProductB    string `json:"productB"`
+	IngredientA string `json:"ingredientA"`
+	IngredientB string `json:"ingredientB"`
+}
+
+// CompatibilityReport is the result of catalog_compatibility (rendered as an info card)
+type CompatibilityReport struct {
+	Checked  int                  `json:"checked"` // products with known ingredients
+	Warnings []InteractionWarning `json:"warnings,omitempty"`
+	TurnID   string               `json:"turnId,omitempty"` // turn that ran the check: the card is shown on that turn only
+}
+
+// CheckInteractions applies the rules to every pair of products, "avoid" warnings first.
+// A product is not checked against itself (its formula is balanced by the maker), and a
+// pair used only at different routine times (retinol PM, vitamin C AM) does not interact.
+// Each rule is reported once per pair.
+func CheckInteractions(sets []IngredientSet, rules []IngredientInteraction) []InteractionWarning {
+	var warnings []InteractionWarning
+	for i := 0; i < len(sets); i++ {
+		for j := i + 1; j < len(sets); j++ {
+			a, b := sets[i], sets[j]
+			if !sharesTime(a.Times, b.Times) {
+				continue
+			}
+			for _, r := range rules {
+				ingA, ingB := matchIngredientGroup(a.Ingredients, r.GroupA), matchIngredientGroup(b.Ingredients, r.GroupB)
+				first, second := a, b
+				if ingA == "" || ingB == "" {
+					ingA, ingB = matchIngredientGroup(b.Ingredients, r.GroupA), matchIngredientGroup(a.Ingredients, r.GroupB)
+					first, second = b, a
+				}
+				if ingA == "" || ingB == "" {
+					continue
+				}
+				warnings = append(warnings, InteractionWarning{
+					Rule:        r.Name,
+					Severity:    r.Severity,
+					Message:     r.Message,
+					Advice:      r.Advice,
+					ProductA:    first.ProductName,
+					ProductB:    second.ProductName,
+					IngredientA: ingA,
+					IngredientB: ingB,
+				})
+			}
+		}
+	}
+	sort.SliceStable(warnings, func(i, j int) bool {
+		return warnings[i].Severity == InteractionAvoid && warnings[j].Severity != InteractionAvoid
+	})
+	return warnings
+}
+
+// matchIngredientGroup returns the first ingredient matching an entry of the group, "" for none
+func matchIngredientGroup(ingredients []string, group []string) string {
+	for _, ing := range ingredients {
+		norm := normalizeIngredient(ing)
+		for _, g := range group {
+			if g = normalizeIngredient(g); g != "" && strings.Contains(norm, g) {
+				return ing
+			}
+		}
+	}
+	return ""
+}
+
+func normalizeIngredient(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.NewReplacer("-", " ", "_", " ").Replace(s)
+}
+
+// sharesTime reports whether two products can meet on the skin: unknown times always can
+func sharesTime(a, b []string) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return true
+	}
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
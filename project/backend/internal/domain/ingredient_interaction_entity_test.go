@@ -0,0 +1,44 @@
+package domain
+
+import "testing"
+
+var testInteractionRules = []IngredientInteraction{
+	{Name: "vitamin C + niacinamide", GroupA: []string{"ascorbic acid", "vitamin c"}, GroupB: []string{"niacinamide"}, Severity: InteractionCaution},
+	{Name: "retinoids + AHA/BHA", GroupA: []string{"retinol", "retinyl"}, GroupB: []string{"salicylic acid", "aha bha"}, Severity: InteractionAvoid},
+}
+
+func TestCheckInteractions_PairsBothDirections(t *testing.T) {
+	sets := []IngredientSet{
+		{ProductID: "p1", ProductName: "Toner", Ingredients: []string{"Aqua", "Niacinamide"}},
+		{ProductID: "p2", ProductName: "Serum C", Ingredients: []string{"Aqua", "Ascorbic Acid"}},
+		{ProductID: "p3", ProductName: "Night Cream", Ingredients: []string{"retinol"}}, // key_ingredients slug
+		{ProductID: "p4", ProductName: "Peel", Ingredients: []string{"aha-bha"}},
+	}
+
+	warnings := CheckInteractions(sets, testInteractionRules)
+	if len(warnings) != 2 {
+		t.Fatalf("want 2 warnings, got %+v", warnings)
+	}
+	if w := warnings[0]; w.Severity != InteractionAvoid || w.ProductA != "Night Cream" || w.IngredientB != "aha-bha" {
+		t.Errorf("avoid warning should come first with the retinoid product as A, got %+v", w)
+	}
+	if w := warnings[1]; w.ProductA != "Serum C" || w.IngredientA != "Ascorbic Acid" || w.ProductB != "Toner" {
+		t.Errorf("reversed pair should be reported in rule order, got %+v", w)
+	}
+}
+
+func TestCheckInteractions_SameProductAndSeparateTimes(t *testing.T) {
+	sets := []IngredientSet{
+		{ProductID: "p1", ProductName: "All-in-one", Ingredients: []string{"Retinol", "Salicylic Acid"}},
+		{ProductID: "p2", ProductName: "AM Serum", Ingredients: []string{"Vitamin C"}, Times: []string{"am"}},
+		{ProductID: "p3", ProductName: "PM Toner", Ingredients: []string{"Niacinamide"}, Times: []string{"pm"}},
+	}
+	if warnings := CheckInteractions(sets, testInteractionRules); len(warnings) != 0 {
+		t.Errorf("want no warnings, got %+v", warnings)
+	}
+
+	sets[2].Times = []string{"am", "pm"}
+	if warnings := CheckInteractions(sets, testInteractionRules); len(warnings) != 1 {
+		t.Errorf("products meeting in the morning should interact, got %+v", warnings)
+	}
+}
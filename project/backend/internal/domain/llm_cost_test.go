@@ -145,3 +145,36 @@ func TestCalculateCost_Sonnet35Legacy(t *testing.T) {
 		t.Errorf("Sonnet 3.5 legacy: want 18.0, got %f", cost)
 	}
 }
+
+func TestCalculateCost_ModelFamilyAlias(t *testing.T) {
+	u := &LLMUsage{
+		Model:        "claude-sonnet-4-5",
+		InputTokens:  1_000_000,
+		OutputTokens: 1_000_000,
+	}
+	// Undated alias priced as Sonnet 4.5, not the Haiku fallback
+	if cost := u.CalculateCost(); !almostEqual(cost, 18.0, 0.001) {
+		t.Errorf("Sonnet alias: want 18.0, got %f", cost)
+	}
+	if _, ok := PricingForModel("gpt-4o-mini"); ok {
+		t.Error("unknown model must not have pricing")
+	}
+}
+
+func TestPricingForModel_FamilyPicksLatestSnapshot(t *testing.T) {
+	saved := LLMPricing
+	t.Cleanup(func() { LLMPricing = saved })
+	LLMPricing = map[string]ModelPricing{
+		"claude-test-4-20250101": {1.0, 1.0},
+		"claude-test-4-20250601": {2.0, 2.0},
+		"claude-test-4-20250301": {3.0, 3.0},
+	}
+
+	// Same answer on every lookup, whatever the map iteration order
+	for i := 0; i < 20; i++ {
+		p, ok := PricingForModel("claude-test-4")
+		if !ok || p.InputPerMillion != 2.0 {
+			t.Fatalf("want the latest snapshot (20250601) pricing, got %+v ok=%v", p, ok)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"context"
+	"regexp"
+	"strings"
+)
+
+// QueryComplexity is a routing hint: complex queries may go to a stronger model
+type QueryComplexity string
+
+const (
+	QueryComplexitySimple  QueryComplexity = "simple"
+	QueryComplexityComplex QueryComplexity = "complex"
+)
+
+// LLMRouteHints carries request attributes the LLM router matches rules against.
+// Stage comes from StageFromContext.
+type LLMRouteHints struct {
+	TenantSlug string
+	Complexity QueryComplexity
+}
+
+type ctxKeyRouteHints struct{}
+
+// WithRouteHints attaches routing hints to the context
+func WithRouteHints(ctx context.Context, hints LLMRouteHints) context.Context {
+	return context.WithValue(ctx, ctxKeyRouteHints{}, hints)
+}
+
+// RouteHintsFromContext retrieves routing hints, or zero value if not set
+func RouteHintsFromContext(ctx context.Context) LLMRouteHints {
+	hints, _ := ctx.Value(ctxKeyRouteHints{}).(LLMRouteHints)
+	return hints
+}
+
+// WithQueryComplexity sets the complexity hint, keeping other hints
+func WithQueryComplexity(ctx context.Context, c QueryComplexity) context.Context {
+	hints := RouteHintsFromContext(ctx)
+	hints.Complexity = c
+	return WithRouteHints(ctx, hints)
+}
+
+// complexTriggers: comparisons, alternatives, references to earlier turns
+var complexTriggers = regexp.MustCompile(`(?i)(сравни|чем отлича|разниц|вместо|или |как в прошл|как раньше|как было|до этого|запроса назад|в начале|compare|versus|\bvs\b|instead|earlier|previous)`)
+
+// complexWordCount: longer queries usually combine several constraints
+const complexWordCount = 12
+
+// ClassifyQueryComplexity is a cheap heuristic for model routing. It never calls an LLM.
+func ClassifyQueryComplexity(query string) QueryComplexity {
+	if complexTriggers.MatchString(query) || len(strings.Fields(query)) >= complexWordCount {
+		return QueryComplexityComplex
+	}
+	return QueryComplexitySimple
+}
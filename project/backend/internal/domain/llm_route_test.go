@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"testing"
+)
+
+func TestClassifyQueryComplexity(t *testing.T) {
+	cases := []struct {
+		query string
+		want  QueryComplexity
+	}{
+		{"покажи кроссовки Nike", QueryComplexitySimple},
+		{"дешевле 5000", QueryComplexitySimple},
+		{"покажи дешевле, чем было два запроса назад", QueryComplexityComplex},
+		{"сравни COSRX и Some By Mi", QueryComplexityComplex},
+		{"nike or adidas, compare cushioning", QueryComplexityComplex},
+		{"нужен крем для сухой кожи зимой без отдушек и спирта чтобы не щипало после умывания", QueryComplexityComplex},
+	}
+	for _, c := range cases {
+		if got := ClassifyQueryComplexity(c.query); got != c.want {
+			t.Errorf("%q: want %s, got %s", c.query, c.want, got)
+		}
+	}
+}
+
+func TestRouteHints_Context(t *testing.T) {
+	ctx := WithRouteHints(context.Background(), LLMRouteHints{TenantSlug: "nike"})
+	ctx = WithQueryComplexity(ctx, QueryComplexityComplex)
+
+	hints := RouteHintsFromContext(ctx)
+	if hints.TenantSlug != "nike" || hints.Complexity != QueryComplexityComplex {
+		t.Errorf("hints not preserved: %+v", hints)
+	}
+	if RouteHintsFromContext(context.Background()) != (LLMRouteHints{}) {
+		t.Error("empty context must return zero hints")
+	}
+}
@@ -0,0 +1,75 @@
+package domain
+
+import "context"
+
+// PipelineEventType identifies a progress event emitted while the pipeline runs
+type PipelineEventType string
+
+const (
+	PipelineEventStarted    PipelineEventType = "started"     // pipeline accepted, session resolved
+	PipelineEventToolCall   PipelineEventType = "tool_call"   // Agent 1 chose a tool (before execution)
+	PipelineEventToolResult PipelineEventType = "tool_result" // tool finished, product/service counts known
+	PipelineEventSkeleton   PipelineEventType = "skeleton"    // default-layout template + entities, before Agent 2
+	PipelineEventFormation  PipelineEventType = "formation"   // final formation + adjacent templates
+	PipelineEventSpans      PipelineEventType = "spans"       // waterfall spans for this run
+	PipelineEventDone       PipelineEventType = "done"        // pipeline finished, timings
+	PipelineEventError      PipelineEventType = "error"       // pipeline failed
+)
+
+// PipelineEvent is one typed progress event. Data is JSON-serializable.
+type PipelineEvent struct {
+	Type PipelineEventType `json:"type"`
+	Data interface{}       `json:"data,omitempty"`
+}
+
+// PipelineEventSink receives pipeline events. Must be safe to call from any goroutine.
+type PipelineEventSink func(event PipelineEvent)
+
+type ctxKeyEventSink struct{}
+
+// WithEventSink attaches a PipelineEventSink to the context
+func WithEventSink(ctx context.Context, sink PipelineEventSink) context.Context {
+	return context.WithValue(ctx, ctxKeyEventSink{}, sink)
+}
+
+// EventSinkFromContext retrieves the PipelineEventSink from context, or nil
+func EventSinkFromContext(ctx context.Context) PipelineEventSink {
+	sink, _ := ctx.Value(ctxKeyEventSink{}).(PipelineEventSink)
+	return sink
+}
+
+// EmitEvent sends an event to the sink in context. No-op when no sink is attached.
+func EmitEvent(ctx context.Context, eventType PipelineEventType, data interface{}) {
+	if sink := EventSinkFromContext(ctx); sink != nil {
+		sink(PipelineEvent{Type: eventType, Data: data})
+	}
+}
+
+// ToolCallEventData is the payload of PipelineEventToolCall
+type ToolCallEventData struct {
+	Tool  string                 `json:"tool"`
+	Input map[string]interface{} `json:"input,omitempty"`
+}
+
+// ToolResultEventData is the payload of PipelineEventToolResult
+type ToolResultEventData struct {
+	Tool         string `json:"tool"`
+	Result       string `json:"result"`
+	ProductCount int    `json:"productCount"`
+	ServiceCount int    `json:"serviceCount"`
+}
+
+// SkeletonEventData is the payload of PipelineEventSkeleton.
+// Formation is a template formation (atoms with FieldName, Value=nil) that the
+// frontend fills from Entities until Agent 2 delivers the final formation.
+type SkeletonEventData struct {
+	Formation *FormationWithData `json:"formation"`
+	Entities  *StateData         `json:"entities,omitempty"`
+}
+
+// FormationEventData is the payload of PipelineEventFormation
+type FormationEventData struct {
+	Formation         *FormationWithData            `json:"formation,omitempty"`
+	AdjacentTemplates map[string]*FormationWithData `json:"adjacentTemplates,omitempty"`
+	Entities          *StateData                    `json:"entities,omitempty"`
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEmitEvent_NoSink(t *testing.T) {
+	// Must not panic when no sink is attached
+	EmitEvent(context.Background(), PipelineEventToolCall, ToolCallEventData{Tool: "catalog_search"})
+}
+
+func TestEmitEvent_DeliversToSink(t *testing.T) {
+	var got []PipelineEvent
+	ctx := WithEventSink(context.Background(), func(e PipelineEvent) {
+		got = append(got, e)
+	})
+
+	EmitEvent(ctx, PipelineEventToolCall, ToolCallEventData{Tool: "catalog_search"})
+	EmitEvent(ctx, PipelineEventToolResult, ToolResultEventData{Tool: "catalog_search", ProductCount: 12})
+
+	if len(got) != 2 {
+		t.Fatalf("want 2 events, got %d", len(got))
+	}
+	if got[0].Type != PipelineEventToolCall {
+		t.Errorf("want tool_call, got %s", got[0].Type)
+	}
+	res, ok := got[1].Data.(ToolResultEventData)
+	if !ok {
+		t.Fatalf("want ToolResultEventData, got %T", got[1].Data)
+	}
+	if res.ProductCount != 12 {
+		t.Errorf("want productCount 12, got %d", res.ProductCount)
+	}
+}
+
+func TestEventSinkFromContext_Empty(t *testing.T) {
+	if sink := EventSinkFromContext(context.Background()); sink != nil {
+		t.Error("want nil sink for empty context")
+	}
+}
@@ -1,10 +1,13 @@
 package domain
 
+import "time"
+
 // Product represents a product/service in the catalog
 type Product struct {
 	ID              string   `json:"id"`
 	TenantID        string   `json:"tenantId"`
 	MasterProductID string   `json:"masterProductId,omitempty"`
+	SKU             string   `json:"sku,omitempty"` // master product SKU
 	Name            string   `json:"name"`
 	Description     string   `json:"description,omitempty"`
 	Price           int      `json:"price,omitempty"`
@@ -13,6 +16,7 @@ type Product struct {
 	Images          []string `json:"images,omitempty"`
 	Rating          float64  `json:"rating,omitempty"`
 	StockQuantity   int      `json:"stockQuantity"`
+	StockAvailable  *int     `json:"stockAvailable,omitempty"` // quantity - reserved; nil = stock not tracked
 	Brand           string   `json:"brand,omitempty"`
 	Category        string   `json:"category,omitempty"`
 	Tags            []string `json:"tags,omitempty"`
@@ -27,4 +31,15 @@ type Product struct {
 	TargetArea     []string `json:"targetArea,omitempty"`
 	MarketingClaim string   `json:"marketingClaim,omitempty"`
 	Benefits       []string `json:"benefits,omitempty"`
+	FreeFrom       []string `json:"freeFrom,omitempty"` // "fragrance", "parabens", ...
+
+	CreatedAt *time.Time `json:"createdAt,omitempty"` // listing date, recency feature of the reranker
+}
+
+// AvailableStock returns the sellable quantity and whether stock is tracked for the product
+func (p Product) AvailableStock() (int, bool) {
+	if p.StockAvailable == nil {
+		return 0, false
+	}
+	return *p.StockAvailable, true
 }
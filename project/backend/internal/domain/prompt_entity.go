@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"hash/fnv"
+	"sort"
+	"time"
+)
+
+// Prompt names in the registry (one per LLM stage)
+const (
+	PromptAgent1 = "agent1" // Agent 1 system prompt (data retrieval)
+	PromptAgent2 = "agent2" // Agent 2 system prompt (widget composition)
+)
+
+// BuiltinPromptVersion marks the Go constant prompt used when the registry has no active version
+const BuiltinPromptVersion = 0
+
+// PromptVersion is one stored version of a named prompt.
+// TenantSlug "" is global; active tenant versions override all global ones for that tenant.
+// Active versions of the same name/tenant form an experiment, traffic split by Weight.
+type PromptVersion struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Version    int       `json:"version"` // per name, increasing; 0 = built-in
+	TenantSlug string    `json:"tenantSlug,omitempty"`
+	Content    string    `json:"content"`
+	Weight     int       `json:"weight"` // relative traffic share among active versions
+	Active     bool      `json:"active"`
+	Note       string    `json:"note,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// SelectPromptVariant picks one of the active versions for a session.
+// The same session always gets the same version (FNV hash of name+session ID over the weights),
+// so a conversation never switches prompts mid-way while the experiment is unchanged.
+func SelectPromptVariant(variants []PromptVersion, sessionID string) (PromptVersion, bool) {
+	if len(variants) == 0 {
+		return PromptVersion{}, false
+	}
+	sorted := append([]PromptVersion(nil), variants...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	total := 0
+	for _, v := range sorted {
+		if v.Weight > 0 {
+			total += v.Weight
+		}
+	}
+	if total == 0 {
+		// No weights configured: equal split
+		for i := range sorted {
+			sorted[i].Weight = 1
+		}
+		total = len(sorted)
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(sorted[0].Name + ":" + sessionID))
+	bucket := int(h.Sum32() % uint32(total))
+	for _, v := range sorted {
+		if v.Weight <= 0 {
+			continue
+		}
+		if bucket < v.Weight {
+			return v, true
+		}
+		bucket -= v.Weight
+	}
+	return sorted[len(sorted)-1], true
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSelectPromptVariant_DeterministicPerSession(t *testing.T) {
+	variants := []PromptVersion{
+		{Name: PromptAgent1, Version: 2, Weight: 50},
+		{Name: PromptAgent1, Version: 3, Weight: 50},
+	}
+	first, ok := SelectPromptVariant(variants, "session-42")
+	if !ok {
+		t.Fatal("want a variant")
+	}
+	for i := 0; i < 10; i++ {
+		// Order of candidates must not matter
+		got, _ := SelectPromptVariant([]PromptVersion{variants[1], variants[0]}, "session-42")
+		if got.Version != first.Version {
+			t.Fatalf("session switched version: %d → %d", first.Version, got.Version)
+		}
+	}
+}
+
+func TestSelectPromptVariant_SplitFollowsWeights(t *testing.T) {
+	variants := []PromptVersion{
+		{Name: PromptAgent2, Version: 1, Weight: 90},
+		{Name: PromptAgent2, Version: 2, Weight: 10},
+	}
+	counts := map[int]int{}
+	for i := 0; i < 5000; i++ {
+		v, _ := SelectPromptVariant(variants, fmt.Sprintf("s-%d", i))
+		counts[v.Version]++
+	}
+	share := float64(counts[2]) / 5000
+	if share < 0.07 || share > 0.13 {
+		t.Errorf("want ~10%% on v2, got %.3f (%v)", share, counts)
+	}
+}
+
+func TestSelectPromptVariant_ZeroWeightExcludedAndEmpty(t *testing.T) {
+	variants := []PromptVersion{
+		{Name: PromptAgent1, Version: 1, Weight: 0},
+		{Name: PromptAgent1, Version: 2, Weight: 5},
+	}
+	for i := 0; i < 100; i++ {
+		if v, _ := SelectPromptVariant(variants, fmt.Sprintf("s-%d", i)); v.Version != 2 {
+			t.Fatalf("zero-weight version must get no traffic, got v%d", v.Version)
+		}
+	}
+	if _, ok := SelectPromptVariant(nil, "s"); ok {
+		t.Error("no variants must return ok=false")
+	}
+}
@@ -0,0 +1,184 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Filter value correction methods
+const (
+	CorrectionLayout   = "layout"   // typed in the wrong keyboard layout ("rhtv" → "крем")
+	CorrectionTranslit = "translit" // Cyrillic↔Latin spelling ("сераве" → "CeraVe")
+	CorrectionFuzzy    = "fuzzy"    // misspelling snapped by trigram similarity ("la roshe" → "La Roche-Posay")
+)
+
+// FilterCorrection records a search input that was rewritten before querying the catalog
+type FilterCorrection struct {
+	Field      string  `json:"field"` // "brand", "category" or "query"
+	From       string  `json:"from"`
+	To         string  `json:"to"`
+	Method     string  `json:"method"`
+	Similarity float64 `json:"similarity,omitempty"` // trigram similarity, 1 for exact after rewrite
+}
+
+// qwerty and jcuken are the same physical keys on US and Russian layouts
+const (
+	qwertyKeys = "qwertyuiop[]asdfghjkl;'zxcvbnm,.`QWERTYUIOP{}ASDFGHJKL:\"ZXCVBNM<>~"
+	jcukenKeys = "йцукенгшщзхъфывапролджэячсмитьбюёЙЦУКЕНГШЩЗХЪФЫВАПРОЛДЖЭЯЧСМИТЬБЮЁ"
+)
+
+var (
+	latinToCyrillicKey = make(map[rune]rune)
+	cyrillicToLatinKey = make(map[rune]rune)
+)
+
+func init() {
+	lat, cyr := []rune(qwertyKeys), []rune(jcukenKeys)
+	for i := range lat {
+		latinToCyrillicKey[lat[i]] = cyr[i]
+		cyrillicToLatinKey[cyr[i]] = lat[i]
+	}
+}
+
+// SwitchKeyboardLayout retypes text on the other layout: "rhtv" → "крем", "тшлу" → "nike".
+// Direction is decided per rune, characters without a counterpart are kept.
+func SwitchKeyboardLayout(text string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if c, ok := latinToCyrillicKey[r]; ok {
+			b.WriteRune(c)
+		} else if l, ok := cyrillicToLatinKey[r]; ok {
+			b.WriteRune(l)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// FixKeyboardLayout retypes the words of a query that look typed in the wrong layout:
+// Latin words with no Latin vowels or whose retyped form starts with a known Russian
+// shopping word ("edkf;yz.obq" → "увлажняющий"), and Cyrillic words with no Cyrillic vowels.
+// Punctuation alone does not count: "l'oreal", "dr.jart" and "it's" are real Latin words.
+// Words shorter than 4 runes, all-caps words and words with digits are kept.
+// Returns the text and whether anything changed.
+func FixKeyboardLayout(text string) (string, bool) {
+	words := strings.Fields(text)
+	changed := false
+	for i, w := range words {
+		if wrongLayout(w) {
+			words[i] = SwitchKeyboardLayout(w)
+			changed = true
+		}
+	}
+	if !changed {
+		return text, false
+	}
+	return strings.Join(words, " "), true
+}
+
+func wrongLayout(word string) bool {
+	// Short words, acronyms ("SPF", "CBD") and words with digits are left alone
+	if len([]rune(word)) < 4 || strings.ToUpper(word) == word || strings.IndexFunc(word, unicode.IsDigit) >= 0 {
+		return false
+	}
+	var latin, cyrillic, latinVowels, cyrillicVowels int
+	for _, r := range strings.ToLower(word) {
+		switch {
+		case r >= 'a' && r <= 'z':
+			latin++
+			if strings.ContainsRune("aeiouy", r) {
+				latinVowels++
+			}
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+			if strings.ContainsRune("аеёиоуыэюя", r) {
+				cyrillicVowels++
+			}
+		}
+	}
+	switch {
+	case latin > 0 && cyrillic == 0:
+		return latinVowels == 0 || knownRussianWord(SwitchKeyboardLayout(strings.ToLower(word)))
+	case cyrillic > 0 && latin == 0:
+		return cyrillicVowels == 0
+	}
+	return false
+}
+
+// layoutDictionary holds stems of common shopping words; a Latin word with vowels is
+// retyped only when its Cyrillic form starts with one of them
+var layoutDictionary = []string{
+	"увлажн", "питат", "очищ", "тонизир", "матир", "отбел", "успока", "восстан", "омолаж",
+	"крем", "сыворот", "маск", "шампун", "кондиционер", "бальзам", "лосьон", "тоник",
+	"пенк", "скраб", "пилинг", "масл", "эссенц", "флюид", "мицеляр", "солнцезащ", "патч",
+	"помад", "пудр", "румян", "консилер", "тональн", "парфюм", "аромат", "космет",
+	"чувствит", "проблемн", "жирн", "сух", "нормальн", "комбинир", "кож", "лиц", "тел",
+	"волос", "глаз", "губ", "ресниц", "недорог", "дешев", "подар", "набор", "уход",
+	"кроссов", "кед", "ботин", "куртк", "футбол", "брюк", "плать",
+}
+
+// knownRussianWord reports whether a retyped word starts with a dictionary stem
+func knownRussianWord(word string) bool {
+	for _, stem := range layoutDictionary {
+		if strings.HasPrefix(word, stem) {
+			return true
+		}
+	}
+	return false
+}
+
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o",
+	'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts",
+	'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu",
+	'я': "ya",
+}
+
+// phonetic spellings of brand names written in Cyrillic ("сераве" → "cerave", "найк" → "nik")
+var cyrillicToLatinPhonetic = strings.NewReplacer("ай", "i", "с", "c", "х", "h", "к", "k", "ю", "u")
+
+var latinToCyrillic = strings.NewReplacer(
+	"shch", "щ", "sh", "ш", "ch", "ч", "zh", "ж", "kh", "х", "ts", "ц", "ya", "я", "yu", "ю",
+	"a", "а", "b", "б", "c", "к", "d", "д", "e", "е", "f", "ф", "g", "г", "h", "х",
+	"i", "и", "j", "дж", "k", "к", "l", "л", "m", "м", "n", "н", "o", "о", "p", "п",
+	"q", "к", "r", "р", "s", "с", "t", "т", "u", "у", "v", "в", "w", "в", "x", "кс",
+	"y", "й", "z", "з",
+)
+
+// TransliterateVariants spells text in the other script. Cyrillic input yields a standard
+// and a phonetic Latin spelling (brands are usually Latin), Latin input a Cyrillic one.
+func TransliterateVariants(text string) []string {
+	lower := strings.ToLower(text)
+	hasCyrillic := strings.IndexFunc(lower, func(r rune) bool { return unicode.Is(unicode.Cyrillic, r) }) >= 0
+	if !hasCyrillic {
+		if v := latinToCyrillic.Replace(lower); v != lower {
+			return []string{v}
+		}
+		return nil
+	}
+
+	var b strings.Builder
+	for _, r := range lower {
+		if l, ok := cyrillicToLatin[r]; ok {
+			b.WriteString(l)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	variants := []string{b.String()}
+
+	b.Reset()
+	for _, r := range cyrillicToLatinPhonetic.Replace(lower) {
+		if l, ok := cyrillicToLatin[r]; ok {
+			b.WriteString(l)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	if phonetic := b.String(); phonetic != variants[0] {
+		variants = append(variants, phonetic)
+	}
+	return variants
+}
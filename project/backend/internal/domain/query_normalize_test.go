@@ -0,0 +1,66 @@
+package domain
+
+import "testing"
+
+func TestSwitchKeyboardLayout(t *testing.T) {
+	cases := map[string]string{
+		"rhtv":         "крем",
+		"тшлу":         "nike",
+		"edkf;yz.obq":  "увлажняющий",
+		"крем 50 ml":   "rhtv 50 ьд",
+		"Cthsq {bkrfq": "Серый Хилкай",
+	}
+	for in, want := range cases {
+		if got := SwitchKeyboardLayout(in); got != want {
+			t.Errorf("SwitchKeyboardLayout(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := SwitchKeyboardLayout(SwitchKeyboardLayout("сыворотка")); got != "сыворотка" {
+		t.Errorf("round trip: got %q", got)
+	}
+}
+
+func TestFixKeyboardLayout(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    string
+		changed bool
+	}{
+		{"rhtv для лица", "крем для лица", true},
+		{"edkf;yz.obq крем", "увлажняющий крем", true},
+		{"крем для сухой кожи", "крем для сухой кожи", false},
+		{"cerave moisturizing cream", "cerave moisturizing cream", false},
+		{"крем SPF 50", "крем SPF 50", false},
+		{"spf50 крем", "spf50 крем", false},
+		{"ifvgeym от перхоти", "шампунь от перхоти", true},
+		// Brand punctuation is not a layout signal
+		{"крем l'oreal", "крем l'oreal", false},
+		{"сыворотка dr.jart", "сыворотка dr.jart", false},
+		{"it's skin", "it's skin", false},
+		{"st.ives скраб", "st.ives скраб", false},
+	}
+	for _, c := range cases {
+		got, changed := FixKeyboardLayout(c.in)
+		if got != c.want || changed != c.changed {
+			t.Errorf("FixKeyboardLayout(%q) = %q, %v; want %q, %v", c.in, got, changed, c.want, c.changed)
+		}
+	}
+}
+
+func TestTransliterateVariants(t *testing.T) {
+	got := TransliterateVariants("Сераве")
+	if len(got) != 2 || got[0] != "serave" || got[1] != "cerave" {
+		t.Errorf("Сераве: got %v, want [serave cerave]", got)
+	}
+	got = TransliterateVariants("найк")
+	if len(got) != 2 || got[1] != "nik" {
+		t.Errorf("найк: got %v, want phonetic variant nik", got)
+	}
+	got = TransliterateVariants("la roshe")
+	if len(got) != 1 || got[0] != "ла роше" {
+		t.Errorf("la roshe: got %v, want [ла роше]", got)
+	}
+	if got := TransliterateVariants("123"); got != nil {
+		t.Errorf("digits: want nil, got %v", got)
+	}
+}
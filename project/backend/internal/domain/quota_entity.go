@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// TenantQuota holds per-tenant LLM limits. Zero means unlimited.
+// Read from tenant settings: {"quota": {"daily_tokens": 2000000, "monthly_usd": 50, ...}}
+type TenantQuota struct {
+	DailyTokens   int64   `json:"daily_tokens,omitempty"`
+	MonthlyTokens int64   `json:"monthly_tokens,omitempty"`
+	DailyUSD      float64 `json:"daily_usd,omitempty"`
+	MonthlyUSD    float64 `json:"monthly_usd,omitempty"`
+}
+
+// IsZero returns true if no limit is configured
+func (q TenantQuota) IsZero() bool {
+	return q.DailyTokens == 0 && q.MonthlyTokens == 0 && q.DailyUSD == 0 && q.MonthlyUSD == 0
+}
+
+// TenantQuotaFromSettings parses settings["quota"]. Missing or malformed values mean unlimited.
+func TenantQuotaFromSettings(settings map[string]any) TenantQuota {
+	raw, ok := settings["quota"].(map[string]any)
+	if !ok {
+		return TenantQuota{}
+	}
+	return TenantQuota{
+		DailyTokens:   int64(numberSetting(raw["daily_tokens"])),
+		MonthlyTokens: int64(numberSetting(raw["monthly_tokens"])),
+		DailyUSD:      numberSetting(raw["daily_usd"]),
+		MonthlyUSD:    numberSetting(raw["monthly_usd"]),
+	}
+}
+
+func numberSetting(v any) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	}
+	return 0
+}
+
+// TenantUsage is accumulated LLM usage over a period
+type TenantUsage struct {
+	Tokens   int64   `json:"tokens"`
+	CostUSD  float64 `json:"costUsd"`
+	Requests int64   `json:"requests"`
+}
+
+// QuotaExceededError is returned when a tenant hit one of its quotas.
+// errors.Is(err, ErrRateLimitExceeded) is true.
+type QuotaExceededError struct {
+	Period  string    `json:"period"` // "daily" or "monthly"
+	Metric  string    `json:"metric"` // "tokens" or "usd"
+	Limit   float64   `json:"limit"`
+	Used    float64   `json:"used"`
+	ResetAt time.Time `json:"resetAt"`
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("%s %s quota exceeded (%.4g of %.4g)", e.Period, e.Metric, e.Used, e.Limit)
+}
+
+func (e *QuotaExceededError) Unwrap() error { return ErrRateLimitExceeded }
+
+// QuotaPeriodStarts returns the start of the current UTC day and month
+func QuotaPeriodStarts(now time.Time) (day, month time.Time) {
+	now = now.UTC()
+	day = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return day, month
+}
+
+// CheckQuota compares daily/monthly usage against the quota.
+// Returns the first exceeded limit (daily before monthly, tokens before USD), or nil.
+func CheckQuota(q TenantQuota, daily, monthly TenantUsage, now time.Time) *QuotaExceededError {
+	day, month := QuotaPeriodStarts(now)
+	dayReset := day.AddDate(0, 0, 1)
+	monthReset := month.AddDate(0, 1, 0)
+
+	switch {
+	case q.DailyTokens > 0 && daily.Tokens >= q.DailyTokens:
+		return &QuotaExceededError{Period: "daily", Metric: "tokens", Limit: float64(q.DailyTokens), Used: float64(daily.Tokens), ResetAt: dayReset}
+	case q.DailyUSD > 0 && daily.CostUSD >= q.DailyUSD:
+		return &QuotaExceededError{Period: "daily", Metric: "usd", Limit: q.DailyUSD, Used: daily.CostUSD, ResetAt: dayReset}
+	case q.MonthlyTokens > 0 && monthly.Tokens >= q.MonthlyTokens:
+		return &QuotaExceededError{Period: "monthly", Metric: "tokens", Limit: float64(q.MonthlyTokens), Used: float64(monthly.Tokens), ResetAt: monthReset}
+	case q.MonthlyUSD > 0 && monthly.CostUSD >= q.MonthlyUSD:
+		return &QuotaExceededError{Period: "monthly", Metric: "usd", Limit: q.MonthlyUSD, Used: monthly.CostUSD, ResetAt: monthReset}
+	}
+	return nil
+}
+
+// QuotaFallbackFormation is shown in the widget instead of results when a quota is hit
+func QuotaFallbackFormation() *FormationWithData {
+	return &FormationWithData{
+		Mode: FormationTypeSingle,
+		Widgets: []Widget{{
+			ID:   "quota-exceeded",
+			Type: WidgetTypeTextBlock,
+			Size: WidgetSizeMedium,
+			Atoms: []Atom{
+				{Type: AtomTypeText, Subtype: SubtypeString, Display: "h3", Value: "Ассистент временно недоступен"},
+				{Type: AtomTypeText, Subtype: SubtypeString, Display: "body-sm", Value: "Лимит запросов исчерпан. Попробуйте позже или воспользуйтесь каталогом."},
+			},
+		}},
+	}
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTenantQuotaFromSettings(t *testing.T) {
+	q := TenantQuotaFromSettings(map[string]any{
+		"quota": map[string]any{"daily_tokens": float64(1000), "monthly_usd": 12.5},
+	})
+	if q.DailyTokens != 1000 || q.MonthlyUSD != 12.5 || q.MonthlyTokens != 0 {
+		t.Errorf("unexpected quota %+v", q)
+	}
+	if !TenantQuotaFromSettings(nil).IsZero() {
+		t.Error("nil settings should mean unlimited")
+	}
+}
+
+func TestCheckQuota(t *testing.T) {
+	now := time.Date(2026, 3, 15, 13, 0, 0, 0, time.UTC)
+	q := TenantQuota{DailyTokens: 1000, MonthlyUSD: 10}
+
+	if err := CheckQuota(q, TenantUsage{Tokens: 999}, TenantUsage{CostUSD: 9.99}, now); err != nil {
+		t.Fatalf("under limits, got %v", err)
+	}
+
+	err := CheckQuota(q, TenantUsage{Tokens: 1000}, TenantUsage{}, now)
+	if err == nil || err.Period != "daily" || err.Metric != "tokens" {
+		t.Fatalf("want daily tokens exceeded, got %+v", err)
+	}
+	if !err.ResetAt.Equal(time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("daily quota resets at next UTC midnight, got %s", err.ResetAt)
+	}
+
+	err = CheckQuota(q, TenantUsage{}, TenantUsage{CostUSD: 10}, now)
+	if err == nil || err.Period != "monthly" || err.Metric != "usd" {
+		t.Fatalf("want monthly usd exceeded, got %+v", err)
+	}
+	if !err.ResetAt.Equal(time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("monthly quota resets on the 1st, got %s", err.ResetAt)
+	}
+	if !errors.Is(err, ErrRateLimitExceeded) {
+		t.Error("QuotaExceededError should match ErrRateLimitExceeded")
+	}
+}
+
+func TestPipelineTrace_TotalTokens(t *testing.T) {
+	tr := &PipelineTrace{
+		Agent1: &AgentTrace{InputTokens: 100, OutputTokens: 20, CacheRead: 500},
+		Agent2: &AgentTrace{InputTokens: 50, OutputTokens: 10, CacheWrite: 30},
+	}
+	if got := tr.TotalTokens(); got != 710 {
+		t.Errorf("want 710, got %d", got)
+	}
+}
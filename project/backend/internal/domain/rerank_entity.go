@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Rerank strategies selectable per tenant
+const (
+	RerankStrategyNone     = "none"
+	RerankStrategyFeatures = "features" // deterministic feature-based scorer
+	RerankStrategyLLM      = "llm"      // LLM relevance judgement blended with the fused score
+)
+
+// DefaultRerankTopN is how many merged candidates are reranked when the tenant does not say
+const DefaultRerankTopN = 20
+
+// RerankWeights weigh the features of a candidate. Every feature is normalized to 0..1.
+type RerankWeights struct {
+	Relevance   float64 `json:"relevance"`    // RRF score relative to the best candidate
+	FilterMatch float64 `json:"filter_match"` // share of requested filter values the product has
+	Stock       float64 `json:"stock"`        // 1 when in stock
+	Rating      float64 `json:"rating"`       // rating / 5
+	Recency     float64 `json:"recency"`      // halves every 90 days since listing
+	LLM         float64 `json:"llm"`          // LLM relevance (llm strategy only)
+}
+
+// DefaultRerankWeights keeps fused relevance dominant and lets the other features break ties
+func DefaultRerankWeights() RerankWeights {
+	return RerankWeights{Relevance: 1, FilterMatch: 0.3, Stock: 0.2, Rating: 0.2, Recency: 0.1, LLM: 1}
+}
+
+// RerankConfig is the tenant's reranking setup.
+// Read from tenant settings: {"rerank": {"strategy": "features", "top_n": 30, "weights": {"stock": 0.5}}}
+type RerankConfig struct {
+	Strategy string
+	TopN     int
+	Weights  RerankWeights
+}
+
+// RerankConfigFromSettings parses settings["rerank"] over defaultStrategy and DefaultRerankWeights.
+// Weights missing from settings keep their defaults; malformed values are ignored.
+func RerankConfigFromSettings(settings map[string]any, defaultStrategy string) RerankConfig {
+	cfg := RerankConfig{Strategy: defaultStrategy, TopN: DefaultRerankTopN, Weights: DefaultRerankWeights()}
+	raw, ok := settings["rerank"].(map[string]any)
+	if !ok {
+		return cfg
+	}
+	if s, ok := raw["strategy"].(string); ok && s != "" {
+		cfg.Strategy = s
+	}
+	if n := int(numberSetting(raw["top_n"])); n > 0 {
+		cfg.TopN = n
+	}
+	if w, ok := raw["weights"].(map[string]any); ok {
+		for key, dst := range map[string]*float64{
+			"relevance":    &cfg.Weights.Relevance,
+			"filter_match": &cfg.Weights.FilterMatch,
+			"stock":        &cfg.Weights.Stock,
+			"rating":       &cfg.Weights.Rating,
+			"recency":      &cfg.Weights.Recency,
+			"llm":          &cfg.Weights.LLM,
+		} {
+			switch v := w[key].(type) {
+			case float64, int, int64:
+				*dst = numberSetting(v)
+			}
+		}
+	}
+	return cfg
+}
+
+// RerankCandidate is a merged search hit with its fused RRF score
+type RerankCandidate struct {
+	Product   Product
+	BaseScore float64
+}
+
+// RerankScore is a reranker's verdict for one candidate, with the features behind it
+type RerankScore struct {
+	ID       string             `json:"id"`
+	Score    float64            `json:"score"`
+	Features map[string]float64 `json:"features,omitempty"`
+}
+
+// String formats the score for trace breakdown: "p1 0.912 (filter_match=1.00 relevance=0.85)"
+func (s RerankScore) String() string {
+	if len(s.Features) == 0 {
+		return fmt.Sprintf("%s %.3f", s.ID, s.Score)
+	}
+	keys := make([]string, 0, len(s.Features))
+	for k := range s.Features {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%.2f", k, s.Features[k])
+	}
+	return fmt.Sprintf("%s %.3f (%s)", s.ID, s.Score, strings.Join(parts, " "))
+}
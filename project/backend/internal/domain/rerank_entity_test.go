@@ -0,0 +1,37 @@
+package domain
+
+import "testing"
+
+func TestRerankConfigFromSettings(t *testing.T) {
+	cfg := RerankConfigFromSettings(nil, RerankStrategyNone)
+	if cfg.Strategy != RerankStrategyNone || cfg.TopN != DefaultRerankTopN || cfg.Weights != DefaultRerankWeights() {
+		t.Errorf("unexpected defaults %+v", cfg)
+	}
+
+	cfg = RerankConfigFromSettings(map[string]any{
+		"rerank": map[string]any{
+			"strategy": "features",
+			"top_n":    float64(30),
+			"weights":  map[string]any{"stock": 0.5, "rating": "bad"},
+		},
+	}, RerankStrategyNone)
+	if cfg.Strategy != RerankStrategyFeatures || cfg.TopN != 30 {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+	if cfg.Weights.Stock != 0.5 || cfg.Weights.Relevance != 1 {
+		t.Errorf("stock should be overridden, relevance kept: %+v", cfg.Weights)
+	}
+	if cfg.Weights.Rating != DefaultRerankWeights().Rating {
+		t.Errorf("malformed weight should keep its default, got %v", cfg.Weights.Rating)
+	}
+}
+
+func TestRerankScore_String(t *testing.T) {
+	s := RerankScore{ID: "p1", Score: 1.25, Features: map[string]float64{"stock": 1, "relevance": 0.5}}
+	if s.String() != "p1 1.250 (relevance=0.50 stock=1.00)" {
+		t.Errorf("unexpected: %q", s.String())
+	}
+	if (RerankScore{ID: "p2", Score: 0.5}).String() != "p2 0.500" {
+		t.Errorf("unexpected: %q", RerankScore{ID: "p2", Score: 0.5}.String())
+	}
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Response cache match kinds
+const (
+	CacheMatchExact    = "exact"    // same normalized query
+	CacheMatchSemantic = "semantic" // query embedding above the similarity threshold
+)
+
+// ResponseCacheEntry is a stored pipeline result for one tenant.
+// Replaying it writes Data/Meta and the formation into the session state
+// exactly like catalog_search + the render tool would have.
+type ResponseCacheEntry struct {
+	ID         string             `json:"id"`
+	TenantSlug string             `json:"tenantSlug"`
+	QueryNorm  string             `json:"queryNorm"`
+	StateKey   string             `json:"stateKey"`
+	Embedding  []float32          `json:"-"`
+	Data       StateData          `json:"data"`
+	Meta       StateMeta          `json:"meta"`
+	Formation  *FormationWithData `json:"formation"`
+	ToolName   string             `json:"toolName"`
+	ToolInput  string             `json:"toolInput,omitempty"`
+	CostUSD    float64            `json:"costUsd"` // LLM cost of the run that produced the entry
+	Hits       int                `json:"hits"`
+	CreatedAt  time.Time          `json:"createdAt"`
+}
+
+// ResponseCacheHit is a successful lookup
+type ResponseCacheHit struct {
+	Entry      *ResponseCacheEntry
+	Match      string  // CacheMatchExact | CacheMatchSemantic
+	Similarity float64 // 1 for exact matches
+}
+
+// NormalizeCacheQuery lowercases, folds ё→е, drops punctuation and collapses whitespace,
+// so "Крем для сухой кожи!" and "крем  для сухой кожи" share a key
+func NormalizeCacheQuery(query string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(query) {
+		switch {
+		case r == 'ё':
+			r = 'е'
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+		default:
+			space = b.Len() > 0
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// ResponseCacheStateKey fingerprints what a cached answer depends on besides the query:
+// the prompt versions that produced it. Only first queries on an empty screen are
+// cacheable (ok=false otherwise): a follow-up like "подешевле" depends on the items
+// on screen and the conversation, which another session does not share.
+func ResponseCacheStateKey(state *SessionState, promptVersions map[string]int) (string, bool) {
+	if state != nil && (len(state.Current.Data.Products) > 0 || len(state.Current.Data.Services) > 0) {
+		return "", false
+	}
+	return fmt.Sprintf("empty|a1v%d|a2v%d", promptVersions[PromptAgent1], promptVersions[PromptAgent2]), true
+}
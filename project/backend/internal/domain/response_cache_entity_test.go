@@ -0,0 +1,48 @@
+package domain
+
+import "testing"
+
+func TestNormalizeCacheQuery(t *testing.T) {
+	cases := map[string]string{
+		"Крем для сухой кожи":        "крем для сухой кожи",
+		"  крем   для сухой кожи!! ": "крем для сухой кожи",
+		"Ёлочный свитер?":            "елочный свитер",
+		"SPF-50, до 2000₽":           "spf 50 до 2000",
+		"!!!":                        "",
+	}
+	for in, want := range cases {
+		if got := NormalizeCacheQuery(in); got != want {
+			t.Errorf("NormalizeCacheQuery(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestResponseCacheStateKey(t *testing.T) {
+	versions := map[string]int{PromptAgent1: 2}
+
+	if got, ok := ResponseCacheStateKey(nil, versions); !ok || got != "empty|a1v2|a2v0" {
+		t.Errorf("nil state: got %q ok=%v", got, ok)
+	}
+	empty := &SessionState{ConversationHistory: []LLMMessage{{Role: "user", Content: "<catalog>...</catalog>"}}}
+	emptyKey, ok := ResponseCacheStateKey(empty, versions)
+	if !ok || emptyKey != "empty|a1v2|a2v0" {
+		t.Errorf("empty screen: got %q ok=%v", emptyKey, ok)
+	}
+	// A/B prompt versions never share entries
+	if key, _ := ResponseCacheStateKey(empty, map[string]int{PromptAgent1: 3}); key == emptyKey {
+		t.Error("prompt versions must be part of the key")
+	}
+
+	// Loaded screens are not cacheable: follow-ups depend on the items shown
+	grid := &SessionState{Current: StateCurrent{
+		Data:     StateData{Products: []Product{{ID: "p1"}}},
+		Template: map[string]interface{}{"formation": &FormationWithData{Config: &RenderConfig{EntityType: "product", Mode: FormationTypeGrid, Size: WidgetSizeMedium}}},
+	}}
+	if key, ok := ResponseCacheStateKey(grid, versions); ok {
+		t.Errorf("loaded screen must not be cacheable, got key %q", key)
+	}
+	services := &SessionState{Current: StateCurrent{Data: StateData{Services: []Service{{ID: "s1"}}}}}
+	if _, ok := ResponseCacheStateKey(services, versions); ok {
+		t.Error("loaded services must not be cacheable")
+	}
+}
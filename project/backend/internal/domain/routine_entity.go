@@ -0,0 +1,92 @@
+package domain
+
+// Routine times (master_products.routine_time)
+const (
+	RoutineTimeAM   = "am"
+	RoutineTimePM   = "pm"
+	RoutineTimeBoth = "both" // product fits morning and evening
+)
+
+// routineSteps is the application order of routine_step values per routine time
+var routineSteps = map[string][]string{
+	RoutineTimeAM: {"cleansing", "toning", "treatment", "moisturizing", "sun-protection"},
+	RoutineTimePM: {"cleansing", "toning", "treatment", "moisturizing"},
+}
+
+var routineStepLabels = map[string]string{
+	"cleansing":      "Очищение",
+	"toning":         "Тоник",
+	"exfoliation":    "Отшелушивание",
+	"treatment":      "Сыворотка",
+	"moisturizing":   "Увлажнение",
+	"sun-protection": "SPF",
+}
+
+var routineTimeLabels = map[string]string{
+	RoutineTimeAM: "Утро",
+	RoutineTimePM: "Вечер",
+}
+
+// RoutineSteps returns the ordered steps of a morning or evening routine (nil for unknown time)
+func RoutineSteps(time string) []string {
+	return routineSteps[time]
+}
+
+// RoutineStepLabel returns the shopper-facing name of a routine step
+func RoutineStepLabel(step string) string {
+	if l, ok := routineStepLabels[step]; ok {
+		return l
+	}
+	return step
+}
+
+// RoutineTimeLabel returns the shopper-facing name of a routine time
+func RoutineTimeLabel(time string) string {
+	if l, ok := routineTimeLabels[time]; ok {
+		return l
+	}
+	return time
+}
+
+// RoutineStep is one step of a routine with the picked product
+type RoutineStep struct {
+	Step      string `json:"step"`                // routine_step value
+	Label     string `json:"label"`               // "Очищение"
+	ProductID string `json:"productId,omitempty"` // empty = nothing in the catalog for this step
+}
+
+// RoutineSection is the morning or evening part of a routine
+type RoutineSection struct {
+	Time  string        `json:"time"`  // RoutineTimeAM | RoutineTimePM
+	Label string        `json:"label"` // "Утро"
+	Steps []RoutineStep `json:"steps"`
+}
+
+// Routine is an ordered skincare regimen assembled by catalog_routine.
+// Products themselves live in StateData; steps reference them by ID.
+type Routine struct {
+	Sections []RoutineSection `json:"sections"`
+	Total    int              `json:"total"` // kopecks, a product used morning and evening counted once
+	Currency string           `json:"currency,omitempty"`
+	Budget   int              `json:"budget,omitempty"` // kopecks, 0 = no budget
+	SkinType string           `json:"skinType,omitempty"`
+	Concern  string           `json:"concern,omitempty"`
+}
+
+// Missing returns "time:step" of steps without a product
+func (r *Routine) Missing() []string {
+	var missing []string
+	for _, s := range r.Sections {
+		for _, st := range s.Steps {
+			if st.ProductID == "" {
+				missing = append(missing, s.Time+":"+st.Step)
+			}
+		}
+	}
+	return missing
+}
+
+// OverBudget reports whether the routine costs more than its budget
+func (r *Routine) OverBudget() bool {
+	return r.Budget > 0 && r.Total > r.Budget
+}
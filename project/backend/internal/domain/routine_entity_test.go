@@ -0,0 +1,39 @@
+package domain
+
+import "testing"
+
+func TestRoutineSteps_Order(t *testing.T) {
+	am := RoutineSteps(RoutineTimeAM)
+	if len(am) != 5 || am[0] != "cleansing" || am[4] != "sun-protection" {
+		t.Errorf("morning should go from cleansing to SPF, got %v", am)
+	}
+	for _, step := range RoutineSteps(RoutineTimePM) {
+		if step == "sun-protection" {
+			t.Error("evening routine should not include SPF")
+		}
+	}
+	if RoutineSteps(RoutineTimeBoth) != nil {
+		t.Error("both is not a routine of its own")
+	}
+}
+
+func TestRoutine_MissingAndOverBudget(t *testing.T) {
+	r := &Routine{
+		Total:  120000,
+		Budget: 100000,
+		Sections: []RoutineSection{{Time: RoutineTimeAM, Steps: []RoutineStep{
+			{Step: "cleansing", ProductID: "p1"},
+			{Step: "toning"},
+		}}},
+	}
+	if m := r.Missing(); len(m) != 1 || m[0] != "am:toning" {
+		t.Errorf("want [am:toning], got %v", m)
+	}
+	if !r.OverBudget() {
+		t.Error("total above budget should be over budget")
+	}
+	r.Budget = 0
+	if r.OverBudget() {
+		t.Error("no budget is never over budget")
+	}
+}
@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+// SessionBranch is one session of a fork tree. The original session is the root;
+// a fork starts from its parent's state at ForkStep and goes on independently.
+type SessionBranch struct {
+	SessionID string           `json:"sessionId"`
+	ParentID  string           `json:"parentId,omitempty"` // empty for the root
+	ForkStep  int              `json:"forkStep"`           // parent step the fork was taken at
+	Step      int              `json:"step"`               // current step of the branch
+	Status    SessionStatus    `json:"status"`
+	CreatedAt time.Time        `json:"createdAt"`
+	Children  []*SessionBranch `json:"children,omitempty"`
+}
+
+// BuildBranchTree links a flat branch list (parents before children) into trees.
+// A branch whose parent is not in the list becomes a root.
+func BuildBranchTree(branches []SessionBranch) []*SessionBranch {
+	nodes := make(map[string]*SessionBranch, len(branches))
+	for i := range branches {
+		b := branches[i]
+		b.Children = nil
+		nodes[b.SessionID] = &b
+	}
+
+	var roots []*SessionBranch
+	for _, b := range branches {
+		node := nodes[b.SessionID]
+		if parent, ok := nodes[b.ParentID]; ok && b.ParentID != b.SessionID {
+			parent.Children = append(parent.Children, node)
+			continue
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+// Find returns the branch of the session in the tree (nil if it is not there)
+func (b *SessionBranch) Find(sessionID string) *SessionBranch {
+	if b.SessionID == sessionID {
+		return b
+	}
+	for _, c := range b.Children {
+		if found := c.Find(sessionID); found != nil {
+			return found
+		}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "testing"
+
+func TestBuildBranchTree(t *testing.T) {
+	roots := BuildBranchTree([]SessionBranch{
+		{SessionID: "root"},
+		{SessionID: "a", ParentID: "root", ForkStep: 2},
+		{SessionID: "b", ParentID: "root", ForkStep: 4},
+		{SessionID: "a1", ParentID: "a", ForkStep: 3},
+		{SessionID: "orphan", ParentID: "deleted"},
+	})
+
+	if len(roots) != 2 || roots[0].SessionID != "root" || roots[1].SessionID != "orphan" {
+		t.Fatalf("want roots [root orphan], got %+v", roots)
+	}
+	root := roots[0]
+	if len(root.Children) != 2 || root.Children[0].SessionID != "a" || root.Children[1].SessionID != "b" {
+		t.Errorf("want root children [a b], got %+v", root.Children)
+	}
+	if a1 := root.Find("a1"); a1 == nil || a1.ForkStep != 3 {
+		t.Errorf("want a1 forked at step 3 under a, got %+v", a1)
+	}
+	if root.Find("orphan") != nil {
+		t.Error("orphan is not in the root tree")
+	}
+}
@@ -42,6 +42,7 @@ const (
 	ActionLayout   ActionType = "LAYOUT"
 	ActionRollback ActionType = "ROLLBACK"
 	ActionClarify  ActionType = "CLARIFY"
+	ActionCheck    ActionType = "CHECK" // read-only analysis of the data (ingredient compatibility)
 )
 
 // Action represents what happened in a delta
@@ -69,10 +70,35 @@ type Delta struct {
 	Path      string                 `json:"path"`       // What changed: "data.products", "view.mode", "viewStack"
 	Action    Action                 `json:"action"`
 	Result    ResultMeta             `json:"result"`
-	Template  map[string]interface{} `json:"template,omitempty"`
+	Template  map[string]interface{} `json:"template,omitempty"` // Template zone after the change (template and rollback deltas)
+	Payload   *DeltaPayload          `json:"payload,omitempty"`  // Data/view zones after the change (nil for legacy deltas)
 	CreatedAt time.Time              `json:"created_at"`
 }
 
+// RollbackTarget returns the to_step of a rollback delta
+// (an int when built in memory, a float64 after a JSON round trip)
+func (d Delta) RollbackTarget() (int, bool) {
+	switch v := d.Action.Params["to_step"].(type) {
+	case int:
+		return v, true
+	case float64:
+		return int(v), true
+	}
+	return 0, false
+}
+
+// DeltaPayload is the content of the zones a delta wrote, so replaying deltas
+// rebuilds the exact screen at any step. Only the written zones are set:
+// UpdateData → Data+Meta, UpdateView → View+stacks, rollback → all of them.
+// Both stacks are restored together with View (nil View = stacks untouched).
+type DeltaPayload struct {
+	Data         *StateData     `json:"data,omitempty"`
+	Meta         *StateMeta     `json:"meta,omitempty"`
+	View         *ViewState     `json:"view,omitempty"`
+	ViewStack    []ViewSnapshot `json:"view_stack,omitempty"`
+	ForwardStack []ViewSnapshot `json:"forward_stack,omitempty"`
+}
+
 // DeltaInfo contains metadata for creating a delta via zone-write.
 // Use ToDelta() to convert to a full Delta.
 type DeltaInfo struct {
@@ -108,6 +134,10 @@ type StateMeta struct {
 	ServiceCount int               `json:"serviceCount,omitempty"`
 	Fields       []string          `json:"fields"`
 	Aliases      map[string]string `json:"aliases,omitempty"`
+	Facets       []Facet           `json:"facets,omitempty"` // value counts of the last catalog search
+	Stock        *StockConfig      `json:"stock,omitempty"`  // stock policy of the last catalog search (drives stock badges)
+	Routine      *Routine          `json:"routine,omitempty"` // step plan when the data is a skincare routine (rendered as sections)
+	Compatibility *CompatibilityReport `json:"compatibility,omitempty"` // ingredient check of the current data (rendered as an info card)
 }
 
 // StateData contains raw data (products, services, etc.)
@@ -144,10 +174,42 @@ type ViewSnapshot struct {
 	Mode      ViewMode    `json:"mode"`
 	Focused   *EntityRef  `json:"focused,omitempty"` // Expanded item (if detail mode)
 	Refs      []EntityRef `json:"refs"`              // What was shown
+	Query     string      `json:"query,omitempty"`   // Shopper query behind the data (breadcrumb label)
+	Title     string      `json:"title,omitempty"`   // Name of the focused item (breadcrumb label)
 	Step      int         `json:"step"`              // Delta step when captured
 	CreatedAt time.Time   `json:"created_at"`
 }
 
+// Label is the breadcrumb text of the view: the focused item name,
+// else the query behind the results, else a generic name by mode
+func (s ViewSnapshot) Label() string {
+	switch {
+	case s.Focused != nil && s.Title != "":
+		return s.Title
+	case s.Focused == nil && s.Query != "":
+		return s.Query
+	case s.Mode == ViewModeDetail:
+		return "Товар"
+	}
+	return "Результаты"
+}
+
+// Breadcrumb is one entry of the navigation trail
+type Breadcrumb struct {
+	Label string   `json:"label"`
+	Mode  ViewMode `json:"mode"`
+	Depth int      `json:"depth"` // "back" presses to reach it, 0 = current view
+}
+
+// Breadcrumbs builds the trail from the back stack (oldest first) to the current view
+func Breadcrumbs(stack []ViewSnapshot, current ViewSnapshot) []Breadcrumb {
+	crumbs := make([]Breadcrumb, 0, len(stack)+1)
+	for i, s := range stack {
+		crumbs = append(crumbs, Breadcrumb{Label: s.Label(), Mode: s.Mode, Depth: len(stack) - i})
+	}
+	return append(crumbs, Breadcrumb{Label: current.Label(), Mode: current.Mode})
+}
+
 // ViewState represents current view configuration
 type ViewState struct {
 	Mode    ViewMode   `json:"mode"`
@@ -160,7 +222,8 @@ type SessionState struct {
 	SessionID           string         `json:"session_id"`
 	Current             StateCurrent   `json:"current"`
 	View                ViewState      `json:"view"`                          // Current view configuration
-	ViewStack           []ViewSnapshot `json:"view_stack"`                    // Navigation history for back/forward
+	ViewStack           []ViewSnapshot `json:"view_stack"`                    // Navigation history for back
+	ForwardStack        []ViewSnapshot `json:"forward_stack,omitempty"`       // Views left by back, for forward; cleared on new navigation or query
 	ConversationHistory []LLMMessage   `json:"conversation_history,omitempty"` // LLM conversation history for caching
 	Step                int            `json:"step"`                          // Current step number
 	CreatedAt           time.Time      `json:"created_at"`
@@ -117,3 +117,30 @@ func TestDeltaInfo_ToDelta_IndependentCalls(t *testing.T) {
 		t.Error("Expected same TurnID from same DeltaInfo")
 	}
 }
+
+func TestBreadcrumbs_LabelsFromSnapshots(t *testing.T) {
+	stack := []ViewSnapshot{
+		{Mode: ViewModeGrid, Query: "увлажняющий крем"},
+		{Mode: ViewModeDetail, Focused: &EntityRef{Type: EntityTypeProduct, ID: "p1"}, Title: "Hydra Cream", Query: "увлажняющий крем"},
+	}
+	crumbs := Breadcrumbs(stack, ViewSnapshot{Mode: ViewModeGrid})
+
+	want := []Breadcrumb{
+		{Label: "увлажняющий крем", Mode: ViewModeGrid, Depth: 2},
+		{Label: "Hydra Cream", Mode: ViewModeDetail, Depth: 1},
+		{Label: "Результаты", Mode: ViewModeGrid, Depth: 0},
+	}
+	if len(crumbs) != len(want) {
+		t.Fatalf("want %d breadcrumbs, got %+v", len(want), crumbs)
+	}
+	for i := range want {
+		if crumbs[i] != want[i] {
+			t.Errorf("crumb %d: want %+v, got %+v", i, want[i], crumbs[i])
+		}
+	}
+
+	// Focused item that is no longer in the data has no title
+	if got := (ViewSnapshot{Mode: ViewModeDetail, Focused: &EntityRef{ID: "gone"}, Query: "крем"}).Label(); got != "Товар" {
+		t.Errorf("want generic detail label, got %q", got)
+	}
+}
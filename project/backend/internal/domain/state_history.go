@@ -0,0 +1,128 @@
+package domain
+
+// History actions recorded in rollback deltas (Action.Params["history"])
+const (
+	HistoryUndo = "undo" // back to the previous screen
+	HistoryRedo = "redo" // forward to the screen undone last
+	HistoryGoto = "goto" // jump to any earlier step
+)
+
+// History errors
+var (
+	ErrNothingToUndo = &Error{Code: "NOTHING_TO_UNDO", Message: "nothing to undo"}
+	ErrNothingToRedo = &Error{Code: "NOTHING_TO_REDO", Message: "nothing to redo"}
+	ErrInvalidStep   = &Error{Code: "INVALID_STEP", Message: "invalid step"}
+)
+
+// StateHistory is the undo/redo position of a session, derived from its deltas.
+// A screen is identified by the step of the last delta of the turn that built it;
+// step 0 is the empty screen the session starts with.
+type StateHistory struct {
+	Past []int // screens shown so far, the current one last
+	Redo []int // screens undone since the last change, the next redo last
+}
+
+// BuildStateHistory replays the delta log into a history. Deltas of one turn
+// (same TurnID) build one screen. Only a new shopper query (TriggerUserQuery) drops
+// the redo list; expand, back and other widget or system changes keep it.
+// Rollback deltas without a history action count as a goto.
+func BuildStateHistory(deltas []Delta) *StateHistory {
+	h := &StateHistory{Past: []int{0}}
+	lastTurn := ""
+	for _, d := range deltas {
+		if d.DeltaType == DeltaTypeRollback {
+			if toStep, ok := d.RollbackTarget(); ok {
+				action, _ := d.Action.Params["history"].(string)
+				h.Record(action, toStep)
+			}
+			lastTurn = ""
+			continue
+		}
+		if d.Trigger == TriggerUserQuery {
+			h.Redo = nil
+		}
+		if d.TurnID != "" && d.TurnID == lastTurn {
+			h.Past[len(h.Past)-1] = d.Step
+			continue
+		}
+		h.Past = append(h.Past, d.Step)
+		lastTurn = d.TurnID
+	}
+	return h
+}
+
+// Current returns the step of the screen shown now
+func (h *StateHistory) Current() int {
+	return h.Past[len(h.Past)-1]
+}
+
+// CanUndo reports whether there is a previous screen
+func (h *StateHistory) CanUndo() bool {
+	return len(h.Past) > 1
+}
+
+// CanRedo reports whether an undone screen can be restored
+func (h *StateHistory) CanRedo() bool {
+	return len(h.Redo) > 0
+}
+
+// UndoTarget returns the step an undo goes back to
+func (h *StateHistory) UndoTarget() (int, bool) {
+	if !h.CanUndo() {
+		return 0, false
+	}
+	return h.Past[len(h.Past)-2], true
+}
+
+// RedoTarget returns the step a redo restores
+func (h *StateHistory) RedoTarget() (int, bool) {
+	if !h.CanRedo() {
+		return 0, false
+	}
+	return h.Redo[len(h.Redo)-1], true
+}
+
+// Record moves the history after a rollback to toStep
+func (h *StateHistory) Record(action string, toStep int) {
+	switch action {
+	case HistoryUndo:
+		if h.CanUndo() {
+			h.Redo = append(h.Redo, h.Current())
+			h.Past = h.Past[:len(h.Past)-1]
+		}
+	case HistoryRedo:
+		if h.CanRedo() {
+			h.Past = append(h.Past, h.Redo[len(h.Redo)-1])
+			h.Redo = h.Redo[:len(h.Redo)-1]
+		}
+	default:
+		h.Past = append(h.Past, toStep)
+		h.Redo = nil
+	}
+}
+
+// ConversationUpToStep cuts the conversation history back to the screen of step: everything
+// from the first message of a turn whose first delta comes after step is dropped. deltas is the
+// full log of the session. Messages are matched by TurnID, so history trimmed by retention still
+// cuts right; messages without a turn (catalog seed) and turns that wrote no delta never start
+// the cut and stay when they come before it.
+func ConversationUpToStep(history []LLMMessage, deltas []Delta, step int) []LLMMessage {
+	firstStep := make(map[string]int)
+	for _, d := range deltas {
+		if d.TurnID == "" {
+			continue
+		}
+		if s, ok := firstStep[d.TurnID]; !ok || d.Step < s {
+			firstStep[d.TurnID] = d.Step
+		}
+	}
+	for i, msg := range history {
+		if msg.TurnID == "" {
+			continue
+		}
+		if s, ok := firstStep[msg.TurnID]; ok && s > step {
+			return append([]LLMMessage(nil), history[:i]...)
+		}
+	}
+	return append([]LLMMessage(nil), history...)
+}
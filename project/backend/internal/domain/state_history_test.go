@@ -0,0 +1,144 @@
+package domain
+
+import "testing"
+
+func historyRollback(step int, action string, toStep int) Delta {
+	return Delta{
+		Step:      step,
+		DeltaType: DeltaTypeRollback,
+		Action:    Action{Type: ActionRollback, Params: map[string]interface{}{"to_step": float64(toStep), "history": action}},
+	}
+}
+
+func TestBuildStateHistory_TurnsAreScreens(t *testing.T) {
+	h := BuildStateHistory([]Delta{
+		{Step: 1, TurnID: "t1", DeltaType: DeltaTypeAdd},
+		{Step: 2, TurnID: "t1", DeltaType: DeltaTypeUpdate},
+		{Step: 3, TurnID: "t2", DeltaType: DeltaTypeUpdate},
+		{Step: 4, DeltaType: DeltaTypeUpdate}, // no turn: a screen of its own
+		{Step: 5, DeltaType: DeltaTypeUpdate},
+	})
+	if got := h.Past; len(got) != 5 || got[1] != 2 || got[2] != 3 || got[4] != 5 {
+		t.Errorf("want screens [0 2 3 4 5], got %v", got)
+	}
+	if target, ok := h.UndoTarget(); !ok || target != 4 {
+		t.Errorf("undo should go to step 4, got %d %v", target, ok)
+	}
+	if h.CanRedo() {
+		t.Error("nothing was undone")
+	}
+}
+
+func TestBuildStateHistory_UndoRedo(t *testing.T) {
+	deltas := []Delta{
+		{Step: 1, TurnID: "t1"},
+		{Step: 2, TurnID: "t2"},
+		{Step: 3, TurnID: "t3"},
+		historyRollback(4, HistoryUndo, 2),
+		historyRollback(5, HistoryUndo, 1),
+		historyRollback(6, HistoryRedo, 2),
+	}
+	h := BuildStateHistory(deltas)
+	if h.Current() != 2 {
+		t.Errorf("want screen of step 2 after undo, undo, redo; got %d", h.Current())
+	}
+	if target, ok := h.RedoTarget(); !ok || target != 3 {
+		t.Errorf("redo should restore step 3, got %d %v", target, ok)
+	}
+	if target, ok := h.UndoTarget(); !ok || target != 1 {
+		t.Errorf("undo should go to step 1, got %d %v", target, ok)
+	}
+
+	// A new query after an undo drops the redo list
+	h = BuildStateHistory(append(deltas, Delta{Step: 7, TurnID: "t4", Trigger: TriggerUserQuery}))
+	if h.CanRedo() || h.Current() != 7 {
+		t.Errorf("new query should clear redo, got %+v", h)
+	}
+}
+
+func TestBuildStateHistory_NavigationKeepsRedo(t *testing.T) {
+	deltas := []Delta{
+		{Step: 1, TurnID: "t1", Trigger: TriggerUserQuery},
+		{Step: 2, TurnID: "t2", Trigger: TriggerUserQuery},
+		historyRollback(3, HistoryUndo, 1),
+		{Step: 4, TurnID: "expand", Trigger: TriggerWidgetAction},
+		{Step: 5, TurnID: "back", Trigger: TriggerWidgetAction},
+	}
+	h := BuildStateHistory(deltas)
+	if target, ok := h.RedoTarget(); !ok || target != 2 {
+		t.Errorf("expand and back should keep redo of step 2, got %+v", h)
+	}
+	if h.Current() != 5 {
+		t.Errorf("navigation still adds screens, got current %d", h.Current())
+	}
+
+	// The query's later deltas are what matters, not the first one of its turn
+	h = BuildStateHistory(append(deltas,
+		Delta{Step: 6, TurnID: "t3", Trigger: TriggerSystem},
+		Delta{Step: 7, TurnID: "t3", Trigger: TriggerUserQuery},
+	))
+	if h.CanRedo() {
+		t.Errorf("a query turn should clear redo, got %+v", h)
+	}
+}
+
+func TestBuildStateHistory_Goto(t *testing.T) {
+	h := BuildStateHistory([]Delta{
+		{Step: 1, TurnID: "t1"},
+		{Step: 2, TurnID: "t2"},
+		historyRollback(3, HistoryUndo, 1),
+		historyRollback(4, HistoryGoto, 2),
+	})
+	if h.Current() != 2 || h.CanRedo() {
+		t.Errorf("goto should show step 2 and clear redo, got %+v", h)
+	}
+	if target, _ := h.UndoTarget(); target != 1 {
+		t.Errorf("undo after goto should return to the screen before it, got %d", target)
+	}
+
+	// A plain rollback (no history action) is a goto
+	h = BuildStateHistory([]Delta{{Step: 1}, {Step: 2}, {Step: 3, DeltaType: DeltaTypeRollback,
+		Action: Action{Params: map[string]interface{}{"to_step": 1}}}})
+	if h.Current() != 1 || len(h.Past) != 4 {
+		t.Errorf("rollback should count as goto, got %+v", h)
+	}
+
+	h = BuildStateHistory(nil)
+	if h.CanUndo() || h.Current() != 0 {
+		t.Errorf("empty session has nothing to undo, got %+v", h)
+	}
+}
+
+func TestConversationUpToStep(t *testing.T) {
+	history := []LLMMessage{
+		{Role: "user", Content: "<catalog>…</catalog>"}, // session seed, no turn
+		{Role: "assistant", Content: "ok"},
+		{Role: "user", Content: "кремы", TurnID: "t1"},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "c1", Name: "catalog_search"}}, TurnID: "t1"},
+		{Role: "user", ToolResult: &ToolResult{ToolUseID: "c1", Content: "ok"}, TurnID: "t1"},
+		{Role: "user", Content: "привет", TurnID: "t2"}, // answered in text, wrote no delta
+		{Role: "user", Content: "подешевле", TurnID: "t3"},
+		{Role: "user", Content: "сыворотки", TurnID: "t4"},
+	}
+	deltas := []Delta{
+		{Step: 1, TurnID: "t1"}, {Step: 2, TurnID: "t1"},
+		{Step: 3, TurnID: "t3"}, {Step: 4, TurnID: "t3"},
+		{Step: 5, TurnID: "t4"},
+	}
+
+	got := ConversationUpToStep(history, deltas, 2)
+	if len(got) != 6 || got[5].Content != "привет" {
+		t.Errorf("step 2: want seed, t1 and the delta-less t2, got %d messages %+v", len(got), got)
+	}
+	if got := ConversationUpToStep(history, deltas, 4); len(got) != 7 {
+		t.Errorf("step 4: want everything before t4, got %d messages", len(got))
+	}
+	if got := ConversationUpToStep(history, deltas, 0); len(got) != 2 {
+		t.Errorf("step 0: want only the seed, got %d messages", len(got))
+	}
+
+	// Retention dropped the seed and t1: the cut still falls on t3
+	if got := ConversationUpToStep(history[5:], deltas, 2); len(got) != 1 || got[0].TurnID != "t2" {
+		t.Errorf("trimmed history: want only t2, got %+v", got)
+	}
+}
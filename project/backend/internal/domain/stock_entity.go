@@ -11,3 +11,49 @@ type Stock struct {
 	Reserved  int       `json:"reserved"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
+
+// Available returns the sellable quantity: Quantity minus Reserved, never negative
+func (s Stock) Available() int {
+	if n := s.Quantity - s.Reserved; n > 0 {
+		return n
+	}
+	return 0
+}
+
+// Stock policies: what catalog search does with products that have no available stock
+const (
+	StockPolicyHide   = "hide"   // filtered out before ranking
+	StockPolicyDemote = "demote" // moved after all in-stock products
+	StockPolicyBadge  = "badge"  // order kept, the card says "нет в наличии"
+)
+
+// DefaultLowStockThreshold is the available quantity at or below which a card gets the "осталось N шт." badge
+const DefaultLowStockThreshold = 3
+
+// StockConfig is the tenant's stock policy.
+// Read from tenant settings: {"stock": {"policy": "hide", "low_threshold": 5}}
+type StockConfig struct {
+	Policy       string `json:"policy"`
+	LowThreshold int    `json:"lowThreshold"` // 0 = no low-stock badge
+}
+
+// StockConfigFromSettings parses settings["stock"] over defaultPolicy and DefaultLowStockThreshold.
+// Unknown policies fall back to defaultPolicy; "low_threshold": 0 turns the low-stock badge off.
+func StockConfigFromSettings(settings map[string]any, defaultPolicy string) StockConfig {
+	cfg := StockConfig{Policy: defaultPolicy, LowThreshold: DefaultLowStockThreshold}
+	raw, ok := settings["stock"].(map[string]any)
+	if !ok {
+		return cfg
+	}
+	switch p, _ := raw["policy"].(string); p {
+	case StockPolicyHide, StockPolicyDemote, StockPolicyBadge:
+		cfg.Policy = p
+	}
+	switch v := raw["low_threshold"].(type) {
+	case float64, int, int64:
+		if n := int(numberSetting(v)); n >= 0 {
+			cfg.LowThreshold = n
+		}
+	}
+	return cfg
+}
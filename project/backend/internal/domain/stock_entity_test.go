@@ -0,0 +1,46 @@
+package domain
+
+import "testing"
+
+func TestStock_Available(t *testing.T) {
+	if n := (Stock{Quantity: 5, Reserved: 2}).Available(); n != 3 {
+		t.Errorf("want 3 available, got %d", n)
+	}
+	if n := (Stock{Quantity: 1, Reserved: 4}).Available(); n != 0 {
+		t.Errorf("over-reserved stock should be 0 available, got %d", n)
+	}
+}
+
+func TestStockConfigFromSettings(t *testing.T) {
+	cfg := StockConfigFromSettings(nil, StockPolicyDemote)
+	if cfg.Policy != StockPolicyDemote || cfg.LowThreshold != DefaultLowStockThreshold {
+		t.Errorf("nil settings should give defaults, got %+v", cfg)
+	}
+
+	cfg = StockConfigFromSettings(map[string]any{
+		"stock": map[string]any{"policy": "hide", "low_threshold": float64(5)},
+	}, StockPolicyDemote)
+	if cfg.Policy != StockPolicyHide || cfg.LowThreshold != 5 {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+
+	cfg = StockConfigFromSettings(map[string]any{
+		"stock": map[string]any{"policy": "shuffle", "low_threshold": float64(0)},
+	}, StockPolicyBadge)
+	if cfg.Policy != StockPolicyBadge {
+		t.Errorf("unknown policy should fall back to default, got %q", cfg.Policy)
+	}
+	if cfg.LowThreshold != 0 {
+		t.Errorf("low_threshold 0 should turn the badge off, got %d", cfg.LowThreshold)
+	}
+}
+
+func TestProduct_AvailableStock(t *testing.T) {
+	if _, tracked := (Product{StockQuantity: 7}).AvailableStock(); tracked {
+		t.Error("product without StockAvailable should not be tracked")
+	}
+	n := 0
+	if got, tracked := (Product{StockQuantity: 2, StockAvailable: &n}).AvailableStock(); !tracked || got != 0 {
+		t.Errorf("want tracked 0, got %d tracked=%v", got, tracked)
+	}
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Synonym is a tenant-scoped vocabulary rule managed in the admin panel.
+// One-way: Term in a query adds Synonyms ("патчи" → "hydrogel eye patches").
+// Two-way: any of Term/Synonyms adds all the others ("SPF" ↔ "солнцезащитный").
+type Synonym struct {
+	ID            string   `json:"id"`
+	Term          string   `json:"term"`
+	Synonyms      []string `json:"synonyms"`
+	Bidirectional bool     `json:"bidirectional"`
+}
+
+// SynonymExpansion records the phrases a matched term added to a query
+type SynonymExpansion struct {
+	Term  string   `json:"term"`
+	Added []string `json:"added"`
+}
+
+// String formats the expansion for trace breakdown: "spf → солнцезащитный, sunscreen"
+func (e SynonymExpansion) String() string {
+	return e.Term + " → " + strings.Join(e.Added, ", ")
+}
+
+// ExpandQuery appends the synonyms of every rule term found in query (whole words,
+// case-insensitive). Phrases already in the query are not added twice.
+func ExpandQuery(query string, synonyms []Synonym) (string, []SynonymExpansion) {
+	lower := strings.ToLower(query)
+	seen := make(map[string]bool)
+	var added []string
+	var expansions []SynonymExpansion
+
+	for _, s := range synonyms {
+		group := append([]string{s.Term}, s.Synonyms...)
+		triggers := group[:1]
+		if s.Bidirectional {
+			triggers = group
+		}
+		for _, trigger := range triggers {
+			t := strings.ToLower(strings.TrimSpace(trigger))
+			if t == "" || !containsPhrase(lower, t) {
+				continue
+			}
+			var exp SynonymExpansion
+			for _, g := range group {
+				p := strings.ToLower(strings.TrimSpace(g))
+				if p == "" || p == t || seen[p] || containsPhrase(lower, p) {
+					continue
+				}
+				seen[p] = true
+				exp.Added = append(exp.Added, g)
+			}
+			if len(exp.Added) > 0 {
+				exp.Term = trigger
+				expansions = append(expansions, exp)
+				added = append(added, exp.Added...)
+			}
+		}
+	}
+
+	if len(added) == 0 {
+		return query, nil
+	}
+	return query + " " + strings.Join(added, " "), expansions
+}
+
+// containsPhrase reports whether phrase occurs in text on word boundaries (both lowercased)
+func containsPhrase(text, phrase string) bool {
+	for from := 0; from <= len(text)-len(phrase); {
+		i := strings.Index(text[from:], phrase)
+		if i < 0 {
+			return false
+		}
+		start, end := from+i, from+i+len(phrase)
+		if !wordRuneBefore(text, start) && !wordRuneAfter(text, end) {
+			return true
+		}
+		from = start + 1
+	}
+	return false
+}
+
+func wordRuneBefore(text string, i int) bool {
+	if i == 0 {
+		return false
+	}
+	r := []rune(text[:i])
+	return isWordRune(r[len(r)-1])
+}
+
+func wordRuneAfter(text string, i int) bool {
+	for _, r := range text[i:] {
+		return isWordRune(r)
+	}
+	return false
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandQuery_TwoWay(t *testing.T) {
+	rules := []Synonym{{Term: "SPF", Synonyms: []string{"солнцезащитный", "sunscreen"}, Bidirectional: true}}
+
+	got, exp := ExpandQuery("крем spf 50", rules)
+	if got != "крем spf 50 солнцезащитный sunscreen" {
+		t.Errorf("unexpected query: %q", got)
+	}
+	if len(exp) != 1 || exp[0].Term != "SPF" {
+		t.Fatalf("unexpected expansions: %+v", exp)
+	}
+
+	// Reverse direction: a synonym adds the term and the other synonyms
+	got, exp = ExpandQuery("Солнцезащитный крем", rules)
+	if got != "Солнцезащитный крем SPF sunscreen" {
+		t.Errorf("unexpected query: %q", got)
+	}
+	if len(exp) != 1 || !reflect.DeepEqual(exp[0].Added, []string{"SPF", "sunscreen"}) {
+		t.Errorf("unexpected expansions: %+v", exp)
+	}
+}
+
+func TestExpandQuery_OneWay(t *testing.T) {
+	rules := []Synonym{{Term: "патчи", Synonyms: []string{"hydrogel eye patches"}}}
+
+	got, exp := ExpandQuery("патчи под глаза", rules)
+	if got != "патчи под глаза hydrogel eye patches" || len(exp) != 1 {
+		t.Errorf("unexpected expansion: %q %+v", got, exp)
+	}
+
+	// Synonym side does not trigger a one-way rule
+	got, exp = ExpandQuery("hydrogel eye patches", rules)
+	if got != "hydrogel eye patches" || exp != nil {
+		t.Errorf("one-way rule expanded in reverse: %q %+v", got, exp)
+	}
+}
+
+func TestExpandQuery_WholeWordsOnly(t *testing.T) {
+	rules := []Synonym{{Term: "spf", Synonyms: []string{"sunscreen"}, Bidirectional: true}}
+
+	got, exp := ExpandQuery("spfx тональный", rules)
+	if got != "spfx тональный" || exp != nil {
+		t.Errorf("partial word must not match: %q %+v", got, exp)
+	}
+}
+
+func TestExpandQuery_NoDuplicates(t *testing.T) {
+	rules := []Synonym{
+		{Term: "spf", Synonyms: []string{"sunscreen"}, Bidirectional: true},
+		{Term: "солнцезащитный", Synonyms: []string{"sunscreen"}, Bidirectional: true},
+	}
+
+	got, exp := ExpandQuery("spf солнцезащитный", rules)
+	if got != "spf солнцезащитный sunscreen" {
+		t.Errorf("unexpected query: %q", got)
+	}
+	if len(exp) != 1 {
+		t.Errorf("want 1 expansion, got %+v", exp)
+	}
+}
+
+func TestSynonymExpansion_String(t *testing.T) {
+	e := SynonymExpansion{Term: "spf", Added: []string{"солнцезащитный", "sunscreen"}}
+	if e.String() != "spf → солнцезащитный, sunscreen" {
+		t.Errorf("unexpected: %q", e.String())
+	}
+}
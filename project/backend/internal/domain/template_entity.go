@@ -71,4 +71,5 @@ type FormationWithData struct {
 	Config     *RenderConfig     `json:"config,omitempty"`
 	Sections   []FormationSection `json:"sections,omitempty"`
 	Pagination *PaginationMeta   `json:"pagination,omitempty"`
+	FacetBar   *Widget           `json:"facetBar,omitempty"` // refinement chips from StateMeta.Facets
 }
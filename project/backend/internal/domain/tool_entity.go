@@ -1,5 +1,7 @@
 package domain
 
+import "regexp"
+
 // ToolDefinition describes a tool for the LLM
 type ToolDefinition struct {
 	Name        string                 `json:"name"`
@@ -20,6 +22,7 @@ type ToolResult struct {
 	Content   string                 `json:"content"` // "ok", "empty", or error message
 	IsError   bool                   `json:"is_error,omitempty"`
 	Metadata  map[string]interface{} `json:"metadata,omitempty"` // Internal breakdown for tracing
+	Usage     *LLMUsage              `json:"-"`                  // LLM calls made inside the tool (e.g. rerank judge); added to the agent's usage
 }
 
 // LLMMessage represents a message in conversation (extended for tools)
@@ -28,6 +31,7 @@ type LLMMessage struct {
 	Content    string      `json:"content,omitempty"`
 	ToolCalls  []ToolCall  `json:"tool_calls,omitempty"`  // For assistant
 	ToolResult *ToolResult `json:"tool_result,omitempty"` // For user (tool_result)
+	TurnID     string      `json:"turn_id,omitempty"`     // turn that appended it; "" = session seed (undo and fork cut the history by turn)
 }
 
 // LLMResponse represents response from LLM with potential tool calls
@@ -49,22 +53,67 @@ type LLMUsage struct {
 	CacheReadInputTokens     int     `json:"cache_read_input_tokens,omitempty"`
 }
 
+// Add accumulates usage of another LLM call (multi-step agents). Model is taken from the latest call.
+func (u *LLMUsage) Add(other LLMUsage) {
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.TotalTokens += other.TotalTokens
+	u.CostUSD += other.CostUSD
+	u.CacheCreationInputTokens += other.CacheCreationInputTokens
+	u.CacheReadInputTokens += other.CacheReadInputTokens
+	if other.Model != "" {
+		u.Model = other.Model
+	}
+}
+
 // LLM pricing per million tokens (as of 2024)
 // Source: https://platform.claude.com/docs/en/about-claude/pricing
-var LLMPricing = map[string]struct {
-	InputPerMillion  float64
-	OutputPerMillion float64
-}{
+var LLMPricing = map[string]ModelPricing{
 	"claude-haiku-4-5-20251001":  {1.0, 5.0},   // Haiku 4.5
 	"claude-sonnet-4-5-20251014": {3.0, 15.0},  // Sonnet 4.5
+	"claude-sonnet-4-5-20250929": {3.0, 15.0},  // Sonnet 4.5
+	"claude-sonnet-4-20250514":   {3.0, 15.0},  // Sonnet 4
+	"claude-opus-4-1-20250805":   {15.0, 75.0}, // Opus 4.1
 	"claude-opus-4-5-20251101":   {5.0, 25.0},  // Opus 4.5
 	"claude-3-5-sonnet-20241022": {3.0, 15.0},  // Sonnet 3.5
 	"claude-3-haiku-20240307":    {0.25, 1.25}, // Haiku 3
 }
 
+// ModelPricing is USD per million input/output tokens
+type ModelPricing struct {
+	InputPerMillion  float64
+	OutputPerMillion float64
+}
+
+// modelDateSuffix matches the snapshot date of versioned model IDs ("-20251001")
+var modelDateSuffix = regexp.MustCompile(`-\d{8}$`)
+
+// PricingForModel looks up pricing by exact model ID, then by model family
+// ignoring the snapshot date ("claude-sonnet-4-5" → the latest "claude-sonnet-4-5-YYYYMMDD").
+func PricingForModel(model string) (ModelPricing, bool) {
+	if p, ok := LLMPricing[model]; ok {
+		return p, true
+	}
+	family := modelDateSuffix.ReplaceAllString(model, "")
+	if family == "" {
+		return ModelPricing{}, false
+	}
+	// Map order is random: pick the newest snapshot of the family so the price is stable
+	latest := ""
+	for id := range LLMPricing {
+		if modelDateSuffix.ReplaceAllString(id, "") == family && id > latest {
+			latest = id
+		}
+	}
+	if latest == "" {
+		return ModelPricing{}, false
+	}
+	return LLMPricing[latest], true
+}
+
 // CalculateCost calculates USD cost for token usage including cache pricing
 func (u *LLMUsage) CalculateCost() float64 {
-	pricing, ok := LLMPricing[u.Model]
+	pricing, ok := PricingForModel(u.Model)
 	if !ok {
 		// Default to Haiku pricing if unknown model
 		pricing = LLMPricing["claude-haiku-4-5-20251001"]
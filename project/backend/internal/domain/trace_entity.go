@@ -4,11 +4,18 @@ import "time"
 
 // PipelineTrace captures the full trace of one pipeline execution
 type PipelineTrace struct {
-	ID        string    `json:"id"`
-	SessionID string    `json:"sessionId"`
-	Query     string    `json:"query"`
-	TurnID    string    `json:"turnId"`
-	Timestamp time.Time `json:"timestamp"`
+	ID         string    `json:"id"`
+	SessionID  string    `json:"sessionId"`
+	TenantSlug string    `json:"tenantSlug,omitempty"`
+	Query      string    `json:"query"`
+	TurnID     string    `json:"turnId"`
+	Timestamp  time.Time `json:"timestamp"`
+
+	// Prompt registry versions used in this run (name → version, 0 = built-in)
+	PromptVersions map[string]int `json:"promptVersions,omitempty"`
+
+	// Response cache lookup (nil = cache disabled)
+	ResponseCache *ResponseCacheTrace `json:"responseCache,omitempty"`
 
 	// Agent1
 	Agent1 *AgentTrace `json:"agent1,omitempty"`
@@ -31,12 +38,38 @@ type PipelineTrace struct {
 	Spans []Span `json:"spans,omitempty"`
 }
 
+// TotalTokens sums all LLM tokens of both agents (input, output, cache read/write).
+// This is the number charged against tenant token quotas.
+func (t *PipelineTrace) TotalTokens() int64 {
+	var total int64
+	for _, a := range []*AgentTrace{t.Agent1, t.Agent2} {
+		if a == nil {
+			continue
+		}
+		total += int64(a.InputTokens + a.OutputTokens + a.CacheRead + a.CacheWrite)
+	}
+	return total
+}
+
+// ResponseCacheTrace records the response cache lookup and store for one run
+type ResponseCacheTrace struct {
+	Hit          bool    `json:"hit"`
+	Match        string  `json:"match,omitempty"` // "exact" | "semantic"
+	Similarity   float64 `json:"similarity,omitempty"`
+	EntryID      string  `json:"entryId,omitempty"`
+	StateKey     string  `json:"stateKey"`
+	LookupMs     int64   `json:"lookupMs"`
+	SavedCostUSD float64 `json:"savedCostUsd,omitempty"` // cost of the run that produced the entry
+	Stored       bool    `json:"stored,omitempty"`       // miss whose result was written to the cache
+	Error        string  `json:"error,omitempty"`
+}
+
 // AgentTrace captures one agent's execution
 type AgentTrace struct {
-	Name     string `json:"name"` // "agent1" or "agent2"
-	LLMMs    int64  `json:"llmMs"`
-	ToolMs   int64  `json:"toolMs,omitempty"`
-	TotalMs  int    `json:"totalMs"`
+	Name       string `json:"name"` // "agent1" or "agent2"
+	LLMMs      int64  `json:"llmMs"`
+	ToolMs     int64  `json:"toolMs,omitempty"`
+	TotalMs    int    `json:"totalMs"`
 	StopReason string `json:"stopReason,omitempty"`
 
 	// LLM
@@ -60,11 +93,39 @@ type AgentTrace struct {
 	ToolResult    string                 `json:"toolResult,omitempty"`
 	ToolBreakdown map[string]interface{} `json:"toolBreakdown,omitempty"` // Internal tool breakdown (normalize, fallback, etc.)
 
+	// Agent loop iterations (Agent1): one entry per LLM call with the tools it requested.
+	// Tool* fields above keep the primary (last state-writing) tool for quick checks.
+	Steps []AgentStep `json:"steps,omitempty"`
+
 	// Agent2-specific
 	PromptSent  string `json:"promptSent,omitempty"`
 	RawResponse string `json:"rawResponse,omitempty"`
 }
 
+// AgentStep captures one iteration of an agent loop: LLM call + tool executions
+type AgentStep struct {
+	Step         int         `json:"step"`            // 1-based
+	Model        string      `json:"model,omitempty"` // model that answered (after routing/fallback)
+	LLMMs        int64       `json:"llmMs"`
+	ToolMs       int64       `json:"toolMs,omitempty"` // wall time; parallel tools overlap
+	InputTokens  int         `json:"inputTokens"`
+	OutputTokens int         `json:"outputTokens"`
+	CostUSD      float64     `json:"costUsd"`
+	StopReason   string      `json:"stopReason,omitempty"`
+	Text         string      `json:"text,omitempty"`
+	Tools        []ToolTrace `json:"tools,omitempty"`
+}
+
+// ToolTrace captures one tool execution inside an agent step
+type ToolTrace struct {
+	Name      string                 `json:"name"`
+	Input     string                 `json:"input,omitempty"`
+	Result    string                 `json:"result,omitempty"`
+	IsError   bool                   `json:"isError,omitempty"`
+	Ms        int64                  `json:"ms"`
+	Breakdown map[string]interface{} `json:"breakdown,omitempty"`
+}
+
 // StateSnapshot captures state at a point in the pipeline
 type StateSnapshot struct {
 	ProductCount int               `json:"productCount"`
@@ -16,6 +16,8 @@ const (
 const (
 	WidgetTemplateProductCard       = "ProductCard"
 	WidgetTemplateProductComparison = "ProductComparison"
+	WidgetTemplateFacetBar          = "FacetBar"   // refinement chips above the results
+	WidgetTemplateFacetGroup        = "FacetGroup" // one facet inside FacetBar
 )
 
 // WidgetSize defines widget size constraints
@@ -213,6 +213,68 @@ func toFloat(v interface{}) (float64, bool) {
 	}
 }
 
+// --- Stock Styling ---
+
+// StockStatusField is the field name of the stock badge atom added by ApplyStockStyling
+const StockStatusField = "stockStatus"
+
+// ApplyStockStyling adds a stock badge to product widgets: "Нет в наличии" (badge-error) when
+// nothing is available and "Осталось N шт." (badge-warning) at or below lowThreshold (0 = off).
+// Out-of-stock widgets also get Meta["out_of_stock"] so the card can be dimmed.
+// Products without a stock row (StockAvailable nil) get no badge. The badge goes first and W1 (max 2 badges)
+// is re-applied, so the stock badge wins over the widget's own badges.
+func ApplyStockStyling(widgets []domain.Widget, products []domain.Product, lowThreshold int) {
+	available := make(map[string]int, len(products))
+	for _, p := range products {
+		if n, tracked := p.AvailableStock(); tracked {
+			available[p.ID] = n
+		}
+	}
+	for wi := range widgets {
+		w := &widgets[wi]
+		if w.EntityRef == nil || w.EntityRef.Type != domain.EntityTypeProduct {
+			continue
+		}
+		n, ok := available[w.EntityRef.ID]
+		if !ok {
+			continue
+		}
+		var text, display string
+		switch {
+		case n <= 0:
+			text, display = "Нет в наличии", "badge-error"
+			if w.Meta == nil {
+				w.Meta = make(map[string]interface{})
+			}
+			w.Meta["out_of_stock"] = true
+		case n <= lowThreshold:
+			text, display = fmt.Sprintf("Осталось %d шт.", n), "badge-warning"
+		default:
+			continue
+		}
+		badge := domain.Atom{
+			Type:      domain.AtomTypeText,
+			Subtype:   domain.SubtypeString,
+			Display:   display,
+			Value:     text,
+			FieldName: StockStatusField,
+			Slot:      domain.AtomSlotBadge,
+			Meta:      map[string]interface{}{"conditional_display": display},
+		}
+		w.Atoms = append([]domain.Atom{badge}, w.Atoms...)
+
+		badgeCount := 0
+		for ai := range w.Atoms {
+			if strings.HasPrefix(w.Atoms[ai].Display, "badge") {
+				badgeCount++
+				if badgeCount > 2 {
+					w.Atoms[ai].Display = "tag"
+				}
+			}
+		}
+	}
+}
+
 // BuildComposedFormation builds a multi-section formation from compose[] input
 func BuildComposedFormation(presetRegistry *presets.PresetRegistry, composeRaw []interface{}, products []domain.Product, services []domain.Service, displayOverrides map[string]string, formatOverrides map[string]string, template string, size domain.WidgetSize, entityType string) *domain.FormationWithData {
 	sections := make([]domain.FormationSection, 0, len(composeRaw))
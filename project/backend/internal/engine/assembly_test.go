@@ -0,0 +1,85 @@
+package engine
+
+import (
+	"testing"
+
+	"keepstar/internal/domain"
+)
+
+func TestApplyStockStyling(t *testing.T) {
+	stock := func(n int) *int { return &n }
+	products := testProducts(4)
+	products[0].StockAvailable = stock(0)
+	products[1].StockAvailable = stock(2)
+	products[2].StockAvailable = stock(50)
+	// products[3]: stock not tracked
+
+	widgets := make([]domain.Widget, len(products))
+	for i, p := range products {
+		widgets[i] = domain.Widget{
+			Atoms:     []domain.Atom{{Type: domain.AtomTypeText, Value: p.Name, FieldName: "name"}},
+			EntityRef: &domain.EntityRef{Type: domain.EntityTypeProduct, ID: p.ID},
+		}
+	}
+
+	ApplyStockStyling(widgets, products, 3)
+
+	first := widgets[0].Atoms[0]
+	if first.FieldName != StockStatusField || first.Value != "Нет в наличии" || first.Display != "badge-error" {
+		t.Errorf("out of stock: unexpected badge %+v", first)
+	}
+	if first.Meta["conditional_display"] != "badge-error" {
+		t.Errorf("out of stock badge should carry conditional_display, got %v", first.Meta)
+	}
+	if widgets[0].Meta["out_of_stock"] != true {
+		t.Error("out of stock widget should be marked in meta")
+	}
+
+	low := widgets[1].Atoms[0]
+	if low.Value != "Осталось 2 шт." || low.Display != "badge-warning" {
+		t.Errorf("low stock: unexpected badge %+v", low)
+	}
+	if widgets[1].Meta["out_of_stock"] != nil {
+		t.Error("low stock widget is not out of stock")
+	}
+
+	for _, i := range []int{2, 3} {
+		if len(widgets[i].Atoms) != 1 {
+			t.Errorf("widget %d should get no stock badge, got %+v", i, widgets[i].Atoms)
+		}
+	}
+}
+
+func TestApplyStockStyling_KeepsTwoBadges(t *testing.T) {
+	zero := 0
+	products := []domain.Product{{ID: "p1", StockAvailable: &zero}}
+	widgets := []domain.Widget{{
+		Atoms: []domain.Atom{
+			{Type: domain.AtomTypeText, Value: "Хит", Display: "badge"},
+			{Type: domain.AtomTypeText, Value: "-20%", Display: "badge-success"},
+		},
+		EntityRef: &domain.EntityRef{Type: domain.EntityTypeProduct, ID: "p1"},
+	}}
+
+	ApplyStockStyling(widgets, products, 0)
+
+	atoms := widgets[0].Atoms
+	if len(atoms) != 3 || atoms[0].Display != "badge-error" {
+		t.Fatalf("stock badge should go first, got %+v", atoms)
+	}
+	if atoms[1].Display != "badge" || atoms[2].Display != "tag" {
+		t.Errorf("third badge should become a tag, got %q, %q", atoms[1].Display, atoms[2].Display)
+	}
+}
+
+func TestApplyStockStyling_LowThresholdOff(t *testing.T) {
+	one := 1
+	products := []domain.Product{{ID: "p1", StockAvailable: &one}}
+	widgets := []domain.Widget{{EntityRef: &domain.EntityRef{Type: domain.EntityTypeProduct, ID: "p1"}}}
+
+	ApplyStockStyling(widgets, products, 0)
+
+	if len(widgets[0].Atoms) != 0 {
+		t.Errorf("threshold 0 should add no low-stock badge, got %+v", widgets[0].Atoms)
+	}
+}
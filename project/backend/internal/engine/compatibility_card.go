@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"fmt"
+
+	"keepstar/internal/domain"
+)
+
+// compatibilityBadges maps interaction severity to the badge shown on a warning
+var compatibilityBadges = map[string]struct {
+	label   string
+	display domain.AtomDisplay
+}{
+	domain.InteractionAvoid:   {"Не сочетать", domain.DisplayBadgeError},
+	domain.InteractionCaution: {"С осторожностью", domain.DisplayBadgeWarning},
+}
+
+// BuildCompatibilityCard turns an ingredient compatibility report into an info card:
+// a title, then per warning a severity badge, the product pair with the clashing
+// ingredients, why and how to combine. No warnings → a "no known conflicts" card.
+// Returns nil for a nil report.
+func BuildCompatibilityCard(report *domain.CompatibilityReport) *domain.Widget {
+	if report == nil {
+		return nil
+	}
+	text := func(display domain.AtomDisplay, slot domain.AtomSlot, value string) domain.Atom {
+		return domain.Atom{
+			Type:    domain.AtomTypeText,
+			Subtype: domain.SubtypeString,
+			Display: string(display),
+			Slot:    slot,
+			Value:   value,
+		}
+	}
+
+	title := "Можно сочетать"
+	if len(report.Warnings) > 0 {
+		title = "Совместимость ингредиентов"
+	}
+	atoms := []domain.Atom{text(domain.DisplayH3, domain.AtomSlotTitle, title)}
+	if len(report.Warnings) == 0 {
+		atoms = append(atoms, text(domain.DisplayBodySm, domain.AtomSlotSecondary,
+			fmt.Sprintf("Известных конфликтов ингредиентов нет (проверено средств: %d)", report.Checked)))
+	}
+	for _, w := range report.Warnings {
+		badge, ok := compatibilityBadges[w.Severity]
+		if !ok {
+			badge = compatibilityBadges[domain.InteractionCaution]
+		}
+		atoms = append(atoms,
+			text(badge.display, domain.AtomSlotBadge, badge.label),
+			text(domain.DisplayBody, domain.AtomSlotSecondary,
+				fmt.Sprintf("%s (%s) + %s (%s)", w.ProductA, w.IngredientA, w.ProductB, w.IngredientB)),
+			text(domain.DisplayBodySm, domain.AtomSlotSecondary, w.Message),
+		)
+		if w.Advice != "" {
+			atoms = append(atoms, text(domain.DisplayCaption, domain.AtomSlotSecondary, w.Advice))
+		}
+	}
+
+	return &domain.Widget{
+		ID:    "compatibility",
+		Type:  domain.WidgetTypeTextBlock,
+		Atoms: atoms,
+		Meta: map[string]interface{}{
+			"compatibility": true,
+			"warnings":      len(report.Warnings),
+		},
+	}
+}
+
+// ApplyCompatibilityCard puts the compatibility card in front of the formation's widgets
+// (and as a first "Совместимость" section when the formation is sectioned, e.g. a routine).
+func ApplyCompatibilityCard(formation *domain.FormationWithData, report *domain.CompatibilityReport) {
+	card := BuildCompatibilityCard(report)
+	if formation == nil || card == nil {
+		return
+	}
+	formation.Widgets = append([]domain.Widget{*card}, formation.Widgets...)
+	if len(formation.Sections) > 0 {
+		formation.Sections = append([]domain.FormationSection{{
+			Mode:    domain.FormationTypeSingle,
+			Widgets: []domain.Widget{*card},
+			Label:   "Совместимость",
+		}}, formation.Sections...)
+	}
+}
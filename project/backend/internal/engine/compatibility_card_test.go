@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"testing"
+
+	"keepstar/internal/domain"
+)
+
+func TestBuildCompatibilityCard_Warnings(t *testing.T) {
+	card := BuildCompatibilityCard(&domain.CompatibilityReport{
+		Checked: 2,
+		Warnings: []domain.InteractionWarning{{
+			Severity: domain.InteractionAvoid,
+			Message:  "Ретиноиды и кислоты вместе усиливают раздражение",
+			Advice:   "Кислоты утром, ретиноид вечером",
+			ProductA: "Retinol Cream", IngredientA: "Retinol",
+			ProductB: "BHA Toner", IngredientB: "Salicylic Acid",
+		}},
+	})
+	if card == nil || card.Type != domain.WidgetTypeTextBlock {
+		t.Fatalf("want text block card, got %+v", card)
+	}
+	if len(card.Atoms) != 5 {
+		t.Fatalf("want title, badge, pair, message, advice; got %d atoms", len(card.Atoms))
+	}
+	if a := card.Atoms[1]; a.Display != string(domain.DisplayBadgeError) || a.Value != "Не сочетать" {
+		t.Errorf("avoid should be an error badge, got %+v", a)
+	}
+	if a := card.Atoms[2]; a.Value != "Retinol Cream (Retinol) + BHA Toner (Salicylic Acid)" {
+		t.Errorf("unexpected pair text %v", a.Value)
+	}
+}
+
+func TestApplyCompatibilityCard_NoConflictsAndSections(t *testing.T) {
+	formation := &domain.FormationWithData{
+		Widgets:  []domain.Widget{{ID: "w1"}},
+		Sections: []domain.FormationSection{{Label: "Утро · 1. Очищение", Widgets: []domain.Widget{{ID: "w1"}}}},
+	}
+	ApplyCompatibilityCard(formation, &domain.CompatibilityReport{Checked: 3})
+
+	if len(formation.Widgets) != 2 || formation.Widgets[0].ID != "compatibility" {
+		t.Fatalf("card should lead the widgets, got %+v", formation.Widgets)
+	}
+	if formation.Widgets[0].Atoms[0].Value != "Можно сочетать" {
+		t.Errorf("clean report should say the products can be combined, got %v", formation.Widgets[0].Atoms[0].Value)
+	}
+	if len(formation.Sections) != 2 || formation.Sections[0].Label != "Совместимость" {
+		t.Errorf("card should be the first section of a sectioned formation, got %+v", formation.Sections)
+	}
+
+	ApplyCompatibilityCard(formation, nil)
+	if len(formation.Widgets) != 2 {
+		t.Error("nil report should not add a card")
+	}
+}
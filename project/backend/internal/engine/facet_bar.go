@@ -0,0 +1,95 @@
+package engine
+
+import (
+	"fmt"
+
+	"keepstar/internal/domain"
+)
+
+// facetLabels are the group captions shown in the facet bar
+var facetLabels = map[string]string{
+	domain.FacetBrand:       "Бренд",
+	domain.FacetCategory:    "Категория",
+	domain.FacetPrice:       "Цена",
+	domain.FacetProductForm: "Форма",
+	domain.FacetSkinType:    "Тип кожи",
+	domain.FacetConcern:     "Проблема",
+}
+
+// facetChipsVisible is how many chips a group shows before folding
+const facetChipsVisible = 5
+
+// BuildFacetBar turns facet counts into a FacetBar widget: one FacetGroup child per facet,
+// one tag atom per value ("CeraVe (12)"). Atom meta carries field/value/count (and min/max
+// for price buckets) so a click can be sent back as a filter.
+// Facets with fewer than two values offer no refinement and are skipped; returns nil if none remain.
+func BuildFacetBar(facets []domain.Facet) *domain.Widget {
+	var groups []domain.Widget
+	for _, f := range facets {
+		if len(f.Values) < 2 {
+			continue
+		}
+		atoms := make([]domain.Atom, 0, len(f.Values))
+		indices := make([]int, 0, len(f.Values))
+		for i, v := range f.Values {
+			meta := map[string]interface{}{
+				"field": f.Field,
+				"value": v.Value,
+				"count": v.Count,
+			}
+			if f.Field == domain.FacetPrice {
+				meta["min"] = v.Min
+				meta["max"] = v.Max
+			}
+			atoms = append(atoms, domain.Atom{
+				Type:    domain.AtomTypeText,
+				Subtype: domain.SubtypeString,
+				Display: string(domain.DisplayTag),
+				Value:   fmt.Sprintf("%s (%d)", facetValueLabel(f.Field, v), v.Count),
+				Meta:    meta,
+			})
+			indices = append(indices, i)
+		}
+		label := facetLabels[f.Field]
+		if label == "" {
+			label = f.Field
+		}
+		groups = append(groups, domain.Widget{
+			ID:       "facet-" + f.Field,
+			Template: domain.WidgetTemplateFacetGroup,
+			Priority: len(groups),
+			Atoms:    atoms,
+			Zones: []domain.Zone{{
+				Type:        domain.ZoneFlow,
+				AtomIndices: indices,
+				MaxVisible:  facetChipsVisible,
+				FoldLabel:   "ещё",
+			}},
+			Meta: map[string]interface{}{"field": f.Field, "label": label},
+		})
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+	return &domain.Widget{
+		ID:       "facets",
+		Template: domain.WidgetTemplateFacetBar,
+		Atoms:    []domain.Atom{},
+		Children: groups,
+	}
+}
+
+// facetValueLabel renders a facet value for a chip; price buckets become ruble ranges
+func facetValueLabel(field string, v domain.FacetValue) string {
+	if field != domain.FacetPrice {
+		return v.Value
+	}
+	switch {
+	case v.Min == 0:
+		return fmt.Sprintf("до %d ₽", v.Max)
+	case v.Max == 0:
+		return fmt.Sprintf("от %d ₽", v.Min)
+	default:
+		return fmt.Sprintf("%d–%d ₽", v.Min, v.Max)
+	}
+}
@@ -0,0 +1,68 @@
+package engine
+
+import (
+	"testing"
+
+	"keepstar/internal/domain"
+)
+
+func TestBuildFacetBar_GroupsAndChips(t *testing.T) {
+	facets := []domain.Facet{
+		{Field: domain.FacetBrand, Values: []domain.FacetValue{
+			{Value: "CeraVe", Count: 12},
+			{Value: "La Roche-Posay", Count: 8},
+		}},
+		{Field: domain.FacetCategory, Values: []domain.FacetValue{
+			{Value: "Кремы", Count: 20}, // single value — no refinement
+		}},
+		{Field: domain.FacetPrice, Values: []domain.FacetValue{
+			domain.FacetPriceBucket(0),
+			domain.FacetPriceBucket(len(domain.FacetPriceEdges)),
+		}},
+	}
+	facets[2].Values[0].Count = 5
+	facets[2].Values[1].Count = 3
+
+	bar := BuildFacetBar(facets)
+	if bar == nil {
+		t.Fatal("expected facet bar")
+	}
+	if bar.Template != domain.WidgetTemplateFacetBar {
+		t.Errorf("want template FacetBar, got %s", bar.Template)
+	}
+	if len(bar.Children) != 2 {
+		t.Fatalf("want 2 groups (category skipped), got %d", len(bar.Children))
+	}
+
+	brand := bar.Children[0]
+	if brand.Meta["field"] != domain.FacetBrand || brand.Meta["label"] != "Бренд" {
+		t.Errorf("unexpected brand group meta: %v", brand.Meta)
+	}
+	if got := brand.Atoms[0].Value; got != "CeraVe (12)" {
+		t.Errorf("want chip 'CeraVe (12)', got %v", got)
+	}
+	if brand.Atoms[1].Meta["value"] != "La Roche-Posay" || brand.Atoms[1].Meta["count"] != 8 {
+		t.Errorf("unexpected chip meta: %v", brand.Atoms[1].Meta)
+	}
+
+	price := bar.Children[1]
+	if got := price.Atoms[0].Value; got != "до 1000 ₽ (5)" {
+		t.Errorf("want 'до 1000 ₽ (5)', got %v", got)
+	}
+	if got := price.Atoms[1].Value; got != "от 10000 ₽ (3)" {
+		t.Errorf("want 'от 10000 ₽ (3)', got %v", got)
+	}
+	if price.Atoms[1].Meta["min"] != 10000 || price.Atoms[1].Meta["max"] != 0 {
+		t.Errorf("unexpected price meta: %v", price.Atoms[1].Meta)
+	}
+}
+
+func TestBuildFacetBar_NothingToRefine(t *testing.T) {
+	if bar := BuildFacetBar(nil); bar != nil {
+		t.Errorf("want nil for no facets, got %+v", bar)
+	}
+	single := []domain.Facet{{Field: domain.FacetBrand, Values: []domain.FacetValue{{Value: "CeraVe", Count: 3}}}}
+	if bar := BuildFacetBar(single); bar != nil {
+		t.Errorf("want nil for single-value facets, got %+v", bar)
+	}
+}
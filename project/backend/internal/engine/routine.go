@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"keepstar/internal/domain"
+)
+
+// ApplyRoutineSections lays the product widgets out as a skincare routine: one list section per
+// step ("Утро · 1. Очищение") with the widget of the picked product, and a closing "Итого" section
+// with the total price. Widgets are matched by EntityRef.ID; a product used in both routines gets
+// a widget copy per routine time. Steps without a product are skipped (listed in the total).
+func ApplyRoutineSections(formation *domain.FormationWithData, routine *domain.Routine) {
+	if formation == nil || routine == nil {
+		return
+	}
+	byID := make(map[string]domain.Widget, len(formation.Widgets))
+	for _, w := range formation.Widgets {
+		if w.EntityRef != nil {
+			byID[w.EntityRef.ID] = w
+		}
+	}
+
+	var sections []domain.FormationSection
+	for _, rs := range routine.Sections {
+		n := 0
+		for _, step := range rs.Steps {
+			w, ok := byID[step.ProductID]
+			if !ok {
+				continue
+			}
+			n++
+			w.ID = fmt.Sprintf("%s-%s", w.ID, rs.Time)
+			meta := make(map[string]interface{}, len(w.Meta)+2)
+			for k, v := range w.Meta {
+				meta[k] = v
+			}
+			meta["routine_time"] = rs.Time
+			meta["routine_step"] = step.Step
+			w.Meta = meta
+			sections = append(sections, domain.FormationSection{
+				Mode:    domain.FormationTypeList,
+				Widgets: []domain.Widget{w},
+				Label:   fmt.Sprintf("%s · %d. %s", rs.Label, n, step.Label),
+			})
+		}
+	}
+	if len(sections) == 0 {
+		return
+	}
+	sections = append(sections, domain.FormationSection{
+		Mode:    domain.FormationTypeSingle,
+		Widgets: []domain.Widget{routineTotalWidget(routine)},
+		Label:   "Итого",
+	})
+
+	// Merge all widgets into top-level for backward compat
+	var allWidgets []domain.Widget
+	for _, s := range sections {
+		allWidgets = append(allWidgets, s.Widgets...)
+	}
+	formation.Mode = domain.FormationTypeList
+	formation.Grid = nil
+	formation.Widgets = allWidgets
+	formation.Sections = sections
+}
+
+// routineTotalWidget is the summary card: total price, budget overrun and steps left empty
+func routineTotalWidget(routine *domain.Routine) domain.Widget {
+	currency := routine.Currency
+	if currency == "" {
+		currency = "$"
+	}
+	atoms := []domain.Atom{
+		{
+			Type:    domain.AtomTypeText,
+			Subtype: domain.SubtypeString,
+			Display: string(domain.DisplayH3),
+			Slot:    domain.AtomSlotTitle,
+			Value:   "Итого за уход",
+		},
+		{
+			Type:    domain.AtomTypeNumber,
+			Subtype: domain.SubtypeCurrency,
+			Display: string(domain.DisplayPriceLg),
+			Slot:    domain.AtomSlotPrice,
+			Value:   routine.Total,
+			Meta:    map[string]interface{}{"currency": currency},
+		},
+	}
+	if routine.OverBudget() {
+		atoms = append(atoms, domain.Atom{
+			Type:    domain.AtomTypeText,
+			Subtype: domain.SubtypeString,
+			Display: string(domain.DisplayBodySm),
+			Slot:    domain.AtomSlotSecondary,
+			Value:   fmt.Sprintf("Дороже бюджета на %d %s", (routine.Total-routine.Budget)/100, currency),
+		})
+	}
+	if missing := routine.Missing(); len(missing) > 0 {
+		labels := make([]string, 0, len(missing))
+		for _, m := range missing {
+			rt, step, _ := strings.Cut(m, ":")
+			labels = append(labels, domain.RoutineTimeLabel(rt)+": "+strings.ToLower(domain.RoutineStepLabel(step)))
+		}
+		atoms = append(atoms, domain.Atom{
+			Type:    domain.AtomTypeText,
+			Subtype: domain.SubtypeString,
+			Display: string(domain.DisplayBodySm),
+			Slot:    domain.AtomSlotSecondary,
+			Value:   "Не нашлось: " + strings.Join(labels, ", "),
+		})
+	}
+	return domain.Widget{
+		ID:    "routine-total",
+		Type:  domain.WidgetTypeTextBlock,
+		Atoms: atoms,
+	}
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"testing"
+
+	"keepstar/internal/domain"
+)
+
+func TestApplyRoutineSections_StepsAndTotal(t *testing.T) {
+	formation := &domain.FormationWithData{
+		Mode: domain.FormationTypeGrid,
+		Grid: &domain.GridConfig{Rows: 1, Cols: 3},
+		Widgets: []domain.Widget{
+			{ID: "w1", EntityRef: &domain.EntityRef{Type: domain.EntityTypeProduct, ID: "cl"}},
+			{ID: "w2", EntityRef: &domain.EntityRef{Type: domain.EntityTypeProduct, ID: "spf"}},
+			{ID: "w3", EntityRef: &domain.EntityRef{Type: domain.EntityTypeProduct, ID: "ret"}},
+		},
+	}
+	routine := &domain.Routine{
+		Total:    360000,
+		Currency: "₽",
+		Budget:   300000,
+		Sections: []domain.RoutineSection{
+			{Time: domain.RoutineTimeAM, Label: "Утро", Steps: []domain.RoutineStep{
+				{Step: "cleansing", Label: "Очищение", ProductID: "cl"},
+				{Step: "toning", Label: "Тоник"}, // nothing found
+				{Step: "sun-protection", Label: "SPF", ProductID: "spf"},
+			}},
+			{Time: domain.RoutineTimePM, Label: "Вечер", Steps: []domain.RoutineStep{
+				{Step: "cleansing", Label: "Очищение", ProductID: "cl"},
+				{Step: "treatment", Label: "Сыворотка", ProductID: "ret"},
+			}},
+		},
+	}
+
+	ApplyRoutineSections(formation, routine)
+
+	wantLabels := []string{"Утро · 1. Очищение", "Утро · 2. SPF", "Вечер · 1. Очищение", "Вечер · 2. Сыворотка", "Итого"}
+	if len(formation.Sections) != len(wantLabels) {
+		t.Fatalf("want %d sections, got %d", len(wantLabels), len(formation.Sections))
+	}
+	for i, want := range wantLabels {
+		if got := formation.Sections[i].Label; got != want {
+			t.Errorf("section %d: want label %q, got %q", i, want, got)
+		}
+	}
+
+	am, pm := formation.Sections[0].Widgets[0], formation.Sections[2].Widgets[0]
+	if am.ID == pm.ID {
+		t.Errorf("reused product needs distinct widget IDs, got %q twice", am.ID)
+	}
+	if am.Meta["routine_time"] != "am" || pm.Meta["routine_time"] != "pm" {
+		t.Errorf("widgets should carry their routine time, got %v / %v", am.Meta, pm.Meta)
+	}
+
+	total := formation.Sections[4].Widgets[0]
+	if total.Type != domain.WidgetTypeTextBlock {
+		t.Errorf("want text block total, got %s", total.Type)
+	}
+	var price *domain.Atom
+	for i := range total.Atoms {
+		if total.Atoms[i].Slot == domain.AtomSlotPrice {
+			price = &total.Atoms[i]
+		}
+	}
+	if price == nil || price.Value != 360000 || price.Meta["currency"] != "₽" {
+		t.Errorf("want total price atom 360000 ₽, got %+v", price)
+	}
+	if len(total.Atoms) != 4 {
+		t.Errorf("want title, price, over-budget and missing-step atoms, got %d", len(total.Atoms))
+	}
+
+	if len(formation.Widgets) != 5 || formation.Mode != domain.FormationTypeList || formation.Grid != nil {
+		t.Errorf("top-level widgets should mirror the sections as a list, got %d widgets mode %s", len(formation.Widgets), formation.Mode)
+	}
+}
+
+func TestApplyRoutineSections_NoMatchingWidgets(t *testing.T) {
+	formation := &domain.FormationWithData{
+		Mode:    domain.FormationTypeGrid,
+		Widgets: []domain.Widget{{ID: "w1", EntityRef: &domain.EntityRef{Type: domain.EntityTypeProduct, ID: "other"}}},
+	}
+	routine := &domain.Routine{Sections: []domain.RoutineSection{
+		{Time: domain.RoutineTimeAM, Label: "Утро", Steps: []domain.RoutineStep{{Step: "cleansing", ProductID: "cl"}}},
+	}}
+
+	ApplyRoutineSections(formation, routine)
+
+	if formation.Sections != nil || len(formation.Widgets) != 1 || formation.Mode != domain.FormationTypeGrid {
+		t.Error("formation should stay unchanged when no widget matches the routine")
+	}
+}
@@ -22,9 +22,18 @@ type PipelineMetrics struct {
 	Agent1Metrics *AgentMetrics  `json:"agent1"`
 	Agent2Metrics *AgentMetrics  `json:"agent2"`
 	TotalMs       int            `json:"totalMs"`
+	Cache         string         `json:"cache,omitempty"` // response cache match, empty = agents ran
 	Formation     *FormationInfo `json:"formation,omitempty"`
 }
 
+// ResponseCacheStats counts pipeline runs served from the response cache
+type ResponseCacheStats struct {
+	Hits         int     `json:"hits"`
+	SemanticHits int     `json:"semanticHits"`
+	Misses       int     `json:"misses"`
+	HitRate      float64 `json:"hitRate"` // percentage
+}
+
 // AgentMetrics stores metrics for a single agent
 type AgentMetrics struct {
 	DurationMs   int      `json:"durationMs"`
@@ -63,6 +72,7 @@ type FormationInfo struct {
 type MetricsStore struct {
 	mu      sync.RWMutex
 	metrics map[string]*PipelineMetrics // sessionID -> metrics
+	cache   ResponseCacheStats
 }
 
 // NewMetricsStore creates a new metrics store
@@ -77,6 +87,23 @@ func (s *MetricsStore) Store(m *PipelineMetrics) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.metrics[m.SessionID] = m
+	switch m.Cache {
+	case "":
+		s.cache.Misses++
+	case domain.CacheMatchSemantic:
+		s.cache.SemanticHits++
+		s.cache.Hits++
+	default:
+		s.cache.Hits++
+	}
+	s.cache.HitRate = float64(s.cache.Hits) / float64(s.cache.Hits+s.cache.Misses) * 100
+}
+
+// CacheStats returns response cache hit/miss counters since start
+func (s *MetricsStore) CacheStats() ResponseCacheStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cache
 }
 
 // Get retrieves metrics for a session
@@ -207,7 +234,8 @@ func (h *DebugHandler) HandleDebugAPI(w http.ResponseWriter, r *http.Request) {
 	sessionID := r.URL.Query().Get("session")
 	if sessionID == "" {
 		writeJSON(w, http.StatusOK, map[string]any{
-			"sessions": h.metricsStore.GetAll(),
+			"sessions":      h.metricsStore.GetAll(),
+			"responseCache": h.metricsStore.CacheStats(),
 		})
 		return
 	}
@@ -358,6 +386,12 @@ func (h *DebugHandler) HandleSeedState(w http.ResponseWriter, r *http.Request) {
 		Path:      "data.products",
 		Action:    domain.Action{Type: domain.ActionSearch, Tool: "debug_seed"},
 		Result:    domain.ResultMeta{Count: len(products), Fields: state.Current.Meta.Fields},
+		Template:  state.Current.Template,
+		Payload: &domain.DeltaPayload{
+			Data: &state.Current.Data,
+			Meta: &state.Current.Meta,
+			View: &state.View,
+		},
 		CreatedAt: time.Now(),
 	}
 	h.statePort.AddDelta(ctx, sessionID, delta)
@@ -368,9 +402,10 @@ func (h *DebugHandler) HandleSeedState(w http.ResponseWriter, r *http.Request) {
 	resp := SeedStateResponse{
 		SessionID: sessionID,
 		Formation: &FormationResponse{
-			Mode:    string(formation.Mode),
-			Grid:    formation.Grid,
-			Widgets: formation.Widgets,
+			Mode:     string(formation.Mode),
+			Grid:     formation.Grid,
+			Widgets:  formation.Widgets,
+			FacetBar: formation.FacetBar,
 		},
 		Products: len(products),
 		Message:  "Session created with mock products. Use this sessionId for navigation testing.",
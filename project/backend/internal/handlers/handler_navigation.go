@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/google/uuid"
@@ -10,11 +11,13 @@ import (
 	"keepstar/internal/usecases"
 )
 
-// NavigationHandler handles navigation requests (expand/back)
+// NavigationHandler handles navigation requests (expand/back/forward/similar)
 type NavigationHandler struct {
-	expandUC *usecases.ExpandUseCase
-	backUC   *usecases.BackUseCase
-	log      *logger.Logger
+	expandUC  *usecases.ExpandUseCase
+	backUC    *usecases.BackUseCase
+	forwardUC *usecases.ForwardUseCase // nil = forward disabled
+	similarUC *usecases.SimilarUseCase // nil = similar action disabled
+	log       *logger.Logger
 }
 
 // NewNavigationHandler creates a navigation handler
@@ -26,6 +29,18 @@ func NewNavigationHandler(expandUC *usecases.ExpandUseCase, backUC *usecases.Bac
 	}
 }
 
+// WithSimilar enables the "more like this" action
+func (h *NavigationHandler) WithSimilar(similarUC *usecases.SimilarUseCase) *NavigationHandler {
+	h.similarUC = similarUC
+	return h
+}
+
+// WithForward enables going forward to the view left by back
+func (h *NavigationHandler) WithForward(forwardUC *usecases.ForwardUseCase) *NavigationHandler {
+	h.forwardUC = forwardUC
+	return h
+}
+
 // ExpandRequest is the request body for expand
 type ExpandRequest struct {
 	SessionID  string `json:"sessionId"`
@@ -35,12 +50,14 @@ type ExpandRequest struct {
 
 // ExpandResponse is the response body for expand
 type NavigationResponse struct {
-	Success   bool               `json:"success"`
-	Formation *FormationResponse `json:"formation,omitempty"`
-	ViewMode  string             `json:"viewMode"`
-	Focused   *domain.EntityRef  `json:"focused,omitempty"`
-	StackSize int                `json:"stackSize"`
-	CanGoBack bool               `json:"canGoBack"`
+	Success      bool                `json:"success"`
+	Formation    *FormationResponse  `json:"formation,omitempty"`
+	ViewMode     string              `json:"viewMode"`
+	Focused      *domain.EntityRef   `json:"focused,omitempty"`
+	StackSize    int                 `json:"stackSize"`
+	CanGoBack    bool                `json:"canGoBack"`
+	CanGoForward bool                `json:"canGoForward"`
+	Breadcrumbs  []domain.Breadcrumb `json:"breadcrumbs,omitempty"`
 }
 
 // BackRequest is the request body for back
@@ -48,6 +65,28 @@ type BackRequest struct {
 	SessionID string `json:"sessionId"`
 }
 
+// ForwardRequest is the request body for forward
+type ForwardRequest struct {
+	SessionID string `json:"sessionId"`
+}
+
+// SimilarRequest is the request body for similar
+type SimilarRequest struct {
+	SessionID      string `json:"sessionId"`
+	EntityType     string `json:"entityType"`
+	EntityID       string `json:"entityId"`
+	Cheaper        bool   `json:"cheaper,omitempty"`
+	SameSkinType   bool   `json:"sameSkinType,omitempty"`
+	DifferentBrand bool   `json:"differentBrand,omitempty"`
+}
+
+// SimilarResponse is the response body for similar
+type SimilarResponse struct {
+	NavigationResponse
+	Found   int    `json:"found"`
+	Message string `json:"message,omitempty"`
+}
+
 // HandleExpand handles POST /api/v1/navigation/expand
 func (h *NavigationHandler) HandleExpand(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -102,18 +141,20 @@ func (h *NavigationHandler) HandleExpand(w http.ResponseWriter, r *http.Request)
 	}
 
 	resp := NavigationResponse{
-		Success:   result.Success,
-		ViewMode:  string(result.ViewMode),
-		Focused:   result.Focused,
-		StackSize: result.StackSize,
-		CanGoBack: result.StackSize > 0,
+		Success:     result.Success,
+		ViewMode:    string(result.ViewMode),
+		Focused:     result.Focused,
+		StackSize:   result.StackSize,
+		CanGoBack:   result.StackSize > 0,
+		Breadcrumbs: result.Breadcrumbs,
 	}
 
 	if result.Formation != nil {
 		resp.Formation = &FormationResponse{
-			Mode:    string(result.Formation.Mode),
-			Grid:    result.Formation.Grid,
-			Widgets: result.Formation.Widgets,
+			Mode:     string(result.Formation.Mode),
+			Grid:     result.Formation.Grid,
+			Widgets:  result.Formation.Widgets,
+			FacetBar: result.Formation.FacetBar,
 		}
 	}
 
@@ -164,18 +205,174 @@ func (h *NavigationHandler) HandleBack(w http.ResponseWriter, r *http.Request) {
 	}
 
 	resp := NavigationResponse{
-		Success:   result.Success,
-		ViewMode:  string(result.ViewMode),
-		Focused:   result.Focused,
-		StackSize: result.StackSize,
-		CanGoBack: result.CanGoBack,
+		Success:      result.Success,
+		ViewMode:     string(result.ViewMode),
+		Focused:      result.Focused,
+		StackSize:    result.StackSize,
+		CanGoBack:    result.CanGoBack,
+		CanGoForward: result.CanGoForward,
+		Breadcrumbs:  result.Breadcrumbs,
+	}
+
+	if result.Formation != nil {
+		resp.Formation = &FormationResponse{
+			Mode:     string(result.Formation.Mode),
+			Grid:     result.Formation.Grid,
+			Widgets:  result.Formation.Widgets,
+			FacetBar: result.Formation.FacetBar,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// HandleForward handles POST /api/v1/navigation/forward
+func (h *NavigationHandler) HandleForward(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("handler.forward")
+		defer endSpan()
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.forwardUC == nil {
+		http.Error(w, "forward is not available", http.StatusNotImplemented)
+		return
+	}
+
+	var req ForwardRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.SessionID == "" {
+		http.Error(w, "sessionId is required", http.StatusBadRequest)
+		return
+	}
+
+	ctx = logger.WithSessionID(ctx, req.SessionID)
+	r = r.WithContext(ctx)
+
+	turnID := uuid.New().String()
+	result, err := h.forwardUC.Execute(r.Context(), usecases.ForwardRequest{
+		SessionID: req.SessionID,
+		TurnID:    turnID,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// sync=true: frontend already has the formation, just sync backend state
+	if r.URL.Query().Get("sync") == "true" {
+		writeJSON(w, http.StatusOK, map[string]bool{"ok": true})
+		return
+	}
+
+	resp := NavigationResponse{
+		Success:      result.Success,
+		ViewMode:     string(result.ViewMode),
+		Focused:      result.Focused,
+		StackSize:    result.StackSize,
+		CanGoBack:    result.CanGoBack,
+		CanGoForward: result.CanGoForward,
+		Breadcrumbs:  result.Breadcrumbs,
+	}
+
+	if result.Formation != nil {
+		resp.Formation = &FormationResponse{
+			Mode:     string(result.Formation.Mode),
+			Grid:     result.Formation.Grid,
+			Widgets:  result.Formation.Widgets,
+			FacetBar: result.Formation.FacetBar,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// HandleSimilar handles POST /api/v1/navigation/similar
+func (h *NavigationHandler) HandleSimilar(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("handler.similar")
+		defer endSpan()
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.similarUC == nil {
+		http.Error(w, "similar is not available", http.StatusNotImplemented)
+		return
+	}
+
+	var req SimilarRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.SessionID == "" {
+		http.Error(w, "sessionId is required", http.StatusBadRequest)
+		return
+	}
+	if req.EntityID == "" {
+		http.Error(w, "entityId is required", http.StatusBadRequest)
+		return
+	}
+	if req.EntityType == "" {
+		req.EntityType = string(domain.EntityTypeProduct)
+	}
+
+	ctx = logger.WithSessionID(ctx, req.SessionID)
+	r = r.WithContext(ctx)
+
+	turnID := uuid.New().String()
+	result, err := h.similarUC.Execute(r.Context(), usecases.SimilarRequest{
+		SessionID:      req.SessionID,
+		EntityType:     domain.EntityType(req.EntityType),
+		EntityID:       req.EntityID,
+		TurnID:         turnID,
+		Cheaper:        req.Cheaper,
+		SameSkinType:   req.SameSkinType,
+		DifferentBrand: req.DifferentBrand,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrSessionNotFound), errors.Is(err, domain.ErrProductNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, domain.ErrInvalidEntityType), errors.Is(err, domain.ErrSimilarFailed):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	resp := SimilarResponse{
+		NavigationResponse: NavigationResponse{
+			Success:     result.Success,
+			ViewMode:    string(result.ViewMode),
+			StackSize:   result.StackSize,
+			CanGoBack:   result.StackSize > 0,
+			Breadcrumbs: result.Breadcrumbs,
+		},
+		Found:   result.Found,
+		Message: result.Message,
 	}
 
 	if result.Formation != nil {
 		resp.Formation = &FormationResponse{
-			Mode:    string(result.Formation.Mode),
-			Grid:    result.Formation.Grid,
-			Widgets: result.Formation.Widgets,
+			Mode:     string(result.Formation.Mode),
+			Grid:     result.Formation.Grid,
+			Widgets:  result.Formation.Widgets,
+			FacetBar: result.Formation.FacetBar,
 		}
 	}
 
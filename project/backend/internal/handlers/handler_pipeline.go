@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -50,6 +52,26 @@ type PipelineResponse struct {
 	Agent1Ms           int                            `json:"agent1Ms"`
 	Agent2Ms           int                            `json:"agent2Ms"`
 	TotalMs            int                            `json:"totalMs"`
+	Cache              string                         `json:"cache,omitempty"` // "exact" | "semantic" when served from the response cache
+}
+
+// QuotaExceededResponse is the 429 body when the tenant hit an LLM quota.
+// Formation is a graceful fallback the widget renders like a normal answer.
+type QuotaExceededResponse struct {
+	SessionID string             `json:"sessionId"`
+	Error     QuotaErrorBody     `json:"error"`
+	Formation *FormationResponse `json:"formation"`
+}
+
+// QuotaErrorBody describes which quota was exceeded
+type QuotaErrorBody struct {
+	Code    string    `json:"code"` // "RATE_LIMIT"
+	Message string    `json:"message"`
+	Period  string    `json:"period"` // "daily" | "monthly"
+	Metric  string    `json:"metric"` // "tokens" | "usd"
+	Limit   float64   `json:"limit"`
+	Used    float64   `json:"used"`
+	ResetAt time.Time `json:"resetAt"`
 }
 
 // FormationResponse is the JSON-friendly formation for HTTP response
@@ -59,6 +81,7 @@ type FormationResponse struct {
 	Widgets    []domain.Widget           `json:"widgets"`
 	Sections   []domain.FormationSection `json:"sections,omitempty"`
 	Pagination *domain.PaginationMeta    `json:"pagination,omitempty"`
+	FacetBar   *domain.Widget            `json:"facetBar,omitempty"`
 }
 
 // HandlePipeline handles POST /api/v1/pipeline
@@ -124,69 +147,90 @@ func (h *PipelineHandler) HandlePipeline(w http.ResponseWriter, r *http.Request)
 		ScreenContext: screenCtx,
 	})
 	if err != nil {
+		var quotaErr *domain.QuotaExceededError
+		if errors.As(err, &quotaErr) {
+			writeQuotaExceeded(w, sessionID, quotaErr)
+			return
+		}
+		if errors.Is(err, domain.ErrLLMUnavailable) {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Store metrics for debug page
-	if h.metricsStore != nil {
-		metrics := &PipelineMetrics{
-			SessionID: sessionID,
-			Query:     req.Query,
-			Timestamp: time.Now(),
-			TotalMs:   result.TotalMs,
-			Agent1Metrics: &AgentMetrics{
-				DurationMs:               result.Agent1Ms,
-				LLMCallMs:                result.Agent1LLMMs,
-				ToolMs:                   result.Agent1ToolMs,
-				InputTokens:              result.Agent1Usage.InputTokens,
-				OutputTokens:             result.Agent1Usage.OutputTokens,
-				TotalTokens:              result.Agent1Usage.TotalTokens,
-				CostUSD:                  result.Agent1Usage.CostUSD,
-				Model:                    result.Agent1Usage.Model,
-				CacheCreationInputTokens: result.Agent1Usage.CacheCreationInputTokens,
-				CacheReadInputTokens:     result.Agent1Usage.CacheReadInputTokens,
-				CacheHitRate:             cacheHitRate(result.Agent1Usage),
-				ToolCalled:               result.ToolCalled,
-				ToolInput:                result.ToolInput,
-				ToolResult:               result.ToolResult,
-				ProductsFound:            result.ProductsFound,
-			},
-			Agent2Metrics: &AgentMetrics{
-				DurationMs:               result.Agent2Ms,
-				LLMCallMs:                result.Agent2LLMMs,
-				InputTokens:              result.Agent2Usage.InputTokens,
-				OutputTokens:             result.Agent2Usage.OutputTokens,
-				TotalTokens:              result.Agent2Usage.TotalTokens,
-				CostUSD:                  result.Agent2Usage.CostUSD,
-				Model:                    result.Agent2Usage.Model,
-				CacheCreationInputTokens: result.Agent2Usage.CacheCreationInputTokens,
-				CacheReadInputTokens:     result.Agent2Usage.CacheReadInputTokens,
-				CacheHitRate:             cacheHitRate(result.Agent2Usage),
-				PromptSent:               result.Agent2Prompt,
-				RawResponse:              result.Agent2RawResp,
-				TemplateJSON:             result.TemplateJSON,
-				MetaCount:                result.MetaCount,
-				MetaFields:               result.MetaFields,
-			},
+	h.storeMetrics(sessionID, req.Query, result)
+
+	writeJSON(w, http.StatusOK, buildPipelineResponse(sessionID, result))
+}
+
+// storeMetrics saves pipeline metrics for the debug page
+func (h *PipelineHandler) storeMetrics(sessionID, query string, result *usecases.PipelineExecuteResponse) {
+	if h.metricsStore == nil {
+		return
+	}
+	metrics := &PipelineMetrics{
+		SessionID: sessionID,
+		Query:     query,
+		Timestamp: time.Now(),
+		TotalMs:   result.TotalMs,
+		Cache:     result.CacheMatch,
+		Agent1Metrics: &AgentMetrics{
+			DurationMs:               result.Agent1Ms,
+			LLMCallMs:                result.Agent1LLMMs,
+			ToolMs:                   result.Agent1ToolMs,
+			InputTokens:              result.Agent1Usage.InputTokens,
+			OutputTokens:             result.Agent1Usage.OutputTokens,
+			TotalTokens:              result.Agent1Usage.TotalTokens,
+			CostUSD:                  result.Agent1Usage.CostUSD,
+			Model:                    result.Agent1Usage.Model,
+			CacheCreationInputTokens: result.Agent1Usage.CacheCreationInputTokens,
+			CacheReadInputTokens:     result.Agent1Usage.CacheReadInputTokens,
+			CacheHitRate:             cacheHitRate(result.Agent1Usage),
+			ToolCalled:               result.ToolCalled,
+			ToolInput:                result.ToolInput,
+			ToolResult:               result.ToolResult,
+			ProductsFound:            result.ProductsFound,
+		},
+		Agent2Metrics: &AgentMetrics{
+			DurationMs:               result.Agent2Ms,
+			LLMCallMs:                result.Agent2LLMMs,
+			InputTokens:              result.Agent2Usage.InputTokens,
+			OutputTokens:             result.Agent2Usage.OutputTokens,
+			TotalTokens:              result.Agent2Usage.TotalTokens,
+			CostUSD:                  result.Agent2Usage.CostUSD,
+			Model:                    result.Agent2Usage.Model,
+			CacheCreationInputTokens: result.Agent2Usage.CacheCreationInputTokens,
+			CacheReadInputTokens:     result.Agent2Usage.CacheReadInputTokens,
+			CacheHitRate:             cacheHitRate(result.Agent2Usage),
+			PromptSent:               result.Agent2Prompt,
+			RawResponse:              result.Agent2RawResp,
+			TemplateJSON:             result.TemplateJSON,
+			MetaCount:                result.MetaCount,
+			MetaFields:               result.MetaFields,
+		},
+	}
+	if result.Formation != nil {
+		metrics.Formation = &FormationInfo{
+			Mode:        string(result.Formation.Mode),
+			WidgetCount: len(result.Formation.Widgets),
 		}
-		if result.Formation != nil {
-			metrics.Formation = &FormationInfo{
-				Mode:        string(result.Formation.Mode),
-				WidgetCount: len(result.Formation.Widgets),
-			}
-			if result.Formation.Grid != nil {
-				metrics.Formation.Cols = result.Formation.Grid.Cols
-			}
+		if result.Formation.Grid != nil {
+			metrics.Formation.Cols = result.Formation.Grid.Cols
 		}
-		h.metricsStore.Store(metrics)
 	}
+	h.metricsStore.Store(metrics)
+}
 
+// buildPipelineResponse converts the use case result to the HTTP response body
+func buildPipelineResponse(sessionID string, result *usecases.PipelineExecuteResponse) PipelineResponse {
 	resp := PipelineResponse{
 		SessionID: sessionID,
 		Agent1Ms:  result.Agent1Ms,
 		Agent2Ms:  result.Agent2Ms,
 		TotalMs:   result.TotalMs,
+		Cache:     result.CacheMatch,
 	}
 
 	if result.Formation != nil {
@@ -196,6 +240,7 @@ func (h *PipelineHandler) HandlePipeline(w http.ResponseWriter, r *http.Request)
 			Widgets:    result.Formation.Widgets,
 			Sections:   result.Formation.Sections,
 			Pagination: result.Formation.Pagination,
+			FacetBar:   result.Formation.FacetBar,
 		}
 	}
 
@@ -209,6 +254,7 @@ func (h *PipelineHandler) HandlePipeline(w http.ResponseWriter, r *http.Request)
 				Widgets:    f.Widgets,
 				Sections:   f.Sections,
 				Pagination: f.Pagination,
+				FacetBar:   f.FacetBar,
 			}
 		}
 	}
@@ -216,7 +262,7 @@ func (h *PipelineHandler) HandlePipeline(w http.ResponseWriter, r *http.Request)
 		resp.Entities = result.Entities
 	}
 
-	writeJSON(w, http.StatusOK, resp)
+	return resp
 }
 
 func generateSessionID() string {
@@ -231,3 +277,33 @@ func cacheHitRate(usage domain.LLMUsage) float64 {
 	}
 	return float64(usage.CacheReadInputTokens) / float64(total) * 100
 }
+
+// buildQuotaExceededResponse builds the structured 429 body with the fallback formation
+func buildQuotaExceededResponse(sessionID string, qerr *domain.QuotaExceededError) QuotaExceededResponse {
+	fallback := domain.QuotaFallbackFormation()
+	return QuotaExceededResponse{
+		SessionID: sessionID,
+		Error: QuotaErrorBody{
+			Code:    domain.ErrRateLimitExceeded.Code,
+			Message: qerr.Error(),
+			Period:  qerr.Period,
+			Metric:  qerr.Metric,
+			Limit:   qerr.Limit,
+			Used:    qerr.Used,
+			ResetAt: qerr.ResetAt,
+		},
+		Formation: &FormationResponse{
+			Mode:     string(fallback.Mode),
+			Widgets:  fallback.Widgets,
+			FacetBar: fallback.FacetBar,
+		},
+	}
+}
+
+// writeQuotaExceeded writes 429 with Retry-After until the quota period resets
+func writeQuotaExceeded(w http.ResponseWriter, sessionID string, qerr *domain.QuotaExceededError) {
+	if secs := int(time.Until(qerr.ResetAt).Seconds()); secs > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(secs))
+	}
+	writeJSON(w, http.StatusTooManyRequests, buildQuotaExceededResponse(sessionID, qerr))
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/google/uuid"
+	"keepstar/internal/domain"
+	"keepstar/internal/logger"
+	"keepstar/internal/usecases"
+)
+
+// sseWriter writes Server-Sent Events and flushes after each one.
+// Pipeline events may arrive from tool goroutines, so writes are serialized.
+type sseWriter struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// send writes one event: "event: <name>\ndata: <json>\n\n"
+func (s *sseWriter) send(event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshal %s event: %w", event, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// HandlePipelineStream handles POST /api/v1/pipeline/stream (and GET for EventSource clients).
+// Same input as /api/v1/pipeline; the response is text/event-stream with events:
+// started → tool_call → tool_result → skeleton → formation → spans → done (or error).
+func (h *PipelineHandler) HandlePipelineStream(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("handler.pipeline_stream")
+		defer endSpan()
+	}
+
+	var req PipelineRequest
+	switch r.Method {
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	case http.MethodGet:
+		req.SessionID = r.URL.Query().Get("sessionId")
+		req.Query = r.URL.Query().Get("query")
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if req.Query == "" {
+		http.Error(w, "Query is required", http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	sessionID := req.SessionID
+	if sessionID == "" {
+		sessionID = generateSessionID()
+	}
+
+	ctx = logger.WithSessionID(ctx, sessionID)
+
+	var tenantSlug string
+	if tenant := GetTenantFromContext(ctx); tenant != nil {
+		tenantSlug = tenant.Slug
+	}
+
+	reqLog := h.log.FromContext(ctx)
+	reqLog.Info("pipeline_stream_start", "query", req.Query)
+
+	turnID := uuid.New().String()
+
+	var screenCtx *usecases.ScreenContext
+	if req.ScreenContext != nil {
+		screenCtx = &usecases.ScreenContext{
+			Mode:        req.ScreenContext.Mode,
+			WidgetCount: req.ScreenContext.WidgetCount,
+			Fields:      req.ScreenContext.Fields,
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // disable proxy buffering (nginx)
+	w.WriteHeader(http.StatusOK)
+
+	sse := &sseWriter{w: w, flusher: flusher}
+	_ = sse.send(string(domain.PipelineEventStarted), map[string]string{
+		"sessionId": sessionID,
+		"turnId":    turnID,
+	})
+
+	ctx = domain.WithEventSink(ctx, func(event domain.PipelineEvent) {
+		if err := sse.send(string(event.Type), event.Data); err != nil {
+			reqLog.Warn("pipeline_stream_write_failed", "event", event.Type, "error", err)
+		}
+	})
+
+	result, err := h.pipelineUC.Execute(ctx, usecases.PipelineExecuteRequest{
+		SessionID:     sessionID,
+		Query:         req.Query,
+		TenantSlug:    tenantSlug,
+		TurnID:        turnID,
+		ScreenContext: screenCtx,
+	})
+	if err != nil {
+		var quotaErr *domain.QuotaExceededError
+		if errors.As(err, &quotaErr) {
+			// Headers are already sent: deliver the fallback formation, then the structured error
+			quotaResp := buildQuotaExceededResponse(sessionID, quotaErr)
+			_ = sse.send(string(domain.PipelineEventFormation), map[string]interface{}{"formation": quotaResp.Formation})
+			_ = sse.send(string(domain.PipelineEventError), quotaResp)
+			return
+		}
+		reqLog.Error("pipeline_stream_failed", "error", err)
+		_ = sse.send(string(domain.PipelineEventError), map[string]string{"error": err.Error()})
+		return
+	}
+
+	h.storeMetrics(sessionID, req.Query, result)
+
+	// Formation was already streamed — done carries only session + timings
+	_ = sse.send(string(domain.PipelineEventDone), PipelineResponse{
+		SessionID: sessionID,
+		Agent1Ms:  result.Agent1Ms,
+		Agent2Ms:  result.Agent2Ms,
+		TotalMs:   result.TotalMs,
+		Cache:     result.CacheMatch,
+	})
+}
@@ -10,6 +10,7 @@ import (
 	"keepstar/internal/domain"
 	"keepstar/internal/logger"
 	"keepstar/internal/ports"
+	"keepstar/internal/usecases"
 )
 
 // SessionHandler handles session endpoints
@@ -17,6 +18,8 @@ type SessionHandler struct {
 	cache       ports.CachePort
 	statePort   ports.StatePort
 	catalogPort ports.CatalogPort
+	historyUC   *usecases.HistoryUseCase // nil = undo/redo/goto disabled
+	forkUC      *usecases.ForkUseCase    // nil = fork disabled
 	log         *logger.Logger
 }
 
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/logger"
+	"keepstar/internal/usecases"
+)
+
+// SessionForkResponse is the response for fork: the new session's screen
+// in the pipeline response shape plus the link to its parent
+type SessionForkResponse struct {
+	PipelineResponse
+	ParentID string `json:"parentId"`
+	ForkStep int    `json:"forkStep"` // parent step the fork starts from
+}
+
+// SessionBranchesResponse is the fork tree a session belongs to
+type SessionBranchesResponse struct {
+	SessionID string                `json:"sessionId"`
+	Root      *domain.SessionBranch `json:"root"` // original session, forks as children
+	Count     int                   `json:"count"`
+}
+
+// WithFork enables the fork endpoint
+func (h *SessionHandler) WithFork(forkUC *usecases.ForkUseCase) *SessionHandler {
+	h.forkUC = forkUC
+	return h
+}
+
+// HandleFork handles POST /api/v1/session/{id}/fork?step=N (no step = current step)
+func (h *SessionHandler) HandleFork(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("handler.session_fork")
+		defer endSpan()
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.forkUC == nil {
+		http.Error(w, "fork is not available", http.StatusNotImplemented)
+		return
+	}
+
+	// Path: /api/v1/session/{id}/fork
+	sessionID := splitPath(r.URL.Path)[3]
+
+	req := usecases.ForkRequest{SessionID: sessionID, ToStep: -1}
+	if s := r.URL.Query().Get("step"); s != "" {
+		step, err := strconv.Atoi(s)
+		if err != nil || step < 0 {
+			http.Error(w, "invalid step", http.StatusBadRequest)
+			return
+		}
+		req.ToStep = step
+	}
+
+	ctx = logger.WithSessionID(ctx, sessionID)
+	result, err := h.forkUC.Execute(ctx, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrInvalidStep):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, domain.ErrSessionNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	resp := SessionForkResponse{
+		PipelineResponse: PipelineResponse{
+			SessionID: result.SessionID,
+			Entities:  &result.State.Current.Data,
+		},
+		ParentID: result.ParentID,
+		ForkStep: result.ForkStep,
+	}
+	if f := result.Formation; f != nil {
+		resp.Formation = &FormationResponse{
+			Mode:       string(f.Mode),
+			Grid:       f.Grid,
+			Widgets:    f.Widgets,
+			Sections:   f.Sections,
+			Pagination: f.Pagination,
+			FacetBar:   f.FacetBar,
+		}
+	}
+
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+// HandleBranches handles GET /api/v1/session/{id}/branches
+func (h *SessionHandler) HandleBranches(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("handler.session_branches")
+		defer endSpan()
+	}
+
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.statePort == nil {
+		http.Error(w, "branches are not available", http.StatusNotImplemented)
+		return
+	}
+
+	// Path: /api/v1/session/{id}/branches
+	sessionID := splitPath(r.URL.Path)[3]
+
+	branches, err := h.statePort.GetBranches(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := SessionBranchesResponse{SessionID: sessionID, Count: len(branches)}
+	if roots := domain.BuildBranchTree(branches); len(roots) > 0 {
+		resp.Root = roots[0]
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// isSessionForkPath checks if path matches /api/v1/session/{id}/fork
+func isSessionForkPath(path string) bool {
+	return isSessionSubPath(path, "fork")
+}
+
+// isSessionBranchesPath checks if path matches /api/v1/session/{id}/branches
+func isSessionBranchesPath(path string) bool {
+	return isSessionSubPath(path, "branches")
+}
+
+// isSessionSubPath checks if path matches /api/v1/session/{id}/{action}
+func isSessionSubPath(path, action string) bool {
+	parts := splitPath(path)
+	return len(parts) == 5 && parts[0] == "api" && parts[1] == "v1" && parts[2] == "session" && parts[4] == action
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"keepstar/internal/domain"
+	"keepstar/internal/logger"
+	"keepstar/internal/usecases"
+)
+
+// SessionHistoryResponse is the response for undo/redo/goto: the restored screen
+// in the pipeline response shape plus the history position
+type SessionHistoryResponse struct {
+	PipelineResponse
+	Step    int  `json:"step"` // step whose screen is shown now
+	CanUndo bool `json:"canUndo"`
+	CanRedo bool `json:"canRedo"`
+}
+
+// WithHistory enables the undo/redo/goto endpoints
+func (h *SessionHandler) WithHistory(historyUC *usecases.HistoryUseCase) *SessionHandler {
+	h.historyUC = historyUC
+	return h
+}
+
+// HandleHistory handles POST /api/v1/session/{id}/undo, /redo and /goto?step=N
+func (h *SessionHandler) HandleHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("handler.session_history")
+		defer endSpan()
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.historyUC == nil {
+		http.Error(w, "history is not available", http.StatusNotImplemented)
+		return
+	}
+
+	// Path: /api/v1/session/{id}/{action}
+	parts := splitPath(r.URL.Path)
+	sessionID, action := parts[3], parts[4]
+
+	req := usecases.HistoryRequest{
+		SessionID: sessionID,
+		Action:    action,
+		TurnID:    uuid.New().String(),
+	}
+	if action == domain.HistoryGoto {
+		step, err := strconv.Atoi(r.URL.Query().Get("step"))
+		if err != nil {
+			http.Error(w, "step is required", http.StatusBadRequest)
+			return
+		}
+		req.ToStep = step
+	}
+
+	ctx = logger.WithSessionID(ctx, sessionID)
+	result, err := h.historyUC.Execute(ctx, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNothingToUndo), errors.Is(err, domain.ErrNothingToRedo):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, domain.ErrInvalidStep):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, domain.ErrSessionNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	resp := SessionHistoryResponse{
+		PipelineResponse: PipelineResponse{
+			SessionID: sessionID,
+			Entities:  &result.State.Current.Data,
+		},
+		Step:    result.ToStep,
+		CanUndo: result.CanUndo,
+		CanRedo: result.CanRedo,
+	}
+	if f := result.Formation; f != nil {
+		resp.Formation = &FormationResponse{
+			Mode:       string(f.Mode),
+			Grid:       f.Grid,
+			Widgets:    f.Widgets,
+			Sections:   f.Sections,
+			Pagination: f.Pagination,
+			FacetBar:   f.FacetBar,
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// isSessionHistoryPath checks if path matches /api/v1/session/{id}/{undo|redo|goto}
+func isSessionHistoryPath(path string) bool {
+	parts := splitPath(path)
+	if len(parts) != 5 || parts[0] != "api" || parts[1] != "v1" || parts[2] != "session" {
+		return false
+	}
+	switch parts[4] {
+	case domain.HistoryUndo, domain.HistoryRedo, domain.HistoryGoto:
+		return true
+	}
+	return false
+}
@@ -15,6 +15,7 @@ import (
 type TraceHandler struct {
 	tracePort ports.TracePort
 	cachePort ports.CachePort
+	statePort ports.StatePort // nil = no session fork trees
 }
 
 // NewTraceHandler creates a trace handler
@@ -22,6 +23,24 @@ func NewTraceHandler(tracePort ports.TracePort, cachePort ports.CachePort) *Trac
 	return &TraceHandler{tracePort: tracePort, cachePort: cachePort}
 }
 
+// WithBranches shows session forks as trees in the trace pages
+func (h *TraceHandler) WithBranches(statePort ports.StatePort) *TraceHandler {
+	h.statePort = statePort
+	return h
+}
+
+// forkTree returns the fork tree of a session, nil when it was never forked
+func (h *TraceHandler) forkTree(r *http.Request, sessionID string) *domain.SessionBranch {
+	if h.statePort == nil {
+		return nil
+	}
+	branches, err := h.statePort.GetBranches(r.Context(), sessionID)
+	if err != nil || len(branches) < 2 {
+		return nil
+	}
+	return domain.BuildBranchTree(branches)[0]
+}
+
 // HandleKillSession handles POST /debug/kill-session
 func (h *TraceHandler) HandleKillSession(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -87,11 +106,37 @@ func (h *TraceHandler) handleList(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Fork trees of the listed sessions (one per tree), in the order of the list
+	var forks []*domain.SessionBranch
+	if h.statePort != nil {
+		var sessionIDs []string
+		seen := make(map[string]bool)
+		for _, t := range traces {
+			if !seen[t.SessionID] {
+				seen[t.SessionID] = true
+				sessionIDs = append(sessionIDs, t.SessionID)
+			}
+		}
+		if branches, err := h.statePort.GetBranchTrees(r.Context(), sessionIDs); err == nil {
+			trees := domain.BuildBranchTree(branches)
+			added := make(map[*domain.SessionBranch]bool)
+			for _, id := range sessionIDs {
+				for _, tree := range trees {
+					if len(tree.Children) > 0 && !added[tree] && tree.Find(id) != nil {
+						added[tree] = true
+						forks = append(forks, tree)
+					}
+				}
+			}
+		}
+	}
+
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	traceListTpl.Execute(w, map[string]interface{}{
 		"Traces":        traces,
 		"Count":         len(traces),
 		"AliveSessions": aliveSessions,
+		"Forks":         forks,
 	})
 }
 
@@ -115,6 +160,7 @@ func (h *TraceHandler) handleDetail(w http.ResponseWriter, r *http.Request, trac
 	traceDetailTpl.Execute(w, map[string]interface{}{
 		"Trace":   trace,
 		"RawJSON": string(rawJSON),
+		"Forks":   h.forkTree(r, trace.SessionID),
 	})
 }
 
@@ -166,8 +212,12 @@ var traceFuncs = template.FuncMap{
 			return "SQL keyword"
 		case "vector":
 			return "pgvector"
+		case "facets":
+			return "facet counts"
 		case "state":
 			return "state update"
+		case "cache":
+			return "response cache"
 		}
 		// agent-level: "agent1", "agent2"
 		if len(parts) == 1 {
@@ -228,7 +278,16 @@ var traceFuncs = template.FuncMap{
 	},
 }
 
-var traceListTpl = template.Must(template.New("traceList").Funcs(traceFuncs).Parse(`<!DOCTYPE html>
+// branchTreeTpl renders a session fork tree as nested lists (shared by the trace pages)
+const branchTreeTpl = `{{define "branch"}}<li>
+	<a href="/debug/session/{{.SessionID}}">{{shortID .SessionID}}</a>
+	<span class="ms">step {{.Step}}</span>
+	{{if .ParentID}}<span class="time">forked at step {{.ForkStep}}</span>{{else}}<span class="time">original</span>{{end}}
+	{{if ne .Status "active"}}<span class="time">{{.Status}}</span>{{end}}
+	{{if .Children}}<ul class="forks">{{range .Children}}{{template "branch" .}}{{end}}</ul>{{end}}
+</li>{{end}}`
+
+var traceListTpl = template.Must(template.Must(template.New("traceList").Funcs(traceFuncs).Parse(`<!DOCTYPE html>
 <html>
 <head>
 <title>Pipeline Traces ({{.Count}})</title>
@@ -255,12 +314,22 @@ var traceListTpl = template.Must(template.New("traceList").Funcs(traceFuncs).Par
 	.kill-btn { background: #2a1020; color: #ff6b6b; border: 1px solid #ff6b6b33; padding: 2px 8px; border-radius: 4px; cursor: pointer; font-size: 11px; font-family: inherit; }
 	.kill-btn:hover { background: #ff6b6b; color: #0a0a1a; }
 	.session { color: #565680; font-size: 12px; }
+	ul.forks { list-style: none; margin-left: 20px; border-left: 1px solid #222; padding-left: 12px; font-size: 13px; }
+	ul.forks li { padding: 3px 0; }
+	.fork-trees { margin-bottom: 24px; }
 </style>
 </head>
 <body>
 <h1>Pipeline Traces</h1>
 <p class="subtitle">{{.Count}} traces recorded. <a href="?format=json">JSON</a> &middot; <a id="refreshBtn" href="javascript:refresh()">Refresh</a></p>
 
+{{if .Forks}}
+<div class="fork-trees">
+<h2 style="color:#fff;font-size:14px;margin-bottom:8px;">Session Forks</h2>
+{{range .Forks}}<ul class="forks">{{template "branch" .}}</ul>{{end}}
+</div>
+{{end}}
+
 {{if .Traces}}
 <table>
 <tr>
@@ -285,7 +354,9 @@ var traceListTpl = template.Must(template.New("traceList").Funcs(traceFuncs).Par
 		{{if .Agent1}}
 			<span class="tool">{{.Agent1.ToolName}}</span>
 			<span class="ms">{{.Agent1.TotalMs}}ms</span>
-		{{else}}-{{end}}
+		{{else if .ResponseCache}}{{if .ResponseCache.Hit}}
+			<span class="ok">cache {{.ResponseCache.Match}}</span>
+		{{else}}-{{end}}{{else}}-{{end}}
 	</td>
 	<td>
 		{{if .Agent1}}{{if .Agent1.ToolBreakdown}}{{if index .Agent1.ToolBreakdown "normalize_path"}}
@@ -342,9 +413,9 @@ async function refresh() {
 }
 </script>
 </body>
-</html>`))
+</html>`)).Parse(branchTreeTpl))
 
-var traceDetailTpl = template.Must(template.New("traceDetail").Funcs(traceFuncs).Parse(`<!DOCTYPE html>
+var traceDetailTpl = template.Must(template.Must(template.New("traceDetail").Funcs(traceFuncs).Parse(`<!DOCTYPE html>
 <html>
 <head>
 <title>Trace {{shortID .Trace.ID}}</title>
@@ -373,6 +444,9 @@ var traceDetailTpl = template.Must(template.New("traceDetail").Funcs(traceFuncs)
 	.expandable:hover { color: #fff; }
 	.hidden { display: none; }
 	.error-box { background: #2a1020; border: 1px solid #ff6b6b; border-radius: 8px; padding: 16px; margin-bottom: 16px; }
+	ul.forks { list-style: none; margin-left: 20px; border-left: 1px solid #222; padding-left: 12px; font-size: 13px; }
+	ul.forks li { padding: 3px 0; }
+	.time { color: #565680; }
 </style>
 <script>
 function toggle(id) {
@@ -404,6 +478,28 @@ function toggle(id) {
 	</div>
 </div>
 
+<!-- Response cache -->
+{{if .Trace.ResponseCache}}
+<h2>Response Cache</h2>
+<div class="section">
+	<div class="row">
+		<div class="cell"><div class="label">Result</div><div class="value {{if .Trace.ResponseCache.Hit}}ok{{end}}">{{if .Trace.ResponseCache.Hit}}HIT ({{.Trace.ResponseCache.Match}}){{else}}MISS{{end}}</div></div>
+		{{if .Trace.ResponseCache.Hit}}
+		<div class="cell"><div class="label">Similarity</div><div class="value">{{printf "%.3f" .Trace.ResponseCache.Similarity}}</div></div>
+		<div class="cell"><div class="label">Saved</div><div class="value cost">${{printf "%.6f" .Trace.ResponseCache.SavedCostUSD}}</div></div>
+		{{else}}
+		<div class="cell"><div class="label">Stored</div><div class="value">{{.Trace.ResponseCache.Stored}}</div></div>
+		{{end}}
+		<div class="cell"><div class="label">Lookup</div><div class="value ms">{{.Trace.ResponseCache.LookupMs}}ms</div></div>
+		{{if .Trace.ResponseCache.EntryID}}<div class="cell"><div class="label">Entry</div><div class="value">{{shortID .Trace.ResponseCache.EntryID}}</div></div>{{end}}
+	</div>
+	<div class="row">
+		<div class="cell"><div class="label">State Key</div><div class="value">{{.Trace.ResponseCache.StateKey}}</div></div>
+	</div>
+	{{if .Trace.ResponseCache.Error}}<span class="err">{{.Trace.ResponseCache.Error}}</span>{{end}}
+</div>
+{{end}}
+
 <!-- Agent 1 -->
 {{if .Trace.Agent1}}
 <h2>Agent 1 — Tool Caller</h2>
@@ -460,6 +556,9 @@ function toggle(id) {
 			{{if index .Trace.Agent1.ToolBreakdown "tenant"}}
 			<div class="cell"><div class="label">Tenant</div><div class="value">{{index .Trace.Agent1.ToolBreakdown "tenant"}}</div></div>
 			{{end}}
+			{{if index .Trace.Agent1.ToolBreakdown "stock_policy"}}
+			<div class="cell"><div class="label">Stock</div><div class="value">{{index .Trace.Agent1.ToolBreakdown "stock_policy"}}{{if index .Trace.Agent1.ToolBreakdown "stock_hidden"}} −{{index .Trace.Agent1.ToolBreakdown "stock_hidden"}} hidden{{end}}{{if index .Trace.Agent1.ToolBreakdown "stock_demoted"}} {{index .Trace.Agent1.ToolBreakdown "stock_demoted"}} demoted{{end}}</div></div>
+			{{end}}
 			{{if index .Trace.Agent1.ToolBreakdown "fallback_step"}}
 			<div class="cell"><div class="label">Fallback</div><div class="value" style="color: {{if eq (printf "%v" (index .Trace.Agent1.ToolBreakdown "fallback_step")) "0"}}#00ff88{{else}}#e0af68{{end}}">step {{index .Trace.Agent1.ToolBreakdown "fallback_step"}}</div></div>
 			{{end}}
@@ -488,6 +587,20 @@ function toggle(id) {
 			<pre style="max-height: 40px;">{{index .Trace.Agent1.ToolBreakdown "price_conversion"}}</pre>
 		</div>
 		{{end}}
+		{{if index .Trace.Agent1.ToolBreakdown "synonym_expansions"}}
+		<div style="margin-top: 4px;">
+			<div class="label">Synonym Expansions</div>
+			<pre style="max-height: 60px;">{{range index .Trace.Agent1.ToolBreakdown "synonym_expansions"}}{{.}}
+{{end}}</pre>
+		</div>
+		{{end}}
+		{{if index .Trace.Agent1.ToolBreakdown "reranker"}}
+		<div style="margin-top: 4px;">
+			<div class="label">Rerank ({{index .Trace.Agent1.ToolBreakdown "reranker"}}, {{index .Trace.Agent1.ToolBreakdown "rerank_ms"}}ms){{if index .Trace.Agent1.ToolBreakdown "rerank_error"}} <span class="err">{{index .Trace.Agent1.ToolBreakdown "rerank_error"}}</span>{{end}}</div>
+			<pre style="max-height: 160px;">{{range index .Trace.Agent1.ToolBreakdown "rerank_scores"}}{{.}}
+{{end}}</pre>
+		</div>
+		{{end}}
 	</div>
 	{{end}}
 	{{else}}
@@ -635,10 +748,18 @@ function toggle(id) {
 </div>
 {{end}}
 
+<!-- Session forks -->
+{{if .Forks}}
+<h2>Session Forks</h2>
+<div class="section">
+	<ul class="forks">{{template "branch" .Forks}}</ul>
+</div>
+{{end}}
+
 <!-- Raw JSON -->
 <h2>Full Trace JSON</h2>
 <span class="expandable" onclick="toggle('rawjson')">&#9654; Show raw JSON</span>
 <pre id="rawjson" class="hidden">{{.RawJSON}}</pre>
 
 </body>
-</html>`))
+</html>`)).Parse(branchTreeTpl))
@@ -45,6 +45,24 @@ func (m *middlewareCatalogMock) ListServices(context.Context, string, ports.Prod
 func (m *middlewareCatalogMock) GetService(context.Context, string, string) (*domain.Service, error) {
 	return nil, nil
 }
+func (m *middlewareCatalogMock) GetProductFacets(context.Context, string, ports.ProductFilter, []string) ([]domain.Facet, error) {
+	return nil, nil
+}
+func (m *middlewareCatalogMock) CorrectFilterValue(context.Context, string, string, string) (*domain.FilterCorrection, error) {
+	return nil, nil
+}
+func (m *middlewareCatalogMock) GetSynonyms(context.Context, string) ([]domain.Synonym, error) {
+	return nil, nil
+}
+func (m *middlewareCatalogMock) GetProductEmbedding(context.Context, string, string) ([]float32, error) {
+	return nil, nil
+}
+func (m *middlewareCatalogMock) GetIngredientInteractions(context.Context) ([]domain.IngredientInteraction, error) {
+	return nil, nil
+}
+func (m *middlewareCatalogMock) GetProductIngredients(context.Context, string, []string) (map[string][]string, error) {
+	return nil, nil
+}
 func (m *middlewareCatalogMock) VectorSearchServices(context.Context, string, []float32, int, *ports.VectorFilter) ([]domain.Service, error) {
 	return nil, nil
 }
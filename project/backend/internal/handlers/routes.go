@@ -10,7 +10,23 @@ func SetupRoutes(mux *http.ServeMux, chat *ChatHandler, session *SessionHandler,
 
 	// API v1
 	mux.HandleFunc("/api/v1/chat", chat.HandleChat)
-	mux.HandleFunc("/api/v1/session/", session.HandleGetSession)
+	mux.HandleFunc("/api/v1/session/", func(w http.ResponseWriter, r *http.Request) {
+		// Undo/redo/goto: /api/v1/session/{id}/{action}
+		if isSessionHistoryPath(r.URL.Path) {
+			session.HandleHistory(w, r)
+			return
+		}
+		// Branches: /api/v1/session/{id}/fork, /api/v1/session/{id}/branches
+		if isSessionForkPath(r.URL.Path) {
+			session.HandleFork(w, r)
+			return
+		}
+		if isSessionBranchesPath(r.URL.Path) {
+			session.HandleBranches(w, r)
+			return
+		}
+		session.HandleGetSession(w, r)
+	})
 
 	// Session init (creates session + seeds tenant)
 	if tenantMw != nil {
@@ -22,10 +38,13 @@ func SetupRoutes(mux *http.ServeMux, chat *ChatHandler, session *SessionHandler,
 	// Pipeline API (Two-Agent system) with tenant from header
 	if pipeline != nil {
 		handler := http.HandlerFunc(pipeline.HandlePipeline)
+		streamHandler := http.HandlerFunc(pipeline.HandlePipelineStream)
 		if tenantMw != nil {
 			mux.Handle("/api/v1/pipeline", tenantMw.ResolveFromHeader(defaultTenant)(handler))
+			mux.Handle("/api/v1/pipeline/stream", tenantMw.ResolveFromHeader(defaultTenant)(streamHandler))
 		} else {
 			mux.HandleFunc("/api/v1/pipeline", pipeline.HandlePipeline)
+			mux.HandleFunc("/api/v1/pipeline/stream", pipeline.HandlePipelineStream)
 		}
 	}
 }
@@ -35,10 +54,12 @@ func SetupTestbenchRoutes(mux *http.ServeMux, testbench *TestbenchHandler) {
 	mux.HandleFunc("/api/v1/testbench", testbench.HandleTestbench)
 }
 
-// SetupNavigationRoutes configures navigation routes (expand/back)
+// SetupNavigationRoutes configures navigation routes (expand/back/forward/similar)
 func SetupNavigationRoutes(mux *http.ServeMux, nav *NavigationHandler) {
 	mux.HandleFunc("/api/v1/navigation/expand", nav.HandleExpand)
 	mux.HandleFunc("/api/v1/navigation/back", nav.HandleBack)
+	mux.HandleFunc("/api/v1/navigation/forward", nav.HandleForward)
+	mux.HandleFunc("/api/v1/navigation/similar", nav.HandleSimilar)
 }
 
 // SetupCatalogRoutes configures catalog routes with tenant middleware
@@ -9,10 +9,14 @@ import (
 	"os"
 	"testing"
 
+	"github.com/google/uuid"
 	"keepstar/internal/adapters/postgres"
+	"keepstar/internal/domain"
+	"keepstar/internal/engine"
 	"keepstar/internal/handlers"
 	"keepstar/internal/logger"
 	"keepstar/internal/presets"
+	"keepstar/internal/testutil"
 	"keepstar/internal/usecases"
 )
 
@@ -44,13 +48,16 @@ func smokeServer(t *testing.T) *httptest.Server {
 	presetRegistry := presets.NewPresetRegistry()
 	metricsStore := handlers.NewMetricsStore()
 
-	sessionHandler := handlers.NewSessionHandler(cacheAdapter, stateAdapter, nil, log)
+	sessionHandler := handlers.NewSessionHandler(cacheAdapter, stateAdapter, nil, log).
+		WithHistory(usecases.NewHistoryUseCase(stateAdapter)).
+		WithFork(usecases.NewForkUseCase(stateAdapter))
 	healthHandler := handlers.NewHealthHandler()
 	debugHandler := handlers.NewDebugHandler(stateAdapter, cacheAdapter, metricsStore)
 
 	expandUC := usecases.NewExpandUseCase(stateAdapter, presetRegistry)
 	backUC := usecases.NewBackUseCase(stateAdapter, presetRegistry)
-	navHandler := handlers.NewNavigationHandler(expandUC, backUC, log)
+	navHandler := handlers.NewNavigationHandler(expandUC, backUC, log).
+		WithForward(usecases.NewForwardUseCase(stateAdapter, presetRegistry))
 
 	mux := http.NewServeMux()
 
@@ -174,6 +181,85 @@ func TestSmoke_SeedExpandBack(t *testing.T) {
 	}
 }
 
+func TestSmoke_SeedExpandUndoRedo(t *testing.T) {
+	ts := smokeServer(t)
+	defer ts.Close()
+
+	// Seed
+	resp, _ := http.Post(ts.URL+"/debug/seed", "application/json", nil)
+	var seedResp map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&seedResp)
+	resp.Body.Close()
+	sessionID := seedResp["sessionId"].(string)
+
+	// Nothing to redo yet
+	resp1, err := http.Post(ts.URL+"/api/v1/session/"+sessionID+"/redo", "application/json", nil)
+	if err != nil {
+		t.Fatalf("redo: %v", err)
+	}
+	resp1.Body.Close()
+	if resp1.StatusCode != http.StatusConflict {
+		t.Errorf("redo before undo: want 409, got %d", resp1.StatusCode)
+	}
+
+	// Expand first product
+	expandBody, _ := json.Marshal(map[string]interface{}{
+		"sessionId":  sessionID,
+		"entityType": "product",
+		"entityId":   "prod-1",
+	})
+	resp2, err := http.Post(ts.URL+"/api/v1/navigation/expand", "application/json", bytes.NewReader(expandBody))
+	if err != nil {
+		t.Fatalf("expand: %v", err)
+	}
+	resp2.Body.Close()
+
+	// Undo → seeded grid
+	resp3, err := http.Post(ts.URL+"/api/v1/session/"+sessionID+"/undo", "application/json", nil)
+	if err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	defer resp3.Body.Close()
+	if resp3.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp3.Body)
+		t.Fatalf("undo: want 200, got %d: %s", resp3.StatusCode, body)
+	}
+	var undoResp handlers.SessionHistoryResponse
+	json.NewDecoder(resp3.Body).Decode(&undoResp)
+	if undoResp.Formation == nil || len(undoResp.Formation.Widgets) != 4 {
+		t.Errorf("undo: want the seeded grid of 4 widgets, got %+v", undoResp.Formation)
+	}
+	if !undoResp.CanRedo {
+		t.Error("undo: want canRedo")
+	}
+
+	// Redo → detail again
+	resp4, err := http.Post(ts.URL+"/api/v1/session/"+sessionID+"/redo", "application/json", nil)
+	if err != nil {
+		t.Fatalf("redo: %v", err)
+	}
+	defer resp4.Body.Close()
+	if resp4.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp4.Body)
+		t.Fatalf("redo: want 200, got %d: %s", resp4.StatusCode, body)
+	}
+	var redoResp handlers.SessionHistoryResponse
+	json.NewDecoder(resp4.Body).Decode(&redoResp)
+	if redoResp.Formation == nil || len(redoResp.Formation.Widgets) != 1 || redoResp.CanRedo {
+		t.Errorf("redo: want the detail formation back, got %+v", redoResp)
+	}
+
+	// Goto without step
+	resp5, err := http.Post(ts.URL+"/api/v1/session/"+sessionID+"/goto", "application/json", nil)
+	if err != nil {
+		t.Fatalf("goto: %v", err)
+	}
+	resp5.Body.Close()
+	if resp5.StatusCode != http.StatusBadRequest {
+		t.Errorf("goto without step: want 400, got %d", resp5.StatusCode)
+	}
+}
+
 func TestSmoke_ExpandInvalidSession(t *testing.T) {
 	ts := smokeServer(t)
 	defer ts.Close()
@@ -194,6 +280,20 @@ func TestSmoke_ExpandInvalidSession(t *testing.T) {
 	}
 }
 
+func TestSmoke_UndoUnknownSession(t *testing.T) {
+	ts := smokeServer(t)
+	defer ts.Close()
+
+	resp, err := http.Post(ts.URL+"/api/v1/session/"+uuid.New().String()+"/undo", "application/json", nil)
+	if err != nil {
+		t.Fatalf("undo unknown session: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("undo unknown session: want 404, got %d", resp.StatusCode)
+	}
+}
+
 func TestSmoke_BackEmptyStack(t *testing.T) {
 	ts := smokeServer(t)
 	defer ts.Close()
@@ -325,3 +425,147 @@ func TestSmoke_BackThenExpandAgain(t *testing.T) {
 		t.Fatalf("expand after back: want 200, got %d: %s", resp4.StatusCode, body)
 	}
 }
+
+func TestSmoke_ExpandBackForward(t *testing.T) {
+	ts := smokeServer(t)
+	defer ts.Close()
+
+	// Seed
+	resp, _ := http.Post(ts.URL+"/debug/seed", "application/json", nil)
+	var seedResp map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&seedResp)
+	resp.Body.Close()
+	sessionID := seedResp["sessionId"].(string)
+
+	// Expand → Back
+	expandBody, _ := json.Marshal(map[string]interface{}{
+		"sessionId": sessionID, "entityType": "product", "entityId": "prod-1",
+	})
+	resp2, _ := http.Post(ts.URL+"/api/v1/navigation/expand", "application/json", bytes.NewReader(expandBody))
+	resp2.Body.Close()
+
+	body, _ := json.Marshal(map[string]interface{}{"sessionId": sessionID})
+	resp3, _ := http.Post(ts.URL+"/api/v1/navigation/back", "application/json", bytes.NewReader(body))
+	var backResp handlers.NavigationResponse
+	json.NewDecoder(resp3.Body).Decode(&backResp)
+	resp3.Body.Close()
+	if !backResp.CanGoForward {
+		t.Error("back: want canGoForward")
+	}
+
+	// Forward → detail of prod-1 again
+	resp4, err := http.Post(ts.URL+"/api/v1/navigation/forward", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("forward: %v", err)
+	}
+	defer resp4.Body.Close()
+	if resp4.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp4.Body)
+		t.Fatalf("forward: want 200, got %d: %s", resp4.StatusCode, b)
+	}
+	var fwdResp handlers.NavigationResponse
+	json.NewDecoder(resp4.Body).Decode(&fwdResp)
+	if fwdResp.ViewMode != "detail" || fwdResp.Focused == nil || fwdResp.Focused.ID != "prod-1" {
+		t.Errorf("forward: want detail of prod-1, got %+v", fwdResp)
+	}
+	if fwdResp.CanGoForward || !fwdResp.CanGoBack || len(fwdResp.Breadcrumbs) != 2 {
+		t.Errorf("forward: want back only and 2 breadcrumbs, got %+v", fwdResp)
+	}
+}
+
+func TestSmoke_ForkAndBranches(t *testing.T) {
+	ts := smokeServer(t)
+	defer ts.Close()
+
+	// Seed → expand (step 1: seeded grid, then the detail view)
+	resp, _ := http.Post(ts.URL+"/debug/seed", "application/json", nil)
+	var seedResp map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&seedResp)
+	resp.Body.Close()
+	sessionID := seedResp["sessionId"].(string)
+
+	expandBody, _ := json.Marshal(map[string]interface{}{
+		"sessionId": sessionID, "entityType": "product", "entityId": "prod-1",
+	})
+	resp2, _ := http.Post(ts.URL+"/api/v1/navigation/expand", "application/json", bytes.NewReader(expandBody))
+	resp2.Body.Close()
+
+	// Fork at the seeded grid
+	resp3, err := http.Post(ts.URL+"/api/v1/session/"+sessionID+"/fork?step=1", "application/json", nil)
+	if err != nil {
+		t.Fatalf("fork: %v", err)
+	}
+	defer resp3.Body.Close()
+	if resp3.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp3.Body)
+		t.Fatalf("fork: want 201, got %d: %s", resp3.StatusCode, body)
+	}
+	var forkResp handlers.SessionForkResponse
+	json.NewDecoder(resp3.Body).Decode(&forkResp)
+	if forkResp.SessionID == "" || forkResp.SessionID == sessionID || forkResp.ParentID != sessionID || forkResp.ForkStep != 1 {
+		t.Fatalf("fork: want a new session forked at step 1, got %+v", forkResp)
+	}
+	if forkResp.Formation == nil || len(forkResp.Formation.Widgets) != 4 {
+		t.Errorf("fork: want the seeded grid of 4 widgets, got %+v", forkResp.Formation)
+	}
+
+	// Branches of the fork: the original with one child
+	resp4, err := http.Get(ts.URL + "/api/v1/session/" + forkResp.SessionID + "/branches")
+	if err != nil {
+		t.Fatalf("branches: %v", err)
+	}
+	defer resp4.Body.Close()
+	var branchesResp handlers.SessionBranchesResponse
+	json.NewDecoder(resp4.Body).Decode(&branchesResp)
+	if branchesResp.Count != 2 || branchesResp.Root == nil || branchesResp.Root.SessionID != sessionID || len(branchesResp.Root.Children) != 1 {
+		t.Errorf("branches: want the original with one fork, got %+v", branchesResp)
+	}
+
+	// Step after the current one
+	resp5, _ := http.Post(ts.URL+"/api/v1/session/"+sessionID+"/fork?step=99", "application/json", nil)
+	resp5.Body.Close()
+	if resp5.StatusCode != http.StatusBadRequest {
+		t.Errorf("fork at step 99: want 400, got %d", resp5.StatusCode)
+	}
+}
+
+func TestSmoke_UndoKeepsFacetBar(t *testing.T) {
+	ts := smokeServer(t)
+	defer ts.Close()
+
+	// Search turn with facet chips, then a second template on top of it
+	client := testutil.TestDB(t)
+	sessionID := testutil.TestStateWithProducts(t, client, 3)
+	adapter := postgres.NewStateAdapter(client, logger.New("error"))
+	facetBar := engine.BuildFacetBar([]domain.Facet{{Field: domain.FacetBrand, Values: []domain.FacetValue{
+		{Value: "CeraVe", Count: 2}, {Value: "La Roche-Posay", Count: 1},
+	}}})
+	info := domain.DeltaInfo{Trigger: domain.TriggerSystem, Source: domain.SourceSystem, ActorID: "test", DeltaType: domain.DeltaTypeUpdate, Path: "template"}
+	withBar := &domain.FormationWithData{Mode: domain.FormationTypeGrid, Widgets: []domain.Widget{{ID: "w1"}}, FacetBar: facetBar}
+	if _, err := adapter.UpdateTemplate(t.Context(), sessionID, map[string]interface{}{"formation": withBar}, info); err != nil {
+		t.Fatalf("template with facet bar: %v", err)
+	}
+	plain := &domain.FormationWithData{Mode: domain.FormationTypeSingle, Widgets: []domain.Widget{{ID: "w1"}}}
+	if _, err := adapter.UpdateTemplate(t.Context(), sessionID, map[string]interface{}{"formation": plain}, info); err != nil {
+		t.Fatalf("plain template: %v", err)
+	}
+
+	// Undo → the search formation comes back with its chips
+	resp, err := http.Post(ts.URL+"/api/v1/session/"+sessionID+"/undo", "application/json", nil)
+	if err != nil {
+		t.Fatalf("undo: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("undo: want 200, got %d: %s", resp.StatusCode, body)
+	}
+	var undoResp handlers.SessionHistoryResponse
+	json.NewDecoder(resp.Body).Decode(&undoResp)
+	if undoResp.Formation == nil || undoResp.Formation.FacetBar == nil {
+		t.Fatalf("undo: want the formation with its facet bar, got %+v", undoResp.Formation)
+	}
+	if undoResp.Formation.FacetBar.Template != domain.WidgetTemplateFacetBar || len(undoResp.Formation.FacetBar.Children) != 1 {
+		t.Errorf("undo: want one FacetGroup in the facet bar, got %+v", undoResp.Formation.FacetBar)
+	}
+}
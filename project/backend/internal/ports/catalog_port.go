@@ -12,7 +12,7 @@ type ProductFilter struct {
 	Brand        string
 	MinPrice     int
 	MaxPrice     int
-	Search       string
+	Search       string            // full-text (russian/english stemming), ANY word; ranked by ts_rank_cd
 	SortField    string            // "price", "rating", "name", "" (default: created_at)
 	SortOrder    string            // "asc", "desc" (default: "desc")
 	Limit        int
@@ -24,7 +24,12 @@ type ProductFilter struct {
 	KeyIngredient string // $N = ANY(mp.key_ingredients)
 	TargetArea    string // $N = ANY(mp.target_area)
 	RoutineStep   string // mp.routine_step = $N
+	RoutineTime   string // mp.routine_time IN ($N, 'both')
 	Texture       string // mp.texture = $N
+	// List-valued include/exclude filters, ANDed with the single-value ones
+	AttributeFilter
+	// InStockOnly keeps products with available stock (catalog.stock quantity - reserved > 0)
+	InStockOnly bool
 }
 
 // VectorFilter holds optional filters for VectorSearch to narrow results before ranking.
@@ -38,6 +43,41 @@ type VectorFilter struct {
 	TargetArea    string
 	RoutineStep   string
 	Texture       string
+	MaxPrice      int // p.price <= $N (kopecks), 0 = no limit
+	AttributeFilter
+	InStockOnly bool // see ProductFilter.InStockOnly
+}
+
+// Match modes for include lists on array columns (skin_type, concern, key_ingredients, target_area)
+const (
+	MatchAny = "any" // product has at least one of the values: column && $N
+	MatchAll = "all" // product has every value: column @> $N
+)
+
+// AttributeFilter holds list-valued filters shared by ProductFilter and VectorFilter.
+// Include lists on scalar columns are OR ("dry or sensitive"); Match picks any/all for array columns.
+// Services only honour Brands and ExcludeBrands.
+type AttributeFilter struct {
+	Brands         []string // mp.brand ILIKE ANY($N), substring match
+	ProductForms   []string // mp.product_form = ANY($N)
+	SkinTypes      []string // mp.skin_type && / @> $N
+	Concerns       []string // mp.concern && / @> $N
+	KeyIngredients []string // mp.key_ingredients && / @> $N
+	TargetAreas    []string // mp.target_area && / @> $N
+	FreeFrom       []string // mp.free_from @> $N: free from ALL listed ("fragrance", "parabens")
+	Match          string   // MatchAny (default) | MatchAll
+
+	ExcludeBrands         []string // brand matches none (ILIKE substring)
+	ExcludeProductForms   []string // product_form is none of
+	ExcludeKeyIngredients []string // key_ingredients contain none of
+}
+
+// IsEmpty reports whether no list filter is set
+func (f AttributeFilter) IsEmpty() bool {
+	return len(f.Brands) == 0 && len(f.ProductForms) == 0 && len(f.SkinTypes) == 0 &&
+		len(f.Concerns) == 0 && len(f.KeyIngredients) == 0 && len(f.TargetAreas) == 0 &&
+		len(f.FreeFrom) == 0 && len(f.ExcludeBrands) == 0 && len(f.ExcludeProductForms) == 0 &&
+		len(f.ExcludeKeyIngredients) == 0
 }
 
 type CatalogPort interface {
@@ -54,6 +94,27 @@ type CatalogPort interface {
 	ListProducts(ctx context.Context, tenantID string, filter ProductFilter) ([]domain.Product, int, error)
 	GetProduct(ctx context.Context, tenantID string, productID string) (*domain.Product, error)
 
+	// GetProductFacets counts attribute values (brand, category, price buckets, PIM fields)
+	// over products matching filter's structured conditions and either its full-text Search
+	// or one of productIDs (e.g. vector hits). Limit, Offset and sorting are ignored.
+	GetProductFacets(ctx context.Context, tenantID string, filter ProductFilter, productIDs []string) ([]domain.Facet, error)
+
+	// CorrectFilterValue snaps a brand or category filter value ("brand" | "category") that matches
+	// nothing to a known value of the tenant (CatalogDigest plus all distinct catalog values): keyboard
+	// layout fix, transliteration, then trigram similarity. Returns nil when the value is already known
+	// or nothing is close.
+	CorrectFilterValue(ctx context.Context, tenantID string, field string, value string) (*domain.FilterCorrection, error)
+
+	// GetSynonyms returns the tenant's synonym rules (admin-managed vocabulary for query expansion).
+	GetSynonyms(ctx context.Context, tenantID string) ([]domain.Synonym, error)
+
+	// Ingredient operations
+	// GetIngredientInteractions returns the tenant-agnostic ingredient interaction rules.
+	GetIngredientInteractions(ctx context.Context) ([]domain.IngredientInteraction, error)
+	// GetProductIngredients returns the INCI names of each tenant product's master product
+	// (catalog.product_ingredients, by position). Products without a parsed INCI list are absent.
+	GetProductIngredients(ctx context.Context, tenantID string, productIDs []string) (map[string][]string, error)
+
 	// Stock operations
 	GetStock(ctx context.Context, tenantID string, productID string) (*domain.Stock, error)
 
@@ -67,6 +128,11 @@ type CatalogPort interface {
 	// filter may be nil for unfiltered search.
 	VectorSearch(ctx context.Context, tenantID string, embedding []float32, limit int, filter *VectorFilter) ([]domain.Product, error)
 
+	// GetProductEmbedding returns the stored embedding of a tenant product's master product
+	// (the query vector for "more like this"). Returns domain.ErrProductNotFound for an unknown
+	// product and a nil embedding when the master product has not been embedded yet.
+	GetProductEmbedding(ctx context.Context, tenantID string, productID string) ([]float32, error)
+
 	// SeedEmbedding saves embedding for a master product.
 	SeedEmbedding(ctx context.Context, masterProductID string, embedding []float32) error
 
@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+
+	"keepstar/internal/domain"
+)
+
+// PromptPort reads the prompt registry. Versions are created and (de)activated
+// by the admin backend (/admin/api/prompts, per tenant).
+type PromptPort interface {
+	// GetActivePrompts returns the active versions serving a tenant:
+	// the tenant's own active versions if any, else the global ones
+	GetActivePrompts(ctx context.Context, name, tenantSlug string) ([]domain.PromptVersion, error)
+}
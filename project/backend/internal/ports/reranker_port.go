@@ -0,0 +1,31 @@
+package ports
+
+import (
+	"context"
+
+	"keepstar/internal/domain"
+)
+
+// RerankerPort rescores the top merged search candidates.
+// Implementations: feature-based scorer, LLM judge (adapters/rerank).
+type RerankerPort interface {
+	// Name is the strategy tenants select in settings.rerank.strategy
+	Name() string
+
+	// Rerank returns one score per candidate; higher ranks first
+	Rerank(ctx context.Context, req RerankRequest) (*RerankResponse, error)
+}
+
+// RerankRequest is the original query, the structured filters and the candidates to rescore
+type RerankRequest struct {
+	Query      string
+	Filter     ProductFilter
+	Candidates []domain.RerankCandidate
+	Weights    domain.RerankWeights
+}
+
+// RerankResponse is the candidate scores and the LLM usage spent on them (zero for non-LLM rerankers)
+type RerankResponse struct {
+	Scores []domain.RerankScore
+	Usage  domain.LLMUsage
+}
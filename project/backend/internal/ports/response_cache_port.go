@@ -0,0 +1,24 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"keepstar/internal/domain"
+)
+
+// ResponseCachePort stores tenant-scoped pipeline results for repeated queries.
+// Entries are tied to the tenant's catalog version (digest + stock): once either
+// changes, older entries are never returned.
+type ResponseCachePort interface {
+	// LookupResponse returns the best fresh entry for tenant + state key: exact match on the
+	// normalized query first, then the nearest query embedding with cosine similarity
+	// >= minSimilarity (embedding may be nil to skip). Returns nil, nil on miss.
+	LookupResponse(ctx context.Context, tenantSlug, stateKey, queryNorm string, embedding []float32, minSimilarity float64) (*domain.ResponseCacheHit, error)
+
+	// StoreResponse saves an entry under the tenant's current catalog version; ID is assigned
+	StoreResponse(ctx context.Context, entry *domain.ResponseCacheEntry, ttl time.Duration) error
+
+	// InvalidateTenant drops all entries of a tenant, returns the number removed
+	InvalidateTenant(ctx context.Context, tenantSlug string) (int64, error)
+}
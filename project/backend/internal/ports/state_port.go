@@ -53,4 +53,27 @@ type StatePort interface {
 
 	// GetViewStack retrieves the entire view stack for a session
 	GetViewStack(ctx context.Context, sessionID string) ([]domain.ViewSnapshot, error)
+
+	// PushForward pushes the view left by "back" onto the forward stack
+	PushForward(ctx context.Context, sessionID string, snapshot *domain.ViewSnapshot) error
+
+	// PopForward pops and returns the last view snapshot from the forward stack
+	PopForward(ctx context.Context, sessionID string) (*domain.ViewSnapshot, error)
+
+	// ClearForward empties the forward stack (new navigation branch)
+	ClearForward(ctx context.Context, sessionID string) error
+
+	// GetForwardStack retrieves the entire forward stack for a session
+	GetForwardStack(ctx context.Context, sessionID string) ([]domain.ViewSnapshot, error)
+
+	// ForkState creates session forkID as a branch of parentID: a session linked to
+	// the parent, the given state and a copy of the parent's deltas up to toStep
+	ForkState(ctx context.Context, parentID, forkID string, toStep int, state *domain.SessionState) error
+
+	// GetBranches returns the fork tree the session belongs to, flat, parents first
+	GetBranches(ctx context.Context, sessionID string) ([]domain.SessionBranch, error)
+
+	// GetBranchTrees returns the fork trees of several sessions in one go, flat, parents
+	// first; each tree once, unknown sessions are skipped
+	GetBranchTrees(ctx context.Context, sessionIDs []string) ([]domain.SessionBranch, error)
 }
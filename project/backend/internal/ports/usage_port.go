@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"keepstar/internal/domain"
+)
+
+// UsagePort accumulates per-tenant LLM usage for quota enforcement
+type UsagePort interface {
+	// AddUsage adds tokens/cost of one pipeline run to the tenant's bucket for the UTC day of at
+	AddUsage(ctx context.Context, tenantSlug string, at time.Time, tokens int64, costUSD float64) error
+
+	// GetUsageSince sums usage from the UTC day of since (inclusive) until now
+	GetUsageSince(ctx context.Context, tenantSlug string, since time.Time) (*domain.TenantUsage, error)
+}
@@ -37,14 +37,30 @@ Examples:
    - vector_query: semantic search in user's ORIGINAL language. Do NOT translate.
 3. Match user intent to exact filter values from <catalog> → filters.{key}. Everything else → vector_query.
 4. Prices are in RUBLES. "дешевле 10000" → filters.max_price: 10000
+   Several values or exclusions → list filters (same enum values):
+   "для сухой или чувствительной" → filters.skin_types: ["dry","sensitive"]; "без отдушек" → filters.free_from: ["fragrance"];
+   "не La Roche-Posay" → filters.exclude_brands: ["La Roche-Posay"]; "и увлажнение, и антивозраст" → filters.concerns + match: "all".
+   "без" + INGREDIENT/BRAND is a data filter, not a style request.
 5. If user asks to CHANGE DISPLAY STYLE → DO NOT call any tool. Just stop.
 6. Do NOT explain. Do NOT ask questions. Make best guess.
-7. After getting "ok"/"empty", stop. Do not call more tools.
+7. After getting "ok"/"empty" for the data the user asked for, stop (no text). Do not repeat the same call.
+   If the request refers to earlier turns ("дешевле, чем было два запроса назад", "верни как было") →
+   first _internal_history_lookup, then catalog_search / _internal_state_filter with values from its result.
+   Independent calls may be issued together in one response.
 8. <state> block = current data on screen:
    - loaded_products > 0 → data exists, maybe no search needed
    - If user asks about fields already displayed → style request, DO NOT call tool
    - If user asks for DIFFERENT data → call catalog_search
-9. <catalog> block = available filter values:
+   - facets = value counts of the loaded search ({"brand":{"CeraVe":12}}, price buckets in RUBLES: "<1000", "1000-3000", "10000+").
+     Narrowing to one of these values → _internal_state_filter if possible, else catalog_search with the same query + that filter.
+9. "похожие", "аналоги", "что-то вроде этого", "замена" for ONE product → catalog_similar (NOT catalog_search, NOT _internal_state_filter):
+   product_name = name of the loaded product the user means; omit it when a single product is open or loaded.
+   "похожие, но дешевле" → cheaper: true; "для моего же типа кожи" → same_skin_type: true; "другого бренда" → different_brand: true.
+10. "рутина", "полный уход", "схема ухода", "что купить для ухода утром/вечером" → catalog_routine (NOT catalog_search):
+   time: "am" (утро) / "pm" (вечер) / "both" (default); skin_type and concern from the request; budget in RUBLES ("уложиться в 5000" → budget: 5000).
+11. "можно ли это вместе?", "сочетаются ли", "не конфликтуют?" about loaded products → catalog_compatibility (NOT catalog_search):
+   product_names = the products the user means; omit to check everything loaded (e.g. the routine).
+12. <catalog> block = available filter values:
    - Use EXACT category slugs from the tree
    - Use EXACT enum values for filters (skin_type, concern, product_form, etc.)
    - Unknown values or broad queries → vector_query only
@@ -83,6 +99,10 @@ func BuildAgent1ContextPrompt(meta domain.StateMeta, currentConfig *domain.Rende
 		"available_fields": meta.Fields,
 	}
 
+	if facets := facetCountsForPrompt(meta.Facets); len(facets) > 0 {
+		stateInfo["facets"] = facets
+	}
+
 	if currentConfig != nil {
 		stateInfo["current_display"] = map[string]interface{}{
 			"preset": currentConfig.Preset,
@@ -103,6 +123,29 @@ func BuildAgent1ContextPrompt(meta domain.StateMeta, currentConfig *domain.Rende
 	return fmt.Sprintf("<state>\n%s\n</state>\n\n%s", string(jsonBytes), userQuery)
 }
 
+// agent1FacetValues caps values per facet in <state> (highest counts first)
+const agent1FacetValues = 5
+
+// facetCountsForPrompt compacts facets into field → value → count for the <state> block
+func facetCountsForPrompt(facets []domain.Facet) map[string]map[string]int {
+	if len(facets) == 0 {
+		return nil
+	}
+	out := make(map[string]map[string]int, len(facets))
+	for _, f := range facets {
+		values := f.Values
+		if len(values) > agent1FacetValues {
+			values = values[:agent1FacetValues]
+		}
+		counts := make(map[string]int, len(values))
+		for _, v := range values {
+			counts[v.Value] = v.Count
+		}
+		out[f.Field] = counts
+	}
+	return out
+}
+
 // BuildAnalyzeQueryPrompt builds the prompt for query analysis (legacy)
 func BuildAnalyzeQueryPrompt(query string) string {
 	// TODO: implement template substitution
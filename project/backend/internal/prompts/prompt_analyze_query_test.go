@@ -48,3 +48,19 @@ func TestBuildAgent1ContextPrompt_StateAndQuery(t *testing.T) {
 		t.Errorf("expected query after <state>")
 	}
 }
+
+func TestBuildAgent1ContextPrompt_Facets(t *testing.T) {
+	meta := domain.StateMeta{
+		ProductCount: 20,
+		Fields:       []string{"name", "brand", "price"},
+		Facets: []domain.Facet{
+			{Field: domain.FacetBrand, Values: []domain.FacetValue{{Value: "CeraVe", Count: 12}, {Value: "La Roche-Posay", Count: 8}}},
+			{Field: domain.FacetPrice, Values: []domain.FacetValue{{Value: "1000-3000", Count: 15, Min: 1000, Max: 3000}}},
+		},
+	}
+	result := BuildAgent1ContextPrompt(meta, nil, "только подешевле")
+
+	if !strings.Contains(result, `"facets":{"brand":{"CeraVe":12,"La Roche-Posay":8},"price":{"1000-3000":15}}`) {
+		t.Errorf("expected compact facet counts in <state>, got: %s", result)
+	}
+}
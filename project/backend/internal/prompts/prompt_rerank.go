@@ -0,0 +1,67 @@
+package prompts
+
+import (
+	"encoding/json"
+	"strings"
+
+	"keepstar/internal/domain"
+)
+
+// RerankSystemPrompt is the system prompt of the LLM reranker (adapters/rerank)
+const RerankSystemPrompt = `You are a search relevance judge for an e-commerce catalog.
+
+Given a shopper's query and candidate products, rate how well each product answers the query.
+Score from 0.0 (irrelevant) to 1.0 (exactly what the shopper asked for).
+Judge the product itself: type, purpose, skin type, ingredients, brand. Ignore the order of candidates.
+
+Reply with JSON only, no prose:
+{"scores": [{"id": "<candidate id>", "score": 0.0}]}
+Include every candidate id exactly once.`
+
+// rerankCandidate is the compact product view the reranker sees
+type rerankCandidate struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Brand       string   `json:"brand,omitempty"`
+	Category    string   `json:"category,omitempty"`
+	ProductForm string   `json:"form,omitempty"`
+	SkinType    []string `json:"skin_type,omitempty"`
+	Concern     []string `json:"concern,omitempty"`
+	Ingredients []string `json:"key_ingredients,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// rerankDescriptionRunes caps descriptions to keep the prompt small
+const rerankDescriptionRunes = 200
+
+// BuildRerankPrompt renders the query and candidates as the reranker's user message
+func BuildRerankPrompt(query string, candidates []domain.RerankCandidate) string {
+	items := make([]rerankCandidate, len(candidates))
+	for i, c := range candidates {
+		p := c.Product
+		desc := []rune(p.Description)
+		if len(desc) > rerankDescriptionRunes {
+			desc = desc[:rerankDescriptionRunes]
+		}
+		items[i] = rerankCandidate{
+			ID:          p.ID,
+			Name:        p.Name,
+			Brand:       p.Brand,
+			Category:    p.Category,
+			ProductForm: p.ProductForm,
+			SkinType:    p.SkinType,
+			Concern:     p.Concern,
+			Ingredients: p.KeyIngredients,
+			Description: string(desc),
+		}
+	}
+	data, _ := json.Marshal(items)
+
+	var sb strings.Builder
+	sb.WriteString("<query>")
+	sb.WriteString(query)
+	sb.WriteString("</query>\n<candidates>")
+	sb.Write(data)
+	sb.WriteString("</candidates>")
+	return sb.String()
+}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"keepstar/internal/adapters/anthropic"
+	"keepstar/internal/adapters/cassette"
 	"keepstar/internal/adapters/postgres"
 	"keepstar/internal/domain"
 	"keepstar/internal/logger"
@@ -191,6 +194,32 @@ func (m *MockLLMClient) next() (*domain.LLMResponse, error) {
 	}, nil
 }
 
+// CassetteLLM returns an LLMPort backed by testdata/cassettes/{name}.json.
+// Default is replay (offline, fails on any request not in the cassette).
+// LLM_CASSETTE=record re-records against the real Anthropic API (needs ANTHROPIC_API_KEY).
+func CassetteLLM(t *testing.T, name string) ports.LLMPort {
+	t.Helper()
+	path := filepath.Join("testdata", "cassettes", name+".json")
+
+	if os.Getenv("LLM_CASSETTE") == string(cassette.ModeRecord) {
+		apiKey := os.Getenv("ANTHROPIC_API_KEY")
+		if apiKey == "" {
+			t.Fatal("testutil.CassetteLLM: LLM_CASSETTE=record requires ANTHROPIC_API_KEY")
+		}
+		model := os.Getenv("LLM_MODEL")
+		if model == "" {
+			model = "claude-haiku-4-5-20251001"
+		}
+		return cassette.NewRecorder(path, anthropic.NewClient(apiKey, model))
+	}
+
+	llm, err := cassette.NewReplayer(path)
+	if err != nil {
+		t.Fatalf("testutil.CassetteLLM: %v (record with LLM_CASSETTE=record)", err)
+	}
+	return llm
+}
+
 // TestLogger returns a silent logger for tests.
 func TestLogger() *logger.Logger {
 	return logger.New("error")
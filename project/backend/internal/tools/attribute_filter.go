@@ -0,0 +1,158 @@
+package tools
+
+import (
+	"strings"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+)
+
+// PIM vocabularies shared by single-value and list filters
+var (
+	productFormValues   = []string{"cream", "gel", "serum", "toner", "essence", "lotion", "oil", "balm", "foam", "mousse", "mist", "spray", "powder", "stick", "patch", "sheet-mask", "wash-off-mask", "peel", "scrub", "soap"}
+	skinTypeValues      = []string{"normal", "dry", "oily", "combination", "sensitive", "acne-prone", "mature"}
+	concernValues       = []string{"hydration", "anti-aging", "brightening", "acne", "pores", "dark-spots", "redness", "sun-protection", "exfoliation", "firmness", "dark-circles", "lip-dryness", "oil-control", "texture", "dullness"}
+	keyIngredientValues = []string{"hyaluronic-acid", "niacinamide", "retinol", "vitamin-c", "salicylic-acid", "glycolic-acid", "centella-asiatica", "ceramides", "peptides", "snail-mucin", "tea-tree", "aloe-vera", "collagen", "aha-bha", "squalane", "shea-butter", "argan-oil", "rice-extract", "green-tea", "propolis", "mugwort", "panthenol", "zinc", "turmeric", "charcoal"}
+	targetAreaValues    = []string{"face", "eye-area", "lips", "neck", "body", "hands", "feet", "scalp"}
+	freeFromValues      = []string{"parabens", "sulfates", "alcohol", "fragrance", "silicones", "mineral-oil", "artificial-colors", "phthalates", "formaldehyde", "triclosan"}
+)
+
+// attributeFilterProperties is the JSON schema of list-valued include/exclude filters,
+// shared by catalog_search (filters object) and _internal_state_filter
+func attributeFilterProperties() map[string]interface{} {
+	return map[string]interface{}{
+		"brands":                  stringListSchema(nil, "Any of these brands (OR). Use instead of 'brand' for several brands."),
+		"exclude_brands":          stringListSchema(nil, "Brands to exclude (e.g. 'не La Roche-Posay')."),
+		"product_forms":           stringListSchema(productFormValues, "Any of these product forms (OR)."),
+		"exclude_product_forms":   stringListSchema(productFormValues, "Product forms to exclude."),
+		"skin_types":              stringListSchema(skinTypeValues, "Skin types, combined per 'match' (e.g. 'для сухой или чувствительной' → [dry, sensitive])."),
+		"concerns":                stringListSchema(concernValues, "Concerns, combined per 'match'."),
+		"key_ingredients":         stringListSchema(keyIngredientValues, "Key ingredients, combined per 'match'."),
+		"exclude_key_ingredients": stringListSchema(keyIngredientValues, "Ingredients the product must NOT contain as key actives (e.g. 'без ретинола')."),
+		"target_areas":            stringListSchema(targetAreaValues, "Target areas, combined per 'match'."),
+		"free_from":               stringListSchema(freeFromValues, "Product must be free from ALL of these (e.g. 'без отдушек' → [fragrance])."),
+		"match": map[string]interface{}{
+			"type":        "string",
+			"enum":        []string{ports.MatchAny, ports.MatchAll},
+			"description": "How list filters on skin_types/concerns/key_ingredients/target_areas combine: 'any' (default, OR) or 'all' (AND).",
+		},
+	}
+}
+
+func stringListSchema(enum []string, description string) map[string]interface{} {
+	items := map[string]interface{}{"type": "string"}
+	if enum != nil {
+		items["enum"] = enum
+	}
+	return map[string]interface{}{
+		"type":        "array",
+		"items":       items,
+		"description": description,
+	}
+}
+
+// parseAttributeFilter reads list filters from tool input; a plain string counts as a one-item list
+func parseAttributeFilter(input map[string]interface{}) ports.AttributeFilter {
+	f := ports.AttributeFilter{
+		Brands:                parseStringList(input["brands"]),
+		ProductForms:          parseStringList(input["product_forms"]),
+		SkinTypes:             parseStringList(input["skin_types"]),
+		Concerns:              parseStringList(input["concerns"]),
+		KeyIngredients:        parseStringList(input["key_ingredients"]),
+		TargetAreas:           parseStringList(input["target_areas"]),
+		FreeFrom:              parseStringList(input["free_from"]),
+		ExcludeBrands:         parseStringList(input["exclude_brands"]),
+		ExcludeProductForms:   parseStringList(input["exclude_product_forms"]),
+		ExcludeKeyIngredients: parseStringList(input["exclude_key_ingredients"]),
+	}
+	if m, _ := input["match"].(string); m == ports.MatchAll {
+		f.Match = ports.MatchAll
+	}
+	return f
+}
+
+func parseStringList(v interface{}) []string {
+	var out []string
+	switch val := v.(type) {
+	case string:
+		if s := strings.TrimSpace(val); s != "" {
+			out = append(out, s)
+		}
+	case []interface{}:
+		for _, item := range val {
+			if s, ok := item.(string); ok && strings.TrimSpace(s) != "" {
+				out = append(out, strings.TrimSpace(s))
+			}
+		}
+	case []string:
+		for _, s := range val {
+			if strings.TrimSpace(s) != "" {
+				out = append(out, strings.TrimSpace(s))
+			}
+		}
+	}
+	return out
+}
+
+// matchAttributes applies list filters to an in-memory product with the same semantics as the SQL builder
+func matchAttributes(p domain.Product, f ports.AttributeFilter) bool {
+	if len(f.Brands) > 0 && !containsAnyCI(p.Brand, f.Brands) {
+		return false
+	}
+	if len(f.ExcludeBrands) > 0 && containsAnyCI(p.Brand, f.ExcludeBrands) {
+		return false
+	}
+	if len(f.ProductForms) > 0 && !hasValues([]string{p.ProductForm}, f.ProductForms, false) {
+		return false
+	}
+	if len(f.ExcludeProductForms) > 0 && hasValues([]string{p.ProductForm}, f.ExcludeProductForms, false) {
+		return false
+	}
+	all := f.Match == ports.MatchAll
+	if len(f.SkinTypes) > 0 && !hasValues(p.SkinType, f.SkinTypes, all) {
+		return false
+	}
+	if len(f.Concerns) > 0 && !hasValues(p.Concern, f.Concerns, all) {
+		return false
+	}
+	if len(f.KeyIngredients) > 0 && !hasValues(p.KeyIngredients, f.KeyIngredients, all) {
+		return false
+	}
+	if len(f.TargetAreas) > 0 && !hasValues(p.TargetArea, f.TargetAreas, all) {
+		return false
+	}
+	if len(f.FreeFrom) > 0 && !hasValues(p.FreeFrom, f.FreeFrom, true) {
+		return false
+	}
+	if len(f.ExcludeKeyIngredients) > 0 && hasValues(p.KeyIngredients, f.ExcludeKeyIngredients, false) {
+		return false
+	}
+	return true
+}
+
+// hasValues reports whether have contains any (all=false) or every (all=true) wanted value
+func hasValues(have, want []string, all bool) bool {
+	set := make(map[string]bool, len(have))
+	for _, h := range have {
+		set[h] = true
+	}
+	for _, w := range want {
+		if set[w] && !all {
+			return true
+		}
+		if !set[w] && all {
+			return false
+		}
+	}
+	return all
+}
+
+// containsAnyCI reports whether s contains any of substrs, case-insensitively
+func containsAnyCI(s string, substrs []string) bool {
+	for _, sub := range substrs {
+		if containsCI(s, sub) {
+			return true
+		}
+	}
+	return false
+}
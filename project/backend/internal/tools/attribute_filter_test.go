@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"testing"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+)
+
+func TestParseAttributeFilter(t *testing.T) {
+	f := parseAttributeFilter(map[string]interface{}{
+		"skin_types":     []interface{}{"dry", " sensitive ", ""},
+		"exclude_brands": "La Roche-Posay",
+		"free_from":      []interface{}{"fragrance"},
+		"match":          "all",
+	})
+
+	if len(f.SkinTypes) != 2 || f.SkinTypes[1] != "sensitive" {
+		t.Errorf("unexpected skin types: %v", f.SkinTypes)
+	}
+	if len(f.ExcludeBrands) != 1 || f.ExcludeBrands[0] != "La Roche-Posay" {
+		t.Errorf("string should parse as one-item list, got %v", f.ExcludeBrands)
+	}
+	if f.Match != ports.MatchAll {
+		t.Errorf("expected match=all, got %q", f.Match)
+	}
+	if f.IsEmpty() {
+		t.Error("expected non-empty filter")
+	}
+	if !parseAttributeFilter(map[string]interface{}{"match": "all"}).IsEmpty() {
+		t.Error("match alone should leave the filter empty")
+	}
+}
+
+func TestMatchAttributes(t *testing.T) {
+	p := domain.Product{
+		Brand:          "CeraVe",
+		ProductForm:    "cream",
+		SkinType:       []string{"dry", "normal"},
+		KeyIngredients: []string{"ceramides", "hyaluronic-acid"},
+		FreeFrom:       []string{"fragrance", "parabens"},
+	}
+
+	tests := []struct {
+		name string
+		f    ports.AttributeFilter
+		want bool
+	}{
+		{"empty", ports.AttributeFilter{}, true},
+		{"skin any", ports.AttributeFilter{SkinTypes: []string{"dry", "sensitive"}}, true},
+		{"skin all", ports.AttributeFilter{SkinTypes: []string{"dry", "sensitive"}, Match: ports.MatchAll}, false},
+		{"free from all", ports.AttributeFilter{FreeFrom: []string{"fragrance", "parabens"}}, true},
+		{"free from missing", ports.AttributeFilter{FreeFrom: []string{"fragrance", "alcohol"}}, false},
+		{"brands or", ports.AttributeFilter{Brands: []string{"cerave", "COSRX"}}, true},
+		{"exclude brand", ports.AttributeFilter{ExcludeBrands: []string{"cerave"}}, false},
+		{"exclude other brand", ports.AttributeFilter{ExcludeBrands: []string{"La Roche-Posay"}}, true},
+		{"exclude form", ports.AttributeFilter{ExcludeProductForms: []string{"cream", "gel"}}, false},
+		{"exclude ingredient", ports.AttributeFilter{ExcludeKeyIngredients: []string{"retinol"}}, true},
+		{"exclude present ingredient", ports.AttributeFilter{ExcludeKeyIngredients: []string{"ceramides"}}, false},
+	}
+	for _, tt := range tests {
+		if got := matchAttributes(p, tt.f); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
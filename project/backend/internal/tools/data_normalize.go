@@ -18,6 +18,7 @@ func NormalizeProduct(p *domain.Product) {
 	p.SkinType = dedup(p.SkinType)
 	p.Concern = dedup(p.Concern)
 	p.KeyIngredients = dedup(p.KeyIngredients)
+	p.FreeFrom = dedup(p.FreeFrom)
 	p.Images = filterEmpty(p.Images)
 }
 
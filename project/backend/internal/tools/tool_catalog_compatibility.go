@@ -0,0 +1,216 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+)
+
+// compatibilityMaxProducts caps the products checked pairwise
+const compatibilityMaxProducts = 20
+
+// CatalogCompatibilityTool checks loaded products (or the routine) against the ingredient
+// interaction rules ("можно ли это вместе?"). Data stays as is; the report goes to
+// StateMeta.Compatibility and is rendered as an info card.
+type CatalogCompatibilityTool struct {
+	statePort   ports.StatePort
+	catalogPort ports.CatalogPort
+}
+
+// NewCatalogCompatibilityTool creates the ingredient compatibility tool
+func NewCatalogCompatibilityTool(statePort ports.StatePort, catalogPort ports.CatalogPort) *CatalogCompatibilityTool {
+	return &CatalogCompatibilityTool{
+		statePort:   statePort,
+		catalogPort: catalogPort,
+	}
+}
+
+// Definition returns the tool definition for LLM
+func (t *CatalogCompatibilityTool) Definition() domain.ToolDefinition {
+	return domain.ToolDefinition{
+		Name:        "catalog_compatibility",
+		Description: "Check whether loaded products can be used together (\"можно ли это вместе?\", \"сочетаются ли\"): matches their ingredients against known interactions (retinoids + acids, vitamin C + niacinamide, ...). Does not change the products; the answer is shown as a card with warnings.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"product_names": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Names (or parts of names) of loaded products to check; omit to check all loaded products",
+				},
+			},
+		},
+	}
+}
+
+// Execute checks product pairs and writes the report to StateMeta
+func (t *CatalogCompatibilityTool) Execute(ctx context.Context, toolCtx ToolContext, input map[string]interface{}) (*domain.ToolResult, error) {
+	meta := map[string]interface{}{}
+
+	var names []string
+	if raw, ok := input["product_names"].([]interface{}); ok {
+		for _, v := range raw {
+			if s, ok := v.(string); ok && strings.TrimSpace(s) != "" {
+				names = append(names, strings.ToLower(strings.TrimSpace(s)))
+			}
+		}
+	}
+
+	sc := domain.SpanFromContext(ctx)
+	stage := domain.StageFromContext(ctx)
+
+	state, err := t.statePort.GetState(ctx, toolCtx.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("get state: %w", err)
+	}
+
+	products := selectCompatibilityProducts(state.Current.Data.Products, names)
+	if len(products) > compatibilityMaxProducts {
+		products = products[:compatibilityMaxProducts]
+	}
+	if len(products) < 2 {
+		return &domain.ToolResult{
+			Content:  "error: need at least two loaded products to check compatibility",
+			Metadata: meta,
+			IsError:  true,
+		}, nil
+	}
+
+	tenantSlug := toolTenantSlug(toolCtx, state)
+	meta["tenant"] = tenantSlug
+	tenant, err := t.catalogPort.GetTenantBySlug(ctx, tenantSlug)
+	if err != nil {
+		return nil, fmt.Errorf("get tenant: %w", err)
+	}
+
+	var endSQL func(...string)
+	if sc != nil && stage != "" {
+		endSQL = sc.Start(stage + ".tool.sql")
+	}
+	ids := make([]string, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	inci, err := t.catalogPort.GetProductIngredients(ctx, tenant.ID, ids)
+	if err != nil {
+		if endSQL != nil {
+			endSQL("error")
+		}
+		return nil, fmt.Errorf("get product ingredients: %w", err)
+	}
+	rules, err := t.catalogPort.GetIngredientInteractions(ctx)
+	if err != nil {
+		if endSQL != nil {
+			endSQL("error")
+		}
+		return nil, fmt.Errorf("get ingredient interactions: %w", err)
+	}
+	if endSQL != nil {
+		endSQL(fmt.Sprintf("%d products, %d rules", len(products), len(rules)))
+	}
+	meta["rules"] = len(rules)
+
+	report := checkCompatibility(products, inci, state.Current.Meta.Routine, rules)
+	return t.writeReport(ctx, toolCtx, state, report, input, meta)
+}
+
+// writeReport stores the report next to the unchanged data and describes it for the agent
+func (t *CatalogCompatibilityTool) writeReport(ctx context.Context, toolCtx ToolContext, state *domain.SessionState, report *domain.CompatibilityReport, input map[string]interface{}, meta map[string]interface{}) (*domain.ToolResult, error) {
+	meta["checked"] = report.Checked
+	meta["warnings"] = len(report.Warnings)
+	report.TurnID = toolCtx.TurnID
+
+	stateMeta := state.Current.Meta
+	stateMeta.Compatibility = report
+	info := domain.DeltaInfo{
+		TurnID:    toolCtx.TurnID,
+		Trigger:   domain.TriggerUserQuery,
+		Source:    domain.SourceLLM,
+		ActorID:   toolCtx.ActorID,
+		DeltaType: domain.DeltaTypeUpdate,
+		Path:      "meta.compatibility",
+		Action:    domain.Action{Type: domain.ActionCheck, Tool: "catalog_compatibility", Params: input},
+		Result:    domain.ResultMeta{Count: len(report.Warnings)},
+	}
+	if _, err := t.statePort.UpdateData(ctx, toolCtx.SessionID, state.Current.Data, stateMeta, info); err != nil {
+		return nil, fmt.Errorf("update data: %w", err)
+	}
+
+	if len(report.Warnings) == 0 {
+		return &domain.ToolResult{
+			Content:  fmt.Sprintf("ok: checked %d products, no known ingredient conflicts", report.Checked),
+			Metadata: meta,
+		}, nil
+	}
+	lines := make([]string, 0, len(report.Warnings))
+	for _, w := range report.Warnings {
+		lines = append(lines, fmt.Sprintf("%s: %s (%s) × %s (%s)", w.Severity, w.ProductA, w.IngredientA, w.ProductB, w.IngredientB))
+	}
+	return &domain.ToolResult{
+		Content:  fmt.Sprintf("ok: checked %d products, %d warnings: %s", report.Checked, len(report.Warnings), strings.Join(lines, "; ")),
+		Metadata: meta,
+	}, nil
+}
+
+// turnCompatibility returns the compatibility report of this turn (nil when the last check
+// was made on an earlier turn), so render tools put the card only on the answer to the check
+func turnCompatibility(state *domain.SessionState, toolCtx ToolContext) *domain.CompatibilityReport {
+	if report := state.Current.Meta.Compatibility; report != nil && report.TurnID == toolCtx.TurnID {
+		return report
+	}
+	return nil
+}
+
+// selectCompatibilityProducts returns the loaded products whose name contains one of names (all when names is empty)
+func selectCompatibilityProducts(products []domain.Product, names []string) []domain.Product {
+	if len(names) == 0 {
+		return products
+	}
+	var selected []domain.Product
+	for _, p := range products {
+		lower := strings.ToLower(p.Name)
+		for _, n := range names {
+			if strings.Contains(lower, n) {
+				selected = append(selected, p)
+				break
+			}
+		}
+	}
+	return selected
+}
+
+// checkCompatibility builds ingredient sets (INCI list + key_ingredients; routine times when
+// the data is a routine) and applies the rules. Products with no known ingredients are skipped.
+func checkCompatibility(products []domain.Product, inci map[string][]string, routine *domain.Routine, rules []domain.IngredientInteraction) *domain.CompatibilityReport {
+	times := make(map[string][]string)
+	if routine != nil {
+		for _, s := range routine.Sections {
+			for _, st := range s.Steps {
+				if st.ProductID != "" {
+					times[st.ProductID] = append(times[st.ProductID], s.Time)
+				}
+			}
+		}
+	}
+
+	sets := make([]domain.IngredientSet, 0, len(products))
+	for _, p := range products {
+		ingredients := append(append([]string{}, inci[p.ID]...), p.KeyIngredients...)
+		if len(ingredients) == 0 {
+			continue
+		}
+		sets = append(sets, domain.IngredientSet{
+			ProductID:   p.ID,
+			ProductName: p.Name,
+			Ingredients: ingredients,
+			Times:       times[p.ID],
+		})
+	}
+	return &domain.CompatibilityReport{
+		Checked:  len(sets),
+		Warnings: domain.CheckInteractions(sets, rules),
+	}
+}
@@ -0,0 +1,178 @@
+package tools_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/presets"
+	"keepstar/internal/tools"
+)
+
+// compatibilityCatalogPort serves INCI lists and interaction rules on top of mockCatalogPortCapture
+type compatibilityCatalogPort struct {
+	*mockCatalogPortCapture
+	inci      map[string][]string
+	rules     []domain.IngredientInteraction
+	askedInci []string
+}
+
+func (m *compatibilityCatalogPort) GetIngredientInteractions(_ context.Context) ([]domain.IngredientInteraction, error) {
+	return m.rules, nil
+}
+
+func (m *compatibilityCatalogPort) GetProductIngredients(_ context.Context, _ string, productIDs []string) (map[string][]string, error) {
+	m.askedInci = productIDs
+	return m.inci, nil
+}
+
+func newCompatibilityCatalogPort() *compatibilityCatalogPort {
+	return &compatibilityCatalogPort{
+		mockCatalogPortCapture: &mockCatalogPortCapture{},
+		inci: map[string][]string{
+			"p1": {"Aqua", "Retinol", "Squalane"},
+			"p2": {"Aqua", "Salicylic Acid"},
+		},
+		rules: []domain.IngredientInteraction{{
+			Name:     "retinoids + AHA/BHA",
+			GroupA:   []string{"retinol"},
+			GroupB:   []string{"salicylic acid"},
+			Severity: domain.InteractionAvoid,
+			Message:  "irritation",
+			Advice:   "acids AM, retinol PM",
+		}},
+	}
+}
+
+func compatibilityState() *domain.SessionState {
+	state := defaultState()
+	state.Current.Data.Products = []domain.Product{
+		{ID: "p1", Name: "Retinol Cream"},
+		{ID: "p2", Name: "BHA Toner"},
+		{ID: "p3", Name: "Gentle Gel", KeyIngredients: []string{"centella-asiatica"}},
+	}
+	state.Current.Meta.Count = 3
+	return state
+}
+
+func TestCatalogCompatibility_Warning(t *testing.T) {
+	sp := newMockStatePort(compatibilityState())
+	cp := newCompatibilityCatalogPort()
+	tool := tools.NewCatalogCompatibilityTool(sp, cp)
+
+	result, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(result.Content, "ok: checked 3 products, 1 warnings") {
+		t.Errorf("unexpected result %q", result.Content)
+	}
+
+	report := sp.state.Current.Meta.Compatibility
+	if report == nil || len(report.Warnings) != 1 {
+		t.Fatalf("expected one warning in StateMeta, got %+v", report)
+	}
+	if w := report.Warnings[0]; w.ProductA != "Retinol Cream" || w.IngredientB != "Salicylic Acid" {
+		t.Errorf("unexpected warning %+v", w)
+	}
+	if got := productIDs(sp.state.Current.Data.Products); got != "p1,p2,p3" {
+		t.Errorf("products should stay as they were, got %s", got)
+	}
+	if sp.state.Current.Meta.Aliases["tenant_slug"] != "nike" || sp.state.Current.Meta.Count != 3 {
+		t.Error("existing meta should be preserved")
+	}
+	if d := sp.LastDeltaInfo; d == nil || d.Path != "meta.compatibility" || d.Action.Type != domain.ActionCheck {
+		t.Errorf("expected a meta.compatibility check delta, got %+v", d)
+	}
+}
+
+func TestCatalogCompatibility_ProductNamesAndNoConflicts(t *testing.T) {
+	sp := newMockStatePort(compatibilityState())
+	cp := newCompatibilityCatalogPort()
+	tool := tools.NewCatalogCompatibilityTool(sp, cp)
+
+	result, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{
+		"product_names": []interface{}{"retinol", "gentle"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Join(cp.askedInci, ",") != "p1,p3" {
+		t.Errorf("only named products should be checked, got %v", cp.askedInci)
+	}
+	if !strings.Contains(result.Content, "no known ingredient conflicts") {
+		t.Errorf("unexpected result %q", result.Content)
+	}
+	if report := sp.state.Current.Meta.Compatibility; report == nil || report.Checked != 2 || len(report.Warnings) != 0 {
+		t.Errorf("expected a clean report of 2 products, got %+v", report)
+	}
+}
+
+func TestCatalogCompatibility_CardRenderedIntoTemplateOfItsTurn(t *testing.T) {
+	sp := newMockStatePort(compatibilityState())
+	check := tools.NewCatalogCompatibilityTool(sp, newCompatibilityCatalogPort())
+	render := tools.NewRenderProductPresetTool(sp, presets.NewPresetRegistry())
+	ctx := context.Background()
+
+	turn1 := defaultToolCtx()
+	if _, err := check.Execute(ctx, turn1, map[string]interface{}{}); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if report := sp.state.Current.Meta.Compatibility; report == nil || report.TurnID != turn1.TurnID {
+		t.Fatalf("expected the report keyed by turn-1, got %+v", report)
+	}
+	if _, err := render.Execute(ctx, turn1, map[string]interface{}{"preset": "product_grid"}); err != nil {
+		t.Fatalf("render turn-1: %v", err)
+	}
+	formation, _ := sp.state.Current.Template["formation"].(*domain.FormationWithData)
+	if formation == nil || len(formation.Widgets) != 4 || formation.Widgets[0].ID != "compatibility" {
+		t.Fatalf("expected the card in front of 3 products in the stored template, got %+v", formation)
+	}
+
+	// The next turn renders the same products without the old answer
+	turn2 := defaultToolCtx()
+	turn2.TurnID = "turn-2"
+	if _, err := render.Execute(ctx, turn2, map[string]interface{}{"preset": "product_grid"}); err != nil {
+		t.Fatalf("render turn-2: %v", err)
+	}
+	formation, _ = sp.state.Current.Template["formation"].(*domain.FormationWithData)
+	if formation == nil || len(formation.Widgets) != 3 || formation.Widgets[0].ID == "compatibility" {
+		t.Errorf("expected no card on a later turn, got %+v", formation)
+	}
+}
+
+func TestCatalogCompatibility_RoutineTimes(t *testing.T) {
+	state := compatibilityState()
+	state.Current.Meta.Routine = &domain.Routine{Sections: []domain.RoutineSection{
+		{Time: domain.RoutineTimeAM, Steps: []domain.RoutineStep{{Step: "toning", ProductID: "p2"}}},
+		{Time: domain.RoutineTimePM, Steps: []domain.RoutineStep{{Step: "treatment", ProductID: "p1"}}},
+	}}
+	sp := newMockStatePort(state)
+	tool := tools.NewCatalogCompatibilityTool(sp, newCompatibilityCatalogPort())
+
+	if _, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report := sp.state.Current.Meta.Compatibility; report == nil || len(report.Warnings) != 0 {
+		t.Errorf("acids in the morning and retinol in the evening should not clash, got %+v", report)
+	}
+}
+
+func TestCatalogCompatibility_NeedsTwoProducts(t *testing.T) {
+	state := defaultState()
+	state.Current.Data.Products = []domain.Product{{ID: "p1", Name: "Retinol Cream"}}
+	sp := newMockStatePort(state)
+	tool := tools.NewCatalogCompatibilityTool(sp, newCompatibilityCatalogPort())
+
+	result, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("expected an error result, got %q", result.Content)
+	}
+	if sp.UpdateDataCalls != 0 {
+		t.Error("state should not change")
+	}
+}
@@ -0,0 +1,306 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+)
+
+// routineCandidates is how many products per step are considered when fitting the budget
+const routineCandidates = 10
+
+// CatalogRoutineTool assembles an ordered AM/PM skincare routine: one product per routine step
+type CatalogRoutineTool struct {
+	statePort   ports.StatePort
+	catalogPort ports.CatalogPort
+
+	defaultStock string // stock policy for tenants without settings.stock
+}
+
+// NewCatalogRoutineTool creates the routine builder tool
+func NewCatalogRoutineTool(statePort ports.StatePort, catalogPort ports.CatalogPort) *CatalogRoutineTool {
+	return &CatalogRoutineTool{
+		statePort:   statePort,
+		catalogPort: catalogPort,
+	}
+}
+
+// WithStockPolicy sets the stock policy (domain.StockPolicy*) for tenants without settings.stock
+func (t *CatalogRoutineTool) WithStockPolicy(defaultPolicy string) *CatalogRoutineTool {
+	t.defaultStock = defaultPolicy
+	return t
+}
+
+// Definition returns the tool definition for LLM
+func (t *CatalogRoutineTool) Definition() domain.ToolDefinition {
+	return domain.ToolDefinition{
+		Name:        "catalog_routine",
+		Description: "Build a complete skincare routine (уход, рутина, \"что купить для ухода\"): one product per step, cleanser → toner → serum → moisturizer → SPF (morning) and cleanser → toner → serum → moisturizer (evening). Rendered as a step-by-step regimen with the total price.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"time": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{domain.RoutineTimeAM, domain.RoutineTimePM, domain.RoutineTimeBoth},
+					"description": "Morning (am), evening (pm) or both routines (default both)",
+				},
+				"skin_type": map[string]interface{}{
+					"type":        "string",
+					"enum":        skinTypeValues,
+					"description": "Skin type every product must suit",
+				},
+				"concern": map[string]interface{}{
+					"type":        "string",
+					"enum":        concernValues,
+					"description": "Main concern; steps without a matching product fall back to any product of the step",
+				},
+				"budget": map[string]interface{}{
+					"type":        "number",
+					"description": "Total budget for the whole routine in RUBLES",
+				},
+			},
+		},
+	}
+}
+
+// routineSlot is one step of one routine time with its candidates, best first
+type routineSlot struct {
+	time       string
+	step       string
+	candidates []domain.Product
+}
+
+// Execute picks one product per step within the budget and writes products + StateMeta.Routine
+func (t *CatalogRoutineTool) Execute(ctx context.Context, toolCtx ToolContext, input map[string]interface{}) (*domain.ToolResult, error) {
+	meta := map[string]interface{}{}
+
+	routineTime, _ := input["time"].(string)
+	skinType, _ := input["skin_type"].(string)
+	concern, _ := input["concern"].(string)
+	var budget int
+	if v, ok := input["budget"].(float64); ok && v > 0 {
+		budget = int(v) * 100 // rubles → kopecks
+	}
+	times := []string{domain.RoutineTimeAM, domain.RoutineTimePM}
+	switch routineTime {
+	case domain.RoutineTimeAM, domain.RoutineTimePM:
+		times = []string{routineTime}
+	}
+
+	sc := domain.SpanFromContext(ctx)
+	stage := domain.StageFromContext(ctx)
+
+	state, err := t.statePort.GetState(ctx, toolCtx.SessionID)
+	if err == domain.ErrSessionNotFound {
+		state, err = t.statePort.CreateState(ctx, toolCtx.SessionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get/create state: %w", err)
+	}
+
+	tenantSlug := toolTenantSlug(toolCtx, state)
+	meta["tenant"] = tenantSlug
+	tenant, err := t.catalogPort.GetTenantBySlug(ctx, tenantSlug)
+	if err != nil {
+		return nil, fmt.Errorf("get tenant: %w", err)
+	}
+	stock := domain.StockConfigFromSettings(tenant.Settings, t.defaultStock)
+	if stock.Policy != "" {
+		meta["stock_policy"] = stock.Policy
+	}
+
+	// Candidates per step, loosening the concern and then the routine time when a step has none
+	var endSQL func(...string)
+	if sc != nil && stage != "" {
+		endSQL = sc.Start(stage + ".tool.sql")
+	}
+	var slots []routineSlot
+	var relaxed []string
+	for _, rt := range times {
+		for _, step := range domain.RoutineSteps(rt) {
+			filter := ports.ProductFilter{
+				RoutineStep: step,
+				RoutineTime: rt,
+				SkinType:    skinType,
+				Concern:     concern,
+				SortField:   "rating",
+				SortOrder:   "desc",
+				Limit:       routineCandidates,
+				InStockOnly: stock.Policy == domain.StockPolicyHide,
+			}
+			candidates, _, err := t.catalogPort.ListProducts(ctx, tenant.ID, filter)
+			if err == nil && len(candidates) == 0 && filter.Concern != "" {
+				filter.Concern = ""
+				candidates, _, err = t.catalogPort.ListProducts(ctx, tenant.ID, filter)
+				if len(candidates) > 0 {
+					relaxed = append(relaxed, rt+":"+step+" concern")
+				}
+			}
+			if err == nil && len(candidates) == 0 {
+				filter.RoutineTime = ""
+				candidates, _, err = t.catalogPort.ListProducts(ctx, tenant.ID, filter)
+				if len(candidates) > 0 {
+					relaxed = append(relaxed, rt+":"+step+" time")
+				}
+			}
+			if err != nil {
+				if endSQL != nil {
+					endSQL("error")
+				}
+				return nil, fmt.Errorf("list %s products: %w", step, err)
+			}
+			for i := range candidates {
+				NormalizeProduct(&candidates[i])
+			}
+			candidates = applyStockPolicy(stock.Policy, candidates, map[string]interface{}{})
+			slots = append(slots, routineSlot{time: rt, step: step, candidates: candidates})
+		}
+	}
+	if endSQL != nil {
+		endSQL(fmt.Sprintf("%d steps", len(slots)))
+	}
+	if len(relaxed) > 0 {
+		meta["relaxed"] = relaxed
+	}
+
+	routine, products := buildRoutine(slots, budget)
+	routine.SkinType = skinType
+	routine.Concern = concern
+	meta["routine_total"] = routine.Total
+	if missing := routine.Missing(); len(missing) > 0 {
+		meta["missing_steps"] = missing
+	}
+	if routine.OverBudget() {
+		meta["over_budget"] = routine.Total - routine.Budget
+	}
+
+	info := domain.DeltaInfo{
+		TurnID:    toolCtx.TurnID,
+		Trigger:   domain.TriggerUserQuery,
+		Source:    domain.SourceLLM,
+		ActorID:   toolCtx.ActorID,
+		DeltaType: domain.DeltaTypeAdd,
+		Path:      "data.products",
+		Action:    domain.Action{Type: domain.ActionSearch, Tool: "catalog_routine", Params: input},
+	}
+
+	if len(products) == 0 {
+		// Empty result — don't overwrite state data, just record delta
+		if _, err := t.statePort.AddDelta(ctx, toolCtx.SessionID, info.ToDelta()); err != nil {
+			return nil, fmt.Errorf("add empty delta: %w", err)
+		}
+		return &domain.ToolResult{
+			Content:  "empty: no products for routine steps, previous data preserved",
+			Metadata: meta,
+		}, nil
+	}
+
+	fields := catalogExtractProductFields(products[0])
+	stateMeta := domain.StateMeta{
+		Count:   len(products),
+		Fields:  fields,
+		Aliases: state.Current.Meta.Aliases, // preserve tenant_slug
+		Routine: routine,
+	}
+	if stock.Policy != "" {
+		stateMeta.Stock = &stock
+	}
+	info.Result = domain.ResultMeta{Count: len(products), Fields: fields}
+	if _, err := t.statePort.UpdateData(ctx, toolCtx.SessionID, domain.StateData{Products: products}, stateMeta, info); err != nil {
+		return nil, fmt.Errorf("update data: %w", err)
+	}
+
+	msg := fmt.Sprintf("ok: routine of %d products, total %d руб", len(products), routine.Total/100)
+	if missing := routine.Missing(); len(missing) > 0 {
+		msg += "; no products for " + strings.Join(missing, ", ")
+	}
+	if routine.OverBudget() {
+		msg += fmt.Sprintf("; over budget by %d руб", (routine.Total-routine.Budget)/100)
+	}
+	return &domain.ToolResult{
+		Content:  msg,
+		Metadata: meta,
+	}, nil
+}
+
+// buildRoutine picks one candidate per slot and returns the routine with its distinct products in step order.
+// A product already picked for the other routine time is reused (a cleanser serves morning and evening).
+// With a budget, each slot takes its best candidate that still leaves room for the cheapest
+// candidates of the following slots; when nothing fits, the cheapest one (the routine goes over budget).
+func buildRoutine(slots []routineSlot, budget int) (*domain.Routine, []domain.Product) {
+	routine := &domain.Routine{Budget: budget}
+	picked := make(map[string]bool)
+	var products []domain.Product
+
+	// cheapest[i] = sum of the cheapest candidate prices of slots i..n-1
+	cheapest := make([]int, len(slots)+1)
+	for i := len(slots) - 1; i >= 0; i-- {
+		cheapest[i] = cheapest[i+1]
+		if c := cheapestProduct(slots[i].candidates); c != nil {
+			cheapest[i] += c.Price
+		}
+	}
+
+	for i, slot := range slots {
+		var choice *domain.Product
+		for j := range slot.candidates {
+			if picked[slot.candidates[j].ID] {
+				choice = &slot.candidates[j]
+				break
+			}
+		}
+		if choice == nil && len(slot.candidates) > 0 {
+			if budget > 0 {
+				room := budget - routine.Total - cheapest[i+1]
+				for j := range slot.candidates {
+					if slot.candidates[j].Price <= room {
+						choice = &slot.candidates[j]
+						break
+					}
+				}
+				if choice == nil {
+					choice = cheapestProduct(slot.candidates)
+				}
+			} else {
+				choice = &slot.candidates[0]
+			}
+		}
+
+		step := domain.RoutineStep{Step: slot.step, Label: domain.RoutineStepLabel(slot.step)}
+		if choice != nil {
+			step.ProductID = choice.ID
+			if !picked[choice.ID] {
+				picked[choice.ID] = true
+				routine.Total += choice.Price
+				if routine.Currency == "" {
+					routine.Currency = choice.Currency
+				}
+				products = append(products, *choice)
+			}
+		}
+
+		if n := len(routine.Sections); n == 0 || routine.Sections[n-1].Time != slot.time {
+			routine.Sections = append(routine.Sections, domain.RoutineSection{
+				Time:  slot.time,
+				Label: domain.RoutineTimeLabel(slot.time),
+			})
+		}
+		section := &routine.Sections[len(routine.Sections)-1]
+		section.Steps = append(section.Steps, step)
+	}
+	return routine, products
+}
+
+// cheapestProduct returns the lowest-priced product (first on ties), nil for none
+func cheapestProduct(products []domain.Product) *domain.Product {
+	var best *domain.Product
+	for i := range products {
+		if best == nil || products[i].Price < best.Price {
+			best = &products[i]
+		}
+	}
+	return best
+}
@@ -0,0 +1,185 @@
+package tools_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+	"keepstar/internal/tools"
+)
+
+// routineCatalogPort serves ListProducts by routine step ("time:step" or "step" for any time)
+type routineCatalogPort struct {
+	*mockCatalogPortCapture
+	byStep  map[string][]domain.Product
+	filters []ports.ProductFilter
+}
+
+func (m *routineCatalogPort) ListProducts(_ context.Context, _ string, filter ports.ProductFilter) ([]domain.Product, int, error) {
+	m.filters = append(m.filters, filter)
+	key := filter.RoutineStep
+	if filter.RoutineTime != "" {
+		key = filter.RoutineTime + ":" + key
+	}
+	if filter.Concern != "" {
+		key += "+" + filter.Concern
+	}
+	products := m.byStep[key]
+	return products, len(products), nil
+}
+
+func newRoutineCatalogPort() *routineCatalogPort {
+	cleanser := domain.Product{ID: "cl", Name: "Gel", Price: 100000}
+	return &routineCatalogPort{
+		mockCatalogPortCapture: &mockCatalogPortCapture{},
+		byStep: map[string][]domain.Product{
+			"am:cleansing":      {cleanser},
+			"pm:cleansing":      {{ID: "oil", Name: "Oil", Price: 150000}, cleanser},
+			"am:toning":         {{ID: "tn", Name: "Toner", Price: 80000}},
+			"pm:toning":         {{ID: "tn", Name: "Toner", Price: 80000}},
+			"am:treatment":      {{ID: "vc", Name: "Vitamin C", Price: 300000}, {ID: "nia", Name: "Niacinamide", Price: 90000}},
+			"pm:treatment":      {{ID: "ret", Name: "Retinol", Price: 250000}},
+			"am:moisturizing":   {{ID: "cr", Name: "Cream", Price: 120000}},
+			"pm:moisturizing":   {{ID: "cr", Name: "Cream", Price: 120000}},
+			"am:sun-protection": {{ID: "spf", Name: "SPF 50", Price: 110000}},
+		},
+	}
+}
+
+func TestCatalogRoutine_MorningRoutine(t *testing.T) {
+	sp := newMockStatePort(defaultState())
+	cp := newRoutineCatalogPort()
+	tool := tools.NewCatalogRoutineTool(sp, cp)
+
+	result, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{
+		"time":      "am",
+		"skin_type": "oily",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(result.Content, "ok") {
+		t.Fatalf("expected ok, got %q", result.Content)
+	}
+
+	if got := productIDs(sp.state.Current.Data.Products); got != "cl,tn,vc,cr,spf" {
+		t.Errorf("expected best product per step in routine order, got %s", got)
+	}
+	routine := sp.state.Current.Meta.Routine
+	if routine == nil || len(routine.Sections) != 1 || routine.Sections[0].Time != domain.RoutineTimeAM {
+		t.Fatalf("expected a single morning section, got %+v", routine)
+	}
+	if routine.Total != 710000 {
+		t.Errorf("expected total 710000, got %d", routine.Total)
+	}
+	for _, f := range cp.filters {
+		if f.SkinType != "oily" || f.RoutineTime != domain.RoutineTimeAM {
+			t.Errorf("every step should be filtered by skin type and time, got %+v", f)
+		}
+	}
+	if d := sp.LastDeltaInfo; d == nil || d.Action.Tool != "catalog_routine" {
+		t.Errorf("expected a catalog_routine delta, got %+v", d)
+	}
+}
+
+func TestCatalogRoutine_ReusesProductsAcrossTimes(t *testing.T) {
+	sp := newMockStatePort(defaultState())
+	cp := newRoutineCatalogPort()
+	tool := tools.NewCatalogRoutineTool(sp, cp)
+
+	if _, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	routine := sp.state.Current.Meta.Routine
+	if routine == nil || len(routine.Sections) != 2 {
+		t.Fatalf("expected morning and evening sections, got %+v", routine)
+	}
+	pm := routine.Sections[1]
+	if pm.Steps[0].ProductID != "cl" {
+		t.Errorf("evening cleansing should reuse the morning cleanser, got %q", pm.Steps[0].ProductID)
+	}
+	if got := productIDs(sp.state.Current.Data.Products); got != "cl,tn,vc,cr,spf,ret" {
+		t.Errorf("products should be distinct, got %s", got)
+	}
+	if routine.Total != 960000 {
+		t.Errorf("reused products should be counted once, got total %d", routine.Total)
+	}
+}
+
+func TestCatalogRoutine_Budget(t *testing.T) {
+	sp := newMockStatePort(defaultState())
+	cp := newRoutineCatalogPort()
+	tool := tools.NewCatalogRoutineTool(sp, cp)
+
+	result, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{
+		"time":   "am",
+		"budget": float64(5000),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	routine := sp.state.Current.Meta.Routine
+	if routine.Budget != 500000 {
+		t.Errorf("budget should be stored in kopecks, got %d", routine.Budget)
+	}
+	if got := routine.Sections[0].Steps[2].ProductID; got != "nia" {
+		t.Errorf("treatment should fall back to the candidate that fits the budget, got %q", got)
+	}
+	if routine.Total != 500000 || routine.OverBudget() {
+		t.Errorf("expected total 500000 within budget, got %d", routine.Total)
+	}
+	if strings.Contains(result.Content, "over budget") {
+		t.Errorf("unexpected over budget note: %q", result.Content)
+	}
+}
+
+func TestCatalogRoutine_MissingStepAndRelaxedConcern(t *testing.T) {
+	sp := newMockStatePort(defaultState())
+	cp := newRoutineCatalogPort()
+	delete(cp.byStep, "am:sun-protection")
+	cp.byStep["am:toning+acne"] = []domain.Product{{ID: "bha", Name: "BHA Toner", Price: 95000}}
+	tool := tools.NewCatalogRoutineTool(sp, cp)
+
+	result, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{
+		"time":    "am",
+		"concern": "acne",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	routine := sp.state.Current.Meta.Routine
+	if got := routine.Sections[0].Steps[1].ProductID; got != "bha" {
+		t.Errorf("concern match should win for toning, got %q", got)
+	}
+	if got := routine.Sections[0].Steps[0].ProductID; got != "cl" {
+		t.Errorf("cleansing should fall back to any concern, got %q", got)
+	}
+	if missing := routine.Missing(); len(missing) != 1 || missing[0] != "am:sun-protection" {
+		t.Errorf("expected missing SPF step, got %v", missing)
+	}
+	if !strings.Contains(result.Content, "am:sun-protection") {
+		t.Errorf("result should report the missing step, got %q", result.Content)
+	}
+}
+
+func TestCatalogRoutine_NoProducts(t *testing.T) {
+	sp := newMockStatePort(defaultState())
+	cp := &routineCatalogPort{mockCatalogPortCapture: &mockCatalogPortCapture{}}
+	tool := tools.NewCatalogRoutineTool(sp, cp)
+
+	result, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(result.Content, "empty") {
+		t.Errorf("expected empty, got %q", result.Content)
+	}
+	if sp.UpdateDataCalls != 0 {
+		t.Error("state data should not change")
+	}
+}
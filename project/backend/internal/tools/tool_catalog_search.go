@@ -17,6 +17,11 @@ type CatalogSearchTool struct {
 	statePort   ports.StatePort
 	catalogPort ports.CatalogPort
 	embedding   ports.EmbeddingPort // nil = keyword-only mode
+
+	rerankers     map[string]ports.RerankerPort // by Name(); empty = RRF order as is
+	defaultRerank string                        // strategy for tenants without settings.rerank
+
+	defaultStock string // stock policy for tenants without settings.stock; "" = stock is ignored
 }
 
 // NewCatalogSearchTool creates the catalog search meta-tool
@@ -28,8 +33,81 @@ func NewCatalogSearchTool(statePort ports.StatePort, catalogPort ports.CatalogPo
 	}
 }
 
+// WithRerankers registers rerankers tenants can select; defaultStrategy applies to tenants without settings.rerank
+func (t *CatalogSearchTool) WithRerankers(defaultStrategy string, rerankers ...ports.RerankerPort) *CatalogSearchTool {
+	t.rerankers = make(map[string]ports.RerankerPort, len(rerankers))
+	for _, r := range rerankers {
+		t.rerankers[r.Name()] = r
+	}
+	t.defaultRerank = defaultStrategy
+	return t
+}
+
+// WithStockPolicy sets the stock policy (domain.StockPolicy*) for tenants without settings.stock
+func (t *CatalogSearchTool) WithStockPolicy(defaultPolicy string) *CatalogSearchTool {
+	t.defaultStock = defaultPolicy
+	return t
+}
+
 // Definition returns the tool definition for LLM
 func (t *CatalogSearchTool) Definition() domain.ToolDefinition {
+	filterProps := map[string]interface{}{
+		"brand": map[string]interface{}{
+			"type":        "string",
+			"description": "Brand name (e.g. COSRX, MEDI-PEEL, Holika Holika)",
+		},
+		"category": map[string]interface{}{
+			"type":        "string",
+			"description": "Category name (e.g. Сыворотки, Кремы)",
+		},
+		"min_price": map[string]interface{}{
+			"type":        "number",
+			"description": "Minimum price in RUBLES",
+		},
+		"max_price": map[string]interface{}{
+			"type":        "number",
+			"description": "Maximum price in RUBLES",
+		},
+		"product_form": map[string]interface{}{
+			"type":        "string",
+			"enum":        productFormValues,
+			"description": "Product form/type",
+		},
+		"skin_type": map[string]interface{}{
+			"type":        "string",
+			"enum":        skinTypeValues,
+			"description": "Target skin type",
+		},
+		"concern": map[string]interface{}{
+			"type":        "string",
+			"enum":        concernValues,
+			"description": "Skin concern to address",
+		},
+		"key_ingredient": map[string]interface{}{
+			"type":        "string",
+			"enum":        keyIngredientValues,
+			"description": "Key active ingredient",
+		},
+		"routine_step": map[string]interface{}{
+			"type":        "string",
+			"enum":        []string{"cleansing", "toning", "exfoliation", "treatment", "moisturizing", "sun-protection", "makeup"},
+			"description": "Step in skincare routine",
+		},
+		"texture": map[string]interface{}{
+			"type":        "string",
+			"enum":        []string{"watery", "gel", "milky", "creamy", "thick", "oily", "powdery", "foamy", "balmy"},
+			"description": "Product texture",
+		},
+		"target_area": map[string]interface{}{
+			"type":        "string",
+			"enum":        targetAreaValues,
+			"description": "Target application area",
+		},
+	}
+	for k, v := range attributeFilterProperties() {
+		filterProps[k] = v
+	}
+
 	return domain.ToolDefinition{
 		Name:        "catalog_search",
 		Description: "Hybrid catalog search for products and services. Put structured/exact filters in 'filters'. Put semantic search intent in 'vector_query' in user's original language.",
@@ -48,59 +126,7 @@ func (t *CatalogSearchTool) Definition() domain.ToolDefinition {
 				"filters": map[string]interface{}{
 					"type":        "object",
 					"description": "Exact filters. Only include filters you're confident about.",
-					"properties": map[string]interface{}{
-						"brand": map[string]interface{}{
-							"type":        "string",
-							"description": "Brand name (e.g. COSRX, MEDI-PEEL, Holika Holika)",
-						},
-						"category": map[string]interface{}{
-							"type":        "string",
-							"description": "Category name (e.g. Сыворотки, Кремы)",
-						},
-						"min_price": map[string]interface{}{
-							"type":        "number",
-							"description": "Minimum price in RUBLES",
-						},
-						"max_price": map[string]interface{}{
-							"type":        "number",
-							"description": "Maximum price in RUBLES",
-						},
-						"product_form": map[string]interface{}{
-							"type":        "string",
-							"enum":        []string{"cream", "gel", "serum", "toner", "essence", "lotion", "oil", "balm", "foam", "mousse", "mist", "spray", "powder", "stick", "patch", "sheet-mask", "wash-off-mask", "peel", "scrub", "soap"},
-							"description": "Product form/type",
-						},
-						"skin_type": map[string]interface{}{
-							"type":        "string",
-							"enum":        []string{"normal", "dry", "oily", "combination", "sensitive", "acne-prone", "mature"},
-							"description": "Target skin type",
-						},
-						"concern": map[string]interface{}{
-							"type":        "string",
-							"enum":        []string{"hydration", "anti-aging", "brightening", "acne", "pores", "dark-spots", "redness", "sun-protection", "exfoliation", "firmness", "dark-circles", "lip-dryness", "oil-control", "texture", "dullness"},
-							"description": "Skin concern to address",
-						},
-						"key_ingredient": map[string]interface{}{
-							"type":        "string",
-							"enum":        []string{"hyaluronic-acid", "niacinamide", "retinol", "vitamin-c", "salicylic-acid", "glycolic-acid", "centella-asiatica", "ceramides", "peptides", "snail-mucin", "tea-tree", "aloe-vera", "collagen", "aha-bha", "squalane", "shea-butter", "argan-oil", "rice-extract", "green-tea", "propolis", "mugwort", "panthenol", "zinc", "turmeric", "charcoal"},
-							"description": "Key active ingredient",
-						},
-						"routine_step": map[string]interface{}{
-							"type":        "string",
-							"enum":        []string{"cleansing", "toning", "exfoliation", "treatment", "moisturizing", "sun-protection", "makeup"},
-							"description": "Step in skincare routine",
-						},
-						"texture": map[string]interface{}{
-							"type":        "string",
-							"enum":        []string{"watery", "gel", "milky", "creamy", "thick", "oily", "powdery", "foamy", "balmy"},
-							"description": "Product texture",
-						},
-						"target_area": map[string]interface{}{
-							"type":        "string",
-							"enum":        []string{"face", "eye-area", "lips", "neck", "body", "hands", "feet", "scalp"},
-							"description": "Target application area",
-						},
-					},
+					"properties":  filterProps,
 				},
 				"sort_by": map[string]interface{}{
 					"type": "string",
@@ -144,6 +170,7 @@ func (t *CatalogSearchTool) Execute(ctx context.Context, toolCtx ToolContext, in
 	var brand, category string
 	var minPrice, maxPrice int
 	var productForm, skinType, concern, keyIngredient, routineStep, texture, targetArea string
+	var attrs ports.AttributeFilter
 
 	if filters, ok := input["filters"].(map[string]interface{}); ok {
 		brand, _ = filters["brand"].(string)
@@ -161,6 +188,7 @@ func (t *CatalogSearchTool) Execute(ctx context.Context, toolCtx ToolContext, in
 		routineStep, _ = filters["routine_step"].(string)
 		texture, _ = filters["texture"].(string)
 		targetArea, _ = filters["target_area"].(string)
+		attrs = parseAttributeFilter(filters)
 	}
 
 	// Convert prices: rubles → kopecks (×100)
@@ -201,50 +229,118 @@ func (t *CatalogSearchTool) Execute(ctx context.Context, toolCtx ToolContext, in
 	if err != nil {
 		return nil, fmt.Errorf("get tenant: %w", err)
 	}
+	stock := domain.StockConfigFromSettings(tenant.Settings, t.defaultStock)
+	if stock.Policy != "" {
+		meta["stock_policy"] = stock.Policy
+	}
 
-	// Prepare product filter
-	filter := ports.ProductFilter{
-		Search:        vectorQuery,
-		Brand:         brand,
-		CategoryName:  category,
-		MinPrice:      minPriceKopecks,
-		MaxPrice:      maxPriceKopecks,
-		SortField:     sortBy,
-		SortOrder:     sortOrder,
-		Limit:         limit * 2,
-		ProductForm:   productForm,
-		SkinType:      skinType,
-		Concern:       concern,
-		KeyIngredient: keyIngredient,
-		TargetArea:    targetArea,
-		RoutineStep:   routineStep,
-		Texture:       texture,
-	}
-	if filter.Brand != "" && filter.Search != "" {
-		cleaned := strings.TrimSpace(removeSubstringIgnoreCase(filter.Search, filter.Brand))
-		if cleaned != "" {
-			filter.Search = cleaned
+	// With a reranker the product legs fetch and merge at least top_n candidates, so rerank can
+	// promote items from below the page; the list is cut back to limit after rerank
+	rerankCfg := domain.RerankConfigFromSettings(tenant.Settings, t.defaultRerank)
+	reranker := t.rerankers[rerankCfg.Strategy]
+	pool := limit
+	if reranker != nil && rerankCfg.TopN > pool {
+		pool = rerankCfg.TopN
+	}
+
+	// Normalize inputs: wrong keyboard layout in the query, misspelled or transliterated brand/category
+	var corrections []domain.FilterCorrection
+	if fixed, ok := domain.FixKeyboardLayout(vectorQuery); ok {
+		corrections = append(corrections, domain.FilterCorrection{Field: "query", From: vectorQuery, To: fixed, Method: domain.CorrectionLayout})
+		vectorQuery = fixed
+	}
+	typedBrands := append([]string{brand}, attrs.Brands...)
+	if brand != "" || category != "" || len(attrs.Brands) > 0 || len(attrs.ExcludeBrands) > 0 {
+		var endNormalize func(...string)
+		if sc != nil && stage != "" {
+			endNormalize = sc.Start(stage + ".tool.normalize")
 		}
+		if c := t.correctFilterValue(ctx, tenant.ID, "brand", brand, meta); c != nil {
+			corrections = append(corrections, *c)
+			brand = c.To
+		}
+		if c := t.correctFilterValue(ctx, tenant.ID, "category", category, meta); c != nil {
+			corrections = append(corrections, *c)
+			category = c.To
+		}
+		for _, brands := range [][]string{attrs.Brands, attrs.ExcludeBrands} {
+			for i, b := range brands {
+				if c := t.correctFilterValue(ctx, tenant.ID, "brand", b, meta); c != nil {
+					corrections = append(corrections, *c)
+					brands[i] = c.To
+				}
+			}
+		}
+		if endNormalize != nil {
+			endNormalize("filter correction")
+		}
+	}
+	if len(corrections) > 0 {
+		meta["corrections"] = corrections
 	}
 
-	// Prepare service filter
-	svcFilter := ports.ProductFilter{
-		Search:       vectorQuery,
-		Brand:        brand,
-		CategoryName: category,
-		MinPrice:     minPriceKopecks,
-		MaxPrice:     maxPriceKopecks,
-		SortField:    sortBy,
-		SortOrder:    sortOrder,
-		Limit:        limit * 2,
-	}
-	if svcFilter.Brand != "" && svcFilter.Search != "" {
-		cleaned := strings.TrimSpace(removeSubstringIgnoreCase(svcFilter.Search, svcFilter.Brand))
-		if cleaned != "" {
-			svcFilter.Search = cleaned
+	// Expand the query with tenant synonyms (feeds both the keyword leg and the embedding)
+	if vectorQuery != "" {
+		var endSynonyms func(...string)
+		if sc != nil && stage != "" {
+			endSynonyms = sc.Start(stage + ".tool.synonyms")
+		}
+		synonyms, synErr := t.catalogPort.GetSynonyms(ctx, tenant.ID)
+		if synErr != nil {
+			meta["synonyms_error"] = synErr.Error()
+		}
+		expanded, expansions := domain.ExpandQuery(vectorQuery, synonyms)
+		if len(expansions) > 0 {
+			vectorQuery = expanded
+			lines := make([]string, len(expansions))
+			for i, e := range expansions {
+				lines[i] = e.String()
+			}
+			meta["synonym_expansions"] = lines
+		}
+		if endSynonyms != nil {
+			endSynonyms(fmt.Sprintf("%d expansions", len(expansions)))
 		}
 	}
 
+	// Prepare product filter
+	filter := ports.ProductFilter{
+		Search:          vectorQuery,
+		Brand:           brand,
+		CategoryName:    category,
+		MinPrice:        minPriceKopecks,
+		MaxPrice:        maxPriceKopecks,
+		SortField:       sortBy,
+		SortOrder:       sortOrder,
+		Limit:           pool * 2,
+		ProductForm:     productForm,
+		SkinType:        skinType,
+		Concern:         concern,
+		KeyIngredient:   keyIngredient,
+		TargetArea:      targetArea,
+		RoutineStep:     routineStep,
+		Texture:         texture,
+		AttributeFilter: attrs,
+		InStockOnly:     stock.Policy == domain.StockPolicyHide,
+	}
+	// Brands are matched by filters; as typed and as corrected they only add noise to keyword search
+	brandTerms := append(append(append(typedBrands, brand), attrs.Brands...), attrs.ExcludeBrands...)
+	filter.Search = stripBrands(filter.Search, brandTerms)
+
+	// Prepare service filter
+	svcFilter := ports.ProductFilter{
+		Search:          vectorQuery,
+		Brand:           brand,
+		CategoryName:    category,
+		MinPrice:        minPriceKopecks,
+		MaxPrice:        maxPriceKopecks,
+		SortField:       sortBy,
+		SortOrder:       sortOrder,
+		Limit:           limit * 2,
+		AttributeFilter: ports.AttributeFilter{Brands: attrs.Brands, ExcludeBrands: attrs.ExcludeBrands},
+	}
+	svcFilter.Search = stripBrands(svcFilter.Search, brandTerms)
+
 	// ── Phase 1: Embedding + keyword searches in parallel ──
 
 	var queryEmbedding []float32
@@ -341,8 +437,14 @@ func (t *CatalogSearchTool) Execute(ctx context.Context, toolCtx ToolContext, in
 	if queryEmbedding != nil {
 		// Build vector filter once (shared, read-only)
 		var vf *ports.VectorFilter
-		if brand != "" || category != "" || productForm != "" || skinType != "" || concern != "" || keyIngredient != "" || targetArea != "" || routineStep != "" || texture != "" {
-			vf = &ports.VectorFilter{Brand: brand, CategoryName: category, ProductForm: productForm, SkinType: skinType, Concern: concern, KeyIngredient: keyIngredient, TargetArea: targetArea, RoutineStep: routineStep, Texture: texture}
+		if brand != "" || category != "" || productForm != "" || skinType != "" || concern != "" || keyIngredient != "" || targetArea != "" || routineStep != "" || texture != "" || !attrs.IsEmpty() {
+			vf = &ports.VectorFilter{Brand: brand, CategoryName: category, ProductForm: productForm, SkinType: skinType, Concern: concern, KeyIngredient: keyIngredient, TargetArea: targetArea, RoutineStep: routineStep, Texture: texture, AttributeFilter: attrs}
+		}
+		if filter.InStockOnly {
+			if vf == nil {
+				vf = &ports.VectorFilter{}
+			}
+			vf.InStockOnly = true
 		}
 
 		g2, ctx2 := errgroup.WithContext(ctx)
@@ -355,7 +457,7 @@ func (t *CatalogSearchTool) Execute(ctx context.Context, toolCtx ToolContext, in
 					endVector = sc.Start(stage + ".tool.vector")
 				}
 				vectorStart := time.Now()
-				vectorProducts, vectorErr = t.catalogPort.VectorSearch(ctx2, tenant.ID, queryEmbedding, limit*2, vf)
+				vectorProducts, vectorErr = t.catalogPort.VectorSearch(ctx2, tenant.ID, queryEmbedding, pool*2, vf)
 				vectorMs = time.Since(vectorStart).Milliseconds()
 				if endVector != nil {
 					endVector("pgvector")
@@ -373,8 +475,8 @@ func (t *CatalogSearchTool) Execute(ctx context.Context, toolCtx ToolContext, in
 				}
 				svcVectorStart := time.Now()
 				var svcVF *ports.VectorFilter
-				if brand != "" || category != "" {
-					svcVF = &ports.VectorFilter{Brand: brand, CategoryName: category}
+				if brand != "" || category != "" || !svcFilter.AttributeFilter.IsEmpty() {
+					svcVF = &ports.VectorFilter{Brand: brand, CategoryName: category, AttributeFilter: svcFilter.AttributeFilter}
 				}
 				vectorServices, svcVectorErr = t.catalogPort.VectorSearchServices(ctx2, tenant.ID, queryEmbedding, limit*2, svcVF)
 				svcVectorMs = time.Since(svcVectorStart).Milliseconds()
@@ -410,10 +512,11 @@ func (t *CatalogSearchTool) Execute(ctx context.Context, toolCtx ToolContext, in
 	}
 
 	// RRF merge for products
-	hasFilters := brand != "" || category != "" || productForm != "" || skinType != "" || concern != "" || keyIngredient != "" || routineStep != "" || texture != "" || targetArea != ""
+	hasFilters := brand != "" || category != "" || productForm != "" || skinType != "" || concern != "" || keyIngredient != "" || routineStep != "" || texture != "" || targetArea != "" || !attrs.IsEmpty()
 	var merged []domain.Product
+	var mergedScores []float64
 	if entityType != "service" {
-		merged = rrfMerge(keywordProducts, vectorProducts, limit, hasFilters)
+		merged, mergedScores = rrfMergeScored(keywordProducts, vectorProducts, pool, hasFilters)
 	}
 
 	// Normalize product data
@@ -421,6 +524,22 @@ func (t *CatalogSearchTool) Execute(ctx context.Context, toolCtx ToolContext, in
 		NormalizeProduct(&merged[i])
 	}
 
+	// Rerank the head of the merged list with the tenant's reranker
+	var rerankUsage domain.LLMUsage
+	if reranker != nil && len(merged) > 1 {
+		rerankQuery := toolCtx.UserQuery
+		if rerankQuery == "" {
+			rerankQuery = vectorQuery
+		}
+		merged, rerankUsage = t.rerank(ctx, reranker, rerankCfg, rerankQuery, filter, merged, mergedScores, meta)
+	}
+	if len(merged) > limit {
+		merged = merged[:limit]
+	}
+
+	// Out-of-stock products go last (or away) whatever their relevance
+	merged = applyStockPolicy(stock.Policy, merged, meta)
+
 	// RRF merge for services
 	mergedServices := rrfMergeServices(keywordServices, vectorServices, limit, hasFilters)
 
@@ -460,6 +579,30 @@ func (t *CatalogSearchTool) Execute(ctx context.Context, toolCtx ToolContext, in
 		}, nil
 	}
 
+	// ── Phase 3: Facet counts over everything the search matched ──
+
+	var facets []domain.Facet
+	if len(merged) > 0 {
+		var endFacets func(...string)
+		if sc != nil && stage != "" {
+			endFacets = sc.Start(stage + ".tool.facets")
+		}
+		facetIDs := make([]string, 0, len(vectorProducts))
+		for _, p := range vectorProducts {
+			facetIDs = append(facetIDs, p.ID)
+		}
+		facetsStart := time.Now()
+		var facetsErr error
+		facets, facetsErr = t.catalogPort.GetProductFacets(ctx, tenant.ID, filter, facetIDs)
+		meta["facets_ms"] = time.Since(facetsStart).Milliseconds()
+		if facetsErr != nil {
+			meta["facets_error"] = facetsErr.Error()
+		}
+		if endFacets != nil {
+			endFacets("facet counts")
+		}
+	}
+
 	// Extract fields from first product or service
 	var fields []string
 	if len(merged) > 0 {
@@ -474,6 +617,10 @@ func (t *CatalogSearchTool) Execute(ctx context.Context, toolCtx ToolContext, in
 		Count:   total,
 		Fields:  fields,
 		Aliases: state.Current.Meta.Aliases, // preserve tenant_slug
+		Facets:  facets,
+	}
+	if stock.Policy != "" {
+		stateMeta.Stock = &stock
 	}
 
 	info := domain.DeltaInfo{
@@ -494,16 +641,152 @@ func (t *CatalogSearchTool) Execute(ctx context.Context, toolCtx ToolContext, in
 	if len(mergedServices) > 0 {
 		resultMsg += fmt.Sprintf(", %d services", len(mergedServices))
 	}
+	for _, c := range corrections {
+		resultMsg += fmt.Sprintf("; %s %q corrected to %q", c.Field, c.From, c.To)
+	}
 
-	return &domain.ToolResult{
+	result := &domain.ToolResult{
 		Content:  resultMsg,
 		Metadata: meta,
-	}, nil
+	}
+	if rerankUsage != (domain.LLMUsage{}) {
+		result.Usage = &rerankUsage
+	}
+	return result, nil
+}
+
+// stripBrands removes brand names from keyword search text, keeping the text if nothing else is left
+func stripBrands(search string, brands []string) string {
+	cleaned := search
+	for _, b := range brands {
+		if b != "" {
+			cleaned = removeSubstringIgnoreCase(cleaned, b)
+		}
+	}
+	if cleaned = strings.TrimSpace(cleaned); cleaned == "" {
+		return search
+	}
+	return cleaned
+}
+
+// correctFilterValue snaps a brand/category value to a known catalog value.
+// Fails open: on error the value is used as typed and the error lands in meta.
+func (t *CatalogSearchTool) correctFilterValue(ctx context.Context, tenantID, field, value string, meta map[string]interface{}) *domain.FilterCorrection {
+	if value == "" {
+		return nil
+	}
+	c, err := t.catalogPort.CorrectFilterValue(ctx, tenantID, field, value)
+	if err != nil {
+		meta[field+"_correction_error"] = err.Error()
+		return nil
+	}
+	return c
+}
+
+// rerank rescores the top N merged products with the reranker selected in tenant settings.
+// Products past N keep their RRF order. Fails open: on error the RRF order is kept and the error lands in meta.
+// The returned usage is what an LLM reranker spent, also on error.
+func (t *CatalogSearchTool) rerank(ctx context.Context, reranker ports.RerankerPort, cfg domain.RerankConfig, query string, filter ports.ProductFilter, products []domain.Product, baseScores []float64, meta map[string]interface{}) ([]domain.Product, domain.LLMUsage) {
+	n := cfg.TopN
+	if n > len(products) {
+		n = len(products)
+	}
+	candidates := make([]domain.RerankCandidate, n)
+	for i := 0; i < n; i++ {
+		candidates[i] = domain.RerankCandidate{Product: products[i], BaseScore: baseScores[i]}
+	}
+
+	var endRerank func(...string)
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		if stage := domain.StageFromContext(ctx); stage != "" {
+			endRerank = sc.Start(stage + ".tool.rerank")
+		}
+	}
+	rerankStart := time.Now()
+	resp, err := reranker.Rerank(ctx, ports.RerankRequest{Query: query, Filter: filter, Candidates: candidates, Weights: cfg.Weights})
+	meta["rerank_ms"] = time.Since(rerankStart).Milliseconds()
+	meta["reranker"] = reranker.Name()
+	if endRerank != nil {
+		endRerank(fmt.Sprintf("%s top %d", reranker.Name(), n))
+	}
+	var usage domain.LLMUsage
+	if resp != nil {
+		usage = resp.Usage
+	}
+	if usage != (domain.LLMUsage{}) {
+		meta["rerank_tokens"] = usage.TotalTokens
+		meta["rerank_cost_usd"] = usage.CostUSD
+	}
+	if err != nil {
+		meta["rerank_error"] = err.Error()
+		return products, usage
+	}
+
+	byID := make(map[string]domain.RerankScore, len(resp.Scores))
+	for _, s := range resp.Scores {
+		byID[s.ID] = s
+	}
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	// Stable: equal scores (and candidates the reranker skipped) keep RRF order
+	sort.SliceStable(order, func(a, b int) bool {
+		return byID[products[order[a]].ID].Score > byID[products[order[b]].ID].Score
+	})
+
+	reranked := make([]domain.Product, 0, len(products))
+	lines := make([]string, n)
+	for rank, i := range order {
+		reranked = append(reranked, products[i])
+		s, ok := byID[products[i].ID]
+		if !ok {
+			s = domain.RerankScore{ID: products[i].ID}
+		}
+		lines[rank] = fmt.Sprintf("#%d←#%d %s", rank+1, i+1, s.String())
+	}
+	meta["rerank_scores"] = lines
+	return append(reranked, products[n:]...), usage
+}
+
+// applyStockPolicy hides (StockPolicyHide) or moves to the end (StockPolicyDemote) products
+// without available stock, keeping the order within in-stock and out-of-stock products.
+// Products without a stock row (StockAvailable nil) count as in stock, as they do in SQL.
+// Hide is normally done in SQL (ProductFilter.InStockOnly); here it only guards against
+// adapters that ignore the flag.
+func applyStockPolicy(policy string, products []domain.Product, meta map[string]interface{}) []domain.Product {
+	if policy != domain.StockPolicyHide && policy != domain.StockPolicyDemote {
+		return products
+	}
+	inStock := make([]domain.Product, 0, len(products))
+	var outOfStock []domain.Product
+	for _, p := range products {
+		if n, tracked := p.AvailableStock(); tracked && n <= 0 {
+			outOfStock = append(outOfStock, p)
+		} else {
+			inStock = append(inStock, p)
+		}
+	}
+	if len(outOfStock) == 0 {
+		return products
+	}
+	if policy == domain.StockPolicyHide {
+		meta["stock_hidden"] = len(outOfStock)
+		return inStock
+	}
+	meta["stock_demoted"] = len(outOfStock)
+	return append(inStock, outOfStock...)
 }
 
 // rrfMerge combines keyword and vector results using Reciprocal Rank Fusion (k=60).
 // Keyword results are weighted higher (1.5×, or 2.0× when structured filters are present).
 func rrfMerge(keyword, vector []domain.Product, limit int, hasFilters bool) []domain.Product {
+	merged, _ := rrfMergeScored(keyword, vector, limit, hasFilters)
+	return merged
+}
+
+// rrfMergeScored is rrfMerge that also returns the fused score of every result
+func rrfMergeScored(keyword, vector []domain.Product, limit int, hasFilters bool) ([]domain.Product, []float64) {
 	const k = 60
 	scores := make(map[string]float64)
 	products := make(map[string]domain.Product)
@@ -537,13 +820,15 @@ func rrfMerge(keyword, vector []domain.Product, limit int, hasFilters bool) []do
 	})
 
 	var result []domain.Product
+	var resultScores []float64
 	for i, s := range sorted {
 		if i >= limit {
 			break
 		}
 		result = append(result, products[s.id])
+		resultScores = append(resultScores, s.score)
 	}
-	return result
+	return result, resultScores
 }
 
 // rrfMergeServices combines keyword and vector service results using RRF (k=60).
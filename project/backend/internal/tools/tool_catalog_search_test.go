@@ -2,6 +2,8 @@ package tools_test
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -91,6 +93,27 @@ func (m *mockStatePort) PopView(_ context.Context, _ string) (*domain.ViewSnapsh
 func (m *mockStatePort) GetViewStack(_ context.Context, _ string) ([]domain.ViewSnapshot, error) {
 	return nil, nil
 }
+func (m *mockStatePort) PushForward(_ context.Context, _ string, _ *domain.ViewSnapshot) error {
+	return nil
+}
+func (m *mockStatePort) PopForward(_ context.Context, _ string) (*domain.ViewSnapshot, error) {
+	return nil, nil
+}
+func (m *mockStatePort) ClearForward(_ context.Context, _ string) error {
+	return nil
+}
+func (m *mockStatePort) GetForwardStack(_ context.Context, _ string) ([]domain.ViewSnapshot, error) {
+	return nil, nil
+}
+func (m *mockStatePort) ForkState(_ context.Context, _, _ string, _ int, _ *domain.SessionState) error {
+	return nil
+}
+func (m *mockStatePort) GetBranches(_ context.Context, _ string) ([]domain.SessionBranch, error) {
+	return nil, nil
+}
+func (m *mockStatePort) GetBranchTrees(_ context.Context, _ []string) ([]domain.SessionBranch, error) {
+	return nil, nil
+}
 
 // --- Mock CatalogPort ---
 
@@ -98,6 +121,8 @@ type mockCatalogPort struct {
 	products       []domain.Product
 	total          int
 	vectorProducts []domain.Product
+	facets         []domain.Facet
+	facetIDs       []string // captured productIDs of the last GetProductFacets call
 }
 
 func (m *mockCatalogPort) GetTenantBySlug(_ context.Context, slug string) (*domain.Tenant, error) {
@@ -115,6 +140,25 @@ func (m *mockCatalogPort) ListProducts(_ context.Context, _ string, _ ports.Prod
 func (m *mockCatalogPort) GetProduct(_ context.Context, _ string, _ string) (*domain.Product, error) {
 	return nil, nil
 }
+func (m *mockCatalogPort) GetProductFacets(_ context.Context, _ string, _ ports.ProductFilter, productIDs []string) ([]domain.Facet, error) {
+	m.facetIDs = productIDs
+	return m.facets, nil
+}
+func (m *mockCatalogPort) CorrectFilterValue(_ context.Context, _ string, _ string, _ string) (*domain.FilterCorrection, error) {
+	return nil, nil
+}
+func (m *mockCatalogPort) GetSynonyms(_ context.Context, _ string) ([]domain.Synonym, error) {
+	return nil, nil
+}
+func (m *mockCatalogPort) GetProductEmbedding(_ context.Context, _ string, _ string) ([]float32, error) {
+	return nil, nil
+}
+func (m *mockCatalogPort) GetIngredientInteractions(_ context.Context) ([]domain.IngredientInteraction, error) {
+	return nil, nil
+}
+func (m *mockCatalogPort) GetProductIngredients(_ context.Context, _ string, _ []string) (map[string][]string, error) {
+	return nil, nil
+}
 func (m *mockCatalogPort) VectorSearch(_ context.Context, _ string, _ []float32, _ int, _ *ports.VectorFilter) ([]domain.Product, error) {
 	return m.vectorProducts, nil
 }
@@ -162,11 +206,14 @@ type mockCatalogPortCapture struct {
 	total          int
 	vectorProducts []domain.Product
 	captureFilter  *ports.ProductFilter
-	captureVF      *ports.VectorFilter // captured vector filter
+	captureVF      *ports.VectorFilter                 // captured vector filter
+	corrections    map[string]*domain.FilterCorrection // keyed by field+":"+value
+	synonyms       []domain.Synonym
+	tenantSettings map[string]any
 }
 
 func (m *mockCatalogPortCapture) GetTenantBySlug(_ context.Context, slug string) (*domain.Tenant, error) {
-	return &domain.Tenant{ID: "t1", Slug: slug}, nil
+	return &domain.Tenant{ID: "t1", Slug: slug, Settings: m.tenantSettings}, nil
 }
 func (m *mockCatalogPortCapture) GetCategories(_ context.Context) ([]domain.Category, error) {
 	return nil, nil
@@ -183,6 +230,24 @@ func (m *mockCatalogPortCapture) ListProducts(_ context.Context, _ string, filte
 func (m *mockCatalogPortCapture) GetProduct(_ context.Context, _ string, _ string) (*domain.Product, error) {
 	return nil, nil
 }
+func (m *mockCatalogPortCapture) GetProductFacets(_ context.Context, _ string, _ ports.ProductFilter, _ []string) ([]domain.Facet, error) {
+	return nil, nil
+}
+func (m *mockCatalogPortCapture) CorrectFilterValue(_ context.Context, _ string, field string, value string) (*domain.FilterCorrection, error) {
+	return m.corrections[field+":"+value], nil
+}
+func (m *mockCatalogPortCapture) GetSynonyms(_ context.Context, _ string) ([]domain.Synonym, error) {
+	return m.synonyms, nil
+}
+func (m *mockCatalogPortCapture) GetProductEmbedding(_ context.Context, _ string, _ string) ([]float32, error) {
+	return nil, nil
+}
+func (m *mockCatalogPortCapture) GetIngredientInteractions(_ context.Context) ([]domain.IngredientInteraction, error) {
+	return nil, nil
+}
+func (m *mockCatalogPortCapture) GetProductIngredients(_ context.Context, _ string, _ []string) (map[string][]string, error) {
+	return nil, nil
+}
 func (m *mockCatalogPortCapture) VectorSearch(_ context.Context, _ string, _ []float32, _ int, vf *ports.VectorFilter) ([]domain.Product, error) {
 	m.captureVF = vf
 	return m.vectorProducts, nil
@@ -226,9 +291,12 @@ func (m *mockCatalogPortCapture) SeedServiceEmbedding(_ context.Context, _ strin
 
 // --- Mock EmbeddingPort ---
 
-type mockEmbeddingPort struct{}
+type mockEmbeddingPort struct {
+	texts []string // captured texts of the last Embed call
+}
 
 func (m *mockEmbeddingPort) Embed(_ context.Context, texts []string) ([][]float32, error) {
+	m.texts = texts
 	result := make([][]float32, len(texts))
 	for i := range texts {
 		result[i] = make([]float32, 384)
@@ -297,6 +365,42 @@ func TestCatalogSearch_HybridMerge(t *testing.T) {
 	}
 }
 
+func TestCatalogSearch_FacetsStoredInMeta(t *testing.T) {
+	sp := newMockStatePort(defaultState())
+	facets := []domain.Facet{
+		{Field: domain.FacetBrand, Values: []domain.FacetValue{{Value: "Nike", Count: 12}, {Value: "Adidas", Count: 8}}},
+	}
+	cp := &mockCatalogPort{
+		products:       []domain.Product{{ID: "p1", Name: "Nike Pegasus 41", Brand: "Nike"}},
+		total:          1,
+		vectorProducts: []domain.Product{{ID: "p3", Name: "Adidas Ultraboost 24", Brand: "Adidas"}},
+		facets:         facets,
+	}
+	tool := tools.NewCatalogSearchTool(sp, cp, &mockEmbeddingPort{})
+
+	result, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{
+		"vector_query": "running shoes",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("tool error: %s", result.Content)
+	}
+
+	// Vector hits widen the facet base beyond keyword matches
+	if len(cp.facetIDs) != 1 || cp.facetIDs[0] != "p3" {
+		t.Errorf("expected vector hit IDs [p3] passed to GetProductFacets, got %v", cp.facetIDs)
+	}
+	got := sp.state.Current.Meta.Facets
+	if len(got) != 1 || got[0].Field != domain.FacetBrand || len(got[0].Values) != 2 {
+		t.Errorf("expected brand facet stored in StateMeta, got %+v", got)
+	}
+	if sp.state.Current.Meta.Aliases["tenant_slug"] != "nike" {
+		t.Error("expected aliases preserved alongside facets")
+	}
+}
+
 func TestCatalogSearch_KeywordOnly(t *testing.T) {
 	sp := newMockStatePort(defaultState())
 	cp := &mockCatalogPort{
@@ -397,6 +501,109 @@ func TestCatalogSearch_BrandStrippedFromSearch(t *testing.T) {
 	}
 }
 
+func TestCatalogSearch_BrandCorrected(t *testing.T) {
+	sp := newMockStatePort(defaultState())
+	var capturedFilter ports.ProductFilter
+	cp := &mockCatalogPortCapture{
+		products: []domain.Product{
+			{ID: "p1", Name: "CeraVe Moisturizing Cream", Price: 190000, Brand: "CeraVe"},
+		},
+		total:          1,
+		vectorProducts: []domain.Product{},
+		captureFilter:  &capturedFilter,
+		corrections: map[string]*domain.FilterCorrection{
+			"brand:сераве": {Field: "brand", From: "сераве", To: "CeraVe", Method: domain.CorrectionTranslit, Similarity: 1},
+		},
+	}
+	tool := tools.NewCatalogSearchTool(sp, cp, &mockEmbeddingPort{})
+
+	result, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{
+		"vector_query": "крем сераве",
+		"filters": map[string]interface{}{
+			"brand": "сераве",
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if capturedFilter.Brand != "CeraVe" {
+		t.Errorf("expected Brand=CeraVe, got %s", capturedFilter.Brand)
+	}
+	if capturedFilter.Search != "крем" {
+		t.Errorf("expected Search=крем (typed brand stripped), got %q", capturedFilter.Search)
+	}
+	if cp.captureVF == nil || cp.captureVF.Brand != "CeraVe" {
+		t.Errorf("expected VectorFilter.Brand=CeraVe, got %+v", cp.captureVF)
+	}
+	corrections, ok := result.Metadata["corrections"].([]domain.FilterCorrection)
+	if !ok || len(corrections) != 1 {
+		t.Fatalf("expected 1 correction in metadata, got %v", result.Metadata["corrections"])
+	}
+	if corrections[0].To != "CeraVe" || corrections[0].Method != domain.CorrectionTranslit {
+		t.Errorf("unexpected correction: %+v", corrections[0])
+	}
+}
+
+func TestCatalogSearch_QueryLayoutFixed(t *testing.T) {
+	sp := newMockStatePort(defaultState())
+	var capturedFilter ports.ProductFilter
+	cp := &mockCatalogPortCapture{
+		products:      []domain.Product{{ID: "p1", Name: "Крем", Price: 100000}},
+		total:         1,
+		captureFilter: &capturedFilter,
+	}
+	tool := tools.NewCatalogSearchTool(sp, cp, nil)
+
+	result, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{
+		"vector_query": "rhtv",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if capturedFilter.Search != "крем" {
+		t.Errorf("expected Search=крем, got %q", capturedFilter.Search)
+	}
+	corrections, _ := result.Metadata["corrections"].([]domain.FilterCorrection)
+	if len(corrections) != 1 || corrections[0].Field != "query" || corrections[0].Method != domain.CorrectionLayout {
+		t.Errorf("expected query layout correction, got %v", result.Metadata["corrections"])
+	}
+}
+
+func TestCatalogSearch_SynonymExpansion(t *testing.T) {
+	sp := newMockStatePort(defaultState())
+	var capturedFilter ports.ProductFilter
+	cp := &mockCatalogPortCapture{
+		products:      []domain.Product{{ID: "p1", Name: "Sunscreen SPF 50", Price: 150000}},
+		total:         1,
+		captureFilter: &capturedFilter,
+		synonyms: []domain.Synonym{
+			{Term: "SPF", Synonyms: []string{"солнцезащитный"}, Bidirectional: true},
+		},
+	}
+	emb := &mockEmbeddingPort{}
+	tool := tools.NewCatalogSearchTool(sp, cp, emb)
+
+	result, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{
+		"vector_query": "крем spf",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if capturedFilter.Search != "крем spf солнцезащитный" {
+		t.Errorf("expected expanded keyword search, got %q", capturedFilter.Search)
+	}
+	if len(emb.texts) != 1 || emb.texts[0] != "крем spf солнцезащитный" {
+		t.Errorf("expected expanded embedding text, got %v", emb.texts)
+	}
+	lines, _ := result.Metadata["synonym_expansions"].([]string)
+	if len(lines) != 1 || lines[0] != "SPF → солнцезащитный" {
+		t.Errorf("expected expansion in metadata, got %v", result.Metadata["synonym_expansions"])
+	}
+}
+
 func TestCatalogSearch_FiltersPassed(t *testing.T) {
 	sp := newMockStatePort(defaultState())
 	var capturedFilter ports.ProductFilter
@@ -472,6 +679,225 @@ func TestCatalogSearch_FiltersPassedToVector(t *testing.T) {
 	}
 }
 
+func TestCatalogSearch_ListFiltersPassed(t *testing.T) {
+	sp := newMockStatePort(defaultState())
+	var capturedFilter ports.ProductFilter
+	cp := &mockCatalogPortCapture{
+		products: []domain.Product{
+			{ID: "p1", Name: "CeraVe Moisturizing Cream", Price: 190000, Brand: "CeraVe"},
+		},
+		total:          1,
+		vectorProducts: []domain.Product{},
+		captureFilter:  &capturedFilter,
+	}
+	tool := tools.NewCatalogSearchTool(sp, cp, &mockEmbeddingPort{})
+
+	_, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{
+		"vector_query": "крем CeraVe",
+		"filters": map[string]interface{}{
+			"brands":         []interface{}{"CeraVe", "COSRX"},
+			"exclude_brands": []interface{}{"La Roche-Posay"},
+			"skin_types":     []interface{}{"dry", "sensitive"},
+			"free_from":      []interface{}{"fragrance"},
+			"match":          "all",
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(capturedFilter.Brands) != 2 || capturedFilter.ExcludeBrands[0] != "La Roche-Posay" {
+		t.Errorf("unexpected brand lists: %v / %v", capturedFilter.Brands, capturedFilter.ExcludeBrands)
+	}
+	if len(capturedFilter.SkinTypes) != 2 || capturedFilter.Match != ports.MatchAll {
+		t.Errorf("unexpected skin types: %v match=%q", capturedFilter.SkinTypes, capturedFilter.Match)
+	}
+	if len(capturedFilter.FreeFrom) != 1 || capturedFilter.FreeFrom[0] != "fragrance" {
+		t.Errorf("expected FreeFrom=[fragrance], got %v", capturedFilter.FreeFrom)
+	}
+	if capturedFilter.Search != "крем" {
+		t.Errorf("expected Search=крем (list brand stripped), got %q", capturedFilter.Search)
+	}
+	if cp.captureVF == nil || len(cp.captureVF.SkinTypes) != 2 || len(cp.captureVF.ExcludeBrands) != 1 {
+		t.Errorf("expected list filters in VectorFilter, got %+v", cp.captureVF)
+	}
+}
+
+// stubReranker scores candidates from a fixed table and records the request
+type stubReranker struct {
+	scores map[string]float64
+	usage  domain.LLMUsage
+	err    error
+	req    *ports.RerankRequest
+}
+
+func (r *stubReranker) Name() string { return "stub" }
+
+func (r *stubReranker) Rerank(_ context.Context, req ports.RerankRequest) (*ports.RerankResponse, error) {
+	r.req = &req
+	if r.err != nil {
+		return &ports.RerankResponse{Usage: r.usage}, r.err
+	}
+	out := make([]domain.RerankScore, len(req.Candidates))
+	for i, c := range req.Candidates {
+		out[i] = domain.RerankScore{ID: c.Product.ID, Score: r.scores[c.Product.ID]}
+	}
+	return &ports.RerankResponse{Scores: out, Usage: r.usage}, nil
+}
+
+func rerankCatalogPort() *mockCatalogPortCapture {
+	return &mockCatalogPortCapture{
+		products: []domain.Product{
+			{ID: "p1", Name: "Cream A", Price: 100000},
+			{ID: "p2", Name: "Cream B", Price: 200000},
+			{ID: "p3", Name: "Cream C", Price: 300000},
+		},
+		total:          3,
+		vectorProducts: []domain.Product{},
+	}
+}
+
+func TestCatalogSearch_RerankReorders(t *testing.T) {
+	sp := newMockStatePort(defaultState())
+	cp := rerankCatalogPort()
+	rr := &stubReranker{scores: map[string]float64{"p1": 0.1, "p2": 0.5, "p3": 0.9}}
+	tool := tools.NewCatalogSearchTool(sp, cp, &mockEmbeddingPort{}).WithRerankers("stub", rr)
+
+	toolCtx := defaultToolCtx()
+	toolCtx.UserQuery = "крем для сухой кожи"
+	result, err := tool.Execute(context.Background(), toolCtx, map[string]interface{}{
+		"vector_query": "крем",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	products := sp.state.Current.Data.Products
+	if len(products) != 3 || products[0].ID != "p3" || products[2].ID != "p1" {
+		t.Fatalf("expected reranked order p3,p2,p1, got %v", products)
+	}
+	if rr.req == nil || rr.req.Query != "крем для сухой кожи" {
+		t.Errorf("reranker should get the original user query, got %+v", rr.req)
+	}
+	if rr.req.Candidates[0].BaseScore <= rr.req.Candidates[2].BaseScore {
+		t.Errorf("candidates should carry descending RRF scores: %+v", rr.req.Candidates)
+	}
+	if rr.req.Weights != domain.DefaultRerankWeights() {
+		t.Errorf("expected default weights, got %+v", rr.req.Weights)
+	}
+	lines, ok := result.Metadata["rerank_scores"].([]string)
+	if !ok || len(lines) != 3 || !strings.HasPrefix(lines[0], "#1←#3 p3") {
+		t.Errorf("unexpected rerank_scores: %v", result.Metadata["rerank_scores"])
+	}
+	if result.Metadata["reranker"] != "stub" {
+		t.Errorf("expected reranker=stub, got %v", result.Metadata["reranker"])
+	}
+}
+
+func TestCatalogSearch_RerankTenantSettings(t *testing.T) {
+	sp := newMockStatePort(defaultState())
+	cp := rerankCatalogPort()
+	cp.tenantSettings = map[string]any{"rerank": map[string]any{"strategy": "none"}}
+	rr := &stubReranker{scores: map[string]float64{"p3": 1}}
+	tool := tools.NewCatalogSearchTool(sp, cp, &mockEmbeddingPort{}).WithRerankers("stub", rr)
+
+	result, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{
+		"vector_query": "крем",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rr.req != nil {
+		t.Error("tenant strategy 'none' must skip the reranker")
+	}
+	if sp.state.Current.Data.Products[0].ID != "p1" || result.Metadata["reranker"] != nil {
+		t.Errorf("expected RRF order without rerank, got %v", sp.state.Current.Data.Products)
+	}
+
+	// Tenant top_n and weights are passed through
+	rr.scores = map[string]float64{"p2": 1, "p3": 5}
+	cp.tenantSettings = map[string]any{"rerank": map[string]any{"strategy": "stub", "top_n": float64(2), "weights": map[string]any{"stock": float64(2)}}}
+	if _, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{"vector_query": "крем"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rr.req == nil || len(rr.req.Candidates) != 2 || rr.req.Weights.Stock != 2 {
+		t.Fatalf("expected 2 candidates with stock weight 2, got %+v", rr.req)
+	}
+	products := sp.state.Current.Data.Products
+	if products[0].ID != "p2" || products[2].ID != "p3" {
+		t.Errorf("only the top 2 should be reordered, got %v", products)
+	}
+}
+
+func TestCatalogSearch_RerankErrorKeepsOrder(t *testing.T) {
+	sp := newMockStatePort(defaultState())
+	cp := rerankCatalogPort()
+	rr := &stubReranker{err: fmt.Errorf("llm down")}
+	tool := tools.NewCatalogSearchTool(sp, cp, &mockEmbeddingPort{}).WithRerankers("stub", rr)
+
+	result, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{
+		"vector_query": "крем",
+	})
+	if err != nil {
+		t.Fatalf("rerank error must not fail the search: %v", err)
+	}
+	if sp.state.Current.Data.Products[0].ID != "p1" {
+		t.Errorf("expected RRF order on rerank error, got %v", sp.state.Current.Data.Products)
+	}
+	if result.Metadata["rerank_error"] != "llm down" {
+		t.Errorf("expected rerank_error in metadata, got %v", result.Metadata["rerank_error"])
+	}
+}
+
+func TestCatalogSearch_RerankPoolBeyondLimit(t *testing.T) {
+	sp := newMockStatePort(defaultState())
+	cp := rerankCatalogPort()
+	var capturedFilter ports.ProductFilter
+	cp.captureFilter = &capturedFilter
+	rr := &stubReranker{
+		scores: map[string]float64{"p1": 0.5, "p2": 0.1, "p3": 0.9},
+		usage:  domain.LLMUsage{InputTokens: 400, OutputTokens: 60, TotalTokens: 460, Model: "judge", CostUSD: 0.0007},
+	}
+	tool := tools.NewCatalogSearchTool(sp, cp, &mockEmbeddingPort{}).WithRerankers("stub", rr)
+
+	result, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{
+		"vector_query": "крем",
+		"limit":        float64(2),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Default top_n (20) > limit: the legs fetch and the merge keeps the whole pool for rerank
+	if capturedFilter.Limit != domain.DefaultRerankTopN*2 {
+		t.Errorf("expected keyword leg limit %d, got %d", domain.DefaultRerankTopN*2, capturedFilter.Limit)
+	}
+	if rr.req == nil || len(rr.req.Candidates) != 3 {
+		t.Fatalf("expected all 3 merged products as candidates, got %+v", rr.req)
+	}
+	products := sp.state.Current.Data.Products
+	if len(products) != 2 || products[0].ID != "p3" || products[1].ID != "p1" {
+		t.Errorf("expected p3 promoted from below the page and the list cut to 2, got %v", products)
+	}
+	if result.Usage == nil || result.Usage.TotalTokens != 460 || result.Usage.CostUSD != 0.0007 {
+		t.Errorf("expected rerank usage on the tool result, got %+v", result.Usage)
+	}
+}
+
+func TestCatalogSearch_RerankErrorKeepsUsage(t *testing.T) {
+	sp := newMockStatePort(defaultState())
+	rr := &stubReranker{err: fmt.Errorf("parse llm rerank"), usage: domain.LLMUsage{TotalTokens: 120, CostUSD: 0.0002}}
+	tool := tools.NewCatalogSearchTool(sp, rerankCatalogPort(), &mockEmbeddingPort{}).WithRerankers("stub", rr)
+
+	result, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{"vector_query": "крем"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Usage == nil || result.Usage.TotalTokens != 120 {
+		t.Errorf("a failed judge call is still paid for, expected its usage, got %+v", result.Usage)
+	}
+}
+
 func TestCatalogSearch_RRFWeightsKeywordHigher(t *testing.T) {
 	sp := newMockStatePort(defaultState())
 
@@ -592,6 +1018,24 @@ func (m *tenantCaptureCatalogPort) ListProducts(ctx context.Context, tenantID st
 func (m *tenantCaptureCatalogPort) GetProduct(ctx context.Context, tenantID string, productID string) (*domain.Product, error) {
 	return m.inner.GetProduct(ctx, tenantID, productID)
 }
+func (m *tenantCaptureCatalogPort) GetProductFacets(ctx context.Context, tenantID string, filter ports.ProductFilter, productIDs []string) ([]domain.Facet, error) {
+	return m.inner.GetProductFacets(ctx, tenantID, filter, productIDs)
+}
+func (m *tenantCaptureCatalogPort) CorrectFilterValue(ctx context.Context, tenantID string, field string, value string) (*domain.FilterCorrection, error) {
+	return m.inner.CorrectFilterValue(ctx, tenantID, field, value)
+}
+func (m *tenantCaptureCatalogPort) GetSynonyms(ctx context.Context, tenantID string) ([]domain.Synonym, error) {
+	return m.inner.GetSynonyms(ctx, tenantID)
+}
+func (m *tenantCaptureCatalogPort) GetProductEmbedding(ctx context.Context, tenantID string, productID string) ([]float32, error) {
+	return m.inner.GetProductEmbedding(ctx, tenantID, productID)
+}
+func (m *tenantCaptureCatalogPort) GetIngredientInteractions(ctx context.Context) ([]domain.IngredientInteraction, error) {
+	return m.inner.GetIngredientInteractions(ctx)
+}
+func (m *tenantCaptureCatalogPort) GetProductIngredients(ctx context.Context, tenantID string, productIDs []string) (map[string][]string, error) {
+	return m.inner.GetProductIngredients(ctx, tenantID, productIDs)
+}
 func (m *tenantCaptureCatalogPort) VectorSearch(ctx context.Context, tenantID string, embedding []float32, limit int, filter *ports.VectorFilter) ([]domain.Product, error) {
 	return m.inner.VectorSearch(ctx, tenantID, embedding, limit, filter)
 }
@@ -631,3 +1075,118 @@ func (m *tenantCaptureCatalogPort) GetMasterServicesWithoutEmbedding(ctx context
 func (m *tenantCaptureCatalogPort) SeedServiceEmbedding(ctx context.Context, masterServiceID string, embedding []float32) error {
 	return m.inner.SeedServiceEmbedding(ctx, masterServiceID, embedding)
 }
+
+func stockCatalogPort() *mockCatalogPortCapture {
+	stock := func(n int) *int { return &n }
+	return &mockCatalogPortCapture{
+		products: []domain.Product{
+			{ID: "p1", Name: "Cream A", StockAvailable: stock(0)},
+			{ID: "p2", Name: "Cream B", StockAvailable: stock(4)},
+			{ID: "p3", Name: "Cream C"}, // stock not tracked
+			{ID: "p4", Name: "Cream D", StockAvailable: stock(0)},
+		},
+		total:          4,
+		vectorProducts: []domain.Product{},
+	}
+}
+
+func productIDs(products []domain.Product) string {
+	ids := make([]string, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestCatalogSearch_StockPolicyDemote(t *testing.T) {
+	sp := newMockStatePort(defaultState())
+	cp := stockCatalogPort()
+	tool := tools.NewCatalogSearchTool(sp, cp, nil).WithStockPolicy(domain.StockPolicyDemote)
+
+	result, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{
+		"vector_query": "крем",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := productIDs(sp.state.Current.Data.Products); got != "p2,p3,p1,p4" {
+		t.Errorf("out-of-stock products should go last in their order, got %s", got)
+	}
+	if result.Metadata["stock_policy"] != domain.StockPolicyDemote || result.Metadata["stock_demoted"] != 2 {
+		t.Errorf("unexpected stock meta: %v / %v", result.Metadata["stock_policy"], result.Metadata["stock_demoted"])
+	}
+	stock := sp.state.Current.Meta.Stock
+	if stock == nil || stock.Policy != domain.StockPolicyDemote || stock.LowThreshold != domain.DefaultLowStockThreshold {
+		t.Errorf("state meta should carry the stock policy for badges, got %+v", stock)
+	}
+}
+
+func TestCatalogSearch_StockPolicyHide(t *testing.T) {
+	sp := newMockStatePort(defaultState())
+	cp := stockCatalogPort()
+	cp.captureFilter = &ports.ProductFilter{}
+	cp.tenantSettings = map[string]any{"stock": map[string]any{"policy": "hide"}}
+	tool := tools.NewCatalogSearchTool(sp, cp, &mockEmbeddingPort{}).WithStockPolicy(domain.StockPolicyBadge)
+
+	result, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{
+		"vector_query": "крем",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !cp.captureFilter.InStockOnly {
+		t.Error("hide policy should filter out-of-stock products in SQL")
+	}
+	if cp.captureVF == nil || !cp.captureVF.InStockOnly {
+		t.Errorf("hide policy should reach vector search too, got %+v", cp.captureVF)
+	}
+	if got := productIDs(sp.state.Current.Data.Products); got != "p2,p3" {
+		t.Errorf("out-of-stock products should be hidden, got %s", got)
+	}
+	if result.Metadata["stock_hidden"] != 2 {
+		t.Errorf("expected stock_hidden 2, got %v", result.Metadata["stock_hidden"])
+	}
+}
+
+func TestCatalogSearch_StockPolicyBadgeKeepsOrder(t *testing.T) {
+	sp := newMockStatePort(defaultState())
+	cp := stockCatalogPort()
+	cp.captureFilter = &ports.ProductFilter{}
+	tool := tools.NewCatalogSearchTool(sp, cp, nil).WithStockPolicy(domain.StockPolicyBadge)
+
+	if _, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{
+		"vector_query": "крем",
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cp.captureFilter.InStockOnly {
+		t.Error("badge policy should not filter")
+	}
+	if got := productIDs(sp.state.Current.Data.Products); got != "p1,p2,p3,p4" {
+		t.Errorf("badge policy should keep the order, got %s", got)
+	}
+	if sp.state.Current.Meta.Stock == nil {
+		t.Error("badge policy should still be recorded for the formation engine")
+	}
+}
+
+func TestCatalogSearch_NoStockPolicy(t *testing.T) {
+	sp := newMockStatePort(defaultState())
+	tool := tools.NewCatalogSearchTool(sp, stockCatalogPort(), nil)
+
+	result, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{
+		"vector_query": "крем",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := productIDs(sp.state.Current.Data.Products); got != "p1,p2,p3,p4" {
+		t.Errorf("without a policy stock should be ignored, got %s", got)
+	}
+	if _, ok := result.Metadata["stock_policy"]; ok || sp.state.Current.Meta.Stock != nil {
+		t.Error("without a policy nothing should be recorded")
+	}
+}
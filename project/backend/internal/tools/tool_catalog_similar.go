@@ -0,0 +1,293 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+)
+
+// CatalogSimilarTool finds products similar to a given one ("more like this")
+// by vector search from the product's own stored embedding
+type CatalogSimilarTool struct {
+	statePort   ports.StatePort
+	catalogPort ports.CatalogPort
+	embedding   ports.EmbeddingPort // fallback for products without a stored embedding; nil = none
+
+	defaultStock string // stock policy for tenants without settings.stock
+}
+
+// NewCatalogSimilarTool creates the similar-products tool
+func NewCatalogSimilarTool(statePort ports.StatePort, catalogPort ports.CatalogPort, embedding ports.EmbeddingPort) *CatalogSimilarTool {
+	return &CatalogSimilarTool{
+		statePort:   statePort,
+		catalogPort: catalogPort,
+		embedding:   embedding,
+	}
+}
+
+// WithStockPolicy sets the stock policy (domain.StockPolicy*) for tenants without settings.stock
+func (t *CatalogSimilarTool) WithStockPolicy(defaultPolicy string) *CatalogSimilarTool {
+	t.defaultStock = defaultPolicy
+	return t
+}
+
+// Definition returns the tool definition for LLM
+func (t *CatalogSimilarTool) Definition() domain.ToolDefinition {
+	return domain.ToolDefinition{
+		Name:        "catalog_similar",
+		Description: "Find products similar to one product (\"more like this\", аналоги, похожие). Uses the product's own embedding, excludes the product itself. Without product_id/product_name uses the product open in detail view or the only loaded product.",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"product_id": map[string]interface{}{
+					"type":        "string",
+					"description": "ID of the source product (from loaded data)",
+				},
+				"product_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Name (or part of it) of a loaded product, when the ID is unknown",
+				},
+				"cheaper": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Only products cheaper than the source",
+				},
+				"same_skin_type": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Only products for the source's skin types",
+				},
+				"different_brand": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Exclude the source's brand",
+				},
+				"limit": map[string]interface{}{
+					"type":        "integer",
+					"description": "Max results (default 12)",
+				},
+			},
+		},
+	}
+}
+
+// Execute finds similar products and writes them to state like catalog_search does
+func (t *CatalogSimilarTool) Execute(ctx context.Context, toolCtx ToolContext, input map[string]interface{}) (*domain.ToolResult, error) {
+	meta := map[string]interface{}{}
+
+	productID, _ := input["product_id"].(string)
+	productName, _ := input["product_name"].(string)
+	cheaper, _ := input["cheaper"].(bool)
+	sameSkinType, _ := input["same_skin_type"].(bool)
+	differentBrand, _ := input["different_brand"].(bool)
+	limit := 12
+	if v, ok := input["limit"].(float64); ok && v > 0 {
+		limit = int(v)
+	}
+
+	sc := domain.SpanFromContext(ctx)
+	stage := domain.StageFromContext(ctx)
+
+	state, err := t.statePort.GetState(ctx, toolCtx.SessionID)
+	if err == domain.ErrSessionNotFound {
+		state, err = t.statePort.CreateState(ctx, toolCtx.SessionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get/create state: %w", err)
+	}
+
+	tenantSlug := toolTenantSlug(toolCtx, state)
+	meta["tenant"] = tenantSlug
+
+	tenant, err := t.catalogPort.GetTenantBySlug(ctx, tenantSlug)
+	if err != nil {
+		return nil, fmt.Errorf("get tenant: %w", err)
+	}
+	stock := domain.StockConfigFromSettings(tenant.Settings, t.defaultStock)
+	if stock.Policy != "" {
+		meta["stock_policy"] = stock.Policy
+	}
+
+	// Source product: explicit ID, name among loaded products, focused product, single loaded product
+	if productID == "" {
+		productID = resolveSimilarSource(state, productName)
+	}
+	if productID == "" {
+		return &domain.ToolResult{
+			Content:  "error: source product not specified; pass product_id or product_name of a loaded product",
+			Metadata: meta,
+			IsError:  true,
+		}, nil
+	}
+	meta["source_id"] = productID
+
+	source, err := t.catalogPort.GetProduct(ctx, tenant.ID, productID)
+	if errors.Is(err, domain.ErrProductNotFound) || (err == nil && source == nil) {
+		meta["source_missing"] = true
+		return &domain.ToolResult{
+			Content:  fmt.Sprintf("error: product %s not found", productID),
+			Metadata: meta,
+			IsError:  true,
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get product: %w", err)
+	}
+	meta["source"] = source.Name
+
+	// Query vector: the stored embedding, or an embedding of the product text when there is none
+	var endEmbed func(...string)
+	if sc != nil && stage != "" {
+		endEmbed = sc.Start(stage + ".tool.embedding")
+	}
+	queryEmbedding, err := t.catalogPort.GetProductEmbedding(ctx, tenant.ID, productID)
+	if err != nil {
+		if endEmbed != nil {
+			endEmbed("error")
+		}
+		return nil, fmt.Errorf("get product embedding: %w", err)
+	}
+	meta["embedding_source"] = "stored"
+	if len(queryEmbedding) == 0 && t.embedding != nil {
+		text := strings.TrimSpace(source.Name + " " + source.Brand)
+		embs, embErr := t.embedding.Embed(ctx, []string{text})
+		if embErr == nil && len(embs) > 0 {
+			queryEmbedding = embs[0]
+			meta["embedding_source"] = "text"
+		}
+	}
+	if endEmbed != nil {
+		endEmbed(meta["embedding_source"].(string))
+	}
+	if len(queryEmbedding) == 0 {
+		return &domain.ToolResult{
+			Content:  fmt.Sprintf("error: product %s has no embedding yet", productID),
+			Metadata: meta,
+			IsError:  true,
+		}, nil
+	}
+
+	// Optional constraints relative to the source
+	vf := &ports.VectorFilter{InStockOnly: stock.Policy == domain.StockPolicyHide}
+	var constraints []string
+	if cheaper && source.Price > 0 {
+		vf.MaxPrice = source.Price - 1
+		constraints = append(constraints, "cheaper")
+	}
+	if sameSkinType && len(source.SkinType) > 0 {
+		vf.SkinTypes = source.SkinType
+		vf.Match = ports.MatchAny
+		constraints = append(constraints, "same_skin_type")
+	}
+	if differentBrand && source.Brand != "" {
+		vf.ExcludeBrands = []string{source.Brand}
+		constraints = append(constraints, "different_brand")
+	}
+	if len(constraints) > 0 {
+		meta["constraints"] = strings.Join(constraints, ",")
+	}
+
+	var endVector func(...string)
+	if sc != nil && stage != "" {
+		endVector = sc.Start(stage + ".tool.vector")
+	}
+	// Extra candidates cover the source itself and its offers sharing the master product
+	found, err := t.catalogPort.VectorSearch(ctx, tenant.ID, queryEmbedding, limit*2, vf)
+	if endVector != nil {
+		endVector(fmt.Sprintf("%d results", len(found)))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("vector search: %w", err)
+	}
+	meta["vector_count"] = len(found)
+
+	similar := make([]domain.Product, 0, limit)
+	for _, p := range found {
+		if p.ID == source.ID || (source.MasterProductID != "" && p.MasterProductID == source.MasterProductID) {
+			continue
+		}
+		NormalizeProduct(&p)
+		similar = append(similar, p)
+	}
+	similar = applyStockPolicy(stock.Policy, similar, meta)
+	if len(similar) > limit {
+		similar = similar[:limit]
+	}
+	meta["merged_count"] = len(similar) // SimilarUseCase reads it to tell results from "nothing similar"
+
+	trigger, deltaSource := domain.TriggerUserQuery, domain.SourceLLM
+	if toolCtx.Trigger == domain.TriggerWidgetAction {
+		trigger, deltaSource = domain.TriggerWidgetAction, domain.SourceUser
+	}
+	info := domain.DeltaInfo{
+		TurnID:    toolCtx.TurnID,
+		Trigger:   trigger,
+		Source:    deltaSource,
+		ActorID:   toolCtx.ActorID,
+		DeltaType: domain.DeltaTypeAdd,
+		Path:      "data.products",
+		Action:    domain.Action{Type: domain.ActionSearch, Tool: "catalog_similar", Params: input},
+	}
+
+	if len(similar) == 0 {
+		// Empty result — don't overwrite state data, just record delta
+		if _, err := t.statePort.AddDelta(ctx, toolCtx.SessionID, info.ToDelta()); err != nil {
+			return nil, fmt.Errorf("add empty delta: %w", err)
+		}
+		return &domain.ToolResult{
+			Content:  fmt.Sprintf("empty: no products similar to %q, previous data preserved", source.Name),
+			Metadata: meta,
+		}, nil
+	}
+
+	fields := catalogExtractProductFields(similar[0])
+	stateMeta := domain.StateMeta{
+		Count:   len(similar),
+		Fields:  fields,
+		Aliases: state.Current.Meta.Aliases, // preserve tenant_slug
+	}
+	if stock.Policy != "" {
+		stateMeta.Stock = &stock
+	}
+	info.Result = domain.ResultMeta{Count: len(similar), Fields: fields}
+	if _, err := t.statePort.UpdateData(ctx, toolCtx.SessionID, domain.StateData{Products: similar}, stateMeta, info); err != nil {
+		return nil, fmt.Errorf("update data: %w", err)
+	}
+
+	return &domain.ToolResult{
+		Content:  fmt.Sprintf("ok: found %d products similar to %q", len(similar), source.Name),
+		Metadata: meta,
+	}, nil
+}
+
+// resolveSimilarSource picks the source product ID from state when the caller gave none:
+// a loaded product whose name contains name, else the focused product, else the only loaded product
+func resolveSimilarSource(state *domain.SessionState, name string) string {
+	products := state.Current.Data.Products
+	if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+		for _, p := range products {
+			if strings.Contains(strings.ToLower(p.Name), name) {
+				return p.ID
+			}
+		}
+	}
+	if f := state.View.Focused; f != nil && f.Type == domain.EntityTypeProduct {
+		return f.ID
+	}
+	if len(products) == 1 {
+		return products[0].ID
+	}
+	return ""
+}
+
+// toolTenantSlug resolves the tenant the way catalog_search does: tool context, then session alias
+func toolTenantSlug(toolCtx ToolContext, state *domain.SessionState) string {
+	if toolCtx.TenantSlug != "" {
+		return toolCtx.TenantSlug
+	}
+	if slug := state.Current.Meta.Aliases["tenant_slug"]; slug != "" {
+		return slug
+	}
+	return "nike"
+}
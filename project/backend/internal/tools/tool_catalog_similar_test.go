@@ -0,0 +1,171 @@
+package tools_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+	"keepstar/internal/tools"
+)
+
+// similarCatalogPort serves a source product with a stored embedding on top of mockCatalogPortCapture
+type similarCatalogPort struct {
+	*mockCatalogPortCapture
+	source    *domain.Product
+	embedding []float32
+	embedFor  string // captured productID of the last GetProductEmbedding call
+}
+
+func (m *similarCatalogPort) GetProduct(_ context.Context, _ string, productID string) (*domain.Product, error) {
+	if m.source == nil || m.source.ID != productID {
+		return nil, domain.ErrProductNotFound
+	}
+	return m.source, nil
+}
+
+func (m *similarCatalogPort) GetProductEmbedding(_ context.Context, _ string, productID string) ([]float32, error) {
+	m.embedFor = productID
+	return m.embedding, nil
+}
+
+func newSimilarCatalogPort() *similarCatalogPort {
+	return &similarCatalogPort{
+		mockCatalogPortCapture: &mockCatalogPortCapture{
+			vectorProducts: []domain.Product{
+				{ID: "src", MasterProductID: "mp1", Name: "Cream A", Price: 200000, Brand: "COSRX"},
+				{ID: "p2", MasterProductID: "mp2", Name: "Cream B", Price: 150000, Brand: "Round Lab"},
+				{ID: "src-offer", MasterProductID: "mp1", Name: "Cream A 2 pcs", Price: 380000, Brand: "COSRX"},
+				{ID: "p3", MasterProductID: "mp3", Name: "Cream C", Price: 90000, Brand: "Purito"},
+			},
+		},
+		source: &domain.Product{
+			ID: "src", MasterProductID: "mp1", Name: "Cream A", Price: 200000, Brand: "COSRX",
+			SkinType: []string{"dry", "sensitive"},
+		},
+		embedding: []float32{0.1, 0.2, 0.3},
+	}
+}
+
+func TestCatalogSimilar_ExcludesSource(t *testing.T) {
+	sp := newMockStatePort(defaultState())
+	cp := newSimilarCatalogPort()
+	tool := tools.NewCatalogSimilarTool(sp, cp, nil)
+
+	result, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{
+		"product_id": "src",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(result.Content, "ok") {
+		t.Fatalf("expected ok, got %q", result.Content)
+	}
+
+	if cp.embedFor != "src" {
+		t.Errorf("query vector should come from the source product, got %q", cp.embedFor)
+	}
+	if got := productIDs(sp.state.Current.Data.Products); got != "p2,p3" {
+		t.Errorf("source and its offers should be excluded, got %s", got)
+	}
+	if sp.state.Current.Meta.Aliases["tenant_slug"] != "nike" {
+		t.Error("tenant_slug alias should be preserved")
+	}
+
+	delta := sp.LastDeltaInfo
+	if delta == nil || delta.Path != "data.products" || delta.Action.Tool != "catalog_similar" {
+		t.Fatalf("expected a data.products delta from catalog_similar, got %+v", delta)
+	}
+	if delta.Trigger != domain.TriggerUserQuery || delta.Source != domain.SourceLLM {
+		t.Errorf("agent call should be recorded as user query / llm, got %s / %s", delta.Trigger, delta.Source)
+	}
+}
+
+func TestCatalogSimilar_Constraints(t *testing.T) {
+	sp := newMockStatePort(defaultState())
+	cp := newSimilarCatalogPort()
+	tool := tools.NewCatalogSimilarTool(sp, cp, nil)
+
+	_, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{
+		"product_id":      "src",
+		"cheaper":         true,
+		"same_skin_type":  true,
+		"different_brand": true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	vf := cp.captureVF
+	if vf == nil {
+		t.Fatal("vector filter should be set")
+	}
+	if vf.MaxPrice != 199999 {
+		t.Errorf("cheaper should cap price below the source, got %d", vf.MaxPrice)
+	}
+	if strings.Join(vf.SkinTypes, ",") != "dry,sensitive" || vf.Match != ports.MatchAny {
+		t.Errorf("same_skin_type should match any of the source skin types, got %v %q", vf.SkinTypes, vf.Match)
+	}
+	if len(vf.ExcludeBrands) != 1 || vf.ExcludeBrands[0] != "COSRX" {
+		t.Errorf("different_brand should exclude the source brand, got %v", vf.ExcludeBrands)
+	}
+}
+
+func TestCatalogSimilar_SourceFromFocusedView(t *testing.T) {
+	state := defaultState()
+	state.View = domain.ViewState{Mode: domain.ViewModeDetail, Focused: &domain.EntityRef{Type: domain.EntityTypeProduct, ID: "src"}}
+	sp := newMockStatePort(state)
+	cp := newSimilarCatalogPort()
+	tool := tools.NewCatalogSimilarTool(sp, cp, nil)
+
+	toolCtx := defaultToolCtx()
+	toolCtx.Trigger = domain.TriggerWidgetAction
+	if _, err := tool.Execute(context.Background(), toolCtx, map[string]interface{}{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cp.embedFor != "src" {
+		t.Errorf("focused product should be the source, got %q", cp.embedFor)
+	}
+	if d := sp.LastDeltaInfo; d == nil || d.Trigger != domain.TriggerWidgetAction || d.Source != domain.SourceUser {
+		t.Errorf("widget action should be recorded as user delta, got %+v", d)
+	}
+}
+
+func TestCatalogSimilar_FallbackEmbedding(t *testing.T) {
+	sp := newMockStatePort(defaultState())
+	cp := newSimilarCatalogPort()
+	cp.embedding = nil
+	emb := &mockEmbeddingPort{}
+	tool := tools.NewCatalogSimilarTool(sp, cp, emb)
+
+	result, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{
+		"product_id": "src",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Metadata["embedding_source"] != "text" {
+		t.Errorf("expected text embedding fallback, got %v", result.Metadata["embedding_source"])
+	}
+	if len(emb.texts) != 1 || emb.texts[0] != "Cream A COSRX" {
+		t.Errorf("fallback should embed name and brand, got %v", emb.texts)
+	}
+}
+
+func TestCatalogSimilar_NoSource(t *testing.T) {
+	sp := newMockStatePort(defaultState())
+	tool := tools.NewCatalogSimilarTool(sp, newSimilarCatalogPort(), nil)
+
+	result, err := tool.Execute(context.Background(), defaultToolCtx(), map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("expected an error result without a source product, got %q", result.Content)
+	}
+	if sp.UpdateDataCalls != 0 {
+		t.Error("state data should not change")
+	}
+}
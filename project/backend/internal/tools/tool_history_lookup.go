@@ -2,6 +2,7 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -40,6 +41,9 @@ func (t *HistoryLookupTool) Definition() domain.ToolDefinition {
 	}
 }
 
+// ReadOnly marks the tool as safe to run in parallel with other tools
+func (t *HistoryLookupTool) ReadOnly() bool { return true }
+
 // Execute searches session deltas and returns matching history entries
 func (t *HistoryLookupTool) Execute(ctx context.Context, toolCtx ToolContext, input map[string]interface{}) (*domain.ToolResult, error) {
 	query, _ := input["query"].(string)
@@ -71,6 +75,12 @@ func (t *HistoryLookupTool) Execute(ctx context.Context, toolCtx ToolContext, in
 		if len(d.Result.Fields) > 0 {
 			line += fmt.Sprintf(" [%s]", strings.Join(d.Result.Fields, ","))
 		}
+		// Tool input lets a follow-up call reuse earlier filters ("cheaper than before")
+		if len(d.Action.Params) > 0 {
+			if params, err := json.Marshal(d.Action.Params); err == nil {
+				line += " params=" + string(params)
+			}
+		}
 		lines = append(lines, line)
 	}
 
@@ -15,7 +15,8 @@ type ToolContext struct {
 	TurnID     string
 	ActorID    string
 	TenantSlug string
-	UserQuery  string // Original user query (for post-validation guards)
+	UserQuery  string             // Original user query (for post-validation guards)
+	Trigger    domain.TriggerType // Delta trigger; "" = TriggerUserQuery (agent turn)
 }
 
 // ToolExecutor executes a tool and writes results to state
@@ -27,6 +28,12 @@ type ToolExecutor interface {
 	Execute(ctx context.Context, toolCtx ToolContext, input map[string]interface{}) (*domain.ToolResult, error)
 }
 
+// ReadOnlyTool is implemented by tools that only read state.
+// Agent 1 runs read-only calls from one LLM response concurrently.
+type ReadOnlyTool interface {
+	ReadOnly() bool
+}
+
 // Registry holds all available tools
 type Registry struct {
 	tools          map[string]ToolExecutor
@@ -48,6 +55,9 @@ func NewRegistry(statePort ports.StatePort, catalogPort ports.CatalogPort, prese
 
 	// Data tools (Agent1)
 	r.Register(NewCatalogSearchTool(statePort, catalogPort, embeddingPort))
+	r.Register(NewCatalogSimilarTool(statePort, catalogPort, embeddingPort))
+	r.Register(NewCatalogRoutineTool(statePort, catalogPort))
+	r.Register(NewCatalogCompatibilityTool(statePort, catalogPort))
 	r.Register(NewStateFilterTool(statePort))
 	r.Register(NewHistoryLookupTool(statePort))
 
@@ -57,6 +67,28 @@ func NewRegistry(statePort ports.StatePort, catalogPort ports.CatalogPort, prese
 	return r
 }
 
+// WithRerankers enables the reranking stage of catalog_search (see CatalogSearchTool.WithRerankers)
+func (r *Registry) WithRerankers(defaultStrategy string, rerankers ...ports.RerankerPort) *Registry {
+	if cs, ok := r.tools["catalog_search"].(*CatalogSearchTool); ok {
+		cs.WithRerankers(defaultStrategy, rerankers...)
+	}
+	return r
+}
+
+// WithStockPolicy sets the stock policy of the catalog_* data tools (see CatalogSearchTool.WithStockPolicy)
+func (r *Registry) WithStockPolicy(defaultPolicy string) *Registry {
+	if cs, ok := r.tools["catalog_search"].(*CatalogSearchTool); ok {
+		cs.WithStockPolicy(defaultPolicy)
+	}
+	if sim, ok := r.tools["catalog_similar"].(*CatalogSimilarTool); ok {
+		sim.WithStockPolicy(defaultPolicy)
+	}
+	if rt, ok := r.tools["catalog_routine"].(*CatalogRoutineTool); ok {
+		rt.WithStockPolicy(defaultPolicy)
+	}
+	return r
+}
+
 // Register adds a tool to the registry
 func (r *Registry) Register(tool ToolExecutor) {
 	def := tool.Definition()
@@ -73,6 +105,12 @@ func (r *Registry) GetDefinitions() []domain.ToolDefinition {
 	return defs
 }
 
+// IsReadOnly reports whether the named tool never writes state
+func (r *Registry) IsReadOnly(name string) bool {
+	ro, ok := r.tools[name].(ReadOnlyTool)
+	return ok && ro.ReadOnly()
+}
+
 // Execute runs a tool by name
 func (r *Registry) Execute(ctx context.Context, toolCtx ToolContext, toolCall domain.ToolCall) (*domain.ToolResult, error) {
 	tool, ok := r.tools[toolCall.Name]
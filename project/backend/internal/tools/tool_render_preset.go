@@ -189,8 +189,17 @@ func (t *RenderProductPresetTool) Execute(ctx context.Context, toolCtx ToolConte
 		return engine.ProductFieldGetter(p), func() string { return p.Currency }, func() string { return p.ID }
 	})
 
+	if stock := state.Current.Meta.Stock; stock != nil {
+		engine.ApplyStockStyling(formation.Widgets, products, stock.LowThreshold)
+	}
+
 	formation.Config = buildRenderConfig("product", preset, preset.DefaultSize, fieldSpecs)
 
+	// Ingredient compatibility answer ("можно ли это вместе?") as an info card over the products
+	if report := turnCompatibility(state, toolCtx); report != nil {
+		engine.ApplyCompatibilityCard(formation, report)
+	}
+
 	template := map[string]interface{}{
 		"formation": formation,
 	}
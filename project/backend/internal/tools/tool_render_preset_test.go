@@ -83,6 +83,42 @@ func TestRenderProductPreset_DataZoneUntouched(t *testing.T) {
 	}
 }
 
+func TestRenderProductPreset_StockBadges(t *testing.T) {
+	zero := 0
+	state := &domain.SessionState{
+		ID: "s1", SessionID: "sess-1",
+		Current: domain.StateCurrent{
+			Data: domain.StateData{
+				Products: []domain.Product{
+					{ID: "p1", Name: "Nike Air Max", Price: 12990, Currency: "$", StockAvailable: &zero},
+					{ID: "p2", Name: "Nike Dunk", Price: 9990, Currency: "$"},
+				},
+			},
+			Meta: domain.StateMeta{Count: 2, Stock: &domain.StockConfig{Policy: domain.StockPolicyBadge, LowThreshold: 3}},
+		},
+	}
+	sp := newMockStatePort(state)
+	tool := tools.NewRenderProductPresetTool(sp, presets.NewPresetRegistry())
+
+	toolCtx := tools.ToolContext{SessionID: "sess-1", TurnID: "turn-1", ActorID: "agent2"}
+	if _, err := tool.Execute(context.Background(), toolCtx, map[string]interface{}{"preset": "product_grid"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	formation, ok := state.Current.Template["formation"].(*domain.FormationWithData)
+	if !ok || len(formation.Widgets) != 2 {
+		t.Fatalf("expected formation with 2 widgets, got %+v", state.Current.Template)
+	}
+	if a := formation.Widgets[0].Atoms[0]; a.Value != "Нет в наличии" {
+		t.Errorf("out-of-stock card should start with the stock badge, got %+v", a)
+	}
+	for _, a := range formation.Widgets[1].Atoms {
+		if a.FieldName == "stockStatus" {
+			t.Errorf("untracked stock should get no badge, got %+v", a)
+		}
+	}
+}
+
 func TestRenderServicePreset_UsesUpdateTemplate(t *testing.T) {
 	state := &domain.SessionState{
 		ID: "s1", SessionID: "sess-1",
@@ -21,42 +21,47 @@ func NewStateFilterTool(statePort ports.StatePort) *StateFilterTool {
 
 // Definition returns the tool definition for LLM
 func (t *StateFilterTool) Definition() domain.ToolDefinition {
+	props := map[string]interface{}{
+		"entity_type": map[string]interface{}{
+			"type":        "string",
+			"enum":        []string{"product", "service"},
+			"description": "Entity type to filter. Default: product.",
+		},
+		"brand": map[string]interface{}{
+			"type":        "string",
+			"description": "Filter by brand name (case-insensitive contains).",
+		},
+		"category": map[string]interface{}{
+			"type":        "string",
+			"description": "Filter by category name (case-insensitive contains).",
+		},
+		"min_price": map[string]interface{}{
+			"type":        "number",
+			"description": "Minimum price in RUBLES.",
+		},
+		"max_price": map[string]interface{}{
+			"type":        "number",
+			"description": "Maximum price in RUBLES.",
+		},
+		"min_rating": map[string]interface{}{
+			"type":        "number",
+			"description": "Minimum rating (0-5).",
+		},
+		"text_match": map[string]interface{}{
+			"type":        "string",
+			"description": "Free text match against name and description (case-insensitive).",
+		},
+	}
+	for k, v := range attributeFilterProperties() {
+		props[k] = v
+	}
+
 	return domain.ToolDefinition{
 		Name:        "_internal_state_filter",
 		Description: "Filter already loaded products in state. Use when user wants a SUBSET of existing data (e.g. 'только COSRX', 'дешевле 5000'). Does NOT search catalog — only filters in-memory.",
 		InputSchema: map[string]interface{}{
-			"type": "object",
-			"properties": map[string]interface{}{
-				"entity_type": map[string]interface{}{
-					"type":        "string",
-					"enum":        []string{"product", "service"},
-					"description": "Entity type to filter. Default: product.",
-				},
-				"brand": map[string]interface{}{
-					"type":        "string",
-					"description": "Filter by brand name (case-insensitive contains).",
-				},
-				"category": map[string]interface{}{
-					"type":        "string",
-					"description": "Filter by category name (case-insensitive contains).",
-				},
-				"min_price": map[string]interface{}{
-					"type":        "number",
-					"description": "Minimum price in RUBLES.",
-				},
-				"max_price": map[string]interface{}{
-					"type":        "number",
-					"description": "Maximum price in RUBLES.",
-				},
-				"min_rating": map[string]interface{}{
-					"type":        "number",
-					"description": "Minimum rating (0-5).",
-				},
-				"text_match": map[string]interface{}{
-					"type":        "string",
-					"description": "Free text match against name and description (case-insensitive).",
-				},
-			},
+			"type":       "object",
+			"properties": props,
 		},
 	}
 }
@@ -83,6 +88,7 @@ func (t *StateFilterTool) Execute(ctx context.Context, toolCtx ToolContext, inpu
 	if v, ok := input["min_rating"].(float64); ok {
 		minRating = v
 	}
+	attrs := parseAttributeFilter(input)
 
 	originalProducts := state.Current.Data.Products
 	originalServices := state.Current.Data.Services
@@ -90,7 +96,7 @@ func (t *StateFilterTool) Execute(ctx context.Context, toolCtx ToolContext, inpu
 	// Filter products
 	var filteredProducts []domain.Product
 	for _, p := range originalProducts {
-		if !matchProduct(p, brand, category, textMatch, minPrice, maxPrice, minRating) {
+		if !matchProduct(p, brand, category, textMatch, minPrice, maxPrice, minRating) || !matchAttributes(p, attrs) {
 			continue
 		}
 		filteredProducts = append(filteredProducts, p)
@@ -99,7 +105,7 @@ func (t *StateFilterTool) Execute(ctx context.Context, toolCtx ToolContext, inpu
 	// Filter services
 	var filteredServices []domain.Service
 	for _, s := range originalServices {
-		if !matchService(s, brand, category, textMatch, minPrice, maxPrice, minRating) {
+		if !matchService(s, brand, category, textMatch, minPrice, maxPrice, minRating) || !matchServiceBrands(s, attrs) {
 			continue
 		}
 		filteredServices = append(filteredServices, s)
@@ -143,6 +149,7 @@ func (t *StateFilterTool) Execute(ctx context.Context, toolCtx ToolContext, inpu
 		ServiceCount: len(filteredServices),
 		Fields:       fields,
 		Aliases:      state.Current.Meta.Aliases,
+		Stock:        state.Current.Meta.Stock,
 	}
 
 	info := domain.DeltaInfo{
@@ -210,6 +217,14 @@ func matchService(s domain.Service, brand, category, textMatch string, minPrice,
 	return true
 }
 
+// matchServiceBrands applies the brand lists to a service provider; other list filters are product-only
+func matchServiceBrands(s domain.Service, f ports.AttributeFilter) bool {
+	if len(f.Brands) > 0 && !containsAnyCI(s.Provider, f.Brands) {
+		return false
+	}
+	return len(f.ExcludeBrands) == 0 || !containsAnyCI(s.Provider, f.ExcludeBrands)
+}
+
 // containsCI is case-insensitive string contains
 func containsCI(s, substr string) bool {
 	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
@@ -505,6 +505,9 @@ func (t *VisualAssemblyTool) Execute(ctx context.Context, toolCtx ToolContext, i
 	if composeRaw, ok := input["compose"].([]interface{}); ok && len(composeRaw) > 0 {
 		formation := engine.BuildComposedFormation(t.presetRegistry, composeRaw, products, services, displayOverrides, formatOverrides, template, size, entityType)
 		formation = engine.ApplyPostProcessing(formation, colorMap, perAtomSize, shapeMap, layerMap, anchorMap, direction, place, paginationLimit, paginationOffset)
+		if report := turnCompatibility(state, toolCtx); report != nil {
+			engine.ApplyCompatibilityCard(formation, report)
+		}
 		return t.writeFormation(ctx, toolCtx, formation, entityType, presetName, formationMode, size, fieldConfigs, fields, layout, products, services, degraded)
 	}
 
@@ -565,6 +568,11 @@ func (t *VisualAssemblyTool) Execute(ctx context.Context, toolCtx ToolContext, i
 		engine.ApplyConditionalStyling(formation.Widgets, rules)
 	}
 
+	// Stock badges under the policy of the search that produced the products
+	if stock := state.Current.Meta.Stock; stock != nil {
+		engine.ApplyStockStyling(formation.Widgets, products, stock.LowThreshold)
+	}
+
 	// Calculate layout zones for each widget
 	tokens := engine.DefaultDesignTokens()
 	for i := range formation.Widgets {
@@ -574,6 +582,16 @@ func (t *VisualAssemblyTool) Execute(ctx context.Context, toolCtx ToolContext, i
 	// Apply post-processing (meta, pagination)
 	formation = engine.ApplyPostProcessing(formation, colorMap, perAtomSize, shapeMap, layerMap, anchorMap, direction, place, paginationLimit, paginationOffset)
 
+	// A routine from catalog_routine is shown step by step with the total price
+	if routine := state.Current.Meta.Routine; routine != nil && len(services) == 0 {
+		engine.ApplyRoutineSections(formation, routine)
+	}
+
+	// Ingredient compatibility answer ("можно ли это вместе?") as an info card over the products
+	if report := turnCompatibility(state, toolCtx); report != nil {
+		engine.ApplyCompatibilityCard(formation, report)
+	}
+
 	return t.writeFormation(ctx, toolCtx, formation, entityType, presetName, formationMode, size, fieldConfigs, fields, layout, products, services, degraded)
 }
 
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"keepstar/internal/domain"
@@ -22,12 +23,20 @@ var filterTriggers = regexp.MustCompile(`(?i)(только|лишь|оставь
 // styleFieldNames are display field names — "убери <field>" = style request, not data filter
 var styleFieldNames = regexp.MustCompile(`(?i)(описани|рейтинг|бренд|цен[уыа]|фото|картинк|назван|изображ|тег|катего|рейт|rating|brand|price|image|name|description|tag)`)
 
+// similarTriggers are "more like this" requests — "похожие, но дешевле" is catalog_similar, not a state filter
+var similarTriggers = regexp.MustCompile(`(?i)(похож|аналог|альтернатив|замен|similar|alternative|like this)`)
+
+// routineTriggers are skincare routine requests — "рутина до 5000" is catalog_routine with a budget, not a state filter
+var routineTriggers = regexp.MustCompile(`(?i)(рутин|routine|полный уход|схем\S* ухода)`)
+
 // Agent1ExecuteRequest is the input for Agent 1
 type Agent1ExecuteRequest struct {
 	SessionID  string
 	Query      string
 	TenantSlug string // Tenant context for search
 	TurnID     string // Turn ID for delta grouping
+	// SystemPrompt overrides prompts.Agent1SystemPrompt (prompt registry version)
+	SystemPrompt string
 }
 
 // Agent1ExecuteResponse is the output from Agent 1
@@ -35,14 +44,15 @@ type Agent1ExecuteResponse struct {
 	Usage     domain.LLMUsage
 	LatencyMs int
 	// Detailed timing breakdown
-	LLMCallMs      int64  `json:"llmCallMs"`
-	ToolExecuteMs  int64  `json:"toolExecuteMs"`
-	ToolName       string `json:"toolName"`
-	ToolInput      string `json:"toolInput"`
-	ToolResult     string                 `json:"toolResult"`
-	ToolMetadata   map[string]interface{} `json:"toolMetadata,omitempty"` // Internal breakdown from tool
-	ProductsFound  int                    `json:"productsFound"`
-	StopReason     string                 `json:"stopReason"`
+	LLMCallMs     int64                  `json:"llmCallMs"`
+	ToolExecuteMs int64                  `json:"toolExecuteMs"`
+	ToolName      string                 `json:"toolName"`
+	ToolInput     string                 `json:"toolInput"`
+	ToolResult    string                 `json:"toolResult"`
+	ToolMetadata  map[string]interface{} `json:"toolMetadata,omitempty"` // Internal breakdown from tool
+	ProductsFound int                    `json:"productsFound"`
+	StopReason    string                 `json:"stopReason"`      // LLM stop reason, or "max_steps" / "token_budget" when the loop was capped
+	Steps         []domain.AgentStep     `json:"steps,omitempty"` // One entry per loop iteration
 	// Prompt breakdown for trace
 	SystemPrompt      string `json:"systemPrompt"`
 	SystemPromptChars int    `json:"systemPromptChars"`
@@ -58,8 +68,15 @@ type Agent1ExecuteUseCase struct {
 	catalogPort  ports.CatalogPort
 	toolRegistry *tools.Registry
 	log          *logger.Logger
+	maxSteps     int // LLM calls per turn
+	maxTokens    int // input+output tokens per turn (0 = unlimited)
 }
 
+const (
+	defaultAgent1MaxSteps  = 4
+	defaultAgent1MaxTokens = 60000
+)
+
 // NewAgent1ExecuteUseCase creates Agent 1 use case
 func NewAgent1ExecuteUseCase(
 	llm ports.LLMPort,
@@ -74,10 +91,23 @@ func NewAgent1ExecuteUseCase(
 		catalogPort:  catalogPort,
 		toolRegistry: toolRegistry,
 		log:          log,
+		maxSteps:     defaultAgent1MaxSteps,
+		maxTokens:    defaultAgent1MaxTokens,
+	}
+}
+
+// WithLoopLimits caps the agent loop per turn: LLM calls and input+output tokens (0 = unlimited).
+// The loop always makes at least one LLM call.
+func (uc *Agent1ExecuteUseCase) WithLoopLimits(maxSteps, maxTokens int) *Agent1ExecuteUseCase {
+	if maxSteps < 1 {
+		maxSteps = 1
 	}
+	uc.maxSteps = maxSteps
+	uc.maxTokens = maxTokens
+	return uc
 }
 
-// Execute runs Agent 1: query → tool call → state update → delta
+// Execute runs Agent 1: query → (LLM → tool calls → tool_result)* → state update → delta
 func (uc *Agent1ExecuteUseCase) Execute(ctx context.Context, req Agent1ExecuteRequest) (*Agent1ExecuteResponse, error) {
 	start := time.Now()
 
@@ -88,6 +118,8 @@ func (uc *Agent1ExecuteUseCase) Execute(ctx context.Context, req Agent1ExecuteRe
 		defer endAgent()
 	}
 	ctx = domain.WithStage(ctx, "agent1")
+	// Ambiguous/multi-constraint queries may be routed to a stronger model
+	ctx = domain.WithQueryComplexity(ctx, domain.ClassifyQueryComplexity(req.Query))
 
 	uc.log.Info("agent1_started",
 		"session_id", req.SessionID,
@@ -127,8 +159,8 @@ func (uc *Agent1ExecuteUseCase) Execute(ctx context.Context, req Agent1ExecuteRe
 	}
 
 	// Deterministic pre-check: if data loaded AND query has filter triggers → bypass LLM, call state_filter
-	// But NOT if the query is about display fields (style request)
-	isFilterQuery := filterTriggers.MatchString(req.Query) && !styleFieldNames.MatchString(req.Query)
+	// But NOT if the query is about display fields (style request) or asks for similar products
+	isFilterQuery := filterTriggers.MatchString(req.Query) && !styleFieldNames.MatchString(req.Query) && !similarTriggers.MatchString(req.Query) && !routineTriggers.MatchString(req.Query)
 	if state.Current.Meta.ProductCount > 0 && isFilterQuery {
 		uc.log.Info("deterministic_state_filter",
 			"session_id", req.SessionID,
@@ -141,6 +173,11 @@ func (uc *Agent1ExecuteUseCase) Execute(ctx context.Context, req Agent1ExecuteRe
 			"text_match": req.Query,
 		}
 
+		domain.EmitEvent(ctx, domain.PipelineEventToolCall, domain.ToolCallEventData{
+			Tool:  "_internal_state_filter",
+			Input: filterInput,
+		})
+
 		var endToolSpan func(...string)
 		if sc != nil {
 			endToolSpan = sc.Start("agent1.tool")
@@ -168,7 +205,13 @@ func (uc *Agent1ExecuteUseCase) Execute(ctx context.Context, req Agent1ExecuteRe
 
 			// Update conversation history
 			state, _ = uc.statePort.GetState(ctx, req.SessionID)
-			newHistory := append(state.ConversationHistory,
+			domain.EmitEvent(ctx, domain.PipelineEventToolResult, domain.ToolResultEventData{
+				Tool:         "_internal_state_filter",
+				Result:       result.Content,
+				ProductCount: len(state.Current.Data.Products),
+				ServiceCount: len(state.Current.Data.Services),
+			})
+			newHistory := appendTurn(state.ConversationHistory, req.TurnID,
 				domain.LLMMessage{Role: "user", Content: req.Query},
 			)
 			if err := uc.statePort.AppendConversation(ctx, req.SessionID, newHistory); err != nil {
@@ -192,130 +235,201 @@ func (uc *Agent1ExecuteUseCase) Execute(ctx context.Context, req Agent1ExecuteRe
 	// Note: catalog digest is already in conversation_history from session init — no per-turn loading
 	enrichedQuery := prompts.BuildAgent1ContextPrompt(state.Current.Meta, currentConfig, req.Query)
 
-	// Build messages with conversation history
-	messages := state.ConversationHistory
+	// Build messages with conversation history (copy: the loop appends to it)
+	messages := make([]domain.LLMMessage, 0, len(state.ConversationHistory)+1)
+	messages = append(messages, state.ConversationHistory...)
 	messages = append(messages, domain.LLMMessage{
 		Role:    "user",
 		Content: enrichedQuery,
 	})
+	initialMessageCount := len(messages)
+
+	systemPrompt := req.SystemPrompt
+	if systemPrompt == "" {
+		systemPrompt = prompts.Agent1SystemPrompt
+	}
 
 	// Get data-only tool definitions (Agent1 = data layer, no render tools)
 	toolDefs := uc.getAgent1Tools()
-
-	// Call LLM with caching
-	llmStart := time.Now()
-	llmResp, err := uc.llm.ChatWithToolsCached(
-		ctx,
-		prompts.Agent1SystemPrompt,
-		messages,
-		toolDefs,
-		&ports.CacheConfig{
-			CacheTools:        true,
-			CacheSystem:       true,
-			CacheConversation: len(messages) > 1, // cache if history exists
-		},
-	)
-	llmDuration := time.Since(llmStart).Milliseconds()
-
-	if err != nil {
-		uc.log.Error("llm_call_failed", "error", err, "session_id", req.SessionID)
-		return nil, fmt.Errorf("llm call: %w", err)
+	toolCtx := tools.ToolContext{
+		SessionID:  req.SessionID,
+		TurnID:     req.TurnID,
+		ActorID:    "agent1",
+		TenantSlug: req.TenantSlug,
 	}
 
-	// Log LLM usage with cache metrics
-	uc.log.LLMUsageWithCache(
-		"agent1",
-		llmResp.Usage.Model,
-		llmResp.Usage.InputTokens,
-		llmResp.Usage.OutputTokens,
-		llmResp.Usage.CacheCreationInputTokens,
-		llmResp.Usage.CacheReadInputTokens,
-		llmResp.Usage.CostUSD,
-		llmDuration,
+	// Agent loop: LLM → tools → tool_result → LLM ... until end_turn or a cap.
+	// turnMessages collects assistant tool_use + tool_result messages for history.
+	var (
+		usage        domain.LLMUsage
+		steps        []domain.AgentStep
+		turnMessages []domain.LLMMessage
+		primary      *toolOutcome
+		llmDuration  int64
+		toolDuration int64
+		stopReason   string
+		finalText    string
 	)
+	for step := 1; ; step++ {
+		var endStep func(...string)
+		if sc != nil {
+			endStep = sc.Start("agent1.step")
+		}
 
-	// Process tool calls
-	var toolName string
-	var toolInput string
-	var toolResult string
-	var toolMetadata map[string]interface{}
-	var toolDuration int64
-	var productsFound int
+		// Call LLM with caching
+		llmStart := time.Now()
+		llmResp, err := uc.llm.ChatWithToolsCached(
+			ctx,
+			systemPrompt,
+			messages,
+			toolDefs,
+			&ports.CacheConfig{
+				CacheTools:        true,
+				CacheSystem:       true,
+				CacheConversation: len(messages) > 1, // cache if history exists
+			},
+		)
+		stepLLMMs := time.Since(llmStart).Milliseconds()
+		llmDuration += stepLLMMs
 
-	if len(llmResp.ToolCalls) > 0 {
-		// Execute first tool call (Agent 1 should only call one)
-		toolCall := llmResp.ToolCalls[0]
-		toolName = toolCall.Name
-		// Serialize tool input to JSON string for debug
-		if inputBytes, err := json.Marshal(toolCall.Input); err == nil {
-			toolInput = string(inputBytes)
+		if err != nil {
+			if endStep != nil {
+				endStep(fmt.Sprintf("%d: error", step))
+			}
+			uc.log.Error("llm_call_failed", "error", err, "session_id", req.SessionID, "step", step)
+			return nil, fmt.Errorf("llm call: %w", err)
 		}
 
-		uc.log.Debug("tool_call_received",
-			"tool", toolCall.Name,
-			"input", toolCall.Input,
-			"session_id", req.SessionID,
+		// Log LLM usage with cache metrics
+		uc.log.LLMUsageWithCache(
+			"agent1",
+			llmResp.Usage.Model,
+			llmResp.Usage.InputTokens,
+			llmResp.Usage.OutputTokens,
+			llmResp.Usage.CacheCreationInputTokens,
+			llmResp.Usage.CacheReadInputTokens,
+			llmResp.Usage.CostUSD,
+			stepLLMMs,
 		)
-
-		var endToolSpan func(...string)
-		if sc != nil {
-			endToolSpan = sc.Start("agent1.tool")
+		usage.Add(llmResp.Usage)
+
+		stepTrace := domain.AgentStep{
+			Step:         step,
+			Model:        llmResp.Usage.Model,
+			LLMMs:        stepLLMMs,
+			InputTokens:  llmResp.Usage.InputTokens,
+			OutputTokens: llmResp.Usage.OutputTokens,
+			CostUSD:      llmResp.Usage.CostUSD,
+			StopReason:   llmResp.StopReason,
+			Text:         llmResp.Text,
 		}
-		toolStart := time.Now()
-		result, err := uc.toolRegistry.Execute(ctx, tools.ToolContext{
-			SessionID:  req.SessionID,
-			TurnID:     req.TurnID,
-			ActorID:    "agent1",
-			TenantSlug: req.TenantSlug,
-		}, toolCall)
-		toolDuration = time.Since(toolStart).Milliseconds()
-		if endToolSpan != nil {
-			endToolSpan(toolCall.Name)
+		stopReason = llmResp.StopReason
+
+		if len(llmResp.ToolCalls) == 0 {
+			// end_turn: style request, ambiguous query, or data is ready. Agent2 handles rendering.
+			if step == 1 {
+				uc.log.Info("no_tool_call",
+					"session_id", req.SessionID,
+					"stop_reason", llmResp.StopReason,
+					"text", llmResp.Text,
+				)
+			}
+			finalText = llmResp.Text
+			steps = append(steps, stepTrace)
+			if endStep != nil {
+				endStep(fmt.Sprintf("%d: %s", step, llmResp.StopReason))
+			}
+			break
 		}
-		toolResult = result.Content
-		toolMetadata = result.Metadata
 
-		if err != nil {
-			uc.log.Error("tool_execution_failed", "error", err, "tool", toolCall.Name)
-			return nil, fmt.Errorf("tool execute: %w", err)
-		}
+		// Execute all tool calls of this step (read-only ones concurrently)
+		toolStart := time.Now()
+		outcomes := uc.executeTools(ctx, toolCtx, llmResp.ToolCalls)
+		stepTrace.ToolMs = time.Since(toolStart).Milliseconds()
+		toolDuration += stepTrace.ToolMs
+
+		turnMessages = append(turnMessages, domain.LLMMessage{Role: "assistant", ToolCalls: llmResp.ToolCalls})
+		messages = append(messages, domain.LLMMessage{Role: "assistant", ToolCalls: llmResp.ToolCalls})
+		names := make([]string, 0, len(outcomes))
+		for i := range outcomes {
+			o := &outcomes[i]
+			resultMsg := domain.LLMMessage{
+				Role: "user",
+				ToolResult: &domain.ToolResult{
+					ToolUseID: o.call.ID,
+					Content:   o.result.Content,
+					IsError:   o.result.IsError,
+				},
+			}
+			turnMessages = append(turnMessages, resultMsg)
+			messages = append(messages, resultMsg)
+			stepTrace.Tools = append(stepTrace.Tools, o.trace())
+			names = append(names, o.call.Name)
+
+			// LLM calls inside tools (rerank judge) are part of the turn's cost; the agent model stays the label
+			if o.result.Usage != nil {
+				toolUsage := *o.result.Usage
+				toolUsage.Model = ""
+				usage.Add(toolUsage)
+			}
 
-		uc.log.ToolExecuted(toolCall.Name, req.SessionID, result.Content, toolDuration)
+			// Primary tool = last state-writing call (what Agent2 renders); else the last call
+			if !o.readOnly || primary == nil || primary.readOnly {
+				primary = o
+			}
+		}
+		steps = append(steps, stepTrace)
+		if endStep != nil {
+			endStep(fmt.Sprintf("%d: %s", step, strings.Join(names, ",")))
+		}
 
-		// Get updated state after tool zone-write
-		state, _ = uc.statePort.GetState(ctx, req.SessionID)
-		productsFound = state.Current.Meta.Count
-	} else {
-		// No tool call — style request or ambiguous query. Agent2 handles rendering.
-		uc.log.Info("no_tool_call",
+		// Caps: tool results are already paired with their tool_use, so history stays valid
+		if step >= uc.maxSteps {
+			stopReason = "max_steps"
+			break
+		}
+		if uc.maxTokens > 0 && usage.InputTokens+usage.OutputTokens >= uc.maxTokens {
+			stopReason = "token_budget"
+			break
+		}
+	}
+	if stopReason == "max_steps" || stopReason == "token_budget" {
+		uc.log.Warn("agent1_loop_capped",
 			"session_id", req.SessionID,
-			"stop_reason", llmResp.StopReason,
-			"text", llmResp.Text,
+			"reason", stopReason,
+			"steps", len(steps),
+			"tokens", usage.InputTokens+usage.OutputTokens,
 		)
 	}
 
+	// Tool fields for the response/trace come from the primary tool
+	var toolName, toolInput, toolResult string
+	var toolMetadata map[string]interface{}
+	var productsFound int
+	if primary != nil {
+		toolName = primary.call.Name
+		toolInput = primary.input()
+		toolResult = primary.result.Content
+		toolMetadata = primary.result.Metadata
+		// Get updated state after tool zone-writes
+		if s, err := uc.statePort.GetState(ctx, req.SessionID); err == nil {
+			state = s
+		}
+		productsFound = state.Current.Meta.Count
+	}
+
 	// State update span
 	var endState func(...string)
 	if sc != nil {
 		endState = sc.Start("agent1.state")
 	}
 	// Update conversation history via AppendConversation (zone-write, no blob UpdateState)
-	// Full sequence: user → assistant:tool_use → user:tool_result (required by Anthropic API)
-	newHistory := append(state.ConversationHistory,
-		domain.LLMMessage{Role: "user", Content: req.Query},
-	)
-	if len(llmResp.ToolCalls) > 0 {
-		newHistory = append(newHistory,
-			domain.LLMMessage{Role: "assistant", ToolCalls: llmResp.ToolCalls},
-			domain.LLMMessage{
-				Role: "user",
-				ToolResult: &domain.ToolResult{
-					ToolUseID: llmResp.ToolCalls[0].ID,
-					Content:   toolResult,
-				},
-			},
-		)
+	// Full sequence: user → (assistant:tool_use → user:tool_result)* → assistant text (required by Anthropic API)
+	turnMessages = append([]domain.LLMMessage{{Role: "user", Content: req.Query}}, turnMessages...)
+	if finalText != "" {
+		turnMessages = append(turnMessages, domain.LLMMessage{Role: "assistant", Content: finalText})
 	}
+	newHistory := appendTurn(state.ConversationHistory, req.TurnID, turnMessages...)
 	if err := uc.statePort.AppendConversation(ctx, req.SessionID, newHistory); err != nil {
 		uc.log.Error("append_conversation_failed", "error", err, "session_id", req.SessionID)
 	}
@@ -330,13 +444,13 @@ func (uc *Agent1ExecuteUseCase) Execute(ctx context.Context, req Agent1ExecuteRe
 		req.SessionID,
 		toolName,
 		productsFound,
-		llmResp.Usage.TotalTokens,
-		llmResp.Usage.CostUSD,
+		usage.TotalTokens,
+		usage.CostUSD,
 		totalDuration,
 	)
 
 	return &Agent1ExecuteResponse{
-		Usage:             llmResp.Usage,
+		Usage:             usage,
 		LatencyMs:         int(totalDuration),
 		LLMCallMs:         llmDuration,
 		ToolExecuteMs:     toolDuration,
@@ -345,15 +459,125 @@ func (uc *Agent1ExecuteUseCase) Execute(ctx context.Context, req Agent1ExecuteRe
 		ToolResult:        toolResult,
 		ToolMetadata:      toolMetadata,
 		ProductsFound:     productsFound,
-		StopReason:        llmResp.StopReason,
-		SystemPrompt:      prompts.Agent1SystemPrompt,
-		SystemPromptChars: len(prompts.Agent1SystemPrompt),
+		StopReason:        stopReason,
+		Steps:             steps,
+		SystemPrompt:      systemPrompt,
+		SystemPromptChars: len(systemPrompt),
 		EnrichedQuery:     enrichedQuery,
-		MessageCount:      len(messages),
+		MessageCount:      initialMessageCount,
 		ToolDefCount:      len(toolDefs),
 	}, nil
 }
 
+// appendTurn appends the messages of one turn to the conversation history, tagged with
+// its TurnID so undo and fork can cut the history at a turn (domain.ConversationUpToStep)
+func appendTurn(history []domain.LLMMessage, turnID string, messages ...domain.LLMMessage) []domain.LLMMessage {
+	out := make([]domain.LLMMessage, 0, len(history)+len(messages))
+	out = append(out, history...)
+	for _, msg := range messages {
+		msg.TurnID = turnID
+		out = append(out, msg)
+	}
+	return out
+}
+
+// toolOutcome is one executed tool call of an agent step
+type toolOutcome struct {
+	call     domain.ToolCall
+	result   *domain.ToolResult
+	readOnly bool
+	ms       int64
+}
+
+func (o *toolOutcome) input() string {
+	inputBytes, err := json.Marshal(o.call.Input)
+	if err != nil {
+		return ""
+	}
+	return string(inputBytes)
+}
+
+func (o *toolOutcome) trace() domain.ToolTrace {
+	return domain.ToolTrace{
+		Name:      o.call.Name,
+		Input:     o.input(),
+		Result:    o.result.Content,
+		IsError:   o.result.IsError,
+		Ms:        o.ms,
+		Breakdown: o.result.Metadata,
+	}
+}
+
+// executeTools runs the tool calls of one LLM response. Read-only tools run concurrently;
+// state-writing tools run one after another in the order the model issued them, since they
+// write the same zones (data.products). Outcomes keep the call order.
+func (uc *Agent1ExecuteUseCase) executeTools(ctx context.Context, toolCtx tools.ToolContext, calls []domain.ToolCall) []toolOutcome {
+	outcomes := make([]toolOutcome, len(calls))
+	var wg sync.WaitGroup
+	var writers []int
+	for i, call := range calls {
+		outcomes[i] = toolOutcome{call: call, readOnly: uc.toolRegistry.IsReadOnly(call.Name)}
+		if !outcomes[i].readOnly {
+			writers = append(writers, i)
+			continue
+		}
+		wg.Add(1)
+		go func(o *toolOutcome) {
+			defer wg.Done()
+			uc.executeTool(ctx, toolCtx, o)
+		}(&outcomes[i])
+	}
+	for _, i := range writers {
+		uc.executeTool(ctx, toolCtx, &outcomes[i])
+	}
+	wg.Wait()
+	return outcomes
+}
+
+// executeTool runs one tool call with span, events and logging
+func (uc *Agent1ExecuteUseCase) executeTool(ctx context.Context, toolCtx tools.ToolContext, o *toolOutcome) {
+	uc.log.Debug("tool_call_received",
+		"tool", o.call.Name,
+		"input", o.call.Input,
+		"session_id", toolCtx.SessionID,
+	)
+	domain.EmitEvent(ctx, domain.PipelineEventToolCall, domain.ToolCallEventData{
+		Tool:  o.call.Name,
+		Input: o.call.Input,
+	})
+
+	var endToolSpan func(...string)
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endToolSpan = sc.Start("agent1.tool")
+	}
+	toolStart := time.Now()
+	result, err := uc.toolRegistry.Execute(ctx, toolCtx, o.call)
+	o.ms = time.Since(toolStart).Milliseconds()
+	if endToolSpan != nil {
+		endToolSpan(o.call.Name)
+	}
+	if err != nil || result == nil {
+		// Reported back to the model as an error tool_result; it may retry or stop
+		uc.log.Error("tool_execution_failed", "error", err, "tool", o.call.Name)
+		result = &domain.ToolResult{ToolUseID: o.call.ID, Content: fmt.Sprintf("tool error: %v", err), IsError: true}
+	}
+	o.result = result
+
+	uc.log.ToolExecuted(o.call.Name, toolCtx.SessionID, result.Content, o.ms)
+
+	var productCount, serviceCount int
+	if state, err := uc.statePort.GetState(ctx, toolCtx.SessionID); err == nil {
+		productCount = len(state.Current.Data.Products)
+		serviceCount = len(state.Current.Data.Services)
+	}
+	domain.EmitEvent(ctx, domain.PipelineEventToolResult, domain.ToolResultEventData{
+		Tool:         o.call.Name,
+		Result:       result.Content,
+		ProductCount: productCount,
+		ServiceCount: serviceCount,
+	})
+}
+
 // getAgent1Tools returns data tools only for Agent 1 (catalog_*)
 func (uc *Agent1ExecuteUseCase) getAgent1Tools() []domain.ToolDefinition {
 	allTools := uc.toolRegistry.GetDefinitions()
@@ -0,0 +1,185 @@
+package usecases_test
+
+import (
+	"context"
+	"testing"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/logger"
+	"keepstar/internal/presets"
+	"keepstar/internal/testutil"
+	"keepstar/internal/tools"
+	"keepstar/internal/usecases"
+)
+
+// agent1LoopSetup builds Agent1 over the in-memory state port with loaded products.
+// Catalog is nil: the loop tests only use state tools.
+func agent1LoopSetup(t *testing.T, llm *testutil.MockLLMClient) (*usecases.Agent1ExecuteUseCase, *mockStatePort) {
+	t.Helper()
+	statePort := newMockStatePort()
+	state, _ := statePort.CreateState(context.Background(), "session-loop")
+	state.Current.Data.Products = testutil.SeedProducts(6)
+
+	registry := tools.NewRegistry(statePort, nil, presets.NewPresetRegistry(), nil)
+	uc := usecases.NewAgent1ExecuteUseCase(llm, statePort, nil, registry, logger.New("error"))
+	return uc, statePort
+}
+
+func toolUse(calls ...domain.ToolCall) *domain.LLMResponse {
+	return &domain.LLMResponse{
+		ToolCalls:  calls,
+		StopReason: "tool_use",
+		Usage:      domain.LLMUsage{InputTokens: 100, OutputTokens: 10, TotalTokens: 110, Model: "mock", CostUSD: 0.001},
+	}
+}
+
+func TestAgent1Loop_HistoryLookupThenFilter(t *testing.T) {
+	llm := testutil.NewMockLLMClient(
+		// Step 1: two independent lookups in parallel
+		toolUse(
+			domain.ToolCall{ID: "h1", Name: "_internal_history_lookup", Input: map[string]interface{}{"query": "catalog_search"}},
+			domain.ToolCall{ID: "h2", Name: "_internal_history_lookup", Input: map[string]interface{}{"last_n": float64(2)}},
+		),
+		// Step 2: filter using what the lookup returned
+		toolUse(domain.ToolCall{ID: "f1", Name: "_internal_state_filter", Input: map[string]interface{}{"max_price": float64(100)}}),
+		// Step 3: done
+		&domain.LLMResponse{Text: "готово", StopReason: "end_turn", Usage: domain.LLMUsage{InputTokens: 50, OutputTokens: 5, TotalTokens: 55, Model: "mock"}},
+	)
+	uc, statePort := agent1LoopSetup(t, llm)
+
+	sc := domain.NewSpanCollector()
+	ctx := domain.WithSpanCollector(context.Background(), sc)
+	resp, err := uc.Execute(ctx, usecases.Agent1ExecuteRequest{
+		SessionID: "session-loop",
+		Query:     "покажи то же, что в прошлый раз",
+		TurnID:    "turn-loop",
+	})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	if llm.CallCount != 3 {
+		t.Errorf("want 3 LLM calls, got %d", llm.CallCount)
+	}
+	if len(resp.Steps) != 3 {
+		t.Fatalf("want 3 steps in trace, got %d", len(resp.Steps))
+	}
+	if len(resp.Steps[0].Tools) != 2 || resp.Steps[1].Tools[0].Name != "_internal_state_filter" {
+		t.Errorf("unexpected step tools: %+v", resp.Steps)
+	}
+	if resp.StopReason != "end_turn" {
+		t.Errorf("want end_turn, got %q", resp.StopReason)
+	}
+	// Primary tool is the state-writing one, not the last lookup
+	if resp.ToolName != "_internal_state_filter" {
+		t.Errorf("want primary tool _internal_state_filter, got %q", resp.ToolName)
+	}
+	if resp.Usage.InputTokens != 250 || resp.Usage.OutputTokens != 25 {
+		t.Errorf("usage must be summed across steps, got %+v", resp.Usage)
+	}
+
+	// History: user → assistant(2 tool_use) → 2 tool_result → assistant(tool_use) → tool_result → assistant text
+	history := statePort.LastConversation
+	if len(history) != 7 {
+		t.Fatalf("want 7 history messages, got %d", len(history))
+	}
+	if history[2].ToolResult == nil || history[2].ToolResult.ToolUseID != "h1" || history[3].ToolResult.ToolUseID != "h2" {
+		t.Errorf("tool results must follow call order: %+v %+v", history[2].ToolResult, history[3].ToolResult)
+	}
+	if history[6].Role != "assistant" || history[6].Content != "готово" {
+		t.Errorf("final assistant text should close the turn, got %+v", history[6])
+	}
+	for i, msg := range history {
+		if msg.TurnID != "turn-loop" {
+			t.Errorf("history message %d should carry the turn ID for undo/fork cuts, got %q", i, msg.TurnID)
+		}
+	}
+
+	stepSpans, toolSpans := 0, 0
+	for _, s := range sc.Spans() {
+		switch s.Name {
+		case "agent1.step":
+			stepSpans++
+		case "agent1.tool":
+			toolSpans++
+		}
+	}
+	if stepSpans != 3 || toolSpans != 3 {
+		t.Errorf("want 3 step and 3 tool spans, got %d and %d", stepSpans, toolSpans)
+	}
+}
+
+func TestAgent1Loop_StepCap(t *testing.T) {
+	lookup := toolUse(domain.ToolCall{ID: "h", Name: "_internal_history_lookup", Input: map[string]interface{}{}})
+	llm := testutil.NewMockLLMClient(lookup, lookup, lookup, lookup)
+	uc, statePort := agent1LoopSetup(t, llm)
+	uc.WithLoopLimits(2, 0)
+
+	resp, err := uc.Execute(context.Background(), usecases.Agent1ExecuteRequest{SessionID: "session-loop", Query: "что было"})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if llm.CallCount != 2 || resp.StopReason != "max_steps" {
+		t.Errorf("want loop capped after 2 calls, got %d calls, stop=%q", llm.CallCount, resp.StopReason)
+	}
+	// Every tool_use in history still has its tool_result
+	last := statePort.LastConversation[len(statePort.LastConversation)-1]
+	if last.ToolResult == nil {
+		t.Errorf("history must end with the tool_result of the last call, got %+v", last)
+	}
+}
+
+func TestAgent1Loop_TokenBudget(t *testing.T) {
+	lookup := toolUse(domain.ToolCall{ID: "h", Name: "_internal_history_lookup", Input: map[string]interface{}{}})
+	llm := testutil.NewMockLLMClient(lookup, lookup, lookup)
+	uc, _ := agent1LoopSetup(t, llm)
+	uc.WithLoopLimits(10, 200) // each step uses 110 tokens
+
+	resp, err := uc.Execute(context.Background(), usecases.Agent1ExecuteRequest{SessionID: "session-loop", Query: "что было"})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if llm.CallCount != 2 || resp.StopReason != "token_budget" {
+		t.Errorf("want loop stopped by token budget after 2 calls, got %d calls, stop=%q", llm.CallCount, resp.StopReason)
+	}
+}
+
+// usageTool stands in for catalog_search with an LLM reranker: the tool result carries the judge's usage
+type usageTool struct{}
+
+func (usageTool) Definition() domain.ToolDefinition {
+	return domain.ToolDefinition{Name: "catalog_search", InputSchema: map[string]interface{}{"type": "object"}}
+}
+
+func (usageTool) Execute(_ context.Context, _ tools.ToolContext, _ map[string]interface{}) (*domain.ToolResult, error) {
+	return &domain.ToolResult{
+		Content: "ok: found 3 products",
+		Usage:   &domain.LLMUsage{InputTokens: 400, OutputTokens: 60, TotalTokens: 460, Model: "judge", CostUSD: 0.0007},
+	}, nil
+}
+
+func TestAgent1Loop_ToolUsageCounted(t *testing.T) {
+	llm := testutil.NewMockLLMClient(
+		toolUse(domain.ToolCall{ID: "s1", Name: "catalog_search", Input: map[string]interface{}{"vector_query": "крем"}}),
+		&domain.LLMResponse{Text: "готово", StopReason: "end_turn", Usage: domain.LLMUsage{InputTokens: 50, OutputTokens: 5, TotalTokens: 55, Model: "mock"}},
+	)
+	statePort := newMockStatePort()
+	statePort.CreateState(context.Background(), "session-usage")
+	registry := tools.NewRegistry(statePort, nil, presets.NewPresetRegistry(), nil)
+	registry.Register(usageTool{})
+	uc := usecases.NewAgent1ExecuteUseCase(llm, statePort, nil, registry, logger.New("error"))
+
+	resp, err := uc.Execute(context.Background(), usecases.Agent1ExecuteRequest{SessionID: "session-usage", Query: "крем"})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if resp.Usage.InputTokens != 100+400+50 || resp.Usage.TotalTokens != 110+460+55 {
+		t.Errorf("rerank usage must be part of the turn, got %+v", resp.Usage)
+	}
+	if diff := resp.Usage.CostUSD - (0.001 + 0.0007); diff > 1e-12 || diff < -1e-12 {
+		t.Errorf("rerank cost must be part of the turn cost, got %f", resp.Usage.CostUSD)
+	}
+	if resp.Usage.Model != "mock" {
+		t.Errorf("the agent model stays the usage label, got %q", resp.Usage.Model)
+	}
+}
@@ -20,6 +20,7 @@ type Agent2ExecuteRequest struct {
 	UserQuery     string         // User's original query (for style selection)
 	Microcontext  string         // Pipeline-generated context signal (e.g. "new_search: 23 items found")
 	ScreenContext *ScreenContext  // Current UI state from frontend
+	SystemPrompt  string          // Overrides prompts.Agent2ToolSystemPrompt (prompt registry version)
 }
 
 // Agent2ExecuteResponse is the output from Agent 2
@@ -187,11 +188,16 @@ func (uc *Agent2ExecuteUseCase) Execute(ctx context.Context, req Agent2ExecuteRe
 	// Get render tool definitions (filter only render_* tools)
 	toolDefs := uc.getAgent2Tools()
 
+	systemPrompt := req.SystemPrompt
+	if systemPrompt == "" {
+		systemPrompt = prompts.Agent2ToolSystemPrompt
+	}
+
 	// Call LLM with caching and forced tool use
 	llmStart := time.Now()
 	llmResp, err := uc.llm.ChatWithToolsCached(
 		ctx,
-		prompts.Agent2ToolSystemPrompt,
+		systemPrompt,
 		messages,
 		toolDefs,
 		&ports.CacheConfig{
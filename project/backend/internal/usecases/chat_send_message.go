@@ -56,6 +56,7 @@ func (uc *SendMessageUseCase) Execute(ctx context.Context, req SendMessageReques
 		endSpan := sc.Start("usecase.send_message")
 		defer endSpan()
 	}
+	ctx = domain.WithStage(ctx, "chat") // model routing key for the legacy chat
 
 	now := time.Now()
 	isNewSession := false
@@ -5,7 +5,6 @@ import (
 	"fmt"
 
 	"keepstar/internal/domain"
-	"keepstar/internal/engine"
 	"keepstar/internal/ports"
 	"keepstar/internal/presets"
 )
@@ -18,12 +17,14 @@ type BackRequest struct {
 
 // BackResponse is the response from back operation
 type BackResponse struct {
-	Success   bool
-	Formation *domain.FormationWithData
-	ViewMode  domain.ViewMode
-	Focused   *domain.EntityRef
-	StackSize int
-	CanGoBack bool
+	Success      bool
+	Formation    *domain.FormationWithData
+	ViewMode     domain.ViewMode
+	Focused      *domain.EntityRef
+	StackSize    int
+	CanGoBack    bool
+	CanGoForward bool
+	Breadcrumbs  []domain.Breadcrumb
 }
 
 // BackUseCase handles going back to previous view
@@ -47,30 +48,53 @@ func (uc *BackUseCase) Execute(ctx context.Context, req BackRequest) (*BackRespo
 		defer endSpan()
 	}
 
-	// 1. Pop from stack
+	// 1. Get current state (the view we leave goes on the forward stack)
+	state, err := uc.statePort.GetState(ctx, req.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("get state: %w", err)
+	}
+
+	// 2. Pop from stack
 	snapshot, err := uc.statePort.PopView(ctx, req.SessionID)
 	if err != nil {
 		return nil, fmt.Errorf("pop view: %w", err)
 	}
 	if snapshot == nil {
-		return &BackResponse{Success: true, CanGoBack: false}, nil
+		return &BackResponse{Success: true, CanGoBack: false, CanGoForward: len(state.ForwardStack) > 0}, nil
 	}
-
-	// 2. Get current state
-	state, err := uc.statePort.GetState(ctx, req.SessionID)
-	if err != nil {
-		return nil, fmt.Errorf("get state: %w", err)
+	if err := uc.statePort.PushForward(ctx, req.SessionID, captureView(state, state.View)); err != nil {
+		return nil, fmt.Errorf("push forward: %w", err)
 	}
 
-	// 3. Rebuild formation from state data using grid preset
-	formation := uc.rebuildFormationFromState(state)
+	// 3. Data zone: the data the previous view was shown on (similar replaces it)
+	dataInfo := domain.DeltaInfo{
+		TurnID:    req.TurnID,
+		Trigger:   domain.TriggerWidgetAction,
+		Source:    domain.SourceUser,
+		ActorID:   "user_back",
+		DeltaType: domain.DeltaTypeUpdate,
+		Path:      "data",
+	}
+	if err := restoreSnapshotData(ctx, uc.statePort, state, req.SessionID, snapshot, dataInfo); err != nil {
+		return nil, err
+	}
 
-	// 4. Zone-write: UpdateView (view zone -- restore previous)
-	stack, _ := uc.statePort.GetViewStack(ctx, req.SessionID)
+	// 4. Rebuild formation: detail of the focused item while it is in the data, else grid
 	restoredView := domain.ViewState{
 		Mode:    snapshot.Mode,
 		Focused: snapshot.Focused,
 	}
+	var formation *domain.FormationWithData
+	if snapshot.Focused != nil {
+		formation, err = detailFormation(uc.presetRegistry, state.Current.Data, *snapshot.Focused)
+	}
+	if formation == nil || err != nil {
+		formation = gridFormationFromState(uc.presetRegistry, state)
+		restoredView = domain.ViewState{Mode: domain.ViewModeGrid}
+	}
+
+	// 5. Zone-write: UpdateView (view zone -- restore previous)
+	stack, _ := uc.statePort.GetViewStack(ctx, req.SessionID)
 	viewInfo := domain.DeltaInfo{
 		TurnID:    req.TurnID,
 		Trigger:   domain.TriggerWidgetAction,
@@ -83,7 +107,7 @@ func (uc *BackUseCase) Execute(ctx context.Context, req BackRequest) (*BackRespo
 		return nil, fmt.Errorf("update view: %w", err)
 	}
 
-	// 5. Zone-write: UpdateTemplate (template zone)
+	// 6. Zone-write: UpdateTemplate (template zone)
 	template := map[string]interface{}{
 		"formation": formation,
 	}
@@ -100,41 +124,13 @@ func (uc *BackUseCase) Execute(ctx context.Context, req BackRequest) (*BackRespo
 	}
 
 	return &BackResponse{
-		Success:   true,
-		Formation: formation,
-		ViewMode:  restoredView.Mode,
-		Focused:   restoredView.Focused,
-		StackSize: len(stack),
-		CanGoBack: len(stack) > 0,
+		Success:      true,
+		Formation:    formation,
+		ViewMode:     restoredView.Mode,
+		Focused:      restoredView.Focused,
+		StackSize:    len(stack),
+		CanGoBack:    len(stack) > 0,
+		CanGoForward: true,
+		Breadcrumbs:  navigationBreadcrumbs(state, stack, restoredView),
 	}, nil
 }
-
-// rebuildFormationFromState rebuilds formation from current state data using grid preset
-func (uc *BackUseCase) rebuildFormationFromState(state *domain.SessionState) *domain.FormationWithData {
-	products := state.Current.Data.Products
-	services := state.Current.Data.Services
-
-	// If we have products, use product_grid preset
-	if len(products) > 0 {
-		preset, _ := uc.presetRegistry.Get(domain.PresetProductGrid)
-		return engine.BuildFormation(preset, len(products), func(i int) (engine.FieldGetter, engine.CurrencyGetter, engine.IDGetter) {
-			p := products[i]
-			return engine.ProductFieldGetter(p), func() string { return p.Currency }, func() string { return p.ID }
-		})
-	}
-
-	// If we have services, use service_card preset
-	if len(services) > 0 {
-		preset, _ := uc.presetRegistry.Get(domain.PresetServiceCard)
-		return engine.BuildFormation(preset, len(services), func(i int) (engine.FieldGetter, engine.CurrencyGetter, engine.IDGetter) {
-			s := services[i]
-			return engine.ServiceFieldGetter(s), func() string { return s.Currency }, func() string { return s.ID }
-		})
-	}
-
-	// Empty formation if no data
-	return &domain.FormationWithData{
-		Mode:    domain.FormationTypeGrid,
-		Widgets: []domain.Widget{},
-	}
-}
@@ -3,10 +3,8 @@ package usecases
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"keepstar/internal/domain"
-	"keepstar/internal/engine"
 	"keepstar/internal/ports"
 	"keepstar/internal/presets"
 )
@@ -21,11 +19,12 @@ type ExpandRequest struct {
 
 // ExpandResponse is the response from expand operation
 type ExpandResponse struct {
-	Success   bool
-	Formation *domain.FormationWithData
-	ViewMode  domain.ViewMode
-	Focused   *domain.EntityRef
-	StackSize int
+	Success     bool
+	Formation   *domain.FormationWithData
+	ViewMode    domain.ViewMode
+	Focused     *domain.EntityRef
+	StackSize   int
+	Breadcrumbs []domain.Breadcrumb
 }
 
 // ExpandUseCase handles expanding a widget to detail view
@@ -55,75 +54,26 @@ func (uc *ExpandUseCase) Execute(ctx context.Context, req ExpandRequest) (*Expan
 		return nil, fmt.Errorf("get state: %w", err)
 	}
 
-	// 2. Find entity by ID and get preset
-	var entity interface{}
-	var preset domain.Preset
-	var found bool
-
-	if req.EntityType == domain.EntityTypeProduct {
-		for _, p := range state.Current.Data.Products {
-			if p.ID == req.EntityID {
-				entity = p
-				break
-			}
-		}
-		preset, found = uc.presetRegistry.Get(domain.PresetProductDetail)
-	} else {
-		for _, s := range state.Current.Data.Services {
-			if s.ID == req.EntityID {
-				entity = s
-				break
-			}
-		}
-		preset, found = uc.presetRegistry.Get(domain.PresetServiceDetail)
-	}
-
-	if entity == nil {
-		return nil, fmt.Errorf("entity not found: %s", req.EntityID)
-	}
-	if !found {
-		return nil, fmt.Errorf("detail preset not found for entity type: %s", req.EntityType)
+	// 2. Build detail formation for the entity (fails if it is not in the data)
+	focused := domain.EntityRef{Type: req.EntityType, ID: req.EntityID}
+	formation, err := detailFormation(uc.presetRegistry, state.Current.Data, focused)
+	if err != nil {
+		return nil, err
 	}
 
-	// 3. Build refs from current data for snapshot
-	refs := buildEntityRefs(state.Current.Data)
-
-	// 4. Push current view to stack
-	snapshot := &domain.ViewSnapshot{
-		Mode:      state.View.Mode,
-		Focused:   state.View.Focused,
-		Refs:      refs,
-		Step:      state.Step,
-		CreatedAt: time.Now(),
-	}
-	if err := uc.statePort.PushView(ctx, req.SessionID, snapshot); err != nil {
+	// 3. Push current view to stack; a new navigation branch drops the forward stack
+	if err := uc.statePort.PushView(ctx, req.SessionID, captureView(state, state.View)); err != nil {
 		return nil, fmt.Errorf("push view: %w", err)
 	}
-
-	// 5. Build detail formation (with RenderConfig so Agent1 knows we're on detail view)
-	formation := uc.buildDetailFormation(preset, entity, req.EntityType)
-	// Build FieldSpecs from preset for Agent1 context (displayed_fields)
-	fieldSpecs := make([]domain.FieldSpec, 0, len(preset.Fields))
-	for _, f := range preset.Fields {
-		fieldSpecs = append(fieldSpecs, domain.FieldSpec{
-			Name:    f.Name,
-			Slot:    string(f.Slot),
-			Display: string(f.Display),
-		})
-	}
-	formation.Config = &domain.RenderConfig{
-		EntityType: string(req.EntityType),
-		Preset:     string(preset.Name),
-		Mode:       preset.DefaultMode,
-		Size:       preset.DefaultSize,
-		Fields:     fieldSpecs,
+	if err := uc.statePort.ClearForward(ctx, req.SessionID); err != nil {
+		return nil, fmt.Errorf("clear forward: %w", err)
 	}
 
-	// 6. Zone-write: UpdateView (view zone)
+	// 4. Zone-write: UpdateView (view zone)
 	stack, _ := uc.statePort.GetViewStack(ctx, req.SessionID)
 	newView := domain.ViewState{
 		Mode:    domain.ViewModeDetail,
-		Focused: &domain.EntityRef{Type: req.EntityType, ID: req.EntityID},
+		Focused: &focused,
 	}
 	viewInfo := domain.DeltaInfo{
 		TurnID:    req.TurnID,
@@ -137,7 +87,7 @@ func (uc *ExpandUseCase) Execute(ctx context.Context, req ExpandRequest) (*Expan
 		return nil, fmt.Errorf("update view: %w", err)
 	}
 
-	// 7. Zone-write: UpdateTemplate (template zone)
+	// 5. Zone-write: UpdateTemplate (template zone)
 	template := map[string]interface{}{
 		"formation": formation,
 	}
@@ -154,11 +104,12 @@ func (uc *ExpandUseCase) Execute(ctx context.Context, req ExpandRequest) (*Expan
 	}
 
 	return &ExpandResponse{
-		Success:   true,
-		Formation: formation,
-		ViewMode:  newView.Mode,
-		Focused:   newView.Focused,
-		StackSize: len(stack),
+		Success:     true,
+		Formation:   formation,
+		ViewMode:    newView.Mode,
+		Focused:     newView.Focused,
+		StackSize:   len(stack),
+		Breadcrumbs: navigationBreadcrumbs(state, stack, newView),
 	}, nil
 }
 
@@ -173,17 +124,3 @@ func buildEntityRefs(data domain.StateData) []domain.EntityRef {
 	}
 	return refs
 }
-
-// buildDetailFormation creates a formation for a single entity in detail view
-func (uc *ExpandUseCase) buildDetailFormation(preset domain.Preset, entity interface{}, entityType domain.EntityType) *domain.FormationWithData {
-	if entityType == domain.EntityTypeProduct {
-		p := entity.(domain.Product)
-		return engine.BuildFormation(preset, 1, func(i int) (engine.FieldGetter, engine.CurrencyGetter, engine.IDGetter) {
-			return engine.ProductFieldGetter(p), func() string { return p.Currency }, func() string { return p.ID }
-		})
-	}
-	s := entity.(domain.Service)
-	return engine.BuildFormation(preset, 1, func(i int) (engine.FieldGetter, engine.CurrencyGetter, engine.IDGetter) {
-		return engine.ServiceFieldGetter(s), func() string { return s.Currency }, func() string { return s.ID }
-	})
-}
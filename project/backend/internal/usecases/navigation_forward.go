@@ -0,0 +1,138 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+	"keepstar/internal/presets"
+)
+
+// ForwardRequest is the request for returning to the view left by back
+type ForwardRequest struct {
+	SessionID string
+	TurnID    string // Turn ID for delta grouping
+}
+
+// ForwardResponse is the response from forward operation
+type ForwardResponse struct {
+	Success      bool
+	Formation    *domain.FormationWithData
+	ViewMode     domain.ViewMode
+	Focused      *domain.EntityRef
+	StackSize    int
+	CanGoBack    bool
+	CanGoForward bool
+	Breadcrumbs  []domain.Breadcrumb
+}
+
+// ForwardUseCase handles going forward to the view left by back.
+// The current view goes back on the view stack, so back and forward stay symmetric.
+type ForwardUseCase struct {
+	statePort      ports.StatePort
+	presetRegistry *presets.PresetRegistry
+}
+
+// NewForwardUseCase creates a new ForwardUseCase
+func NewForwardUseCase(statePort ports.StatePort, presetRegistry *presets.PresetRegistry) *ForwardUseCase {
+	return &ForwardUseCase{
+		statePort:      statePort,
+		presetRegistry: presetRegistry,
+	}
+}
+
+// Execute goes forward to the next view
+func (uc *ForwardUseCase) Execute(ctx context.Context, req ForwardRequest) (*ForwardResponse, error) {
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("usecase.forward")
+		defer endSpan()
+	}
+
+	// 1. Get current state (the view we leave goes back on the view stack)
+	state, err := uc.statePort.GetState(ctx, req.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("get state: %w", err)
+	}
+
+	// 2. Pop from forward stack
+	snapshot, err := uc.statePort.PopForward(ctx, req.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("pop forward: %w", err)
+	}
+	if snapshot == nil {
+		return &ForwardResponse{Success: true, CanGoBack: len(state.ViewStack) > 0, CanGoForward: false}, nil
+	}
+	if err := uc.statePort.PushView(ctx, req.SessionID, captureView(state, state.View)); err != nil {
+		return nil, fmt.Errorf("push view: %w", err)
+	}
+
+	// 3. Data zone: the data the next view was shown on (back may have restored older data)
+	dataInfo := domain.DeltaInfo{
+		TurnID:    req.TurnID,
+		Trigger:   domain.TriggerWidgetAction,
+		Source:    domain.SourceUser,
+		ActorID:   "user_forward",
+		DeltaType: domain.DeltaTypeUpdate,
+		Path:      "data",
+	}
+	if err := restoreSnapshotData(ctx, uc.statePort, state, req.SessionID, snapshot, dataInfo); err != nil {
+		return nil, err
+	}
+
+	// 4. Rebuild formation: detail of the focused item while it is still in the data, else grid
+	restoredView := domain.ViewState{
+		Mode:    snapshot.Mode,
+		Focused: snapshot.Focused,
+	}
+	var formation *domain.FormationWithData
+	if snapshot.Focused != nil {
+		formation, err = detailFormation(uc.presetRegistry, state.Current.Data, *snapshot.Focused)
+	}
+	if formation == nil || err != nil {
+		formation = gridFormationFromState(uc.presetRegistry, state)
+		restoredView = domain.ViewState{Mode: domain.ViewModeGrid}
+	}
+
+	// 5. Zone-write: UpdateView (view zone -- restore next)
+	stack, _ := uc.statePort.GetViewStack(ctx, req.SessionID)
+	forward, _ := uc.statePort.GetForwardStack(ctx, req.SessionID)
+	viewInfo := domain.DeltaInfo{
+		TurnID:    req.TurnID,
+		Trigger:   domain.TriggerWidgetAction,
+		Source:    domain.SourceUser,
+		ActorID:   "user_forward",
+		DeltaType: domain.DeltaTypePush,
+		Path:      "view",
+	}
+	if _, err := uc.statePort.UpdateView(ctx, req.SessionID, restoredView, stack, viewInfo); err != nil {
+		return nil, fmt.Errorf("update view: %w", err)
+	}
+
+	// 6. Zone-write: UpdateTemplate (template zone)
+	template := map[string]interface{}{
+		"formation": formation,
+	}
+	templateInfo := domain.DeltaInfo{
+		TurnID:    req.TurnID,
+		Trigger:   domain.TriggerWidgetAction,
+		Source:    domain.SourceUser,
+		ActorID:   "user_forward",
+		DeltaType: domain.DeltaTypeUpdate,
+		Path:      "template",
+	}
+	if _, err := uc.statePort.UpdateTemplate(ctx, req.SessionID, template, templateInfo); err != nil {
+		return nil, fmt.Errorf("update template: %w", err)
+	}
+
+	return &ForwardResponse{
+		Success:      true,
+		Formation:    formation,
+		ViewMode:     restoredView.Mode,
+		Focused:      restoredView.Focused,
+		StackSize:    len(stack),
+		CanGoBack:    len(stack) > 0,
+		CanGoForward: len(forward) > 0,
+		Breadcrumbs:  navigationBreadcrumbs(state, stack, restoredView),
+	}, nil
+}
@@ -0,0 +1,189 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/engine"
+	"keepstar/internal/ports"
+	"keepstar/internal/presets"
+	"keepstar/internal/tools"
+)
+
+// SimilarRequest is the request for the "more like this" widget action
+type SimilarRequest struct {
+	SessionID      string
+	EntityType     domain.EntityType
+	EntityID       string
+	TurnID         string // Turn ID for delta grouping
+	Cheaper        bool
+	SameSkinType   bool
+	DifferentBrand bool
+}
+
+// SimilarResponse is the response from similar operation
+type SimilarResponse struct {
+	Success     bool
+	Found       int    // similar products written to state; 0 = data and view unchanged
+	Message     string // tool result ("ok: ...", "empty: ...")
+	Formation   *domain.FormationWithData
+	ViewMode    domain.ViewMode
+	StackSize   int
+	Breadcrumbs []domain.Breadcrumb
+}
+
+// SimilarUseCase handles the "more like this" widget action: catalog_similar from an
+// EntityRef, then a product grid of the results. The previous view goes on the stack.
+type SimilarUseCase struct {
+	statePort      ports.StatePort
+	toolRegistry   *tools.Registry
+	presetRegistry *presets.PresetRegistry
+}
+
+// NewSimilarUseCase creates a new SimilarUseCase
+func NewSimilarUseCase(statePort ports.StatePort, toolRegistry *tools.Registry, presetRegistry *presets.PresetRegistry) *SimilarUseCase {
+	return &SimilarUseCase{
+		statePort:      statePort,
+		toolRegistry:   toolRegistry,
+		presetRegistry: presetRegistry,
+	}
+}
+
+// Execute finds products similar to the entity and shows them as a grid
+func (uc *SimilarUseCase) Execute(ctx context.Context, req SimilarRequest) (*SimilarResponse, error) {
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("usecase.similar")
+		defer endSpan()
+	}
+
+	if req.EntityType != domain.EntityTypeProduct {
+		return nil, fmt.Errorf("similar is supported for products only, got %s: %w", req.EntityType, domain.ErrInvalidEntityType)
+	}
+
+	// 1. Capture the current view before the tool replaces the data
+	state, err := uc.statePort.GetState(ctx, req.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("get state: %w", err)
+	}
+	snapshot := captureView(state, state.View)
+
+	// 2. Data zone: catalog_similar writes the results as a regular data delta
+	input := map[string]interface{}{"product_id": req.EntityID}
+	if req.Cheaper {
+		input["cheaper"] = true
+	}
+	if req.SameSkinType {
+		input["same_skin_type"] = true
+	}
+	if req.DifferentBrand {
+		input["different_brand"] = true
+	}
+	result, err := uc.toolRegistry.Execute(ctx, tools.ToolContext{
+		SessionID: req.SessionID,
+		TurnID:    req.TurnID,
+		ActorID:   "user_similar",
+		Trigger:   domain.TriggerWidgetAction,
+	}, domain.ToolCall{Name: "catalog_similar", Input: input})
+	if err != nil {
+		return nil, fmt.Errorf("catalog_similar: %w", err)
+	}
+	if result.IsError {
+		if missing, _ := result.Metadata["source_missing"].(bool); missing {
+			return nil, fmt.Errorf("catalog_similar: %s: %w", result.Content, domain.ErrProductNotFound)
+		}
+		return nil, fmt.Errorf("catalog_similar: %s: %w", result.Content, domain.ErrSimilarFailed)
+	}
+	// merged_count is how many products the tool wrote to data.products
+	count, _ := result.Metadata["merged_count"].(int)
+	if count == 0 {
+		// Nothing similar: keep data, view and template as they are
+		return &SimilarResponse{Success: true, Message: result.Content, ViewMode: state.View.Mode, StackSize: len(state.ViewStack)}, nil
+	}
+
+	state, err = uc.statePort.GetState(ctx, req.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("get state: %w", err)
+	}
+	preset, found := uc.presetRegistry.Get(domain.PresetProductGrid)
+	if !found {
+		return nil, fmt.Errorf("preset not found: %s", domain.PresetProductGrid)
+	}
+
+	// 3. Push the previous view, switch to grid; a new navigation branch drops the forward stack
+	if err := uc.statePort.PushView(ctx, req.SessionID, snapshot); err != nil {
+		return nil, fmt.Errorf("push view: %w", err)
+	}
+	if err := uc.statePort.ClearForward(ctx, req.SessionID); err != nil {
+		return nil, fmt.Errorf("clear forward: %w", err)
+	}
+	stack, _ := uc.statePort.GetViewStack(ctx, req.SessionID)
+	newView := domain.ViewState{Mode: domain.ViewModeGrid}
+	viewInfo := domain.DeltaInfo{
+		TurnID:    req.TurnID,
+		Trigger:   domain.TriggerWidgetAction,
+		Source:    domain.SourceUser,
+		ActorID:   "user_similar",
+		DeltaType: domain.DeltaTypePush,
+		Path:      "view",
+	}
+	if _, err := uc.statePort.UpdateView(ctx, req.SessionID, newView, stack, viewInfo); err != nil {
+		return nil, fmt.Errorf("update view: %w", err)
+	}
+
+	// 4. Template zone: product grid of the results
+	formation := buildSimilarFormation(preset, state)
+	template := map[string]interface{}{
+		"formation": formation,
+	}
+	templateInfo := domain.DeltaInfo{
+		TurnID:    req.TurnID,
+		Trigger:   domain.TriggerWidgetAction,
+		Source:    domain.SourceUser,
+		ActorID:   "user_similar",
+		DeltaType: domain.DeltaTypeUpdate,
+		Path:      "template",
+	}
+	if _, err := uc.statePort.UpdateTemplate(ctx, req.SessionID, template, templateInfo); err != nil {
+		return nil, fmt.Errorf("update template: %w", err)
+	}
+
+	return &SimilarResponse{
+		Success:     true,
+		Found:       count,
+		Message:     result.Content,
+		Formation:   formation,
+		ViewMode:    newView.Mode,
+		StackSize:   len(stack),
+		Breadcrumbs: navigationBreadcrumbs(state, stack, newView),
+	}, nil
+}
+
+// buildSimilarFormation renders state products with the grid preset (with RenderConfig so Agent1 knows what is shown)
+func buildSimilarFormation(preset domain.Preset, state *domain.SessionState) *domain.FormationWithData {
+	products := state.Current.Data.Products
+	formation := engine.BuildFormation(preset, len(products), func(i int) (engine.FieldGetter, engine.CurrencyGetter, engine.IDGetter) {
+		p := products[i]
+		return engine.ProductFieldGetter(p), func() string { return p.Currency }, func() string { return p.ID }
+	})
+	if stock := state.Current.Meta.Stock; stock != nil {
+		engine.ApplyStockStyling(formation.Widgets, products, stock.LowThreshold)
+	}
+
+	fieldSpecs := make([]domain.FieldSpec, 0, len(preset.Fields))
+	for _, f := range preset.Fields {
+		fieldSpecs = append(fieldSpecs, domain.FieldSpec{
+			Name:    f.Name,
+			Slot:    string(f.Slot),
+			Display: string(f.Display),
+		})
+	}
+	formation.Config = &domain.RenderConfig{
+		EntityType: string(domain.EntityTypeProduct),
+		Preset:     string(preset.Name),
+		Mode:       preset.DefaultMode,
+		Size:       preset.DefaultSize,
+		Fields:     fieldSpecs,
+	}
+	return formation
+}
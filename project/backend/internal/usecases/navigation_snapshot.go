@@ -0,0 +1,173 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/engine"
+	"keepstar/internal/ports"
+	"keepstar/internal/presets"
+)
+
+// captureView snapshots a view of the session for the back/forward stacks,
+// labelled for breadcrumbs with the shopper query and the focused item name
+func captureView(state *domain.SessionState, view domain.ViewState) *domain.ViewSnapshot {
+	return &domain.ViewSnapshot{
+		Mode:      view.Mode,
+		Focused:   view.Focused,
+		Refs:      buildEntityRefs(state.Current.Data),
+		Query:     lastUserQuery(state.ConversationHistory),
+		Title:     entityName(state.Current.Data, view.Focused),
+		Step:      state.Step,
+		CreatedAt: time.Now(),
+	}
+}
+
+// restoreSnapshotData brings back the data a snapshot was shown on when a later write replaced it
+// (catalog_similar swaps the products under the view it pushed). The data zone is replayed from the
+// deltas up to the snapshot step and written as a data delta of this turn; state is updated in place.
+// Sessions whose deltas cannot give that data back (legacy, no payload) keep the data they have.
+func restoreSnapshotData(ctx context.Context, statePort ports.StatePort, state *domain.SessionState, sessionID string, snapshot *domain.ViewSnapshot, info domain.DeltaInfo) error {
+	if slices.Equal(buildEntityRefs(state.Current.Data), snapshot.Refs) {
+		return nil
+	}
+	deltas, err := statePort.GetDeltasUntil(ctx, sessionID, snapshot.Step)
+	if err != nil {
+		return fmt.Errorf("get deltas until step %d: %w", snapshot.Step, err)
+	}
+	restored := replayDeltas(sessionID, deltas)
+	if !slices.Equal(buildEntityRefs(restored.Current.Data), snapshot.Refs) {
+		return nil
+	}
+	if _, err := statePort.UpdateData(ctx, sessionID, restored.Current.Data, restored.Current.Meta, info); err != nil {
+		return fmt.Errorf("update data: %w", err)
+	}
+	state.Current.Data = restored.Current.Data
+	state.Current.Meta = restored.Current.Meta
+	return nil
+}
+
+// navigationBreadcrumbs builds the trail from the back stack to the view shown now
+func navigationBreadcrumbs(state *domain.SessionState, stack []domain.ViewSnapshot, view domain.ViewState) []domain.Breadcrumb {
+	return domain.Breadcrumbs(stack, *captureView(state, view))
+}
+
+// lastUserQuery returns the latest shopper query (skips tool results and enriched prompts)
+func lastUserQuery(history []domain.LLMMessage) string {
+	for i := len(history) - 1; i >= 0; i-- {
+		msg := history[i]
+		if msg.Role == "user" && msg.Content != "" && msg.ToolResult == nil {
+			return msg.Content
+		}
+	}
+	return ""
+}
+
+// entityName returns the name of the referenced entity in the data ("" if not there)
+func entityName(data domain.StateData, ref *domain.EntityRef) string {
+	if ref == nil {
+		return ""
+	}
+	if ref.Type == domain.EntityTypeService {
+		for _, s := range data.Services {
+			if s.ID == ref.ID {
+				return s.Name
+			}
+		}
+		return ""
+	}
+	for _, p := range data.Products {
+		if p.ID == ref.ID {
+			return p.Name
+		}
+	}
+	return ""
+}
+
+// detailFormation builds the detail formation of one entity from state data
+// (with RenderConfig so Agent1 knows we're on detail view)
+func detailFormation(presetRegistry *presets.PresetRegistry, data domain.StateData, ref domain.EntityRef) (*domain.FormationWithData, error) {
+	var formation *domain.FormationWithData
+	var preset domain.Preset
+	var found bool
+
+	if ref.Type == domain.EntityTypeProduct {
+		preset, found = presetRegistry.Get(domain.PresetProductDetail)
+		for _, p := range data.Products {
+			if p.ID == ref.ID {
+				formation = engine.BuildFormation(preset, 1, func(i int) (engine.FieldGetter, engine.CurrencyGetter, engine.IDGetter) {
+					return engine.ProductFieldGetter(p), func() string { return p.Currency }, func() string { return p.ID }
+				})
+				break
+			}
+		}
+	} else {
+		preset, found = presetRegistry.Get(domain.PresetServiceDetail)
+		for _, s := range data.Services {
+			if s.ID == ref.ID {
+				formation = engine.BuildFormation(preset, 1, func(i int) (engine.FieldGetter, engine.CurrencyGetter, engine.IDGetter) {
+					return engine.ServiceFieldGetter(s), func() string { return s.Currency }, func() string { return s.ID }
+				})
+				break
+			}
+		}
+	}
+
+	if formation == nil {
+		return nil, fmt.Errorf("entity not found: %s", ref.ID)
+	}
+	if !found {
+		return nil, fmt.Errorf("detail preset not found for entity type: %s", ref.Type)
+	}
+
+	// Build FieldSpecs from preset for Agent1 context (displayed_fields)
+	fieldSpecs := make([]domain.FieldSpec, 0, len(preset.Fields))
+	for _, f := range preset.Fields {
+		fieldSpecs = append(fieldSpecs, domain.FieldSpec{
+			Name:    f.Name,
+			Slot:    string(f.Slot),
+			Display: string(f.Display),
+		})
+	}
+	formation.Config = &domain.RenderConfig{
+		EntityType: string(ref.Type),
+		Preset:     string(preset.Name),
+		Mode:       preset.DefaultMode,
+		Size:       preset.DefaultSize,
+		Fields:     fieldSpecs,
+	}
+	return formation, nil
+}
+
+// gridFormationFromState rebuilds formation from current state data using grid preset
+func gridFormationFromState(presetRegistry *presets.PresetRegistry, state *domain.SessionState) *domain.FormationWithData {
+	products := state.Current.Data.Products
+	services := state.Current.Data.Services
+
+	// If we have products, use product_grid preset
+	if len(products) > 0 {
+		preset, _ := presetRegistry.Get(domain.PresetProductGrid)
+		return engine.BuildFormation(preset, len(products), func(i int) (engine.FieldGetter, engine.CurrencyGetter, engine.IDGetter) {
+			p := products[i]
+			return engine.ProductFieldGetter(p), func() string { return p.Currency }, func() string { return p.ID }
+		})
+	}
+
+	// If we have services, use service_card preset
+	if len(services) > 0 {
+		preset, _ := presetRegistry.Get(domain.PresetServiceCard)
+		return engine.BuildFormation(preset, len(services), func(i int) (engine.FieldGetter, engine.CurrencyGetter, engine.IDGetter) {
+			s := services[i]
+			return engine.ServiceFieldGetter(s), func() string { return s.Currency }, func() string { return s.ID }
+		})
+	}
+
+	// Empty formation if no data
+	return &domain.FormationWithData{
+		Mode:    domain.FormationTypeGrid,
+		Widgets: []domain.Widget{},
+	}
+}
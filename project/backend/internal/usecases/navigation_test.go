@@ -2,19 +2,26 @@ package usecases_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"keepstar/internal/domain"
+	"keepstar/internal/logger"
+	"keepstar/internal/ports"
 	"keepstar/internal/presets"
+	"keepstar/internal/testutil"
+	"keepstar/internal/tools"
 	"keepstar/internal/usecases"
 )
 
 // mockStatePort implements ports.StatePort for testing without database
 type mockStatePort struct {
-	state     *domain.SessionState
-	deltas    []domain.Delta
-	viewStack []domain.ViewSnapshot
+	state        *domain.SessionState
+	deltas       []domain.Delta
+	viewStack    []domain.ViewSnapshot
+	forwardStack []domain.ViewSnapshot
+	forks        map[string]*mockFork // forkID → fork
 	// Call tracking for zone-write assertions
 	UpdateDataCalls         int
 	UpdateTemplateCalls     int
@@ -69,11 +76,12 @@ func (m *mockStatePort) UpdateData(ctx context.Context, sessionID string, data d
 		m.state.Current.Data = data
 		m.state.Current.Meta = meta
 	}
+	if info.Trigger == domain.TriggerUserQuery {
+		m.ClearForward(ctx, sessionID)
+	}
 	delta := info.ToDelta()
-	step := len(m.deltas) + 1
-	delta.Step = step
-	m.deltas = append(m.deltas, *delta)
-	return step, nil
+	delta.Payload = &domain.DeltaPayload{Data: &data, Meta: &meta}
+	return m.appendDelta(delta), nil
 }
 
 func (m *mockStatePort) UpdateTemplate(ctx context.Context, sessionID string, template map[string]interface{}, info domain.DeltaInfo) (int, error) {
@@ -82,10 +90,8 @@ func (m *mockStatePort) UpdateTemplate(ctx context.Context, sessionID string, te
 		m.state.Current.Template = template
 	}
 	delta := info.ToDelta()
-	step := len(m.deltas) + 1
-	delta.Step = step
-	m.deltas = append(m.deltas, *delta)
-	return step, nil
+	delta.Template = template
+	return m.appendDelta(delta), nil
 }
 
 func (m *mockStatePort) UpdateView(ctx context.Context, sessionID string, view domain.ViewState, stack []domain.ViewSnapshot, info domain.DeltaInfo) (int, error) {
@@ -95,10 +101,12 @@ func (m *mockStatePort) UpdateView(ctx context.Context, sessionID string, view d
 		m.state.ViewStack = stack
 	}
 	delta := info.ToDelta()
-	step := len(m.deltas) + 1
-	delta.Step = step
-	m.deltas = append(m.deltas, *delta)
-	return step, nil
+	delta.Payload = &domain.DeltaPayload{
+		View:         &view,
+		ViewStack:    append([]domain.ViewSnapshot{}, stack...),
+		ForwardStack: append([]domain.ViewSnapshot{}, m.forwardStack...),
+	}
+	return m.appendDelta(delta), nil
 }
 
 func (m *mockStatePort) AppendConversation(ctx context.Context, sessionID string, messages []domain.LLMMessage) error {
@@ -111,10 +119,17 @@ func (m *mockStatePort) AppendConversation(ctx context.Context, sessionID string
 }
 
 func (m *mockStatePort) AddDelta(ctx context.Context, sessionID string, delta *domain.Delta) (int, error) {
-	step := len(m.deltas) + 1
-	delta.Step = step
+	return m.appendDelta(delta), nil
+}
+
+// appendDelta assigns the next step and moves state.step to it, as the adapter does
+func (m *mockStatePort) appendDelta(delta *domain.Delta) int {
+	delta.Step = len(m.deltas) + 1
 	m.deltas = append(m.deltas, *delta)
-	return step, nil
+	if m.state != nil {
+		m.state.Step = delta.Step
+	}
+	return delta.Step
 }
 
 func (m *mockStatePort) GetDeltas(ctx context.Context, sessionID string) ([]domain.Delta, error) {
@@ -165,6 +180,84 @@ func (m *mockStatePort) GetViewStack(ctx context.Context, sessionID string) ([]d
 	return m.viewStack, nil
 }
 
+func (m *mockStatePort) PushForward(ctx context.Context, sessionID string, snapshot *domain.ViewSnapshot) error {
+	m.forwardStack = append(m.forwardStack, *snapshot)
+	if m.state != nil {
+		m.state.ForwardStack = m.forwardStack
+	}
+	return nil
+}
+
+func (m *mockStatePort) PopForward(ctx context.Context, sessionID string) (*domain.ViewSnapshot, error) {
+	if len(m.forwardStack) == 0 {
+		return nil, nil
+	}
+	last := m.forwardStack[len(m.forwardStack)-1]
+	m.forwardStack = m.forwardStack[:len(m.forwardStack)-1]
+	if m.state != nil {
+		m.state.ForwardStack = m.forwardStack
+	}
+	return &last, nil
+}
+
+func (m *mockStatePort) ClearForward(ctx context.Context, sessionID string) error {
+	m.forwardStack = nil
+	if m.state != nil {
+		m.state.ForwardStack = nil
+	}
+	return nil
+}
+
+func (m *mockStatePort) GetForwardStack(ctx context.Context, sessionID string) ([]domain.ViewSnapshot, error) {
+	return m.forwardStack, nil
+}
+
+// mockFork is a session created by ForkState
+type mockFork struct {
+	parentID string
+	toStep   int
+	state    *domain.SessionState
+	deltas   []domain.Delta
+}
+
+func (m *mockStatePort) ForkState(ctx context.Context, parentID, forkID string, toStep int, state *domain.SessionState) error {
+	if m.state == nil || m.state.SessionID != parentID {
+		return domain.ErrSessionNotFound
+	}
+	if m.forks == nil {
+		m.forks = make(map[string]*mockFork)
+	}
+	fork := &mockFork{parentID: parentID, toStep: toStep, state: state}
+	for _, d := range m.deltas {
+		if d.Step <= toStep {
+			fork.deltas = append(fork.deltas, d)
+		}
+	}
+	m.forks[forkID] = fork
+	return nil
+}
+
+func (m *mockStatePort) GetBranches(ctx context.Context, sessionID string) ([]domain.SessionBranch, error) {
+	if m.state == nil {
+		return nil, domain.ErrSessionNotFound
+	}
+	branches := []domain.SessionBranch{{SessionID: m.state.SessionID, Step: m.state.Step, Status: domain.SessionStatusActive}}
+	for id, f := range m.forks {
+		branches = append(branches, domain.SessionBranch{SessionID: id, ParentID: f.parentID, ForkStep: f.toStep, Step: f.state.Step, Status: domain.SessionStatusActive})
+	}
+	return branches, nil
+}
+
+func (m *mockStatePort) GetBranchTrees(ctx context.Context, sessionIDs []string) ([]domain.SessionBranch, error) {
+	// The mock holds a single tree: the session and its forks
+	for _, id := range sessionIDs {
+		if _, isFork := m.forks[id]; isFork || (m.state != nil && m.state.SessionID == id) {
+			return m.GetBranches(ctx, id)
+		}
+	}
+	return nil, nil
+}
+
 // =============================================================================
 // Test: ExpandUseCase
 // =============================================================================
@@ -602,3 +695,318 @@ func TestExpandUseCase_Service(t *testing.T) {
 
 	t.Logf("Service expand successful: template=%s", resp.Formation.Widgets[0].Template)
 }
+
+// =============================================================================
+// Test: SimilarUseCase
+// =============================================================================
+
+// similarCatalogMock serves only what catalog_similar calls; other CatalogPort methods panic via the nil interface
+type similarCatalogMock struct {
+	ports.CatalogPort
+	similar []domain.Product
+	missing bool // source product is not in the catalog
+}
+
+func (m *similarCatalogMock) GetTenantBySlug(ctx context.Context, slug string) (*domain.Tenant, error) {
+	return &domain.Tenant{ID: "t1", Slug: slug}, nil
+}
+
+func (m *similarCatalogMock) GetProduct(ctx context.Context, tenantID, productID string) (*domain.Product, error) {
+	if m.missing {
+		return nil, domain.ErrProductNotFound
+	}
+	return &domain.Product{ID: productID, Name: "Source", Price: 100000}, nil
+}
+
+func (m *similarCatalogMock) GetProductEmbedding(ctx context.Context, tenantID, productID string) ([]float32, error) {
+	return []float32{0.1, 0.2}, nil
+}
+
+func (m *similarCatalogMock) VectorSearch(ctx context.Context, tenantID string, embedding []float32, limit int, filter *ports.VectorFilter) ([]domain.Product, error) {
+	return m.similar, nil
+}
+
+func TestNavigationFlow_SimilarAndBack(t *testing.T) {
+	ctx := context.Background()
+	statePort := newMockStatePort()
+	presetRegistry := presets.NewPresetRegistry()
+
+	statePort.CreateState(ctx, "session-1")
+	// The source was found by a search: its data is in the delta log, so Back can restore it
+	statePort.UpdateData(ctx, "session-1", domain.StateData{Products: []domain.Product{
+		{ID: "product-1", Name: "Source", Price: 100000},
+		{ID: "product-9", Name: "Other result", Price: 50000},
+	}}, domain.StateMeta{Count: 2}, domain.DeltaInfo{TurnID: "turn-search", Trigger: domain.TriggerUserQuery, DeltaType: domain.DeltaTypeAdd, Path: "data.products"})
+	statePort.UpdateDataCalls = 0
+	statePort.state.View = domain.ViewState{
+		Mode:    domain.ViewModeDetail,
+		Focused: &domain.EntityRef{Type: domain.EntityTypeProduct, ID: "product-1"},
+	}
+	seedDeltas := len(statePort.deltas)
+
+	catalog := &similarCatalogMock{similar: []domain.Product{
+		{ID: "product-1", Name: "Source", Price: 100000},
+		{ID: "product-2", Name: "Alike", Price: 90000},
+		{ID: "product-3", Name: "Alike too", Price: 80000},
+	}}
+	registry := tools.NewRegistry(statePort, catalog, presetRegistry, nil)
+	similarUC := usecases.NewSimilarUseCase(statePort, registry, presetRegistry)
+
+	resp, err := similarUC.Execute(ctx, usecases.SimilarRequest{
+		SessionID:  "session-1",
+		EntityType: domain.EntityTypeProduct,
+		EntityID:   "product-1",
+		TurnID:     "turn-similar",
+		Cheaper:    true,
+	})
+	if err != nil {
+		t.Fatalf("Similar failed: %v", err)
+	}
+
+	if resp.Found != 2 || len(resp.Formation.Widgets) != 2 {
+		t.Fatalf("Expected 2 similar products in grid, got found=%d widgets=%d", resp.Found, len(resp.Formation.Widgets))
+	}
+	if resp.ViewMode != domain.ViewModeGrid || resp.StackSize != 1 {
+		t.Errorf("Expected grid view with 1 snapshot, got %s / %d", resp.ViewMode, resp.StackSize)
+	}
+	if statePort.UpdateDataCalls != 1 {
+		t.Errorf("Expected results written as one data delta, got %d UpdateData calls", statePort.UpdateDataCalls)
+	}
+	for _, d := range statePort.deltas[seedDeltas:] {
+		if d.TurnID != "turn-similar" || d.Trigger != domain.TriggerWidgetAction || d.Source != domain.SourceUser {
+			t.Errorf("Expected widget-action deltas of one turn, got %s %s/%s/%s", d.Path, d.TurnID, d.Trigger, d.Source)
+		}
+	}
+
+	// Back returns to the detail view of the source product
+	backUC := usecases.NewBackUseCase(statePort, presetRegistry)
+	backResp, err := backUC.Execute(ctx, usecases.BackRequest{SessionID: "session-1", TurnID: "turn-back"})
+	if err != nil {
+		t.Fatalf("Back failed: %v", err)
+	}
+	if backResp.ViewMode != domain.ViewModeDetail || backResp.Focused == nil || backResp.Focused.ID != "product-1" {
+		t.Errorf("Expected back to detail of product-1, got %s %+v", backResp.ViewMode, backResp.Focused)
+	}
+	if backResp.Formation == nil || len(backResp.Formation.Widgets) != 1 || backResp.Formation.Widgets[0].Template != "ProductDetail" {
+		t.Fatalf("Expected the ProductDetail widget of the source, got %+v", backResp.Formation)
+	}
+	if id := backResp.Formation.Widgets[0].EntityRef; id == nil || id.ID != "product-1" {
+		t.Errorf("Expected the detail widget of product-1, got %+v", id)
+	}
+	if products := statePort.state.Current.Data.Products; len(products) != 2 || products[1].ID != "product-9" {
+		t.Errorf("Expected the search results back in state data, got %+v", products)
+	}
+
+	// Forward returns to the similar grid with its data
+	forwardUC := usecases.NewForwardUseCase(statePort, presetRegistry)
+	fwdResp, err := forwardUC.Execute(ctx, usecases.ForwardRequest{SessionID: "session-1", TurnID: "turn-forward"})
+	if err != nil {
+		t.Fatalf("Forward failed: %v", err)
+	}
+	if fwdResp.ViewMode != domain.ViewModeGrid || fwdResp.Formation == nil || len(fwdResp.Formation.Widgets) != 2 {
+		t.Errorf("Expected the grid of 2 similar products, got %s %+v", fwdResp.ViewMode, fwdResp.Formation)
+	}
+	if products := statePort.state.Current.Data.Products; len(products) != 2 || products[0].ID != "product-2" {
+		t.Errorf("Expected the similar results back in state data, got %+v", products)
+	}
+}
+
+func TestNavigationFlow_SimilarNothingFound(t *testing.T) {
+	ctx := context.Background()
+	statePort := newMockStatePort()
+	presetRegistry := presets.NewPresetRegistry()
+
+	statePort.CreateState(ctx, "session-1")
+	statePort.state.Current.Data.Products = []domain.Product{{ID: "product-1", Name: "Source", Price: 100000}}
+	statePort.state.View = domain.ViewState{
+		Mode:    domain.ViewModeDetail,
+		Focused: &domain.EntityRef{Type: domain.EntityTypeProduct, ID: "product-1"},
+	}
+
+	// Only the source itself comes back: nothing similar
+	catalog := &similarCatalogMock{similar: []domain.Product{{ID: "product-1", Name: "Source", Price: 100000}}}
+	registry := tools.NewRegistry(statePort, catalog, presetRegistry, nil)
+	similarUC := usecases.NewSimilarUseCase(statePort, registry, presetRegistry)
+
+	resp, err := similarUC.Execute(ctx, usecases.SimilarRequest{
+		SessionID:  "session-1",
+		EntityType: domain.EntityTypeProduct,
+		EntityID:   "product-1",
+		TurnID:     "turn-similar",
+	})
+	if err != nil {
+		t.Fatalf("Similar failed: %v", err)
+	}
+	if !resp.Success || resp.Found != 0 || resp.Formation != nil {
+		t.Errorf("Expected success without results or formation, got %+v", resp)
+	}
+	if resp.ViewMode != domain.ViewModeDetail || len(statePort.state.ViewStack) != 0 {
+		t.Errorf("Expected the detail view kept without a snapshot, got %s / %d", resp.ViewMode, len(statePort.state.ViewStack))
+	}
+	if statePort.UpdateDataCalls != 0 || len(statePort.state.Current.Data.Products) != 1 {
+		t.Errorf("Expected data untouched, got %d UpdateData calls", statePort.UpdateDataCalls)
+	}
+}
+
+func TestNavigationFlow_SimilarTypedErrors(t *testing.T) {
+	ctx := context.Background()
+	statePort := newMockStatePort()
+	presetRegistry := presets.NewPresetRegistry()
+	statePort.CreateState(ctx, "session-1")
+
+	catalog := &similarCatalogMock{missing: true}
+	registry := tools.NewRegistry(statePort, catalog, presetRegistry, nil)
+	similarUC := usecases.NewSimilarUseCase(statePort, registry, presetRegistry)
+
+	_, err := similarUC.Execute(ctx, usecases.SimilarRequest{SessionID: "session-1", EntityType: domain.EntityTypeService, EntityID: "service-1"})
+	if !errors.Is(err, domain.ErrInvalidEntityType) {
+		t.Errorf("Expected ErrInvalidEntityType for a service, got %v", err)
+	}
+
+	_, err = similarUC.Execute(ctx, usecases.SimilarRequest{SessionID: "session-1", EntityType: domain.EntityTypeProduct, EntityID: "product-404"})
+	if !errors.Is(err, domain.ErrProductNotFound) {
+		t.Errorf("Expected ErrProductNotFound for an unknown source, got %v", err)
+	}
+}
+
+func TestNavigationFlow_ExpandBackForward(t *testing.T) {
+	ctx := context.Background()
+	statePort := newMockStatePort()
+	presetRegistry := presets.NewPresetRegistry()
+	seedGridSession(t, statePort)
+	statePort.state.ConversationHistory = []domain.LLMMessage{
+		{Role: "user", Content: "nike sneakers"},
+		{Role: "assistant", Content: "Found 2 products"},
+	}
+
+	expandUC := usecases.NewExpandUseCase(statePort, presetRegistry)
+	backUC := usecases.NewBackUseCase(statePort, presetRegistry)
+	forwardUC := usecases.NewForwardUseCase(statePort, presetRegistry)
+
+	expandResp, err := expandUC.Execute(ctx, usecases.ExpandRequest{
+		SessionID: "session-1", EntityType: domain.EntityTypeProduct, EntityID: "product-1", TurnID: "turn-expand",
+	})
+	if err != nil {
+		t.Fatalf("Expand failed: %v", err)
+	}
+	if crumbs := expandResp.Breadcrumbs; len(crumbs) != 2 || crumbs[0].Label != "nike sneakers" || crumbs[1].Label != "Nike Air Max 90" {
+		t.Errorf("Expected breadcrumbs query > product name, got %+v", crumbs)
+	}
+
+	backResp, err := backUC.Execute(ctx, usecases.BackRequest{SessionID: "session-1", TurnID: "turn-back"})
+	if err != nil {
+		t.Fatalf("Back failed: %v", err)
+	}
+	if !backResp.CanGoForward || len(statePort.forwardStack) != 1 || backResp.StackSize != 0 {
+		t.Errorf("Back should move the detail view to the forward stack, got %+v", backResp)
+	}
+
+	forwardResp, err := forwardUC.Execute(ctx, usecases.ForwardRequest{SessionID: "session-1", TurnID: "turn-forward"})
+	if err != nil {
+		t.Fatalf("Forward failed: %v", err)
+	}
+	if forwardResp.ViewMode != domain.ViewModeDetail || forwardResp.Focused == nil || forwardResp.Focused.ID != "product-1" {
+		t.Errorf("Expected forward to detail of product-1, got %s %+v", forwardResp.ViewMode, forwardResp.Focused)
+	}
+	if forwardResp.Formation == nil || len(forwardResp.Formation.Widgets) != 1 {
+		t.Errorf("Expected the detail formation, got %+v", forwardResp.Formation)
+	}
+	if forwardResp.CanGoForward || !forwardResp.CanGoBack || forwardResp.StackSize != 1 {
+		t.Errorf("Forward should move the grid back onto the view stack, got %+v", forwardResp)
+	}
+	if d := statePort.deltas[len(statePort.deltas)-2]; d.ActorID != "user_forward" || d.Payload == nil || len(d.Payload.ForwardStack) != 0 {
+		t.Errorf("Expected a user_forward view delta with the emptied forward stack, got %+v", d)
+	}
+
+	// Nothing left: forward is a no-op
+	emptyResp, err := forwardUC.Execute(ctx, usecases.ForwardRequest{SessionID: "session-1", TurnID: "turn-forward-2"})
+	if err != nil || emptyResp.CanGoForward || emptyResp.Formation != nil {
+		t.Errorf("Expected empty forward to change nothing, got %+v %v", emptyResp, err)
+	}
+}
+
+func TestNavigationFlow_NewExpandClearsForward(t *testing.T) {
+	ctx := context.Background()
+	statePort := newMockStatePort()
+	presetRegistry := presets.NewPresetRegistry()
+	seedGridSession(t, statePort)
+
+	expandUC := usecases.NewExpandUseCase(statePort, presetRegistry)
+	backUC := usecases.NewBackUseCase(statePort, presetRegistry)
+
+	for _, step := range []func() error{
+		func() error {
+			_, err := expandUC.Execute(ctx, usecases.ExpandRequest{SessionID: "session-1", EntityType: domain.EntityTypeProduct, EntityID: "product-1"})
+			return err
+		},
+		func() error {
+			_, err := backUC.Execute(ctx, usecases.BackRequest{SessionID: "session-1"})
+			return err
+		},
+		func() error {
+			_, err := expandUC.Execute(ctx, usecases.ExpandRequest{SessionID: "session-1", EntityType: domain.EntityTypeProduct, EntityID: "product-2"})
+			return err
+		},
+	} {
+		if err := step(); err != nil {
+			t.Fatalf("navigation failed: %v", err)
+		}
+	}
+
+	if len(statePort.forwardStack) != 0 || len(statePort.state.ForwardStack) != 0 {
+		t.Errorf("Expand after back should drop the forward stack, got %+v", statePort.forwardStack)
+	}
+	if len(statePort.viewStack) != 1 {
+		t.Errorf("Expected one view on the back stack, got %d", len(statePort.viewStack))
+	}
+}
+
+func TestNavigationFlow_BackQueryForward(t *testing.T) {
+	ctx := context.Background()
+	statePort := newMockStatePort()
+	presetRegistry := presets.NewPresetRegistry()
+	seedGridSession(t, statePort)
+
+	expandUC := usecases.NewExpandUseCase(statePort, presetRegistry)
+	backUC := usecases.NewBackUseCase(statePort, presetRegistry)
+	forwardUC := usecases.NewForwardUseCase(statePort, presetRegistry)
+
+	if _, err := expandUC.Execute(ctx, usecases.ExpandRequest{SessionID: "session-1", EntityType: domain.EntityTypeProduct, EntityID: "product-1"}); err != nil {
+		t.Fatalf("Expand failed: %v", err)
+	}
+	if _, err := backUC.Execute(ctx, usecases.BackRequest{SessionID: "session-1"}); err != nil {
+		t.Fatalf("Back failed: %v", err)
+	}
+	if len(statePort.forwardStack) != 1 {
+		t.Fatalf("Expected the detail view on the forward stack, got %d", len(statePort.forwardStack))
+	}
+
+	// New shopper query: Agent1 writes fresh data
+	llm := testutil.NewMockLLMClient(
+		toolUse(domain.ToolCall{ID: "f1", Name: "_internal_state_filter", Input: map[string]interface{}{"max_price": float64(10000)}}),
+		&domain.LLMResponse{Text: "готово", StopReason: "end_turn"},
+	)
+	registry := tools.NewRegistry(statePort, nil, presetRegistry, nil)
+	agent1 := usecases.NewAgent1ExecuteUseCase(llm, statePort, nil, registry, logger.New("error"))
+	if _, err := agent1.Execute(ctx, usecases.Agent1ExecuteRequest{SessionID: "session-1", Query: "а что-нибудь недорогое есть?", TurnID: "turn-query"}); err != nil {
+		t.Fatalf("Agent1 failed: %v", err)
+	}
+	if len(statePort.forwardStack) != 0 || len(statePort.state.ForwardStack) != 0 {
+		t.Errorf("A new query should drop the forward stack, got %+v", statePort.forwardStack)
+	}
+	replayed, err := usecases.NewReconstructStateUseCase(statePort).Execute(ctx, usecases.ReconstructRequest{
+		SessionID: "session-1", ToStep: statePort.state.Step,
+	})
+	if err != nil || len(replayed.State.ForwardStack) != 0 {
+		t.Errorf("Replay should drop the forward stack at the query's data write, got %+v %v", replayed, err)
+	}
+
+	// The old detail view is gone: forward changes nothing
+	resp, err := forwardUC.Execute(ctx, usecases.ForwardRequest{SessionID: "session-1", TurnID: "turn-forward"})
+	if err != nil {
+		t.Fatalf("Forward failed: %v", err)
+	}
+	if resp.CanGoForward || resp.Formation != nil || statePort.state.View.Mode == domain.ViewModeDetail {
+		t.Errorf("Expected forward after a new query to be a no-op, got %+v", resp)
+	}
+}
@@ -0,0 +1,55 @@
+package usecases_test
+
+import (
+	"context"
+	"testing"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/logger"
+	"keepstar/internal/presets"
+	"keepstar/internal/testutil"
+	"keepstar/internal/tools"
+	"keepstar/internal/usecases"
+)
+
+// TestPipeline_Cassette_BrandFollowUp replays testdata/cassettes/handwritten_filter_loaded_grid.json:
+// a brand follow-up on a loaded grid → Agent 1 filters state (2 LLM calls) → Agent 2 renders a grid.
+// The cassette is a hand-authored fixture, not recorded provider output: it pins the pipeline wiring
+// (request keys, tool loop, usage accounting), not how a real model answers. A changed prompt, tool set
+// or message sequence is a cassette miss; regenerate the keys or record a real scenario with LLM_CASSETTE=record.
+func TestPipeline_Cassette_BrandFollowUp(t *testing.T) {
+	llm := testutil.CassetteLLM(t, "handwritten_filter_loaded_grid")
+
+	statePort := newMockStatePort()
+	state, _ := statePort.CreateState(context.Background(), "session-cassette")
+	state.Current.Data.Products = testutil.SeedProducts(4)
+	state.Current.Meta = domain.StateMeta{Count: 4, ProductCount: 4, Fields: []string{"id", "name", "price", "brand"}}
+
+	log := logger.New("error")
+	registry := tools.NewRegistry(statePort, nil, presets.NewPresetRegistry(), nil)
+	pipeline := usecases.NewPipelineExecuteUseCase(llm, statePort, nil, nil, nil, registry, presets.NewPresetRegistry(), log)
+
+	resp, err := pipeline.Execute(context.Background(), usecases.PipelineExecuteRequest{
+		SessionID: "session-cassette",
+		Query:     "а что есть от Nike?",
+		TurnID:    "turn-1",
+	})
+	if err != nil {
+		t.Fatalf("pipeline: %v", err)
+	}
+
+	if resp.ToolCalled != "_internal_state_filter" {
+		t.Errorf("want Agent 1 to filter loaded data, got tool %q", resp.ToolCalled)
+	}
+	if resp.Formation == nil || resp.Formation.Mode != domain.FormationTypeGrid || len(resp.Formation.Widgets) != 1 {
+		t.Fatalf("want a grid with the one Nike product, got %+v", resp.Formation)
+	}
+	if got := resp.Agent1Usage.InputTokens + resp.Agent2Usage.InputTokens; got != 1850+1960+1400 {
+		t.Errorf("usage must come from the cassette responses, got %d input tokens", got)
+	}
+
+	state, _ = statePort.GetState(context.Background(), "session-cassette")
+	if len(state.Current.Data.Products) != 1 || state.Current.Data.Products[0].Brand != "Nike" {
+		t.Errorf("want state filtered to Nike, got %+v", state.Current.Data.Products)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"keepstar/internal/logger"
 	"keepstar/internal/ports"
 	"keepstar/internal/presets"
+	"keepstar/internal/prompts"
 	"keepstar/internal/tools"
 )
 
@@ -55,6 +56,9 @@ type PipelineExecuteResponse struct {
 	TemplateJSON     string
 	MetaCount        int
 	MetaFields       []string
+	// Response cache (empty match = pipeline ran)
+	CacheMatch      string
+	CacheSimilarity float64
 }
 
 // PipelineExecuteUseCase orchestrates Agent 1 → Agent 2 → Formation
@@ -64,6 +68,10 @@ type PipelineExecuteUseCase struct {
 	statePort      ports.StatePort
 	cachePort      ports.CachePort
 	tracePort      ports.TracePort
+	catalogPort    ports.CatalogPort
+	usagePort      ports.UsagePort // nil = quotas disabled
+	promptSelector *PromptSelector // nil = built-in prompts
+	responseCache  *ResponseCache  // nil = every query runs the agents
 	presetRegistry *presets.PresetRegistry
 	log            *logger.Logger
 }
@@ -86,11 +94,36 @@ func NewPipelineExecuteUseCase(
 		statePort:      statePort,
 		cachePort:      cachePort,
 		tracePort:      tracePort,
+		catalogPort:    catalogPort,
 		presetRegistry: presetRegistry,
 		log:            log,
 	}
 }
 
+// WithUsage enables per-tenant quota checks and usage accounting
+func (uc *PipelineExecuteUseCase) WithUsage(usagePort ports.UsagePort) *PipelineExecuteUseCase {
+	uc.usagePort = usagePort
+	return uc
+}
+
+// WithPrompts enables the prompt registry (versioned, per-tenant, A/B split by session)
+func (uc *PipelineExecuteUseCase) WithPrompts(selector *PromptSelector) *PipelineExecuteUseCase {
+	uc.promptSelector = selector
+	return uc
+}
+
+// WithResponseCache serves repeated tenant queries from stored results (see ResponseCache)
+func (uc *PipelineExecuteUseCase) WithResponseCache(cache *ResponseCache) *PipelineExecuteUseCase {
+	uc.responseCache = cache
+	return uc
+}
+
+// WithAgent1Limits caps the Agent 1 loop per turn (LLM calls, input+output tokens; 0 tokens = unlimited)
+func (uc *PipelineExecuteUseCase) WithAgent1Limits(maxSteps, maxTokens int) *PipelineExecuteUseCase {
+	uc.agent1UC.WithLoopLimits(maxSteps, maxTokens)
+	return uc
+}
+
 // Execute runs the full pipeline: query → Agent 1 → Agent 2 → Formation
 func (uc *PipelineExecuteUseCase) Execute(ctx context.Context, req PipelineExecuteRequest) (*PipelineExecuteResponse, error) {
 	start := time.Now()
@@ -98,14 +131,17 @@ func (uc *PipelineExecuteUseCase) Execute(ctx context.Context, req PipelineExecu
 	// Create SpanCollector for waterfall timeline
 	sc := domain.NewSpanCollector()
 	ctx = domain.WithSpanCollector(ctx, sc)
+	// Tenant is a model routing key (per-tenant model overrides)
+	ctx = domain.WithRouteHints(ctx, domain.LLMRouteHints{TenantSlug: req.TenantSlug})
 	endPipeline := sc.Start("pipeline")
 
 	// Prepare trace
 	trace := &domain.PipelineTrace{
 		ID:        uuid.New().String(),
-		SessionID: req.SessionID,
-		Query:     req.Query,
-		Timestamp: time.Now(),
+		SessionID:  req.SessionID,
+		TenantSlug: req.TenantSlug,
+		Query:      req.Query,
+		Timestamp:  time.Now(),
 	}
 
 	// Ensure session exists (required for FK constraint on state table)
@@ -132,12 +168,62 @@ func (uc *PipelineExecuteUseCase) Execute(ctx context.Context, req PipelineExecu
 	}
 	trace.TurnID = turnID
 
+	// Resolve prompt versions (stamped on the trace for version comparison, part of the cache key)
+	agent1Prompt := uc.promptSelector.Select(ctx, domain.PromptAgent1, req.TenantSlug, req.SessionID, prompts.Agent1SystemPrompt)
+	agent2Prompt := uc.promptSelector.Select(ctx, domain.PromptAgent2, req.TenantSlug, req.SessionID, prompts.Agent2ToolSystemPrompt)
+	promptVersions := map[string]int{
+		domain.PromptAgent1: agent1Prompt.Version,
+		domain.PromptAgent2: agent2Prompt.Version,
+	}
+	// Stamped before the cache and quota early returns: every turn counts for its version
+	trace.PromptVersions = promptVersions
+
+	// Response cache: a hit replays a stored result without any LLM call (so before the quota gate)
+	var cacheLookup *responseCacheLookup
+	if uc.responseCache != nil && req.TenantSlug != "" {
+		current, _ := uc.statePort.GetState(ctx, req.SessionID)
+		if stateKey, ok := domain.ResponseCacheStateKey(current, promptVersions); ok {
+			cacheLookup = uc.responseCache.lookup(ctx, req.TenantSlug, stateKey, req.Query)
+			trace.ResponseCache = cacheLookup.trace
+		}
+		if cacheLookup != nil && cacheLookup.hit != nil {
+			resp, err := uc.replayCachedResponse(ctx, req, turnID, cacheLookup.hit, trace)
+			if err == nil {
+				endPipeline()
+				trace.Spans = sc.Spans()
+				domain.EmitEvent(ctx, domain.PipelineEventSpans, trace.Spans)
+				trace.TotalMs = int(time.Since(start).Milliseconds())
+				resp.TotalMs = trace.TotalMs
+				uc.recordTrace(ctx, trace)
+				return resp, nil
+			}
+			// Broken entry or state write failure: run the agents as on a miss
+			uc.log.FromContext(ctx).Warn("response_cache_replay_failed", "entry", cacheLookup.hit.Entry.ID, "error", err)
+			cacheLookup.trace.Hit = false
+			cacheLookup.trace.Error = fmt.Sprintf("replay: %v", err)
+			cacheLookup.hit = nil
+		}
+	}
+
+	// Quota gate: refuse before spending any tokens
+	if qerr := uc.checkQuota(ctx, req.TenantSlug); qerr != nil {
+		uc.log.FromContext(ctx).Warn("tenant_quota_exceeded", "tenant", req.TenantSlug,
+			"period", qerr.Period, "metric", qerr.Metric, "limit", qerr.Limit, "used", qerr.Used)
+		trace.Error = qerr.Error()
+		endPipeline()
+		trace.Spans = sc.Spans()
+		trace.TotalMs = int(time.Since(start).Milliseconds())
+		uc.recordTrace(ctx, trace)
+		return nil, qerr
+	}
+
 	// Step 1: Agent 1 (Tool Caller)
 	agent1Resp, err := uc.agent1UC.Execute(ctx, Agent1ExecuteRequest{
-		SessionID:  req.SessionID,
-		Query:      req.Query,
-		TenantSlug: req.TenantSlug,
-		TurnID:     turnID,
+		SessionID:    req.SessionID,
+		Query:        req.Query,
+		TenantSlug:   req.TenantSlug,
+		TurnID:       turnID,
+		SystemPrompt: agent1Prompt.Content,
 	})
 	if err != nil {
 		trace.Error = fmt.Sprintf("agent1: %v", err)
@@ -170,6 +256,7 @@ func (uc *PipelineExecuteUseCase) Execute(ctx context.Context, req PipelineExecu
 		ToolInput:         agent1Resp.ToolInput,
 		ToolResult:        agent1Resp.ToolResult,
 		ToolBreakdown:     agent1Resp.ToolMetadata,
+		Steps:             agent1Resp.Steps,
 	}
 
 	// Snapshot state after Agent1
@@ -200,6 +287,19 @@ func (uc *PipelineExecuteUseCase) Execute(ctx context.Context, req PipelineExecu
 			snapshot.Deltas = append(snapshot.Deltas, dt)
 		}
 		trace.StateAfterAgent1 = snapshot
+
+		// Early skeleton for streaming clients: default layout filled from fresh data
+		if agent1Resp.ToolName != "" {
+			if skeleton := buildSkeletonFormation(midState); skeleton != nil {
+				domain.EmitEvent(ctx, domain.PipelineEventSkeleton, domain.SkeletonEventData{
+					Formation: skeleton,
+					Entities: &domain.StateData{
+						Products: midState.Current.Data.Products,
+						Services: midState.Current.Data.Services,
+					},
+				})
+			}
+		}
 	}
 
 	// Generate microcontext signal for Agent2
@@ -212,6 +312,7 @@ func (uc *PipelineExecuteUseCase) Execute(ctx context.Context, req PipelineExecu
 		UserQuery:     req.Query,
 		Microcontext:  microcontext,
 		ScreenContext: req.ScreenContext,
+		SystemPrompt:  agent2Prompt.Content,
 	})
 	if err != nil {
 		trace.Error = fmt.Sprintf("agent2: %v", err)
@@ -266,6 +367,11 @@ func (uc *PipelineExecuteUseCase) Execute(ctx context.Context, req PipelineExecu
 		}
 	}
 
+	// Refinement chips from the facet counts of the last catalog search
+	if formation != nil && formation.Mode != domain.FormationTypeSingle && len(state.Current.Data.Products) > 1 {
+		formation.FacetBar = engine.BuildFacetBar(state.Current.Meta.Facets)
+	}
+
 	// Build adjacent templates for instant expand (1 template per entity type + raw entities)
 	var adjacentTemplates map[string]*domain.FormationWithData
 	var entities *domain.StateData
@@ -273,31 +379,23 @@ func (uc *PipelineExecuteUseCase) Execute(ctx context.Context, req PipelineExecu
 		adjacentTemplates, entities = uc.buildAdjacentTemplates(state)
 	}
 
-	// Fill formation trace
-	if formation != nil {
-		ft := &domain.FormationTrace{
-			Mode:        string(formation.Mode),
-			WidgetCount: len(formation.Widgets),
-		}
-		if formation.Grid != nil {
-			ft.Cols = formation.Grid.Cols
-		}
-		if len(formation.Widgets) > 0 {
-			for _, atom := range formation.Widgets[0].Atoms {
-				if atom.Slot == domain.AtomSlotTitle {
-					if s, ok := atom.Value.(string); ok {
-						ft.FirstWidget = s
-					}
-					break
-				}
-			}
-		}
-		trace.FormationResult = ft
+	trace.FormationResult = buildFormationTrace(formation)
+
+	// Store fresh catalog answers for the next shopper asking the same
+	if cacheLookup != nil && responseCacheable(agent1Resp, state, formation) {
+		uc.responseCache.store(ctx, cacheLookup, state, formation, agent1Resp, agent1Resp.Usage.CostUSD+agent2Resp.Usage.CostUSD)
 	}
 
+	domain.EmitEvent(ctx, domain.PipelineEventFormation, domain.FormationEventData{
+		Formation:         formation,
+		AdjacentTemplates: adjacentTemplates,
+		Entities:          entities,
+	})
+
 	// Finalize and record trace
 	endPipeline()
 	trace.Spans = sc.Spans()
+	domain.EmitEvent(ctx, domain.PipelineEventSpans, trace.Spans)
 	trace.TotalMs = int(time.Since(start).Milliseconds())
 	trace.CostUSD = agent1Resp.Usage.CostUSD + agent2Resp.Usage.CostUSD
 	uc.recordTrace(ctx, trace)
@@ -326,8 +424,123 @@ func (uc *PipelineExecuteUseCase) Execute(ctx context.Context, req PipelineExecu
 	}, nil
 }
 
-// recordTrace saves trace if tracePort is available
+// replayCachedResponse writes a cached result into the session state (data, template,
+// conversation) the way catalog_search + render would, and builds the response from it
+func (uc *PipelineExecuteUseCase) replayCachedResponse(ctx context.Context, req PipelineExecuteRequest, turnID string, hit *domain.ResponseCacheHit, trace *domain.PipelineTrace) (*PipelineExecuteResponse, error) {
+	entry := hit.Entry
+	if entry.Formation == nil {
+		return nil, fmt.Errorf("entry %s has no formation", entry.ID)
+	}
+
+	if _, err := uc.statePort.GetState(ctx, req.SessionID); err == domain.ErrSessionNotFound {
+		if _, err := uc.statePort.CreateState(ctx, req.SessionID); err != nil {
+			return nil, fmt.Errorf("create state: %w", err)
+		}
+	}
+
+	meta := entry.Meta
+	if req.TenantSlug != "" {
+		aliases := make(map[string]string, len(meta.Aliases)+1)
+		for k, v := range meta.Aliases {
+			aliases[k] = v
+		}
+		aliases["tenant_slug"] = req.TenantSlug
+		meta.Aliases = aliases
+	}
+
+	dataInfo := domain.DeltaInfo{
+		TurnID:    turnID,
+		Trigger:   domain.TriggerUserQuery,
+		Source:    domain.SourceSystem,
+		ActorID:   "response_cache",
+		DeltaType: domain.DeltaTypeAdd,
+		Path:      "data.products",
+		Action: domain.Action{Type: domain.ActionSearch, Tool: entry.ToolName, Params: map[string]interface{}{
+			"cache_entry": entry.ID,
+			"match":       hit.Match,
+		}},
+		Result: domain.ResultMeta{Count: meta.Count, Fields: meta.Fields},
+	}
+	if _, err := uc.statePort.UpdateData(ctx, req.SessionID, entry.Data, meta, dataInfo); err != nil {
+		return nil, fmt.Errorf("update data: %w", err)
+	}
+
+	templateInfo := domain.DeltaInfo{
+		TurnID:    turnID,
+		Trigger:   domain.TriggerUserQuery,
+		Source:    domain.SourceSystem,
+		ActorID:   "response_cache",
+		DeltaType: domain.DeltaTypeUpdate,
+		Path:      "template",
+		Action:    domain.Action{Type: domain.ActionLayout, Tool: "response_cache"},
+	}
+	if _, err := uc.statePort.UpdateTemplate(ctx, req.SessionID, map[string]interface{}{"formation": entry.Formation}, templateInfo); err != nil {
+		return nil, fmt.Errorf("update template: %w", err)
+	}
+
+	state, err := uc.statePort.GetState(ctx, req.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("get state: %w", err)
+	}
+	// Same as the deterministic filter path: the query joins history, the LLM sees the data via <state>
+	history := appendTurn(state.ConversationHistory, req.TurnID, domain.LLMMessage{Role: "user", Content: req.Query})
+	if err := uc.statePort.AppendConversation(ctx, req.SessionID, history); err != nil {
+		uc.log.Error("append_conversation_failed", "error", err, "session_id", req.SessionID)
+	}
+
+	var adjacentTemplates map[string]*domain.FormationWithData
+	var entities *domain.StateData
+	if entry.Formation.Mode != domain.FormationTypeSingle && uc.presetRegistry != nil {
+		adjacentTemplates, entities = uc.buildAdjacentTemplates(state)
+	}
+
+	trace.FormationResult = buildFormationTrace(entry.Formation)
+	domain.EmitEvent(ctx, domain.PipelineEventFormation, domain.FormationEventData{
+		Formation:         entry.Formation,
+		AdjacentTemplates: adjacentTemplates,
+		Entities:          entities,
+	})
+
+	return &PipelineExecuteResponse{
+		Formation:         entry.Formation,
+		AdjacentTemplates: adjacentTemplates,
+		Entities:          entities,
+		ToolCalled:        entry.ToolName,
+		ToolInput:         entry.ToolInput,
+		ProductsFound:     meta.Count,
+		CacheMatch:        hit.Match,
+		CacheSimilarity:   hit.Similarity,
+	}, nil
+}
+
+// buildFormationTrace summarizes a formation for the trace (nil for no formation)
+func buildFormationTrace(formation *domain.FormationWithData) *domain.FormationTrace {
+	if formation == nil {
+		return nil
+	}
+	ft := &domain.FormationTrace{
+		Mode:        string(formation.Mode),
+		WidgetCount: len(formation.Widgets),
+	}
+	if formation.Grid != nil {
+		ft.Cols = formation.Grid.Cols
+	}
+	if len(formation.Widgets) > 0 {
+		for _, atom := range formation.Widgets[0].Atoms {
+			if atom.Slot == domain.AtomSlotTitle {
+				if s, ok := atom.Value.(string); ok {
+					ft.FirstWidget = s
+				}
+				break
+			}
+		}
+	}
+	return ft
+}
+
+// recordTrace saves trace if tracePort is available and charges its usage to the tenant
 func (uc *PipelineExecuteUseCase) recordTrace(ctx context.Context, trace *domain.PipelineTrace) {
+	uc.recordUsage(ctx, trace)
 	if uc.tracePort == nil {
 		return
 	}
@@ -336,6 +549,56 @@ func (uc *PipelineExecuteUseCase) recordTrace(ctx context.Context, trace *domain
 	}
 }
 
+// recordUsage accumulates the trace's tokens and cost into the tenant's daily bucket
+func (uc *PipelineExecuteUseCase) recordUsage(ctx context.Context, trace *domain.PipelineTrace) {
+	if uc.usagePort == nil || trace.TenantSlug == "" {
+		return
+	}
+	tokens := trace.TotalTokens()
+	if tokens == 0 && trace.CostUSD == 0 {
+		return
+	}
+	if err := uc.usagePort.AddUsage(ctx, trace.TenantSlug, trace.Timestamp, tokens, trace.CostUSD); err != nil {
+		uc.log.Error("usage_record_failed", "error", err, "tenant", trace.TenantSlug)
+	}
+}
+
+// checkQuota returns a QuotaExceededError if the tenant is over a daily/monthly limit.
+// Fails open: lookup errors are logged and the request proceeds.
+func (uc *PipelineExecuteUseCase) checkQuota(ctx context.Context, tenantSlug string) *domain.QuotaExceededError {
+	if uc.usagePort == nil || uc.catalogPort == nil || tenantSlug == "" {
+		return nil
+	}
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("pipeline.quota")
+		defer endSpan()
+	}
+
+	tenant, err := uc.catalogPort.GetTenantBySlug(ctx, tenantSlug)
+	if err != nil {
+		uc.log.Warn("quota_tenant_lookup_failed", "tenant", tenantSlug, "error", err)
+		return nil
+	}
+	quota := domain.TenantQuotaFromSettings(tenant.Settings)
+	if quota.IsZero() {
+		return nil
+	}
+
+	now := time.Now()
+	dayStart, monthStart := domain.QuotaPeriodStarts(now)
+	daily, err := uc.usagePort.GetUsageSince(ctx, tenantSlug, dayStart)
+	if err != nil {
+		uc.log.Warn("quota_usage_lookup_failed", "tenant", tenantSlug, "error", err)
+		return nil
+	}
+	monthly, err := uc.usagePort.GetUsageSince(ctx, tenantSlug, monthStart)
+	if err != nil {
+		uc.log.Warn("quota_usage_lookup_failed", "tenant", tenantSlug, "error", err)
+		return nil
+	}
+	return domain.CheckQuota(quota, *daily, *monthly, now)
+}
+
 // buildAdjacentTemplates builds 1 template per entity type + returns raw entity data.
 // Frontend fills templates with entity data at click time (instant, no round-trip).
 // Uses defaults engine with maxFields=10 for detail view.
@@ -370,6 +633,29 @@ func (uc *PipelineExecuteUseCase) buildAdjacentTemplates(state *domain.SessionSt
 	return templates, entities
 }
 
+// buildSkeletonFormation builds a template formation for the data Agent 1 just wrote,
+// using the defaults engine (same as adjacent templates, but sized for the list view).
+// Returns nil when there is nothing to show.
+func buildSkeletonFormation(state *domain.SessionState) *domain.FormationWithData {
+	entityType := "product"
+	count := len(state.Current.Data.Products)
+	if count == 0 {
+		entityType = "service"
+		count = len(state.Current.Data.Services)
+	}
+	if count == 0 {
+		return nil
+	}
+
+	resolved := engine.AutoResolve(entityType, count)
+	fieldConfigs := engine.BuildFieldConfigs(resolved.Fields, nil)
+	formation := engine.BuildTemplateFormation(buildGenericPreset(fieldConfigs, resolved))
+	if formation.Mode == domain.FormationTypeGrid {
+		formation.Grid = engine.CalcGridConfig(count, resolved.Size)
+	}
+	return formation
+}
+
 // buildGenericPreset creates a Preset from field configs and resolved defaults for template building
 func buildGenericPreset(fieldConfigs []domain.FieldConfig, resolved engine.ResolvedDefaults) domain.Preset {
 	mode := domain.FormationTypeSingle
@@ -416,6 +702,12 @@ func buildMicrocontext(agent1Resp *Agent1ExecuteResponse) string {
 	switch {
 	case agent1Resp.ToolName == "catalog_search":
 		return fmt.Sprintf("new_search: %d items found", agent1Resp.ProductsFound)
+	case agent1Resp.ToolName == "catalog_similar":
+		return fmt.Sprintf("similar: %d items found", agent1Resp.ProductsFound)
+	case agent1Resp.ToolName == "catalog_routine":
+		return fmt.Sprintf("routine: %d items", agent1Resp.ProductsFound)
+	case agent1Resp.ToolName == "catalog_compatibility":
+		return fmt.Sprintf("compatibility_checked: %d items unchanged", agent1Resp.ProductsFound)
 	case agent1Resp.ToolName == "_internal_state_filter":
 		return fmt.Sprintf("filtered: %d items", agent1Resp.ProductsFound)
 	case agent1Resp.ToolName == "":
@@ -0,0 +1,67 @@
+package usecases
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+)
+
+// PromptSelector resolves the system prompt version for a pipeline stage:
+// tenant override → global → built-in constant. Active versions of one
+// name form an experiment; sessions are split deterministically by ID.
+// Active sets are cached for ttl to keep the registry off the hot path;
+// versions changed in the admin backend are picked up when it expires.
+type PromptSelector struct {
+	promptPort ports.PromptPort
+	ttl        time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedPrompts // name|tenant → active versions
+	now   func() time.Time
+}
+
+type cachedPrompts struct {
+	versions  []domain.PromptVersion
+	expiresAt time.Time
+}
+
+// NewPromptSelector creates a selector over the prompt registry
+func NewPromptSelector(promptPort ports.PromptPort, ttl time.Duration) *PromptSelector {
+	return &PromptSelector{
+		promptPort: promptPort,
+		ttl:        ttl,
+		cache:      make(map[string]cachedPrompts),
+		now:        time.Now,
+	}
+}
+
+// Select returns the prompt version for this session. Registry errors fail open to builtin.
+func (s *PromptSelector) Select(ctx context.Context, name, tenantSlug, sessionID, builtin string) domain.PromptVersion {
+	fallback := domain.PromptVersion{Name: name, Version: domain.BuiltinPromptVersion, Content: builtin}
+	if s == nil || s.promptPort == nil {
+		return fallback
+	}
+
+	key := name + "|" + tenantSlug
+	s.mu.Lock()
+	cached, ok := s.cache[key]
+	s.mu.Unlock()
+	if !ok || s.now().After(cached.expiresAt) {
+		versions, err := s.promptPort.GetActivePrompts(ctx, name, tenantSlug)
+		if err != nil {
+			return fallback
+		}
+		cached = cachedPrompts{versions: versions, expiresAt: s.now().Add(s.ttl)}
+		s.mu.Lock()
+		s.cache[key] = cached
+		s.mu.Unlock()
+	}
+
+	if v, ok := domain.SelectPromptVariant(cached.versions, sessionID); ok {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,69 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/usecases"
+)
+
+// fakePromptPort serves fixed active versions and counts lookups
+type fakePromptPort struct {
+	active  []domain.PromptVersion
+	err     error
+	lookups int
+}
+
+func (f *fakePromptPort) GetActivePrompts(ctx context.Context, name, tenantSlug string) ([]domain.PromptVersion, error) {
+	f.lookups++
+	return f.active, f.err
+}
+
+func TestPromptSelector_UsesRegistryAndCaches(t *testing.T) {
+	port := &fakePromptPort{active: []domain.PromptVersion{
+		{Name: domain.PromptAgent1, Version: 4, Content: "registry v4", Weight: 100, Active: true},
+	}}
+	selector := usecases.NewPromptSelector(port, time.Minute)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		got := selector.Select(ctx, domain.PromptAgent1, "nike", "session-1", "builtin")
+		if got.Version != 4 || got.Content != "registry v4" {
+			t.Fatalf("want registry v4, got %+v", got)
+		}
+	}
+	if port.lookups != 1 {
+		t.Errorf("active set should be cached, got %d lookups", port.lookups)
+	}
+
+	// An expired active set is reloaded (admin changes reach the chat backend)
+	expiring := usecases.NewPromptSelector(port, time.Nanosecond)
+	expiring.Select(ctx, domain.PromptAgent1, "nike", "session-1", "builtin")
+	time.Sleep(time.Millisecond)
+	expiring.Select(ctx, domain.PromptAgent1, "nike", "session-1", "builtin")
+	if port.lookups != 3 {
+		t.Errorf("expired cache should force a reload, got %d lookups", port.lookups)
+	}
+}
+
+func TestPromptSelector_FallsBackToBuiltin(t *testing.T) {
+	ctx := context.Background()
+
+	empty := usecases.NewPromptSelector(&fakePromptPort{}, time.Minute)
+	if got := empty.Select(ctx, domain.PromptAgent2, "", "s", "builtin"); got.Version != domain.BuiltinPromptVersion || got.Content != "builtin" {
+		t.Errorf("no active versions: want builtin, got %+v", got)
+	}
+
+	broken := usecases.NewPromptSelector(&fakePromptPort{err: errors.New("db down")}, time.Minute)
+	if got := broken.Select(ctx, domain.PromptAgent2, "", "s", "builtin"); got.Content != "builtin" {
+		t.Errorf("registry error: want builtin, got %+v", got)
+	}
+
+	var disabled *usecases.PromptSelector
+	if got := disabled.Select(ctx, domain.PromptAgent1, "", "s", "builtin"); got.Content != "builtin" {
+		t.Errorf("nil selector: want builtin, got %+v", got)
+	}
+}
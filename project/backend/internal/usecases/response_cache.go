@@ -0,0 +1,139 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/logger"
+	"keepstar/internal/ports"
+)
+
+// ResponseCache serves repeated catalog queries of a tenant from stored pipeline results.
+// Lookup is exact on the normalized query, then by query embedding similarity.
+type ResponseCache struct {
+	port          ports.ResponseCachePort
+	embedder      ports.EmbeddingPort // nil = exact matches only
+	minSimilarity float64
+	ttl           time.Duration
+	log           *logger.Logger
+}
+
+// NewResponseCache creates a response cache; embedder may be nil
+func NewResponseCache(port ports.ResponseCachePort, embedder ports.EmbeddingPort, minSimilarity float64, ttl time.Duration, log *logger.Logger) *ResponseCache {
+	return &ResponseCache{
+		port:          port,
+		embedder:      embedder,
+		minSimilarity: minSimilarity,
+		ttl:           ttl,
+		log:           log,
+	}
+}
+
+// responseCacheLookup carries one lookup's key and outcome through the pipeline run
+type responseCacheLookup struct {
+	tenantSlug string
+	stateKey   string
+	queryNorm  string
+	embedding  []float32
+	hit        *domain.ResponseCacheHit
+	trace      *domain.ResponseCacheTrace
+}
+
+// lookup finds a cached response. Fails open: errors are recorded on the trace and count as a miss.
+func (c *ResponseCache) lookup(ctx context.Context, tenantSlug, stateKey, query string) *responseCacheLookup {
+	start := time.Now()
+	var endSpan func(...string)
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan = sc.Start("pipeline.cache")
+	}
+
+	l := &responseCacheLookup{
+		tenantSlug: tenantSlug,
+		stateKey:   stateKey,
+		queryNorm:  domain.NormalizeCacheQuery(query),
+		trace:      &domain.ResponseCacheTrace{StateKey: stateKey},
+	}
+	defer func() {
+		l.trace.LookupMs = time.Since(start).Milliseconds()
+		if endSpan != nil {
+			if l.hit != nil {
+				endSpan(fmt.Sprintf("hit %s %.3f", l.hit.Match, l.hit.Similarity))
+			} else {
+				endSpan("miss")
+			}
+		}
+	}()
+	if l.queryNorm == "" {
+		return l
+	}
+
+	hit, err := c.port.LookupResponse(ctx, tenantSlug, stateKey, l.queryNorm, nil, c.minSimilarity)
+	if err == nil && hit == nil && c.embedder != nil {
+		// Exact miss: embed once, used for the similarity lookup and for storing the result
+		embeddings, embedErr := c.embedder.Embed(ctx, []string{query})
+		if embedErr != nil {
+			l.trace.Error = fmt.Sprintf("embed: %v", embedErr)
+		} else if len(embeddings) == 1 {
+			l.embedding = embeddings[0]
+			hit, err = c.port.LookupResponse(ctx, tenantSlug, stateKey, l.queryNorm, l.embedding, c.minSimilarity)
+		}
+	}
+	if err != nil {
+		c.log.Warn("response_cache_lookup_failed", "tenant", tenantSlug, "error", err)
+		l.trace.Error = err.Error()
+		return l
+	}
+	if hit != nil {
+		l.hit = hit
+		l.trace.Hit = true
+		l.trace.Match = hit.Match
+		l.trace.Similarity = hit.Similarity
+		l.trace.EntryID = hit.Entry.ID
+		l.trace.SavedCostUSD = hit.Entry.CostUSD
+	}
+	return l
+}
+
+// store saves a completed run under the lookup's key
+func (c *ResponseCache) store(ctx context.Context, l *responseCacheLookup, state *domain.SessionState, formation *domain.FormationWithData, agent1Resp *Agent1ExecuteResponse, costUSD float64) {
+	entry := &domain.ResponseCacheEntry{
+		TenantSlug: l.tenantSlug,
+		QueryNorm:  l.queryNorm,
+		StateKey:   l.stateKey,
+		Embedding:  l.embedding,
+		Data:       state.Current.Data,
+		Meta:       state.Current.Meta,
+		Formation:  formation,
+		ToolName:   agent1Resp.ToolName,
+		ToolInput:  agent1Resp.ToolInput,
+		CostUSD:    costUSD,
+	}
+	if err := c.port.StoreResponse(ctx, entry, c.ttl); err != nil {
+		c.log.Warn("response_cache_store_failed", "tenant", l.tenantSlug, "error", err)
+		return
+	}
+	l.trace.Stored = true
+	l.trace.EntryID = entry.ID
+}
+
+// responseCacheable reports whether a run produced fresh catalog data independent of
+// what was on screen before: only successful catalog_search calls, nothing else.
+func responseCacheable(agent1Resp *Agent1ExecuteResponse, state *domain.SessionState, formation *domain.FormationWithData) bool {
+	if formation == nil || agent1Resp.ToolName != "catalog_search" || !strings.HasPrefix(agent1Resp.ToolResult, "ok") {
+		return false
+	}
+	if len(state.Current.Data.Products) == 0 && len(state.Current.Data.Services) == 0 {
+		return false
+	}
+	for _, step := range agent1Resp.Steps {
+		for _, t := range step.Tools {
+			if t.Name != "catalog_search" || t.IsError {
+				return false
+			}
+		}
+	}
+	return true
+}
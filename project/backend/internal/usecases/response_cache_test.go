@@ -0,0 +1,176 @@
+package usecases_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/logger"
+	"keepstar/internal/presets"
+	"keepstar/internal/testutil"
+	"keepstar/internal/tools"
+	"keepstar/internal/usecases"
+)
+
+// fakeResponseCache serves one stored entry for an exact normalized query
+type fakeResponseCache struct {
+	entry   *domain.ResponseCacheEntry
+	lookups []string // state keys looked up
+	stored  []*domain.ResponseCacheEntry
+}
+
+func (f *fakeResponseCache) LookupResponse(ctx context.Context, tenantSlug, stateKey, queryNorm string, embedding []float32, minSimilarity float64) (*domain.ResponseCacheHit, error) {
+	f.lookups = append(f.lookups, stateKey)
+	if f.entry == nil || f.entry.TenantSlug != tenantSlug || f.entry.StateKey != stateKey || f.entry.QueryNorm != queryNorm {
+		return nil, nil
+	}
+	return &domain.ResponseCacheHit{Entry: f.entry, Match: domain.CacheMatchExact, Similarity: 1}, nil
+}
+
+func (f *fakeResponseCache) StoreResponse(ctx context.Context, entry *domain.ResponseCacheEntry, ttl time.Duration) error {
+	f.stored = append(f.stored, entry)
+	return nil
+}
+
+func (f *fakeResponseCache) InvalidateTenant(ctx context.Context, tenantSlug string) (int64, error) {
+	return 0, nil
+}
+
+// fakeTracePort keeps recorded traces in memory
+type fakeTracePort struct {
+	recorded []*domain.PipelineTrace
+}
+
+func (f *fakeTracePort) Record(ctx context.Context, trace *domain.PipelineTrace) error {
+	f.recorded = append(f.recorded, trace)
+	return nil
+}
+
+func (f *fakeTracePort) List(ctx context.Context, limit int) ([]*domain.PipelineTrace, error) {
+	return f.recorded, nil
+}
+
+func (f *fakeTracePort) Get(ctx context.Context, traceID string) (*domain.PipelineTrace, error) {
+	return nil, nil
+}
+
+func TestPipeline_ResponseCacheHitSkipsAgents(t *testing.T) {
+	products := testutil.SeedProducts(3)
+	cache := &fakeResponseCache{entry: &domain.ResponseCacheEntry{
+		ID:         "entry-1",
+		TenantSlug: "nike",
+		QueryNorm:  "крем для сухой кожи",
+		StateKey:   "empty|a1v0|a2v0",
+		Data:       domain.StateData{Products: products},
+		Meta:       domain.StateMeta{Count: 3, ProductCount: 3, Fields: []string{"name", "price"}},
+		Formation:  &domain.FormationWithData{Mode: domain.FormationTypeGrid, Widgets: []domain.Widget{{ID: "w1"}, {ID: "w2"}, {ID: "w3"}}},
+		ToolName:   "catalog_search",
+		CostUSD:    0.004,
+	}}
+
+	llm := testutil.NewMockLLMClient() // any LLM call would fail the test
+	statePort := newMockStatePort()
+	log := logger.New("error")
+	traces := &fakeTracePort{}
+	registry := tools.NewRegistry(statePort, nil, presets.NewPresetRegistry(), nil)
+	pipeline := usecases.NewPipelineExecuteUseCase(llm, statePort, nil, traces, nil, registry, presets.NewPresetRegistry(), log).
+		WithResponseCache(usecases.NewResponseCache(cache, nil, 0.92, time.Hour, log))
+
+	resp, err := pipeline.Execute(context.Background(), usecases.PipelineExecuteRequest{
+		SessionID:  "session-cache",
+		Query:      "Крем для сухой кожи!",
+		TenantSlug: "nike",
+		TurnID:     "turn-1",
+	})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+
+	if llm.CallCount != 0 {
+		t.Errorf("cache hit must not call the LLM, got %d calls", llm.CallCount)
+	}
+	if resp.CacheMatch != domain.CacheMatchExact || resp.Formation == nil || len(resp.Formation.Widgets) != 3 {
+		t.Fatalf("want cached formation with exact match, got match=%q formation=%+v", resp.CacheMatch, resp.Formation)
+	}
+	if resp.Entities == nil || len(resp.Entities.Products) != 3 {
+		t.Errorf("want adjacent entities from replayed state, got %+v", resp.Entities)
+	}
+
+	// State looks like catalog_search + render ran: data, template, deltas, history
+	state, _ := statePort.GetState(context.Background(), "session-cache")
+	if len(state.Current.Data.Products) != 3 || state.Current.Meta.Aliases["tenant_slug"] != "nike" {
+		t.Errorf("replay must write data with tenant alias, got %+v", state.Current.Meta)
+	}
+	if _, ok := state.Current.Template["formation"]; !ok {
+		t.Error("replay must write the formation template")
+	}
+	deltas, _ := statePort.GetDeltas(context.Background(), "session-cache")
+	if len(deltas) != 2 || deltas[0].ActorID != "response_cache" || deltas[0].TurnID != "turn-1" {
+		t.Errorf("want 2 response_cache deltas for the turn, got %+v", deltas)
+	}
+	if n := len(state.ConversationHistory); n != 1 || state.ConversationHistory[0].Content != "Крем для сухой кожи!" {
+		t.Errorf("query must join conversation history, got %+v", state.ConversationHistory)
+	}
+	if len(cache.stored) != 0 {
+		t.Error("hits must not be stored again")
+	}
+
+	// Cache hits count towards their prompt versions like any other turn
+	if len(traces.recorded) != 1 {
+		t.Fatalf("want 1 recorded trace, got %d", len(traces.recorded))
+	}
+	if _, ok := traces.recorded[0].PromptVersions[domain.PromptAgent1]; !ok {
+		t.Errorf("cache-hit trace must carry prompt versions, got %v", traces.recorded[0].PromptVersions)
+	}
+}
+
+func TestPipeline_ResponseCacheSkipsLoadedScreens(t *testing.T) {
+	// Another session's serums answer to the same follow-up, stored under any key
+	serums := testutil.SeedProducts(2)
+	serums[0].Name, serums[1].Name = "Сыворотка A", "Сыворотка B"
+	cache := &fakeResponseCache{entry: &domain.ResponseCacheEntry{
+		ID:         "entry-serums",
+		TenantSlug: "nike",
+		QueryNorm:  "покажи подешевле",
+		StateKey:   "empty|a1v0|a2v0",
+		Data:       domain.StateData{Products: serums},
+		Formation:  &domain.FormationWithData{Mode: domain.FormationTypeGrid},
+		ToolName:   "catalog_search",
+	}}
+	gridConfig := &domain.RenderConfig{EntityType: "product", Mode: domain.FormationTypeGrid, Size: domain.WidgetSizeMedium}
+
+	log := logger.New("error")
+	for _, tc := range []struct{ session, name string }{
+		{"session-creams", "Крем"},
+		{"session-serums", "Сыворотка"},
+	} {
+		statePort := newMockStatePort()
+		state, _ := statePort.CreateState(context.Background(), tc.session)
+		state.Current.Data.Products = testutil.SeedProducts(3)
+		for i := range state.Current.Data.Products {
+			state.Current.Data.Products[i].Name = tc.name
+		}
+		state.Current.Template = map[string]interface{}{"formation": &domain.FormationWithData{Mode: domain.FormationTypeGrid, Config: gridConfig}}
+
+		registry := tools.NewRegistry(statePort, nil, presets.NewPresetRegistry(), nil)
+		pipeline := usecases.NewPipelineExecuteUseCase(testutil.NewMockLLMClient(), statePort, nil, nil, nil, registry, presets.NewPresetRegistry(), log).
+			WithResponseCache(usecases.NewResponseCache(cache, nil, 0.92, time.Hour, log))
+		pipeline.Execute(context.Background(), usecases.PipelineExecuteRequest{
+			SessionID:  tc.session,
+			Query:      "Покажи подешевле",
+			TenantSlug: "nike",
+		})
+
+		state, _ = statePort.GetState(context.Background(), tc.session)
+		for _, p := range state.Current.Data.Products {
+			if p.Name != tc.name {
+				t.Errorf("%s: follow-up must work on its own items, got %q", tc.session, p.Name)
+			}
+		}
+	}
+
+	if len(cache.lookups) != 0 || len(cache.stored) != 0 {
+		t.Errorf("loaded screens must not touch the cache, got lookups %v, %d stored", cache.lookups, len(cache.stored))
+	}
+}
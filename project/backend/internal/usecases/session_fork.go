@@ -0,0 +1,100 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+)
+
+// ForkRequest is the request for forking a session
+type ForkRequest struct {
+	SessionID string
+	ToStep    int // step the fork starts from; < 0 = current step
+}
+
+// ForkResponse is the new branch
+type ForkResponse struct {
+	SessionID string                    // ID of the fork
+	ParentID  string                    // session it was forked from
+	ForkStep  int                       // parent step the fork starts from
+	State     *domain.SessionState      // state of the fork
+	Formation *domain.FormationWithData // formation of the fork's template (nil when there was none)
+}
+
+// ForkUseCase clones a session at a step into a new session linked to its parent,
+// so an earlier turn can be explored differently without losing the original path.
+// The fork gets the state at that step, the deltas up to it and the conversation
+// history of the turns up to it (cut by turn, see domain.ConversationUpToStep).
+type ForkUseCase struct {
+	statePort     ports.StatePort
+	reconstructUC *ReconstructStateUseCase
+}
+
+// NewForkUseCase creates a new ForkUseCase
+func NewForkUseCase(statePort ports.StatePort) *ForkUseCase {
+	return &ForkUseCase{
+		statePort:     statePort,
+		reconstructUC: NewReconstructStateUseCase(statePort),
+	}
+}
+
+// Execute forks the session and returns the new branch
+func (uc *ForkUseCase) Execute(ctx context.Context, req ForkRequest) (*ForkResponse, error) {
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("usecase.fork")
+		defer endSpan()
+	}
+
+	parent, err := uc.statePort.GetState(ctx, req.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("get state: %w", err)
+	}
+	toStep := req.ToStep
+	if toStep < 0 {
+		toStep = parent.Step
+	}
+	if toStep > parent.Step {
+		return nil, domain.ErrInvalidStep
+	}
+
+	// State at the fork step: replayed from the deltas, or the live state when
+	// forking the current screen (it also has what deltas don't carry)
+	reconstruct, err := uc.reconstructUC.Execute(ctx, ReconstructRequest{
+		SessionID: req.SessionID,
+		ToStep:    toStep,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reconstruct state at step %d: %w", toStep, err)
+	}
+	deltas, err := uc.statePort.GetDeltas(ctx, req.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("get deltas: %w", err)
+	}
+	state := reconstruct.State
+	state.ConversationHistory = domain.ConversationUpToStep(parent.ConversationHistory, deltas, toStep)
+	if toStep == parent.Step {
+		clone := *parent
+		clone.ConversationHistory = append([]domain.LLMMessage(nil), parent.ConversationHistory...)
+		state = &clone
+	}
+
+	forkID := uuid.New().String()
+	state.ID = ""
+	state.SessionID = forkID
+	state.Step = toStep
+
+	if err := uc.statePort.ForkState(ctx, req.SessionID, forkID, toStep, state); err != nil {
+		return nil, fmt.Errorf("fork state: %w", err)
+	}
+
+	return &ForkResponse{
+		SessionID: forkID,
+		ParentID:  req.SessionID,
+		ForkStep:  toStep,
+		State:     state,
+		Formation: formationFromTemplate(state.Current.Template),
+	}, nil
+}
@@ -0,0 +1,139 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/usecases"
+)
+
+// seedTwoTurns writes two shopper queries: "creams" (steps 1-2) and "serums" (steps 3-4)
+func seedTwoTurns(t *testing.T, statePort *mockStatePort) {
+	t.Helper()
+	ctx := context.Background()
+	statePort.CreateState(ctx, "session-1")
+
+	for i, turn := range []struct{ id, query, product string }{
+		{"turn-1", "creams", "cream-1"},
+		{"turn-2", "serums", "serum-1"},
+	} {
+		data := domain.StateData{Products: []domain.Product{{ID: turn.product, Name: turn.product}}}
+		if _, err := statePort.UpdateData(ctx, "session-1", data, domain.StateMeta{Count: 1}, domain.DeltaInfo{
+			TurnID: turn.id, Trigger: domain.TriggerUserQuery, Source: domain.SourceLLM, ActorID: "agent1",
+			DeltaType: domain.DeltaTypeAdd, Path: "data.products",
+		}); err != nil {
+			t.Fatalf("UpdateData failed: %v", err)
+		}
+		if _, err := statePort.UpdateTemplate(ctx, "session-1", map[string]interface{}{"formation": turn.query}, domain.DeltaInfo{
+			TurnID: turn.id, Trigger: domain.TriggerUserQuery, Source: domain.SourceLLM, ActorID: "agent2",
+			DeltaType: domain.DeltaTypeUpdate, Path: "template",
+		}); err != nil {
+			t.Fatalf("UpdateTemplate failed: %v", err)
+		}
+		statePort.state.ConversationHistory = append(statePort.state.ConversationHistory,
+			domain.LLMMessage{Role: "user", Content: turn.query, TurnID: turn.id},
+			domain.LLMMessage{Role: "assistant", Content: "found", TurnID: turn.id},
+		)
+		statePort.state.Step = (i + 1) * 2
+	}
+}
+
+func TestForkUseCase_AtEarlierStep(t *testing.T) {
+	ctx := context.Background()
+	statePort := newMockStatePort()
+	seedTwoTurns(t, statePort)
+
+	forkUC := usecases.NewForkUseCase(statePort)
+	resp, err := forkUC.Execute(ctx, usecases.ForkRequest{SessionID: "session-1", ToStep: 2})
+	if err != nil {
+		t.Fatalf("Fork failed: %v", err)
+	}
+
+	fork := statePort.forks[resp.SessionID]
+	if fork == nil || fork.parentID != "session-1" || fork.toStep != 2 {
+		t.Fatalf("expected a fork of session-1 at step 2, got %+v", fork)
+	}
+	if len(fork.deltas) != 2 {
+		t.Errorf("expected the 2 deltas of the first turn copied, got %d", len(fork.deltas))
+	}
+	st := fork.state
+	if st.SessionID != resp.SessionID || st.Step != 2 || st.Current.Data.Products[0].ID != "cream-1" || st.Current.Template["formation"] != "creams" {
+		t.Errorf("expected the first turn's screen in the fork, got %+v", st.Current)
+	}
+	if h := st.ConversationHistory; len(h) != 2 || h[0].Content != "creams" {
+		t.Errorf("expected the conversation of the first turn only, got %+v", h)
+	}
+
+	// The parent is untouched
+	if statePort.state.Step != 4 || len(statePort.state.ConversationHistory) != 4 || statePort.state.Current.Data.Products[0].ID != "serum-1" {
+		t.Errorf("parent should keep its state, got %+v", statePort.state)
+	}
+
+	branches, err := statePort.GetBranches(ctx, resp.SessionID)
+	if err != nil {
+		t.Fatalf("GetBranches failed: %v", err)
+	}
+	roots := domain.BuildBranchTree(branches)
+	if len(roots) != 1 || len(roots[0].Children) != 1 || roots[0].Children[0].ForkStep != 2 {
+		t.Errorf("expected session-1 with one fork at step 2, got %+v", roots)
+	}
+}
+
+func TestForkUseCase_CurrentStepAndInvalidStep(t *testing.T) {
+	ctx := context.Background()
+	statePort := newMockStatePort()
+	seedTwoTurns(t, statePort)
+
+	forkUC := usecases.NewForkUseCase(statePort)
+	resp, err := forkUC.Execute(ctx, usecases.ForkRequest{SessionID: "session-1", ToStep: -1})
+	if err != nil {
+		t.Fatalf("Fork failed: %v", err)
+	}
+	if resp.ForkStep != 4 || len(resp.State.ConversationHistory) != 4 || len(statePort.forks[resp.SessionID].deltas) != 4 {
+		t.Errorf("fork of the current step should clone everything, got %+v", resp)
+	}
+	if statePort.state.SessionID != "session-1" {
+		t.Errorf("fork must not change the parent state, got %s", statePort.state.SessionID)
+	}
+
+	if _, err := forkUC.Execute(ctx, usecases.ForkRequest{SessionID: "session-1", ToStep: 5}); !errors.Is(err, domain.ErrInvalidStep) {
+		t.Errorf("expected ErrInvalidStep for a step after the current one, got %v", err)
+	}
+}
+
+func TestForkUseCase_DigestSeededHistory(t *testing.T) {
+	ctx := context.Background()
+	statePort := newMockStatePort()
+	seedTwoTurns(t, statePort)
+	// Session init seeds the catalog digest as an untagged user/assistant pair
+	statePort.state.ConversationHistory = append([]domain.LLMMessage{
+		{Role: "user", Content: "<catalog>\nbrands: CeraVe, La Roche-Posay\n</catalog>"},
+		{Role: "assistant", Content: "ok"},
+	}, statePort.state.ConversationHistory...)
+	// A third query that only answered from history: no delta
+	statePort.state.ConversationHistory = append(statePort.state.ConversationHistory,
+		domain.LLMMessage{Role: "user", Content: "what did I search?", TurnID: "turn-3"},
+		domain.LLMMessage{Role: "assistant", Content: "creams and serums", TurnID: "turn-3"},
+	)
+
+	forkUC := usecases.NewForkUseCase(statePort)
+	resp, err := forkUC.Execute(ctx, usecases.ForkRequest{SessionID: "session-1", ToStep: 2})
+	if err != nil {
+		t.Fatalf("Fork failed: %v", err)
+	}
+	h := resp.State.ConversationHistory
+	if len(h) != 4 || h[0].TurnID != "" || h[2].Content != "creams" || h[3].TurnID != "turn-1" {
+		t.Errorf("expected the digest seed and the first turn, got %+v", h)
+	}
+
+	// Before the first turn only the seed is left
+	resp, err = forkUC.Execute(ctx, usecases.ForkRequest{SessionID: "session-1", ToStep: 0})
+	if err != nil {
+		t.Fatalf("Fork failed: %v", err)
+	}
+	if h := resp.State.ConversationHistory; len(h) != 2 || h[0].TurnID != "" {
+		t.Errorf("expected only the digest seed, got %+v", h)
+	}
+}
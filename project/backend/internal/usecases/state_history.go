@@ -0,0 +1,119 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/ports"
+)
+
+// HistoryRequest is the request for undo/redo/goto
+type HistoryRequest struct {
+	SessionID string
+	Action    string // domain.HistoryUndo / HistoryRedo / HistoryGoto
+	ToStep    int    // target step for goto
+	TurnID    string // Turn ID for delta grouping
+}
+
+// HistoryResponse is the restored screen
+type HistoryResponse struct {
+	State     *domain.SessionState
+	Formation *domain.FormationWithData // formation of the restored template (nil when there was none)
+	FromStep  int                       // step before the action
+	ToStep    int                       // step whose screen is shown now
+	CanUndo   bool
+	CanRedo   bool
+	Delta     *domain.Delta // the rollback delta recording the action
+}
+
+// HistoryUseCase moves a session through its screen history: undo to the previous
+// screen, redo an undone one (until the next change), or go to any earlier step.
+// Each action is a user rollback delta, so the history itself stays replayable.
+type HistoryUseCase struct {
+	statePort  ports.StatePort
+	rollbackUC *RollbackUseCase
+}
+
+// NewHistoryUseCase creates a new HistoryUseCase
+func NewHistoryUseCase(statePort ports.StatePort) *HistoryUseCase {
+	return &HistoryUseCase{
+		statePort:  statePort,
+		rollbackUC: NewRollbackUseCase(statePort),
+	}
+}
+
+// Execute applies the history action and returns the restored screen
+func (uc *HistoryUseCase) Execute(ctx context.Context, req HistoryRequest) (*HistoryResponse, error) {
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("usecase.history")
+		defer endSpan()
+	}
+
+	// An unknown session has no deltas either: report it as missing, not as nothing to undo
+	if _, err := uc.statePort.GetState(ctx, req.SessionID); err != nil {
+		return nil, fmt.Errorf("get state: %w", err)
+	}
+	deltas, err := uc.statePort.GetDeltas(ctx, req.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("get deltas: %w", err)
+	}
+	history := domain.BuildStateHistory(deltas)
+
+	var target int
+	var ok bool
+	switch req.Action {
+	case domain.HistoryUndo:
+		if target, ok = history.UndoTarget(); !ok {
+			return nil, domain.ErrNothingToUndo
+		}
+	case domain.HistoryRedo:
+		if target, ok = history.RedoTarget(); !ok {
+			return nil, domain.ErrNothingToRedo
+		}
+	case domain.HistoryGoto:
+		if len(deltas) == 0 || req.ToStep < 0 || req.ToStep >= deltas[len(deltas)-1].Step {
+			return nil, domain.ErrInvalidStep
+		}
+		target = req.ToStep
+	default:
+		return nil, fmt.Errorf("unknown history action: %q", req.Action)
+	}
+
+	rollback, err := uc.rollbackUC.Execute(ctx, RollbackRequest{
+		SessionID: req.SessionID,
+		ToStep:    target,
+		Source:    domain.SourceUser,
+		ActorID:   "user_" + req.Action,
+		Trigger:   domain.TriggerWidgetAction,
+		TurnID:    req.TurnID,
+		History:   req.Action,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s to step %d: %w", req.Action, target, err)
+	}
+	history.Record(req.Action, target)
+
+	return &HistoryResponse{
+		State:     rollback.State,
+		Formation: formationFromTemplate(rollback.State.Current.Template),
+		FromStep:  rollback.FromStep,
+		ToStep:    target,
+		CanUndo:   history.CanUndo(),
+		CanRedo:   history.CanRedo(),
+		Delta:     rollback.RollbackDelta,
+	}, nil
+}
+
+// formationFromTemplate reads the formation from a template zone
+// (a struct when built in this process, a map after a JSON round trip)
+func formationFromTemplate(template map[string]interface{}) *domain.FormationWithData {
+	data, ok := template["formation"]
+	if !ok {
+		return nil
+	}
+	if f, ok := data.(*domain.FormationWithData); ok {
+		return f
+	}
+	return convertToFormation(data)
+}
@@ -0,0 +1,144 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/presets"
+	"keepstar/internal/usecases"
+)
+
+func TestHistoryUseCase_UndoRedo(t *testing.T) {
+	ctx := context.Background()
+	statePort := newMockStatePort()
+	seedGridSession(t, statePort)
+
+	expandUC := usecases.NewExpandUseCase(statePort, presets.NewPresetRegistry())
+	if _, err := expandUC.Execute(ctx, usecases.ExpandRequest{
+		SessionID: "session-1", EntityType: domain.EntityTypeProduct, EntityID: "product-1", TurnID: "turn-expand",
+	}); err != nil {
+		t.Fatalf("Expand failed: %v", err)
+	}
+
+	historyUC := usecases.NewHistoryUseCase(statePort)
+
+	undo, err := historyUC.Execute(ctx, usecases.HistoryRequest{SessionID: "session-1", Action: domain.HistoryUndo})
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if undo.ToStep != 2 || !undo.CanUndo || !undo.CanRedo {
+		t.Errorf("undo should go to step 2 with undo and redo available, got %+v", undo)
+	}
+	if statePort.state.View.Mode != domain.ViewModeGrid || statePort.state.Current.Template["formation"] != "grid" {
+		t.Errorf("expected the grid screen back, got %+v", statePort.state.View)
+	}
+	if d := undo.Delta; d.DeltaType != domain.DeltaTypeRollback || d.Source != domain.SourceUser || d.Action.Params["history"] != domain.HistoryUndo {
+		t.Errorf("expected a user rollback delta for undo, got %+v", d)
+	}
+
+	redo, err := historyUC.Execute(ctx, usecases.HistoryRequest{SessionID: "session-1", Action: domain.HistoryRedo})
+	if err != nil {
+		t.Fatalf("Redo failed: %v", err)
+	}
+	if redo.ToStep != 4 || redo.CanRedo {
+		t.Errorf("redo should restore step 4 with nothing left to redo, got %+v", redo)
+	}
+	if redo.Formation == nil || statePort.state.View.Mode != domain.ViewModeDetail {
+		t.Errorf("expected the detail screen with its formation, got %+v", statePort.state.View)
+	}
+
+	if _, err := historyUC.Execute(ctx, usecases.HistoryRequest{SessionID: "session-1", Action: domain.HistoryRedo}); !errors.Is(err, domain.ErrNothingToRedo) {
+		t.Errorf("expected ErrNothingToRedo, got %v", err)
+	}
+}
+
+func TestHistoryUseCase_NewChangeDropsRedo(t *testing.T) {
+	ctx := context.Background()
+	statePort := newMockStatePort()
+	seedGridSession(t, statePort)
+
+	historyUC := usecases.NewHistoryUseCase(statePort)
+	if _, err := historyUC.Execute(ctx, usecases.HistoryRequest{SessionID: "session-1", Action: domain.HistoryUndo}); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+
+	// A new query writes data again
+	statePort.UpdateData(ctx, "session-1", domain.StateData{}, domain.StateMeta{}, domain.DeltaInfo{
+		TurnID: "turn-2", Trigger: domain.TriggerUserQuery, DeltaType: domain.DeltaTypeAdd, Path: "data.products",
+	})
+
+	if _, err := historyUC.Execute(ctx, usecases.HistoryRequest{SessionID: "session-1", Action: domain.HistoryRedo}); !errors.Is(err, domain.ErrNothingToRedo) {
+		t.Errorf("redo after a new query should fail with ErrNothingToRedo, got %v", err)
+	}
+}
+
+func TestHistoryUseCase_Goto(t *testing.T) {
+	ctx := context.Background()
+	statePort := newMockStatePort()
+	seedGridSession(t, statePort)
+
+	historyUC := usecases.NewHistoryUseCase(statePort)
+
+	resp, err := historyUC.Execute(ctx, usecases.HistoryRequest{SessionID: "session-1", Action: domain.HistoryGoto, ToStep: 1})
+	if err != nil {
+		t.Fatalf("Goto failed: %v", err)
+	}
+	if resp.Formation != nil || len(resp.State.Current.Data.Products) != 2 {
+		t.Errorf("step 1 has data but no template yet, got %+v", resp.State.Current)
+	}
+
+	for _, step := range []int{-1, len(statePort.deltas)} {
+		_, err := historyUC.Execute(ctx, usecases.HistoryRequest{SessionID: "session-1", Action: domain.HistoryGoto, ToStep: step})
+		if !errors.Is(err, domain.ErrInvalidStep) {
+			t.Errorf("goto %d: expected ErrInvalidStep, got %v", step, err)
+		}
+	}
+}
+
+func TestHistoryUseCase_UndoCutsConversation(t *testing.T) {
+	ctx := context.Background()
+	statePort := newMockStatePort()
+	statePort.CreateState(ctx, "session-1")
+	statePort.state.ConversationHistory = []domain.LLMMessage{{Role: "user", Content: "<catalog>…</catalog>"}}
+
+	// Two queries, each with its data delta and conversation messages
+	for _, turn := range []struct{ id, query string }{{"turn-1", "кремы"}, {"turn-2", "а подешевле?"}} {
+		statePort.UpdateData(ctx, "session-1", domain.StateData{}, domain.StateMeta{}, domain.DeltaInfo{
+			TurnID: turn.id, Trigger: domain.TriggerUserQuery, DeltaType: domain.DeltaTypeAdd, Path: "data.products",
+		})
+		statePort.AppendConversation(ctx, "session-1", append(statePort.state.ConversationHistory,
+			domain.LLMMessage{Role: "user", Content: turn.query, TurnID: turn.id},
+			domain.LLMMessage{Role: "assistant", Content: "готово", TurnID: turn.id},
+		))
+	}
+
+	historyUC := usecases.NewHistoryUseCase(statePort)
+	if _, err := historyUC.Execute(ctx, usecases.HistoryRequest{SessionID: "session-1", Action: domain.HistoryUndo}); err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	history := statePort.state.ConversationHistory
+	if len(history) != 3 || history[1].Content != "кремы" {
+		t.Fatalf("undo should cut the undone query from the conversation, got %+v", history)
+	}
+
+	// Redo restores the screen; the undone messages are not part of the deltas and stay cut
+	if _, err := historyUC.Execute(ctx, usecases.HistoryRequest{SessionID: "session-1", Action: domain.HistoryRedo}); err != nil {
+		t.Fatalf("Redo failed: %v", err)
+	}
+	if len(statePort.state.ConversationHistory) != 3 {
+		t.Errorf("redo should not bring back conversation messages, got %+v", statePort.state.ConversationHistory)
+	}
+}
+
+func TestHistoryUseCase_UnknownSession(t *testing.T) {
+	historyUC := usecases.NewHistoryUseCase(newMockStatePort())
+
+	for _, action := range []string{domain.HistoryUndo, domain.HistoryRedo, domain.HistoryGoto} {
+		_, err := historyUC.Execute(context.Background(), usecases.HistoryRequest{SessionID: "missing", Action: action})
+		if !errors.Is(err, domain.ErrSessionNotFound) {
+			t.Errorf("%s on an unknown session: expected ErrSessionNotFound, got %v", action, err)
+		}
+	}
+}
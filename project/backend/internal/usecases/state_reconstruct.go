@@ -3,6 +3,7 @@ package usecases
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"keepstar/internal/domain"
 	"keepstar/internal/ports"
@@ -40,9 +41,20 @@ func (uc *ReconstructStateUseCase) Execute(ctx context.Context, req ReconstructR
 		return nil, fmt.Errorf("get deltas until step %d: %w", req.ToStep, err)
 	}
 
-	// Build base state (step 0)
-	state := &domain.SessionState{
-		SessionID: req.SessionID,
+	state := replayDeltas(req.SessionID, deltas)
+
+	return &ReconstructResponse{
+		State:      state,
+		Deltas:     deltas,
+		StepNow:    state.Step,
+		DeltaCount: len(deltas),
+	}, nil
+}
+
+// newBaseState returns the empty state a session starts from (step 0)
+func newBaseState(sessionID string) *domain.SessionState {
+	return &domain.SessionState{
+		SessionID: sessionID,
 		Current: domain.StateCurrent{
 			Data: domain.StateData{
 				Products: []domain.Product{},
@@ -60,64 +72,93 @@ func (uc *ReconstructStateUseCase) Execute(ctx context.Context, req ReconstructR
 		ViewStack: []domain.ViewSnapshot{},
 		Step:      0,
 	}
+}
 
-	// Apply each delta sequentially
-	for _, delta := range deltas {
+// replayDeltas applies deltas (ordered by step) to the base state.
+// A legacy rollback delta without payload is resolved by replaying
+// the deltas up to its to_step again.
+func replayDeltas(sessionID string, deltas []domain.Delta) *domain.SessionState {
+	state := newBaseState(sessionID)
+	for i, delta := range deltas {
+		if delta.DeltaType == domain.DeltaTypeRollback && delta.Payload == nil {
+			if toStep, ok := delta.RollbackTarget(); ok {
+				var earlier []domain.Delta
+				for _, d := range deltas[:i] {
+					if d.Step <= toStep {
+						earlier = append(earlier, d)
+					}
+				}
+				state = replayDeltas(sessionID, earlier)
+			}
+		}
 		state = applyDelta(state, delta)
 	}
-
-	return &ReconstructResponse{
-		State:      state,
-		Deltas:     deltas,
-		StepNow:    state.Step,
-		DeltaCount: len(deltas),
-	}, nil
+	return state
 }
 
-// applyDelta applies a single delta to the state
-// This is a simplified implementation - in production, this would
-// need to handle all delta types and paths properly
+// applyDelta applies a single delta to the state.
+// Deltas with a payload carry the zones they wrote, so the zones are
+// restored as they were. Legacy deltas (written before payloads) only
+// have ResultMeta, which is applied to the meta counts.
 func applyDelta(state *domain.SessionState, delta domain.Delta) *domain.SessionState {
 	state.Step = delta.Step
 
-	switch delta.DeltaType {
-	case domain.DeltaTypeAdd:
-		// For add deltas, update counts from result meta
-		state.Current.Meta.Count = delta.Result.Count
-		state.Current.Meta.Fields = delta.Result.Fields
-		if delta.Result.Aliases != nil {
-			for k, v := range delta.Result.Aliases {
-				state.Current.Meta.Aliases[k] = v
+	// A rollback restores the template even when there was none at the target step
+	if delta.Template != nil || (delta.DeltaType == domain.DeltaTypeRollback && delta.Payload != nil) {
+		state.Current.Template = delta.Template
+	}
+
+	if p := delta.Payload; p != nil {
+		if p.Data != nil {
+			state.Current.Data = *p.Data
+			// Same as the adapter: a query's data write drops the forward stack
+			if delta.Trigger == domain.TriggerUserQuery {
+				state.ForwardStack = nil
 			}
 		}
-
-	case domain.DeltaTypeUpdate:
-		// Update meta counts
-		state.Current.Meta.Count = delta.Result.Count
-		state.Current.Meta.Fields = delta.Result.Fields
-
-	case domain.DeltaTypePush:
-		// Push is handled via ViewStack operations
-		// The actual snapshot would be in delta.Template
-		if delta.Template != nil {
-			// ViewStack push recorded in delta
+		if p.Meta != nil {
+			state.Current.Meta = *p.Meta
+			if state.Current.Meta.Aliases == nil {
+				state.Current.Meta.Aliases = make(map[string]string)
+			}
 		}
-
-	case domain.DeltaTypePop:
-		// Pop is handled via ViewStack operations
-
-	case domain.DeltaTypeRollback:
-		// Rollback restores to a previous state
-		// The target step is in delta.Action.Params
-
-	case domain.DeltaTypeRemove:
-		// Remove data from state
+		if p.View != nil {
+			state.View = *p.View
+			state.ViewStack = append([]domain.ViewSnapshot{}, p.ViewStack...)
+			state.ForwardStack = append([]domain.ViewSnapshot(nil), p.ForwardStack...)
+		}
+		return state
 	}
 
-	// Apply template if present
-	if delta.Template != nil {
-		state.Current.Template = delta.Template
+	switch {
+	case strings.HasPrefix(delta.Path, "data"):
+		applyLegacyDataDelta(state, delta)
+	case delta.DeltaType == domain.DeltaTypeRemove && delta.Path == "template":
+		state.Current.Template = nil
 	}
+	// Legacy view deltas (push/pop) and rollbacks did not record the view,
+	// so there is nothing to restore from them.
 
 	return state
 }
+
+// applyLegacyDataDelta applies the counts of a data delta without payload
+func applyLegacyDataDelta(state *domain.SessionState, delta domain.Delta) {
+	switch delta.DeltaType {
+	case domain.DeltaTypeRemove:
+		state.Current.Data = domain.StateData{Products: []domain.Product{}, Services: []domain.Service{}}
+		state.Current.Meta.Count = 0
+		state.Current.Meta.Fields = []string{}
+
+	case domain.DeltaTypeAdd, domain.DeltaTypeUpdate:
+		// Empty results keep the previous data (tools only record the delta)
+		if delta.Result.Count == 0 {
+			return
+		}
+		state.Current.Meta.Count = delta.Result.Count
+		state.Current.Meta.Fields = delta.Result.Fields
+		for k, v := range delta.Result.Aliases {
+			state.Current.Meta.Aliases[k] = v
+		}
+	}
+}
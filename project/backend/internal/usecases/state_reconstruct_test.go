@@ -0,0 +1,166 @@
+package usecases_test
+
+import (
+	"context"
+	"testing"
+
+	"keepstar/internal/domain"
+	"keepstar/internal/presets"
+	"keepstar/internal/usecases"
+)
+
+// seedGridSession writes products (step 1) and a grid template (step 2) through zone-writes
+func seedGridSession(t *testing.T, statePort *mockStatePort) {
+	t.Helper()
+	ctx := context.Background()
+	statePort.CreateState(ctx, "session-1")
+
+	data := domain.StateData{Products: []domain.Product{
+		{ID: "product-1", Name: "Nike Air Max 90", Price: 12990, Currency: "$"},
+		{ID: "product-2", Name: "Nike Air Force 1", Price: 9990, Currency: "$"},
+	}}
+	meta := domain.StateMeta{Count: 2, ProductCount: 2, Fields: []string{"name", "price"}}
+	if _, err := statePort.UpdateData(ctx, "session-1", data, meta, domain.DeltaInfo{
+		Source: domain.SourceLLM, ActorID: "agent1", DeltaType: domain.DeltaTypeAdd, Path: "data.products",
+		Action: domain.Action{Type: domain.ActionSearch}, Result: domain.ResultMeta{Count: 2},
+	}); err != nil {
+		t.Fatalf("UpdateData failed: %v", err)
+	}
+	template := map[string]interface{}{"formation": "grid"}
+	if _, err := statePort.UpdateTemplate(ctx, "session-1", template, domain.DeltaInfo{
+		Source: domain.SourceLLM, ActorID: "agent2", DeltaType: domain.DeltaTypeUpdate, Path: "template",
+	}); err != nil {
+		t.Fatalf("UpdateTemplate failed: %v", err)
+	}
+}
+
+func TestReconstructStateUseCase_ReplaysPayloads(t *testing.T) {
+	ctx := context.Background()
+	statePort := newMockStatePort()
+	seedGridSession(t, statePort)
+
+	// Steps 3-4: expand product-1 (view push + detail template)
+	expandUC := usecases.NewExpandUseCase(statePort, presets.NewPresetRegistry())
+	if _, err := expandUC.Execute(ctx, usecases.ExpandRequest{
+		SessionID: "session-1", EntityType: domain.EntityTypeProduct, EntityID: "product-1",
+	}); err != nil {
+		t.Fatalf("Expand failed: %v", err)
+	}
+
+	reconstructUC := usecases.NewReconstructStateUseCase(statePort)
+
+	resp, err := reconstructUC.Execute(ctx, usecases.ReconstructRequest{SessionID: "session-1", ToStep: 4})
+	if err != nil {
+		t.Fatalf("Reconstruct failed: %v", err)
+	}
+	state := resp.State
+	if state.View.Mode != domain.ViewModeDetail || state.View.Focused == nil || state.View.Focused.ID != "product-1" {
+		t.Errorf("expected detail view of product-1 at step 4, got %+v", state.View)
+	}
+	if len(state.ViewStack) != 1 || state.ViewStack[0].Mode != domain.ViewModeGrid {
+		t.Errorf("expected the grid on the stack, got %+v", state.ViewStack)
+	}
+	if len(state.Current.Data.Products) != 2 {
+		t.Errorf("expected 2 products, got %d", len(state.Current.Data.Products))
+	}
+
+	resp, err = reconstructUC.Execute(ctx, usecases.ReconstructRequest{SessionID: "session-1", ToStep: 2})
+	if err != nil {
+		t.Fatalf("Reconstruct failed: %v", err)
+	}
+	state = resp.State
+	if state.View.Mode != domain.ViewModeGrid || len(state.ViewStack) != 0 {
+		t.Errorf("expected grid view with an empty stack at step 2, got %+v / %d", state.View, len(state.ViewStack))
+	}
+	if state.Current.Template["formation"] != "grid" {
+		t.Errorf("expected grid template at step 2, got %v", state.Current.Template)
+	}
+	if state.Current.Meta.ProductCount != 2 || state.Current.Data.Products[1].ID != "product-2" {
+		t.Errorf("expected data of step 1, got %+v", state.Current)
+	}
+}
+
+func TestRollbackUseCase_RestoresRealScreen(t *testing.T) {
+	ctx := context.Background()
+	statePort := newMockStatePort()
+	seedGridSession(t, statePort)
+
+	expandUC := usecases.NewExpandUseCase(statePort, presets.NewPresetRegistry())
+	if _, err := expandUC.Execute(ctx, usecases.ExpandRequest{
+		SessionID: "session-1", EntityType: domain.EntityTypeProduct, EntityID: "product-2",
+	}); err != nil {
+		t.Fatalf("Expand failed: %v", err)
+	}
+	statePort.state.Step = len(statePort.deltas)
+	statePort.state.ConversationHistory = []domain.LLMMessage{{Role: "user", Content: "кроссовки"}}
+
+	resp, err := usecases.NewRollbackUseCase(statePort).Execute(ctx, usecases.RollbackRequest{
+		SessionID: "session-1", ToStep: 2, Source: domain.SourceUser, ActorID: "user_back",
+	})
+	if err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+
+	state := statePort.state
+	if len(state.Current.Data.Products) != 2 || state.Current.Template["formation"] != "grid" {
+		t.Errorf("expected products and grid template restored, got %+v", state.Current)
+	}
+	if state.View.Mode != domain.ViewModeGrid || state.View.Focused != nil || len(state.ViewStack) != 0 {
+		t.Errorf("expected grid view restored, got %+v / %d", state.View, len(state.ViewStack))
+	}
+	if len(state.ConversationHistory) != 1 {
+		t.Error("conversation history should survive a rollback")
+	}
+	if p := resp.RollbackDelta.Payload; p == nil || p.Data == nil || p.View == nil {
+		t.Fatalf("rollback delta should carry the restored zones, got %+v", p)
+	}
+
+	// Replaying past the rollback gives the restored screen, not the detail view before it
+	replayed, err := usecases.NewReconstructStateUseCase(statePort).Execute(ctx, usecases.ReconstructRequest{
+		SessionID: "session-1", ToStep: resp.RollbackDelta.Step,
+	})
+	if err != nil {
+		t.Fatalf("Reconstruct failed: %v", err)
+	}
+	if replayed.State.View.Mode != domain.ViewModeGrid || len(replayed.State.Current.Data.Products) != 2 {
+		t.Errorf("expected grid with 2 products after the rollback step, got %+v", replayed.State)
+	}
+}
+
+func TestReconstructStateUseCase_LegacyDeltas(t *testing.T) {
+	ctx := context.Background()
+	statePort := newMockStatePort()
+	statePort.CreateState(ctx, "session-1")
+
+	// Deltas written before payloads existed: counts only
+	for _, d := range []domain.Delta{
+		{DeltaType: domain.DeltaTypeAdd, Path: "data.products", Result: domain.ResultMeta{Count: 50, Fields: []string{"name"}}},
+		{DeltaType: domain.DeltaTypeUpdate, Path: "data.products", Result: domain.ResultMeta{Count: 5, Fields: []string{"name"}}},
+		{DeltaType: domain.DeltaTypeAdd, Path: "data.products", Result: domain.ResultMeta{Count: 0}}, // empty search, data kept
+		{DeltaType: domain.DeltaTypeRollback, Path: "state", Action: domain.Action{
+			Type: domain.ActionRollback, Params: map[string]interface{}{"from_step": float64(3), "to_step": float64(1)},
+		}},
+	} {
+		d := d
+		statePort.AddDelta(ctx, "session-1", &d)
+	}
+
+	reconstructUC := usecases.NewReconstructStateUseCase(statePort)
+
+	resp, err := reconstructUC.Execute(ctx, usecases.ReconstructRequest{SessionID: "session-1", ToStep: 3})
+	if err != nil {
+		t.Fatalf("Reconstruct failed: %v", err)
+	}
+	if resp.State.Current.Meta.Count != 5 {
+		t.Errorf("an empty search should keep count 5, got %d", resp.State.Current.Meta.Count)
+	}
+
+	resp, err = reconstructUC.Execute(ctx, usecases.ReconstructRequest{SessionID: "session-1", ToStep: 4})
+	if err != nil {
+		t.Fatalf("Reconstruct failed: %v", err)
+	}
+	if resp.State.Current.Meta.Count != 50 || resp.StepNow != 4 {
+		t.Errorf("legacy rollback should replay to step 1 (count 50) at step 4, got count %d step %d",
+			resp.State.Current.Meta.Count, resp.StepNow)
+	}
+}
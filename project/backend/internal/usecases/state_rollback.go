@@ -29,6 +29,9 @@ type RollbackRequest struct {
 	ToStep    int               // Step to rollback to
 	Source    domain.DeltaSource // Who initiated (user/system)
 	ActorID   string            // Actor identifier (e.g., "user_back", "system_cleanup")
+	Trigger   domain.TriggerType // What initiated it (default: SYSTEM)
+	TurnID    string             // Turn ID for delta grouping
+	History   string             // undo/redo/goto when navigating the history (stored in Action.Params)
 }
 
 // RollbackResponse is the output from rollback
@@ -65,23 +68,51 @@ func (uc *RollbackUseCase) Execute(ctx context.Context, req RollbackRequest) (*R
 		return nil, fmt.Errorf("reconstruct state at step %d: %w", req.ToStep, err)
 	}
 
-	// Create rollback delta to record what was undone (step auto-assigned)
+	// The LLM conversation goes back with the screen: turns that started after the target
+	// step are cut, so the agents don't answer from queries the shopper undid. It is not
+	// part of the deltas: a later redo or goto forward restores the screen, not those messages.
+	deltas, err := uc.statePort.GetDeltas(ctx, req.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("get deltas: %w", err)
+	}
+	conversation := domain.ConversationUpToStep(currentState.ConversationHistory, deltas, req.ToStep)
+
+	// Create rollback delta to record what was undone (step auto-assigned).
+	// It carries the restored zones, so replays past it need no recursion.
+	restored := reconstructResp.State
+	trigger := req.Trigger
+	if trigger == "" {
+		trigger = domain.TriggerSystem
+	}
+	params := map[string]interface{}{
+		"from_step": currentState.Step,
+		"to_step":   req.ToStep,
+	}
+	if req.History != "" {
+		params["history"] = req.History
+	}
 	rollbackDelta := &domain.Delta{
-		Trigger:   domain.TriggerSystem,
+		TurnID:    req.TurnID,
+		Trigger:   trigger,
 		Source:    req.Source,
 		ActorID:   req.ActorID,
 		DeltaType: domain.DeltaTypeRollback,
 		Path:      "state",
 		Action: domain.Action{
-			Type: domain.ActionRollback,
-			Params: map[string]interface{}{
-				"from_step": currentState.Step,
-				"to_step":   req.ToStep,
-			},
+			Type:   domain.ActionRollback,
+			Params: params,
 		},
 		Result: domain.ResultMeta{
-			Count:  reconstructResp.State.Current.Meta.Count,
-			Fields: reconstructResp.State.Current.Meta.Fields,
+			Count:  restored.Current.Meta.Count,
+			Fields: restored.Current.Meta.Fields,
+		},
+		Template: restored.Current.Template,
+		Payload: &domain.DeltaPayload{
+			Data:         &restored.Current.Data,
+			Meta:         &restored.Current.Meta,
+			View:         &restored.View,
+			ViewStack:    restored.ViewStack,
+			ForwardStack: restored.ForwardStack,
 		},
 		CreatedAt: time.Now(),
 	}
@@ -92,10 +123,13 @@ func (uc *RollbackUseCase) Execute(ctx context.Context, req RollbackRequest) (*R
 	}
 
 	// Update the current state with reconstructed state
-	// Note: We keep the new step number (rollbackDelta.Step)
+	// Note: We keep the new step number (rollbackDelta.Step); the conversation
+	// history is the one cut at the target step
 	reconstructResp.State.ID = currentState.ID
 	reconstructResp.State.SessionID = req.SessionID
 	reconstructResp.State.Step = rollbackDelta.Step
+	reconstructResp.State.ConversationHistory = conversation
+	reconstructResp.State.CreatedAt = currentState.CreatedAt
 	reconstructResp.State.UpdatedAt = time.Now()
 
 	if err := uc.statePort.UpdateState(ctx, reconstructResp.State); err != nil {
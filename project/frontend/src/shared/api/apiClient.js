@@ -125,6 +125,11 @@ export async function sendPipelineQuery(sessionId, query, screenContext) {
 
   const response = await timedFetch('POST', '/pipeline', { body: JSON.stringify(body) });
 
+  // 429 quota exceeded: { sessionId, error: { code: 'RATE_LIMIT', ... }, formation } — render the fallback formation
+  if (response.status === 429) {
+    return response.json();
+  }
+
   if (!response.ok) {
     throw new Error(`API error: ${response.status}`);
   }
@@ -133,6 +138,64 @@ export async function sendPipelineQuery(sessionId, query, screenContext) {
   return response.json();
 }
 
+// Pipeline streaming API - same as sendPipelineQuery, but reports progress via SSE.
+// onEvent(type, data) is called for: started, tool_call, tool_result, skeleton, formation, spans, done, error.
+// Resolves with the `done` payload: { sessionId, agent1Ms, agent2Ms, totalMs }
+export async function streamPipelineQuery(sessionId, query, screenContext, onEvent) {
+  const body = { query };
+  if (sessionId) {
+    body.sessionId = sessionId;
+  }
+  if (screenContext) {
+    body.screenContext = screenContext;
+  }
+
+  const response = await timedFetch('POST', '/pipeline/stream', { body: JSON.stringify(body) });
+
+  if (!response.ok || !response.body) {
+    throw new Error(`API error: ${response.status}`);
+  }
+
+  const reader = response.body.getReader();
+  const decoder = new TextDecoder();
+  let buffer = '';
+  let done = null;
+
+  for (;;) {
+    const { value, done: streamDone } = await reader.read();
+    if (streamDone) break;
+    buffer += decoder.decode(value, { stream: true });
+
+    let sep;
+    while ((sep = buffer.indexOf('\n\n')) !== -1) {
+      const raw = buffer.slice(0, sep);
+      buffer = buffer.slice(sep + 2);
+
+      let type = 'message';
+      let data = '';
+      for (const line of raw.split('\n')) {
+        if (line.startsWith('event: ')) type = line.slice(7);
+        else if (line.startsWith('data: ')) data += line.slice(6);
+      }
+      const payload = data ? JSON.parse(data) : null;
+
+      if (type === 'error') {
+        // Quota exceeded: the fallback formation was already streamed — resolve like a normal answer
+        if (payload?.error?.code === 'RATE_LIMIT') {
+          return payload;
+        }
+        throw new Error(payload?.error?.message || payload?.error || 'Pipeline stream error');
+      }
+      if (type === 'done') {
+        done = payload;
+      }
+      onEvent?.(type, payload);
+    }
+  }
+
+  return done;
+}
+
 // Navigation API - expand widget to detail view
 export async function expandView(sessionId, entityType, entityId) {
   const response = await timedFetch('POST', '/navigation/expand', {
@@ -143,7 +206,24 @@ export async function expandView(sessionId, entityType, entityId) {
     throw new Error(`API error: ${response.status}`);
   }
 
-  // Response: { success, formation, viewMode, focused, stackSize, canGoBack }
+  // Response: { success, formation, viewMode, focused, stackSize, canGoBack, breadcrumbs }
+  return response.json();
+}
+
+// Navigation API - "more like this": grid of products similar to the entity
+// options: { cheaper, sameSkinType, differentBrand }
+export async function navigateSimilar(sessionId, entityType, entityId, options = {}) {
+  const { cheaper = false, sameSkinType = false, differentBrand = false } = options;
+  const response = await timedFetch('POST', '/navigation/similar', {
+    body: JSON.stringify({ sessionId, entityType, entityId, cheaper, sameSkinType, differentBrand }),
+  });
+
+  if (!response.ok) {
+    throw new Error(`API error: ${response.status}`);
+  }
+
+  // Response: { success, formation, viewMode, stackSize, canGoBack, breadcrumbs, found, message }
+  // found = 0: nothing similar, data and view unchanged (no formation)
   return response.json();
 }
 
@@ -157,6 +237,73 @@ export async function goBack(sessionId) {
     throw new Error(`API error: ${response.status}`);
   }
 
-  // Response: { success, formation, viewMode, focused, stackSize, canGoBack }
+  // Response: { success, formation, viewMode, focused, stackSize, canGoBack, canGoForward, breadcrumbs }
+  return response.json();
+}
+
+// Navigation API - go forward to the view left by back
+export async function goForward(sessionId) {
+  const response = await timedFetch('POST', '/navigation/forward', {
+    body: JSON.stringify({ sessionId }),
+  });
+
+  if (!response.ok) {
+    throw new Error(`API error: ${response.status}`);
+  }
+
+  // Response: { success, formation, viewMode, focused, stackSize, canGoBack, canGoForward, breadcrumbs }
+  return response.json();
+}
+
+// Session history API - undo/redo the last screen or jump to a step
+async function sessionHistory(sessionId, path) {
+  const response = await timedFetch('POST', `/session/${sessionId}/${path}`);
+
+  if (response.status === 409) {
+    return null; // Nothing to undo/redo
+  }
+
+  if (!response.ok) {
+    throw new Error(`API error: ${response.status}`);
+  }
+
+  // Response: { sessionId, formation, entities, step, canUndo, canRedo }
+  return response.json();
+}
+
+export function undoStep(sessionId) {
+  return sessionHistory(sessionId, 'undo');
+}
+
+export function redoStep(sessionId) {
+  return sessionHistory(sessionId, 'redo');
+}
+
+export function gotoStep(sessionId, step) {
+  return sessionHistory(sessionId, `goto?step=${step}`);
+}
+
+// Session fork API - branch the session at a step (current step when omitted)
+export async function forkSession(sessionId, step) {
+  const query = step === undefined ? '' : `?step=${step}`;
+  const response = await timedFetch('POST', `/session/${sessionId}/fork${query}`);
+
+  if (!response.ok) {
+    throw new Error(`API error: ${response.status}`);
+  }
+
+  // Response: { sessionId, formation, entities, parentId, forkStep }
+  return response.json();
+}
+
+// Session fork API - fork tree the session belongs to
+export async function getBranches(sessionId) {
+  const response = await timedFetch('GET', `/session/${sessionId}/branches`);
+
+  if (!response.ok) {
+    throw new Error(`API error: ${response.status}`);
+  }
+
+  // Response: { sessionId, root: { sessionId, parentId, forkStep, step, status, children }, count }
   return response.json();
 }
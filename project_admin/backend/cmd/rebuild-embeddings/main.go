@@ -11,7 +11,9 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 	pgvector "github.com/pgvector/pgvector-go"
+	"keepstar-admin/internal/adapters/localembed"
 	openaiAdapter "keepstar-admin/internal/adapters/openai"
+	"keepstar-admin/internal/ports"
 )
 
 // buildEmbeddingText creates a compact semantic text for vector embedding.
@@ -88,9 +90,17 @@ func main() {
 	if dbURL == "" {
 		log.Fatal("DATABASE_URL is required")
 	}
-	openaiKey := os.Getenv("OPENAI_API_KEY")
-	if openaiKey == "" {
-		log.Fatal("OPENAI_API_KEY is required")
+	// EMBEDDING_PROVIDER=local uses hashed n-gram vectors (no network); default is OpenAI
+	var embeddingClient ports.EmbeddingPort
+	if os.Getenv("EMBEDDING_PROVIDER") == "local" {
+		embeddingClient = localembed.NewHashEmbedder(384)
+		fmt.Println("Using local hashed n-gram embeddings")
+	} else {
+		openaiKey := os.Getenv("OPENAI_API_KEY")
+		if openaiKey == "" {
+			log.Fatal("OPENAI_API_KEY is required (or set EMBEDDING_PROVIDER=local)")
+		}
+		embeddingClient = openaiAdapter.NewEmbeddingClient(openaiKey, "", 384)
 	}
 
 	resetAll := len(os.Args) > 1 && os.Args[1] == "--reset"
@@ -104,8 +114,6 @@ func main() {
 	}
 	defer pool.Close()
 
-	embeddingClient := openaiAdapter.NewEmbeddingClient(openaiKey, "", 384)
-
 	// Step 1: Optionally reset embeddings for enriched products
 	if resetAll {
 		tag, err := pool.Exec(ctx, `UPDATE catalog.master_products SET embedding = NULL WHERE enrichment_version >= 2`)
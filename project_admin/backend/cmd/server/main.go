@@ -13,6 +13,7 @@ import (
 
 	"github.com/joho/godotenv"
 	anthropicAdapter "keepstar-admin/internal/adapters/anthropic"
+	"keepstar-admin/internal/adapters/localembed"
 	openaiAdapter "keepstar-admin/internal/adapters/openai"
 	"keepstar-admin/internal/adapters/postgres"
 	"keepstar-admin/internal/config"
@@ -70,7 +71,10 @@ func main() {
 
 	// Initialize embedding client
 	var embeddingClient ports.EmbeddingPort
-	if cfg.HasEmbeddings() {
+	if cfg.UsesLocalEmbeddings() {
+		embeddingClient = localembed.NewHashEmbedder(384)
+		log.Info("embedding_client_initialized", "provider", "local")
+	} else if cfg.HasEmbeddings() {
 		embeddingClient = openaiAdapter.NewEmbeddingClient(cfg.OpenAIAPIKey, cfg.EmbeddingModel, 384)
 		log.Info("embedding_client_initialized", "model", cfg.EmbeddingModel)
 	}
@@ -86,6 +90,8 @@ func main() {
 	authAdapter := postgres.NewAuthAdapter(dbClient)
 	catalogAdapter := postgres.NewCatalogAdapter(dbClient, log)
 	importAdapter := postgres.NewImportAdapter(dbClient)
+	synonymAdapter := postgres.NewSynonymAdapter(dbClient)
+	promptAdapter := postgres.NewPromptAdapter(dbClient)
 
 	// Initialize use cases
 	authUC := usecases.NewAuthUseCase(authAdapter, catalogAdapter, cfg.JWTSecret)
@@ -99,6 +105,8 @@ func main() {
 	importUC := usecases.NewImportUseCase(catalogAdapter, importAdapter, embeddingClient, log)
 	settingsUC := usecases.NewSettingsUseCase(catalogAdapter)
 	stockUC := usecases.NewStockUseCase(catalogAdapter)
+	synonymsUC := usecases.NewSynonymsUseCase(synonymAdapter)
+	promptsUC := usecases.NewPromptsUseCase(promptAdapter)
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authUC, log)
@@ -106,6 +114,8 @@ func main() {
 	importHandler := handlers.NewImportHandler(importUC, log)
 	settingsHandler := handlers.NewSettingsHandler(settingsUC, log)
 	stockHandler := handlers.NewStockHandler(stockUC, log)
+	synonymsHandler := handlers.NewSynonymsHandler(synonymsUC, log)
+	promptsHandler := handlers.NewPromptsHandler(promptsUC, log)
 
 	var enrichmentHandler *handlers.EnrichmentHandler
 	if enrichUC != nil {
@@ -170,6 +180,46 @@ func main() {
 		}
 	})
 	protected.HandleFunc("/admin/api/stock/bulk", stockHandler.HandleBulkUpdate)
+	protected.HandleFunc("/admin/api/synonyms", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			synonymsHandler.HandleList(w, r)
+		case http.MethodPost:
+			synonymsHandler.HandleCreate(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	protected.HandleFunc("/admin/api/synonyms/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			synonymsHandler.HandleUpdate(w, r)
+		case http.MethodDelete:
+			synonymsHandler.HandleDelete(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	protected.HandleFunc("/admin/api/prompts", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			promptsHandler.HandleList(w, r)
+		case http.MethodPost:
+			promptsHandler.HandleCreate(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+	protected.HandleFunc("/admin/api/prompts/", func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin/api/prompts/"), "/") == "stats":
+			promptsHandler.HandleStats(w, r)
+		case strings.HasSuffix(strings.TrimSuffix(r.URL.Path, "/"), "/activate"):
+			promptsHandler.HandleActivate(w, r)
+		default:
+			http.Error(w, "not found", http.StatusNotFound)
+		}
+	})
 	if enrichmentHandler != nil {
 		protected.HandleFunc("/admin/api/catalog/enrich", enrichmentHandler.HandleEnrich)
 		protected.HandleFunc("/admin/api/catalog/enrich-v2", enrichmentHandler.HandleEnrichV2)
@@ -186,6 +236,10 @@ func main() {
 	mux.Handle("/admin/api/catalog/imports", authMW(protected))
 	mux.Handle("/admin/api/settings", authMW(protected))
 	mux.Handle("/admin/api/stock/bulk", authMW(protected))
+	mux.Handle("/admin/api/synonyms", authMW(protected))
+	mux.Handle("/admin/api/synonyms/", authMW(protected))
+	mux.Handle("/admin/api/prompts", authMW(protected))
+	mux.Handle("/admin/api/prompts/", authMW(protected))
 	if enrichmentHandler != nil {
 		mux.Handle("/admin/api/catalog/enrich", authMW(protected))
 		mux.Handle("/admin/api/catalog/enrich-v2", authMW(protected))
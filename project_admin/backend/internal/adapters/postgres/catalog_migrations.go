@@ -203,6 +203,18 @@ func (c *Client) RunCatalogMigrations(ctx context.Context) error {
 		`ALTER TABLE catalog.master_products DROP COLUMN IF EXISTS attributes;`,
 		`ALTER TABLE catalog.master_products DROP COLUMN IF EXISTS inci_text;`,
 		`DROP INDEX IF EXISTS idx_catalog_mp_short_name;`,
+
+		// Search vocabulary: tenant synonyms for query expansion (read by the chat backend)
+		`CREATE TABLE IF NOT EXISTS catalog.tenant_synonyms (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			tenant_id UUID NOT NULL REFERENCES catalog.tenants(id) ON DELETE CASCADE,
+			term TEXT NOT NULL,
+			synonyms TEXT[] NOT NULL DEFAULT '{}',
+			bidirectional BOOLEAN NOT NULL DEFAULT TRUE,
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW()
+		);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_catalog_tenant_synonyms_term ON catalog.tenant_synonyms(tenant_id, lower(term));`,
 	}
 
 	for i, m := range migrations {
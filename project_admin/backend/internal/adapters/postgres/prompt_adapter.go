@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"keepstar-admin/internal/domain"
+)
+
+// PromptAdapter writes the tenant's versions of the chat prompt registry.
+// prompt_versions and pipeline_traces are created by the chat backend migrations;
+// rows are keyed by tenant slug, resolved here from the admin's tenant ID.
+type PromptAdapter struct {
+	client *Client
+}
+
+func NewPromptAdapter(client *Client) *PromptAdapter {
+	return &PromptAdapter{client: client}
+}
+
+const promptColumns = `id, name, version, tenant_slug, content, weight, active, note, created_at`
+
+const tenantSlugByID = `(SELECT slug FROM catalog.tenants WHERE id = $2)`
+
+func scanPromptVersion(row pgx.Row) (*domain.PromptVersion, error) {
+	var p domain.PromptVersion
+	err := row.Scan(&p.ID, &p.Name, &p.Version, &p.TenantSlug, &p.Content, &p.Weight, &p.Active, &p.Note, &p.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// ListPromptVersions returns the tenant's and the global versions of a prompt, newest first
+func (a *PromptAdapter) ListPromptVersions(ctx context.Context, tenantID string, name string) ([]domain.PromptVersion, error) {
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("db.admin.list_prompt_versions")
+		defer endSpan()
+	}
+	rows, err := a.client.pool.Query(ctx, `SELECT `+promptColumns+`
+		FROM prompt_versions
+		WHERE name = $1 AND tenant_slug IN (`+tenantSlugByID+`, '')
+		ORDER BY version DESC`, name, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list prompt versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []domain.PromptVersion{}
+	for rows.Next() {
+		p, err := scanPromptVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan prompt version: %w", err)
+		}
+		versions = append(versions, *p)
+	}
+	return versions, rows.Err()
+}
+
+// CreatePromptVersion inserts the next version of the prompt for the tenant
+func (a *PromptAdapter) CreatePromptVersion(ctx context.Context, tenantID string, in domain.PromptVersionInput) (*domain.PromptVersion, error) {
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("db.admin.create_prompt_version")
+		defer endSpan()
+	}
+	query := `INSERT INTO prompt_versions (name, version, tenant_slug, content, weight, active, note)
+		SELECT $1, COALESCE((SELECT MAX(version) FROM prompt_versions WHERE name = $1), 0) + 1, t.slug, $3, $4, $5, $6
+		FROM catalog.tenants t WHERE t.id = $2
+		RETURNING ` + promptColumns
+
+	p, err := scanPromptVersion(a.client.pool.QueryRow(ctx, query, in.Name, tenantID, in.Content, in.Weight, in.Active, in.Note))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrTenantNotFound
+		}
+		return nil, fmt.Errorf("create prompt version: %w", err)
+	}
+	return p, nil
+}
+
+// SetPromptActive updates active flag and weight of one of the tenant's versions
+func (a *PromptAdapter) SetPromptActive(ctx context.Context, tenantID string, promptID string, in domain.PromptActivation) error {
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("db.admin.set_prompt_active")
+		defer endSpan()
+	}
+	tag, err := a.client.pool.Exec(ctx, `UPDATE prompt_versions SET active = $3, weight = $4
+		WHERE id = $1 AND tenant_slug = `+tenantSlugByID, promptID, tenantID, in.Active, in.Weight)
+	if err != nil {
+		return fmt.Errorf("update prompt version: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPromptNotFound
+	}
+	return nil
+}
+
+// GetPromptStats groups the tenant's traces stamped with the prompt by version.
+// Zero-result rate counts Agent 1 turns that called a tool and left no products;
+// widget clicks are WIDGET_ACTION deltas (expand etc.) in the version's sessions.
+func (a *PromptAdapter) GetPromptStats(ctx context.Context, tenantID string, name string, since time.Time) ([]domain.PromptStats, error) {
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("db.admin.prompt_stats")
+		defer endSpan()
+	}
+	rows, err := a.client.pool.Query(ctx, `
+		WITH t AS (
+			SELECT session_id, total_ms, cost_usd,
+				(trace_data->'promptVersions'->>$1)::int AS version,
+				COALESCE(trace_data->'agent1'->>'toolName', '') AS tool,
+				COALESCE((trace_data->'stateAfterAgent1'->>'productCount')::int, 0) AS products
+			FROM pipeline_traces
+			WHERE timestamp >= $3 AND trace_data->'promptVersions' ? $1
+				AND trace_data->>'tenantSlug' = `+tenantSlugByID+`
+		),
+		turns AS (
+			SELECT version,
+				COUNT(DISTINCT session_id) AS sessions,
+				COUNT(*) AS turns,
+				AVG(total_ms) AS avg_ms,
+				AVG(cost_usd) AS avg_cost,
+				COALESCE(AVG(CASE WHEN products = 0 THEN 1.0 ELSE 0.0 END) FILTER (WHERE tool <> ''), 0) AS zero_rate
+			FROM t GROUP BY version
+		),
+		clicks AS (
+			SELECT s.version, COUNT(d.id) AS clicks
+			FROM (SELECT DISTINCT version, session_id FROM t) s
+			JOIN chat_session_deltas d ON d.session_id::text = s.session_id
+			WHERE d.trigger = 'WIDGET_ACTION' AND d.created_at >= $3
+			GROUP BY s.version
+		)
+		SELECT turns.version, turns.sessions, turns.turns, turns.avg_ms, turns.avg_cost, turns.zero_rate,
+			COALESCE(clicks.clicks, 0)
+		FROM turns LEFT JOIN clicks ON clicks.version = turns.version
+		ORDER BY turns.version
+	`, name, tenantID, since)
+	if err != nil {
+		return nil, fmt.Errorf("query prompt stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := []domain.PromptStats{}
+	for rows.Next() {
+		s := domain.PromptStats{Name: name}
+		if err := rows.Scan(&s.Version, &s.Sessions, &s.Turns, &s.AvgTotalMs, &s.AvgCostUSD, &s.ZeroResultRate, &s.WidgetClicks); err != nil {
+			return nil, fmt.Errorf("scan prompt stats: %w", err)
+		}
+		if s.Sessions > 0 {
+			s.ClicksPerSession = float64(s.WidgetClicks) / float64(s.Sessions)
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"keepstar-admin/internal/domain"
+)
+
+type SynonymAdapter struct {
+	client *Client
+}
+
+func NewSynonymAdapter(client *Client) *SynonymAdapter {
+	return &SynonymAdapter{client: client}
+}
+
+const synonymColumns = `id, tenant_id, term, synonyms, bidirectional, created_at, updated_at`
+
+func scanSynonym(row pgx.Row) (*domain.Synonym, error) {
+	var s domain.Synonym
+	err := row.Scan(&s.ID, &s.TenantID, &s.Term, &s.Synonyms, &s.Bidirectional, &s.CreatedAt, &s.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// isUniqueViolation reports a duplicate (tenant_id, lower(term))
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+func (a *SynonymAdapter) ListSynonyms(ctx context.Context, tenantID string) ([]domain.Synonym, error) {
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("db.admin.list_synonyms")
+		defer endSpan()
+	}
+	rows, err := a.client.pool.Query(ctx, `SELECT `+synonymColumns+`
+		FROM catalog.tenant_synonyms WHERE tenant_id = $1 ORDER BY lower(term)`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("list synonyms: %w", err)
+	}
+	defer rows.Close()
+
+	synonyms := []domain.Synonym{}
+	for rows.Next() {
+		s, err := scanSynonym(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan synonym: %w", err)
+		}
+		synonyms = append(synonyms, *s)
+	}
+	return synonyms, rows.Err()
+}
+
+func (a *SynonymAdapter) CreateSynonym(ctx context.Context, tenantID string, in domain.SynonymInput) (*domain.Synonym, error) {
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("db.admin.create_synonym")
+		defer endSpan()
+	}
+	query := `INSERT INTO catalog.tenant_synonyms (tenant_id, term, synonyms, bidirectional)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + synonymColumns
+
+	s, err := scanSynonym(a.client.pool.QueryRow(ctx, query, tenantID, in.Term, in.Synonyms, in.IsBidirectional()))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, domain.ErrSynonymExists
+		}
+		return nil, fmt.Errorf("create synonym: %w", err)
+	}
+	return s, nil
+}
+
+func (a *SynonymAdapter) UpdateSynonym(ctx context.Context, tenantID string, synonymID string, in domain.SynonymInput) (*domain.Synonym, error) {
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("db.admin.update_synonym")
+		defer endSpan()
+	}
+	query := `UPDATE catalog.tenant_synonyms
+		SET term = $3, synonyms = $4, bidirectional = $5, updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2
+		RETURNING ` + synonymColumns
+
+	s, err := scanSynonym(a.client.pool.QueryRow(ctx, query, synonymID, tenantID, in.Term, in.Synonyms, in.IsBidirectional()))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrSynonymNotFound
+		}
+		if isUniqueViolation(err) {
+			return nil, domain.ErrSynonymExists
+		}
+		return nil, fmt.Errorf("update synonym: %w", err)
+	}
+	return s, nil
+}
+
+func (a *SynonymAdapter) DeleteSynonym(ctx context.Context, tenantID string, synonymID string) error {
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("db.admin.delete_synonym")
+		defer endSpan()
+	}
+	tag, err := a.client.pool.Exec(ctx,
+		`DELETE FROM catalog.tenant_synonyms WHERE id = $1 AND tenant_id = $2`, synonymID, tenantID)
+	if err != nil {
+		return fmt.Errorf("delete synonym: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrSynonymNotFound
+	}
+	return nil
+}
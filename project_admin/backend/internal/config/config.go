@@ -9,6 +9,7 @@ type Config struct {
 	JWTSecret       string
 	OpenAIAPIKey    string
 	EmbeddingModel  string
+	EmbeddingProvider string // "openai" (default) or "local" (hashed n-grams, no network)
 	AnthropicAPIKey string
 	EnrichmentModel string
 	LogLevel        string
@@ -23,6 +24,7 @@ func Load() *Config {
 		JWTSecret:      getEnv("JWT_SECRET", "keepstar-admin-secret-change-me"),
 		OpenAIAPIKey:    getEnv("OPENAI_API_KEY", ""),
 		EmbeddingModel:  getEnv("EMBEDDING_MODEL", "text-embedding-3-small"),
+		EmbeddingProvider: getEnv("EMBEDDING_PROVIDER", "openai"),
 		AnthropicAPIKey: getEnv("ANTHROPIC_API_KEY", ""),
 		EnrichmentModel: getEnv("ENRICHMENT_MODEL", "claude-haiku-4-5-20251001"),
 		LogLevel:        getEnv("LOG_LEVEL", "info"),
@@ -31,7 +33,8 @@ func Load() *Config {
 }
 
 func (c *Config) HasDatabase() bool    { return c.DatabaseURL != "" }
-func (c *Config) HasEmbeddings() bool   { return c.OpenAIAPIKey != "" }
+func (c *Config) HasEmbeddings() bool   { return c.UsesLocalEmbeddings() || c.OpenAIAPIKey != "" }
+func (c *Config) UsesLocalEmbeddings() bool { return c.EmbeddingProvider == "local" }
 func (c *Config) HasEnrichment() bool   { return c.AnthropicAPIKey != "" }
 
 func getEnv(key, defaultValue string) string {
@@ -9,6 +9,9 @@ var (
 	ErrProductNotFound  = errors.New("product not found")
 	ErrCategoryNotFound = errors.New("category not found")
 	ErrImportNotFound   = errors.New("import job not found")
+	ErrSynonymNotFound  = errors.New("synonym not found")
+	ErrSynonymExists    = errors.New("synonym term already exists")
+	ErrPromptNotFound   = errors.New("prompt version not found")
 	ErrEmailExists      = errors.New("email already registered")
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrUnauthorized     = errors.New("unauthorized")
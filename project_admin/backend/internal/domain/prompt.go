@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+// Prompt names in the chat backend's prompt registry (one per LLM stage)
+const (
+	PromptAgent1 = "agent1" // Agent 1 system prompt (data retrieval)
+	PromptAgent2 = "agent2" // Agent 2 system prompt (widget composition)
+)
+
+// PromptVersion is one stored version of a named chat prompt.
+// TenantSlug "" is global (read-only here); active tenant versions override all
+// global ones for that tenant, and active versions of a tenant split traffic by Weight.
+type PromptVersion struct {
+	ID         string    `json:"id"`
+	Name       string    `json:"name"`
+	Version    int       `json:"version"`
+	TenantSlug string    `json:"tenantSlug,omitempty"`
+	Content    string    `json:"content"`
+	Weight     int       `json:"weight"`
+	Active     bool      `json:"active"`
+	Note       string    `json:"note,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// PromptVersionInput is the create request body
+type PromptVersionInput struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+	Weight  int    `json:"weight"`
+	Active  bool   `json:"active"`
+	Note    string `json:"note,omitempty"`
+}
+
+// PromptActivation is the activate request body: in/out of the experiment and traffic weight
+type PromptActivation struct {
+	Active bool `json:"active"`
+	Weight int  `json:"weight"`
+}
+
+// PromptStats compares one prompt version over the tenant's pipeline traces
+type PromptStats struct {
+	Name             string  `json:"name"`
+	Version          int     `json:"version"`
+	Sessions         int     `json:"sessions"`
+	Turns            int     `json:"turns"`
+	AvgTotalMs       float64 `json:"avgTotalMs"`
+	AvgCostUSD       float64 `json:"avgCostUsd"`
+	ZeroResultRate   float64 `json:"zeroResultRate"` // share of data-tool turns that left 0 products
+	WidgetClicks     int     `json:"widgetClicks"`   // WIDGET_ACTION deltas in these sessions
+	ClicksPerSession float64 `json:"clicksPerSession"`
+}
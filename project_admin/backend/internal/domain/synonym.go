@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// Synonym is a tenant search vocabulary rule.
+// One-way: the term in a query adds the synonyms ("патчи" → "hydrogel eye patches").
+// Two-way: any of term/synonyms adds all the others ("SPF" ↔ "солнцезащитный").
+type Synonym struct {
+	ID            string    `json:"id"`
+	TenantID      string    `json:"tenantId"`
+	Term          string    `json:"term"`
+	Synonyms      []string  `json:"synonyms"`
+	Bidirectional bool      `json:"bidirectional"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// SynonymInput is the create/update request body
+type SynonymInput struct {
+	Term          string   `json:"term"`
+	Synonyms      []string `json:"synonyms"`
+	Bidirectional *bool    `json:"bidirectional,omitempty"` // nil = two-way (default)
+}
+
+// IsBidirectional reports the rule direction, two-way when not given
+func (in SynonymInput) IsBidirectional() bool {
+	return in.Bidirectional == nil || *in.Bidirectional
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"keepstar-admin/internal/domain"
+	"keepstar-admin/internal/logger"
+	"keepstar-admin/internal/usecases"
+)
+
+type PromptsHandler struct {
+	prompts *usecases.PromptsUseCase
+	log     *logger.Logger
+}
+
+func NewPromptsHandler(prompts *usecases.PromptsUseCase, log *logger.Logger) *PromptsHandler {
+	return &PromptsHandler{prompts: prompts, log: log}
+}
+
+// HandleList handles GET /admin/api/prompts?name=agent1
+func (h *PromptsHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("handler.prompts_list")
+		defer endSpan()
+	}
+
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "GET only")
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	versions, err := h.prompts.List(ctx, TenantID(ctx), name)
+	if err != nil {
+		h.writePromptError(w, r, "prompts_list_failed", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"name": name, "versions": versions})
+}
+
+// HandleCreate handles POST /admin/api/prompts
+func (h *PromptsHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("handler.prompts_create")
+		defer endSpan()
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "POST only")
+		return
+	}
+
+	var in domain.PromptVersionInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	prompt, err := h.prompts.Create(ctx, TenantID(ctx), in)
+	if err != nil {
+		h.writePromptError(w, r, "prompt_create_failed", err)
+		return
+	}
+
+	h.log.FromContext(ctx).Info("prompt_version_created", "name", prompt.Name, "version", prompt.Version, "active", prompt.Active)
+	writeJSON(w, http.StatusCreated, prompt)
+}
+
+// HandleActivate handles POST /admin/api/prompts/{id}/activate
+func (h *PromptsHandler) HandleActivate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("handler.prompts_activate")
+		defer endSpan()
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "POST only")
+		return
+	}
+
+	promptID := strings.TrimSuffix(extractID(r.URL.Path, "/admin/api/prompts/"), "/activate")
+	if _, err := uuid.Parse(promptID); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid prompt id")
+		return
+	}
+
+	var in domain.PromptActivation
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	if err := h.prompts.Activate(ctx, TenantID(ctx), promptID, in); err != nil {
+		h.writePromptError(w, r, "prompt_activate_failed", err)
+		return
+	}
+
+	h.log.FromContext(ctx).Info("prompt_version_activated", "prompt_id", promptID, "active", in.Active, "weight", in.Weight)
+	writeJSON(w, http.StatusOK, map[string]any{"id": promptID, "active": in.Active, "weight": in.Weight})
+}
+
+// HandleStats handles GET /admin/api/prompts/stats?name=agent1&days=7
+func (h *PromptsHandler) HandleStats(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("handler.prompts_stats")
+		defer endSpan()
+	}
+
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "GET only")
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	days := 7
+	if v := r.URL.Query().Get("days"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			days = n
+		}
+	}
+
+	stats, err := h.prompts.Stats(ctx, TenantID(ctx), name, days)
+	if err != nil {
+		h.writePromptError(w, r, "prompt_stats_failed", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"name": name, "days": days, "versions": stats})
+}
+
+func (h *PromptsHandler) writePromptError(w http.ResponseWriter, r *http.Request, event string, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrInvalidPromptName), errors.Is(err, usecases.ErrInvalidPrompt):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrPromptNotFound), errors.Is(err, domain.ErrTenantNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	default:
+		h.log.FromContext(r.Context()).Error(event, "error", err)
+		writeError(w, http.StatusInternalServerError, "prompt operation failed")
+	}
+}
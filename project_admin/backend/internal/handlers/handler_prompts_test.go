@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"keepstar-admin/internal/domain"
+	"keepstar-admin/internal/logger"
+	"keepstar-admin/internal/usecases"
+)
+
+const testPromptID = "1c8d7b3f-2222-4333-8444-955556666777"
+
+// fakePromptPort keeps versions in memory; tenant-1 is "acme", "" rows are global
+type fakePromptPort struct {
+	versions []domain.PromptVersion
+}
+
+func (f *fakePromptPort) ListPromptVersions(ctx context.Context, tenantID string, name string) ([]domain.PromptVersion, error) {
+	out := []domain.PromptVersion{}
+	for _, p := range f.versions {
+		if p.Name == name && (p.TenantSlug == "" || p.TenantSlug == fakeTenantSlug(tenantID)) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakePromptPort) CreatePromptVersion(ctx context.Context, tenantID string, in domain.PromptVersionInput) (*domain.PromptVersion, error) {
+	slug := fakeTenantSlug(tenantID)
+	if slug == "" {
+		return nil, domain.ErrTenantNotFound
+	}
+	p := domain.PromptVersion{ID: testPromptID, Name: in.Name, Version: len(f.versions) + 1, TenantSlug: slug, Content: in.Content, Weight: in.Weight, Active: in.Active, CreatedAt: time.Now()}
+	f.versions = append(f.versions, p)
+	return &p, nil
+}
+
+func (f *fakePromptPort) SetPromptActive(ctx context.Context, tenantID string, promptID string, in domain.PromptActivation) error {
+	for i, p := range f.versions {
+		if p.ID == promptID && p.TenantSlug == fakeTenantSlug(tenantID) {
+			f.versions[i].Active, f.versions[i].Weight = in.Active, in.Weight
+			return nil
+		}
+	}
+	return domain.ErrPromptNotFound
+}
+
+func (f *fakePromptPort) GetPromptStats(ctx context.Context, tenantID string, name string, since time.Time) ([]domain.PromptStats, error) {
+	return []domain.PromptStats{{Name: name, Version: 1, Turns: 3}}, nil
+}
+
+func fakeTenantSlug(tenantID string) string {
+	if tenantID == "tenant-1" {
+		return "acme"
+	}
+	return ""
+}
+
+func newTestPromptsHandler() (*PromptsHandler, *fakePromptPort) {
+	port := &fakePromptPort{}
+	return NewPromptsHandler(usecases.NewPromptsUseCase(port), logger.New("error")), port
+}
+
+func TestPromptsHandler_CreateListActivate(t *testing.T) {
+	h, port := newTestPromptsHandler()
+	port.versions = append(port.versions, domain.PromptVersion{ID: "0d9e8c4a-3333-4444-8555-a66667777888", Name: "agent1", Version: 1, Content: "global", Active: true})
+
+	w := httptest.NewRecorder()
+	h.HandleCreate(w, synonymRequest(http.MethodPost, "/admin/api/prompts", `{"name":"agent1","content":"acme prompt","weight":50}`))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: want 201, got %d: %s", w.Code, w.Body)
+	}
+	var created domain.PromptVersion
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if created.TenantSlug != "acme" || created.Active {
+		t.Errorf("want an inactive version of the admin's tenant, got %+v", created)
+	}
+
+	w = httptest.NewRecorder()
+	h.HandleList(w, synonymRequest(http.MethodGet, "/admin/api/prompts?name=agent1", ""))
+	var list struct {
+		Versions []domain.PromptVersion `json:"versions"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || len(list.Versions) != 2 {
+		t.Errorf("list: want the tenant and global versions, got %d %+v", w.Code, list.Versions)
+	}
+
+	w = httptest.NewRecorder()
+	h.HandleActivate(w, synonymRequest(http.MethodPost, "/admin/api/prompts/"+testPromptID+"/activate", `{"active":true,"weight":100}`))
+	if w.Code != http.StatusOK || !port.versions[1].Active || port.versions[1].Weight != 100 {
+		t.Errorf("activate: want 200 and the version active, got %d %+v", w.Code, port.versions[1])
+	}
+
+	// Global versions are not the tenant's to switch
+	w = httptest.NewRecorder()
+	h.HandleActivate(w, synonymRequest(http.MethodPost, "/admin/api/prompts/"+port.versions[0].ID+"/activate", `{"active":false}`))
+	if w.Code != http.StatusNotFound || !port.versions[0].Active {
+		t.Errorf("activate global: want 404 and the version untouched, got %d", w.Code)
+	}
+}
+
+func TestPromptsHandler_Validation(t *testing.T) {
+	h, _ := newTestPromptsHandler()
+
+	for _, body := range []string{
+		`{"name":"agent3","content":"x"}`,
+		`{"name":"agent1","content":"  "}`,
+		`{"name":"agent2","content":"x","weight":-1}`,
+		`not json`,
+	} {
+		w := httptest.NewRecorder()
+		h.HandleCreate(w, synonymRequest(http.MethodPost, "/admin/api/prompts", body))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("create %s: want 400, got %d", body, w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	h.HandleList(w, synonymRequest(http.MethodGet, "/admin/api/prompts", ""))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("list without name: want 400, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.HandleActivate(w, synonymRequest(http.MethodPost, "/admin/api/prompts/not-a-uuid/activate", `{"active":true}`))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("activate malformed id: want 400, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.HandleStats(w, synonymRequest(http.MethodGet, "/admin/api/prompts/stats?name=agent2&days=3", ""))
+	if w.Code != http.StatusOK {
+		t.Errorf("stats: want 200, got %d", w.Code)
+	}
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"keepstar-admin/internal/domain"
+	"keepstar-admin/internal/logger"
+	"keepstar-admin/internal/usecases"
+)
+
+type SynonymsHandler struct {
+	synonyms *usecases.SynonymsUseCase
+	log      *logger.Logger
+}
+
+func NewSynonymsHandler(synonyms *usecases.SynonymsUseCase, log *logger.Logger) *SynonymsHandler {
+	return &SynonymsHandler{synonyms: synonyms, log: log}
+}
+
+func (h *SynonymsHandler) HandleList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("handler.synonyms_list")
+		defer endSpan()
+	}
+
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "GET only")
+		return
+	}
+
+	synonyms, err := h.synonyms.List(ctx, TenantID(ctx))
+	if err != nil {
+		h.log.FromContext(ctx).Error("synonyms_list_failed", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list synonyms")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"synonyms": synonyms})
+}
+
+func (h *SynonymsHandler) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("handler.synonyms_create")
+		defer endSpan()
+	}
+
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "POST only")
+		return
+	}
+
+	var in domain.SynonymInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	synonym, err := h.synonyms.Create(ctx, TenantID(ctx), in)
+	if err != nil {
+		h.writeSynonymError(w, r, "synonym_create_failed", err)
+		return
+	}
+
+	h.log.FromContext(ctx).Info("synonym_created", "term", synonym.Term, "synonyms", len(synonym.Synonyms))
+	writeJSON(w, http.StatusCreated, synonym)
+}
+
+func (h *SynonymsHandler) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("handler.synonyms_update")
+		defer endSpan()
+	}
+
+	if r.Method != http.MethodPut {
+		writeError(w, http.StatusMethodNotAllowed, "PUT only")
+		return
+	}
+
+	synonymID, ok := synonymIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	var in domain.SynonymInput
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+
+	synonym, err := h.synonyms.Update(ctx, TenantID(ctx), synonymID, in)
+	if err != nil {
+		h.writeSynonymError(w, r, "synonym_update_failed", err)
+		return
+	}
+
+	h.log.FromContext(ctx).Info("synonym_updated", "synonym_id", synonymID)
+	writeJSON(w, http.StatusOK, synonym)
+}
+
+func (h *SynonymsHandler) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if sc := domain.SpanFromContext(ctx); sc != nil {
+		endSpan := sc.Start("handler.synonyms_delete")
+		defer endSpan()
+	}
+
+	if r.Method != http.MethodDelete {
+		writeError(w, http.StatusMethodNotAllowed, "DELETE only")
+		return
+	}
+
+	synonymID, ok := synonymIDFromPath(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.synonyms.Delete(ctx, TenantID(ctx), synonymID); err != nil {
+		h.writeSynonymError(w, r, "synonym_delete_failed", err)
+		return
+	}
+
+	h.log.FromContext(ctx).Info("synonym_deleted", "synonym_id", synonymID)
+	writeJSON(w, http.StatusOK, map[string]bool{"ok": true})
+}
+
+// synonymIDFromPath extracts the rule ID and rejects anything that is not a UUID with 400
+func synonymIDFromPath(w http.ResponseWriter, r *http.Request) (string, bool) {
+	synonymID := extractID(r.URL.Path, "/admin/api/synonyms/")
+	if _, err := uuid.Parse(synonymID); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid synonym id")
+		return "", false
+	}
+	return synonymID, true
+}
+
+func (h *SynonymsHandler) writeSynonymError(w http.ResponseWriter, r *http.Request, event string, err error) {
+	switch {
+	case errors.Is(err, usecases.ErrInvalidSynonym):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrSynonymExists):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, domain.ErrSynonymNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	default:
+		h.log.FromContext(r.Context()).Error(event, "error", err)
+		writeError(w, http.StatusInternalServerError, "synonym operation failed")
+	}
+}
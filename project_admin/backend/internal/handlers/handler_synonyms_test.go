@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"keepstar-admin/internal/domain"
+	"keepstar-admin/internal/logger"
+	"keepstar-admin/internal/usecases"
+)
+
+const testSynonymID = "0b7c6a2e-1111-4222-8333-944445555666"
+
+// fakeSynonymPort keeps rules in memory, one tenant
+type fakeSynonymPort struct {
+	rules map[string]domain.Synonym
+}
+
+func (f *fakeSynonymPort) ListSynonyms(ctx context.Context, tenantID string) ([]domain.Synonym, error) {
+	var out []domain.Synonym
+	for _, s := range f.rules {
+		if s.TenantID == tenantID {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeSynonymPort) CreateSynonym(ctx context.Context, tenantID string, in domain.SynonymInput) (*domain.Synonym, error) {
+	for _, s := range f.rules {
+		if s.TenantID == tenantID && strings.EqualFold(s.Term, in.Term) {
+			return nil, domain.ErrSynonymExists
+		}
+	}
+	s := domain.Synonym{ID: testSynonymID, TenantID: tenantID, Term: in.Term, Synonyms: in.Synonyms, Bidirectional: in.IsBidirectional(), CreatedAt: time.Now()}
+	f.rules[s.ID] = s
+	return &s, nil
+}
+
+func (f *fakeSynonymPort) UpdateSynonym(ctx context.Context, tenantID string, synonymID string, in domain.SynonymInput) (*domain.Synonym, error) {
+	s, ok := f.rules[synonymID]
+	if !ok || s.TenantID != tenantID {
+		return nil, domain.ErrSynonymNotFound
+	}
+	s.Term, s.Synonyms, s.Bidirectional = in.Term, in.Synonyms, in.IsBidirectional()
+	f.rules[synonymID] = s
+	return &s, nil
+}
+
+func (f *fakeSynonymPort) DeleteSynonym(ctx context.Context, tenantID string, synonymID string) error {
+	s, ok := f.rules[synonymID]
+	if !ok || s.TenantID != tenantID {
+		return domain.ErrSynonymNotFound
+	}
+	delete(f.rules, synonymID)
+	return nil
+}
+
+func newTestSynonymsHandler() (*SynonymsHandler, *fakeSynonymPort) {
+	port := &fakeSynonymPort{rules: make(map[string]domain.Synonym)}
+	return NewSynonymsHandler(usecases.NewSynonymsUseCase(port), logger.New("error")), port
+}
+
+func synonymRequest(method, path, body string) *http.Request {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	return r.WithContext(context.WithValue(r.Context(), ctxTenantID, "tenant-1"))
+}
+
+func TestSynonymsHandler_CreateDefaultsToBidirectional(t *testing.T) {
+	h, _ := newTestSynonymsHandler()
+
+	w := httptest.NewRecorder()
+	h.HandleCreate(w, synonymRequest(http.MethodPost, "/admin/api/synonyms", `{"term":"SPF","synonyms":["солнцезащитный"," SPF ",""]}`))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("want 201, got %d: %s", w.Code, w.Body)
+	}
+	var got domain.Synonym
+	json.Unmarshal(w.Body.Bytes(), &got)
+	if !got.Bidirectional {
+		t.Error("omitted bidirectional must default to two-way")
+	}
+	if len(got.Synonyms) != 1 || got.Synonyms[0] != "солнцезащитный" {
+		t.Errorf("want trimmed synonyms without the term and empties, got %v", got.Synonyms)
+	}
+
+	w = httptest.NewRecorder()
+	h.HandleCreate(w, synonymRequest(http.MethodPost, "/admin/api/synonyms", `{"term":"spf","synonyms":["sunscreen"]}`))
+	if w.Code != http.StatusConflict {
+		t.Errorf("duplicate term: want 409, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.HandleCreate(w, synonymRequest(http.MethodPost, "/admin/api/synonyms", `{"term":"патчи","synonyms":[]}`))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("no synonyms: want 400, got %d", w.Code)
+	}
+}
+
+func TestSynonymsHandler_UpdateDeleteAndList(t *testing.T) {
+	h, port := newTestSynonymsHandler()
+	port.rules[testSynonymID] = domain.Synonym{ID: testSynonymID, TenantID: "tenant-1", Term: "патчи", Synonyms: []string{"patches"}, Bidirectional: true}
+
+	w := httptest.NewRecorder()
+	h.HandleUpdate(w, synonymRequest(http.MethodPut, "/admin/api/synonyms/"+testSynonymID, `{"term":"патчи","synonyms":["hydrogel eye patches"],"bidirectional":false}`))
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: want 200, got %d: %s", w.Code, w.Body)
+	}
+	if port.rules[testSynonymID].Bidirectional {
+		t.Error("explicit bidirectional=false must be kept")
+	}
+
+	w = httptest.NewRecorder()
+	h.HandleList(w, synonymRequest(http.MethodGet, "/admin/api/synonyms", ""))
+	var list struct {
+		Synonyms []domain.Synonym `json:"synonyms"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || len(list.Synonyms) != 1 || list.Synonyms[0].Synonyms[0] != "hydrogel eye patches" {
+		t.Errorf("list: got %d %+v", w.Code, list.Synonyms)
+	}
+
+	w = httptest.NewRecorder()
+	h.HandleDelete(w, synonymRequest(http.MethodDelete, "/admin/api/synonyms/"+testSynonymID, ""))
+	if w.Code != http.StatusOK || len(port.rules) != 0 {
+		t.Errorf("delete: want 200 and the rule gone, got %d, %d rules", w.Code, len(port.rules))
+	}
+
+	w = httptest.NewRecorder()
+	h.HandleDelete(w, synonymRequest(http.MethodDelete, "/admin/api/synonyms/"+testSynonymID, ""))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("delete unknown: want 404, got %d", w.Code)
+	}
+}
+
+func TestSynonymsHandler_MalformedIDIsBadRequest(t *testing.T) {
+	h, _ := newTestSynonymsHandler()
+
+	for _, id := range []string{"not-a-uuid", "1", "0b7c6a2e-1111-4222-8333"} {
+		w := httptest.NewRecorder()
+		h.HandleUpdate(w, synonymRequest(http.MethodPut, "/admin/api/synonyms/"+id, `{"term":"a","synonyms":["b"]}`))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("update %q: want 400, got %d", id, w.Code)
+		}
+		w = httptest.NewRecorder()
+		h.HandleDelete(w, synonymRequest(http.MethodDelete, "/admin/api/synonyms/"+id, ""))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("delete %q: want 400, got %d", id, w.Code)
+		}
+	}
+}
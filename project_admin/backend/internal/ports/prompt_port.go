@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"keepstar-admin/internal/domain"
+)
+
+// PromptPort manages the tenant's versions in the chat backend's prompt registry
+type PromptPort interface {
+	ListPromptVersions(ctx context.Context, tenantID string, name string) ([]domain.PromptVersion, error)
+	CreatePromptVersion(ctx context.Context, tenantID string, in domain.PromptVersionInput) (*domain.PromptVersion, error)
+	SetPromptActive(ctx context.Context, tenantID string, promptID string, in domain.PromptActivation) error
+	GetPromptStats(ctx context.Context, tenantID string, name string, since time.Time) ([]domain.PromptStats, error)
+}
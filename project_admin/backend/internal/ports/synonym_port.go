@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+
+	"keepstar-admin/internal/domain"
+)
+
+type SynonymPort interface {
+	ListSynonyms(ctx context.Context, tenantID string) ([]domain.Synonym, error)
+	CreateSynonym(ctx context.Context, tenantID string, in domain.SynonymInput) (*domain.Synonym, error)
+	UpdateSynonym(ctx context.Context, tenantID string, synonymID string, in domain.SynonymInput) (*domain.Synonym, error)
+	DeleteSynonym(ctx context.Context, tenantID string, synonymID string) error
+}
@@ -0,0 +1,64 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"keepstar-admin/internal/domain"
+	"keepstar-admin/internal/ports"
+)
+
+var (
+	// ErrInvalidPromptName is returned for a prompt name outside the registry
+	ErrInvalidPromptName = errors.New("name must be agent1 or agent2")
+	// ErrInvalidPrompt is returned for an empty prompt or a negative weight
+	ErrInvalidPrompt = errors.New("content is required and weight must be >= 0")
+)
+
+// PromptsUseCase manages the tenant's chat prompt versions. The chat backend picks up
+// changes when its active-prompt cache expires (30s).
+type PromptsUseCase struct {
+	prompts ports.PromptPort
+}
+
+func NewPromptsUseCase(prompts ports.PromptPort) *PromptsUseCase {
+	return &PromptsUseCase{prompts: prompts}
+}
+
+func (uc *PromptsUseCase) List(ctx context.Context, tenantID string, name string) ([]domain.PromptVersion, error) {
+	if !isPromptName(name) {
+		return nil, ErrInvalidPromptName
+	}
+	return uc.prompts.ListPromptVersions(ctx, tenantID, name)
+}
+
+func (uc *PromptsUseCase) Create(ctx context.Context, tenantID string, in domain.PromptVersionInput) (*domain.PromptVersion, error) {
+	if !isPromptName(in.Name) {
+		return nil, ErrInvalidPromptName
+	}
+	if strings.TrimSpace(in.Content) == "" || in.Weight < 0 {
+		return nil, ErrInvalidPrompt
+	}
+	return uc.prompts.CreatePromptVersion(ctx, tenantID, in)
+}
+
+func (uc *PromptsUseCase) Activate(ctx context.Context, tenantID string, promptID string, in domain.PromptActivation) error {
+	if in.Weight < 0 {
+		return ErrInvalidPrompt
+	}
+	return uc.prompts.SetPromptActive(ctx, tenantID, promptID, in)
+}
+
+// Stats compares the prompt's versions over the tenant's traces of the last days
+func (uc *PromptsUseCase) Stats(ctx context.Context, tenantID string, name string, days int) ([]domain.PromptStats, error) {
+	if !isPromptName(name) {
+		return nil, ErrInvalidPromptName
+	}
+	return uc.prompts.GetPromptStats(ctx, tenantID, name, time.Now().AddDate(0, 0, -days))
+}
+
+func isPromptName(name string) bool {
+	return name == domain.PromptAgent1 || name == domain.PromptAgent2
+}
@@ -0,0 +1,65 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"keepstar-admin/internal/domain"
+	"keepstar-admin/internal/ports"
+)
+
+// ErrInvalidSynonym is returned for a rule without a term or synonyms
+var ErrInvalidSynonym = errors.New("term and at least one synonym required")
+
+type SynonymsUseCase struct {
+	synonyms ports.SynonymPort
+}
+
+func NewSynonymsUseCase(synonyms ports.SynonymPort) *SynonymsUseCase {
+	return &SynonymsUseCase{synonyms: synonyms}
+}
+
+func (uc *SynonymsUseCase) List(ctx context.Context, tenantID string) ([]domain.Synonym, error) {
+	return uc.synonyms.ListSynonyms(ctx, tenantID)
+}
+
+func (uc *SynonymsUseCase) Create(ctx context.Context, tenantID string, in domain.SynonymInput) (*domain.Synonym, error) {
+	in, err := normalizeSynonymInput(in)
+	if err != nil {
+		return nil, err
+	}
+	return uc.synonyms.CreateSynonym(ctx, tenantID, in)
+}
+
+func (uc *SynonymsUseCase) Update(ctx context.Context, tenantID string, synonymID string, in domain.SynonymInput) (*domain.Synonym, error) {
+	in, err := normalizeSynonymInput(in)
+	if err != nil {
+		return nil, err
+	}
+	return uc.synonyms.UpdateSynonym(ctx, tenantID, synonymID, in)
+}
+
+func (uc *SynonymsUseCase) Delete(ctx context.Context, tenantID string, synonymID string) error {
+	return uc.synonyms.DeleteSynonym(ctx, tenantID, synonymID)
+}
+
+// normalizeSynonymInput trims phrases and drops empties, duplicates and the term itself
+func normalizeSynonymInput(in domain.SynonymInput) (domain.SynonymInput, error) {
+	in.Term = strings.TrimSpace(in.Term)
+	seen := map[string]bool{strings.ToLower(in.Term): true}
+	synonyms := make([]string, 0, len(in.Synonyms))
+	for _, s := range in.Synonyms {
+		s = strings.TrimSpace(s)
+		if s == "" || seen[strings.ToLower(s)] {
+			continue
+		}
+		seen[strings.ToLower(s)] = true
+		synonyms = append(synonyms, s)
+	}
+	in.Synonyms = synonyms
+	if in.Term == "" || len(in.Synonyms) == 0 {
+		return in, ErrInvalidSynonym
+	}
+	return in, nil
+}